@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"nexus-open/nexus"
 	"nexus-open/nexus/configuration"
 )
 
@@ -42,10 +44,9 @@ func (a *App) startup(ctx context.Context) {
 	// // Load initial configuration
 	config, err := configuration.LoadConfig("")
 	if err != nil {
-		fmt.Println("Error loading config:", err)
+		slog.Error("Error loading config", "error", err)
 		return
 	}
-	fmt.Println("Config loaded:", config)
 	a.config = config
 }
 
@@ -70,6 +71,9 @@ func (a *App) UpdateConfig(newConfig Config) error {
 	a.config.TextColor = newConfig.TextColor
 	a.config.ImagePaths = newConfig.ImagePaths
 
+	if err := nexus.GeocodeLocation(a.ctx, a.config); err != nil {
+		return err
+	}
 	return configuration.SaveConfig(a.config, "")
 }
 
@@ -81,7 +85,7 @@ func (a *App) UploadImage(originalName string, data []byte) (*ImageInfo, error)
 	r := bytes.NewReader(data)
 
 	// Save and resize the image
-	err := configuration.SaveImage(storedName, r)
+	storedName, err := configuration.SaveImage(storedName, r)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save image: %w", err)
 	}
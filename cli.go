@@ -0,0 +1,223 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"nexus-open/nexus"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/nexusdisplay"
+)
+
+// command is a subcommand of the CLI. Running Nexus is the default command, see
+// main.
+type command struct {
+	name    string // Words selecting the command, e.g. "config validate"
+	args    string // Arguments shown in the usage
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the commands in the order of the usage. run has no function, as
+// main runs Nexus when no other command is given.
+var commands = []command{
+	{"run", "[flags]", "run Nexus, the default command", nil},
+	{"preview", "--out frame.png [--page name]", "render a page with the configuration to a PNG file, without a device", previewCommand},
+	{"send-image", "file", "show an image on the device, which keeps it until something else is drawn", sendImageCommand},
+	{"devices list", "", "list the attached iCUE Nexus devices", devicesListCommand},
+	{"config init", "", "create the configuration file interactively", configInitCommand},
+	{"config validate", "", "check the configuration file and report its errors", configValidateCommand},
+	{"install-service", "", "install a systemd user unit or a Windows service running Nexus with the given flags", installServiceCommand},
+}
+
+// findCommand returns the command selected by the leading words of args and the
+// remaining arguments. It returns nil if args select no command.
+func findCommand(args []string) (*command, []string) {
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return &commands[i], args[len(words):]
+		}
+	}
+	return nil, args
+}
+
+// usage prints the commands and the flags.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\nCommands:\n", os.Args[0])
+	for _, command := range commands {
+		fmt.Fprintf(out, "  %-50s %s\n", strings.TrimSpace(command.name+" "+command.args), command.summary)
+	}
+	fmt.Fprintf(out, "\nFlags, also accepted after run:\n")
+	flag.PrintDefaults()
+}
+
+// commandFlags returns the flag set of the named command, printing its usage line
+// and flags on errors.
+func commandFlags(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] %s %s\n", os.Args[0], name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// previewCommand renders a page to a PNG file.
+func previewCommand(args []string) error {
+	flags := commandFlags("preview", "--out frame.png [--page name]")
+	out := flags.String("out", "", "PNG `file` the frame is written to")
+	page := flags.String("page", "", "page to render, the first page if empty")
+	flags.Parse(args)
+	if *out == "" || flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := configuration.LoadConfig("")
+	if err != nil {
+		return err
+	}
+
+	frame, err := nexus.RenderPreview(cfg, *page)
+	if err != nil {
+		return err
+	}
+	return writePNG(*out, frame)
+}
+
+// writePNG writes img to the PNG file at path.
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// sendImageCommand shows an image file on the device.
+func sendImageCommand(args []string) error {
+	flags := commandFlags("send-image", "file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	img, _, err := image.Decode(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", flags.Arg(0), err)
+	}
+
+	// A running Nexus holds the device and would draw over the image right away
+	lock, err := nexus.LockInstance(false)
+	if errors.Is(err, nexus.ErrAlreadyRunning) {
+		return fmt.Errorf("%v, push images to it with POST /api/frame instead", err)
+	} else if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	display, err := nexusdisplay.Open(nil)
+	if err != nil {
+		return err
+	}
+	defer display.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return display.WriteFrame(ctx, configuration.FitImage(img).Pix)
+}
+
+// devicesListCommand prints the attached devices.
+func devicesListCommand(args []string) error {
+	flags := commandFlags("devices list", "")
+	flags.Parse(args)
+
+	devices, err := nexusdisplay.List(nil)
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		fmt.Println("No iCUE Nexus attached")
+		return nil
+	}
+
+	for _, device := range devices {
+		fmt.Printf("Bus %03d Device %03d Serial %s\n", device.Bus, device.Address, cmp.Or(device.Serial, "unknown"))
+	}
+	return nil
+}
+
+// configInitCommand creates the configuration file interactively.
+func configInitCommand(args []string) error {
+	flags := commandFlags("config init", "")
+	flags.Parse(args)
+
+	return nexus.InitConfig(os.Stdin, os.Stdout)
+}
+
+// configValidateCommand loads the configuration file, which reports its errors and
+// warns about unknown keys.
+func configValidateCommand(args []string) error {
+	flags := commandFlags("config validate", "")
+	flags.Parse(args)
+
+	path, err := configuration.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	// Loading would write the defaults to a missing file
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	if _, err := configuration.LoadConfig(""); err != nil {
+		return err
+	}
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
+
+// installServiceCommand installs a service running Nexus with the flags given
+// before the command, the config path made absolute.
+func installServiceCommand(args []string) error {
+	flags := commandFlags("install-service", "")
+	flags.Parse(args)
+
+	var serviceArgs []string
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "service" {
+			return
+		}
+		value := f.Value.String()
+		if f.Name == "config" {
+			value, _ = filepath.Abs(value)
+		}
+		serviceArgs = append(serviceArgs, "--"+f.Name+"="+value)
+	})
+
+	return nexus.InstallService(os.Stdout, serviceArgs)
+}
@@ -1,6 +1,6 @@
 import './App.css'
 import { useState, useEffect } from 'react'
-import { UpdateConfig, GetConfig, GetImagePreview, UploadImage, DeleteImage } from '../wailsjs/go/main/App'
+import { UpdateConfig, GetConfig, GetImagePreview, UploadImage, DeleteImage, SubscribeConfig } from './backend'
 import { LocationSettings } from './components/Settings/LocationSettings'
 import { DisplaySettings } from './components/Settings/DisplaySettings'
 import { AppearanceSettings } from './components/Settings/AppearanceSettings'
@@ -39,6 +39,12 @@ function App() {
     loadConfig()
   }, [])
 
+  // Show settings changed elsewhere, e.g. by another client or in the file
+  useEffect(() => SubscribeConfig((savedConfig) => {
+    setConfig(savedConfig)
+    loadImagePreviews(savedConfig.image_paths ?? [])
+  }), [])
+
   const handleSubmit = async () => {
     try {
       await UpdateConfig(config)
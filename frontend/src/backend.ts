@@ -0,0 +1,146 @@
+// Backend calls used by the settings UI. Inside the Wails desktop app they go
+// through the Go bindings; when the UI is served by the daemon in a browser they
+// use the HTTP API on the same origin.
+import * as Wails from '../wailsjs/go/main/App'
+import { main } from '../wailsjs/go/models'
+
+const isWails = () => typeof window !== 'undefined' && (window as any).go?.main?.App !== undefined
+
+// The HTTP API returns the full configuration with Go field names
+type NexusConfig = { [key: string]: unknown }
+
+async function request(path: string, init?: RequestInit): Promise<Response> {
+  const response = await fetch(path, init)
+  if (!response.ok) {
+    const text = await response.text()
+    let message = text
+    try {
+      message = JSON.parse(text).error ?? text
+    } catch {
+      // Plain text error
+    }
+    throw new Error(`${response.status} ${message}`.trim())
+  }
+  return response
+}
+
+async function getNexusConfig(): Promise<NexusConfig> {
+  return (await request('/api/config')).json()
+}
+
+// patchNexusConfig changes only the given settings
+async function patchNexusConfig(patch: NexusConfig): Promise<void> {
+  await request('/api/config', {
+    method: 'PATCH',
+    headers: { 'Content-Type': 'application/merge-patch+json' },
+    body: JSON.stringify(patch),
+  })
+}
+
+export async function GetConfig(): Promise<main.Config> {
+  if (isWails()) {
+    return Wails.GetConfig()
+  }
+
+  return toConfig(await getNexusConfig())
+}
+
+// SubscribeConfig calls onChange with the configuration whenever it changes, e.g.
+// when it is saved by another client or the file is edited, until the returned
+// function is called. In a browser the daemon pushes the changes; the desktop app
+// reads the configuration again when its window regains focus.
+export function SubscribeConfig(onChange: (config: main.Config) => void): () => void {
+  if (isWails()) {
+    const reload = () => {
+      Wails.GetConfig().then(onChange, (error) => console.error('Error loading config:', error))
+    }
+    window.addEventListener('focus', reload)
+    return () => window.removeEventListener('focus', reload)
+  }
+
+  // EventSource reconnects by itself and the daemon sends the configuration on connect
+  const events = new EventSource('/api/config/events')
+  events.addEventListener('config', (event) => {
+    onChange(toConfig(JSON.parse((event as MessageEvent).data)))
+  })
+  return () => events.close()
+}
+
+function toConfig(config: NexusConfig): main.Config {
+  return main.Config.createFrom({
+    location: config.Location ?? '',
+    time_format: config.TimeFormat ?? '24h',
+    unit: config.Unit ?? 'metric',
+    background_color: config.BackgroundColor ?? '#000000',
+    text_color: config.TextColor ?? '#FFFFFF',
+    image_paths: config.ImagePaths ?? [],
+  })
+}
+
+export async function UpdateConfig(config: main.Config): Promise<void> {
+  if (isWails()) {
+    return Wails.UpdateConfig(config)
+  }
+
+  await patchNexusConfig({
+    Location: config.location,
+    TimeFormat: config.time_format,
+    Unit: config.unit,
+    BackgroundColor: config.background_color,
+    TextColor: config.text_color,
+    ImagePaths: config.image_paths,
+  })
+}
+
+export async function UploadImage(name: string, data: number[]): Promise<main.ImageInfo> {
+  if (isWails()) {
+    return Wails.UploadImage(name, data)
+  }
+
+  const form = new FormData()
+  form.append('image', new Blob([new Uint8Array(data)]), name)
+  const { filename } = await (await request('/api/images/upload', { method: 'POST', body: form })).json()
+
+  // Keep the image in the configuration like the desktop app does
+  const current = await getNexusConfig()
+  const paths = (current.ImagePaths as string[] | null) ?? []
+  if (!paths.includes(filename)) {
+    await patchNexusConfig({ ImagePaths: [...paths, filename] })
+  }
+
+  return main.ImageInfo.createFrom({ originalName: name, storedName: filename })
+}
+
+export async function DeleteImage(name: string): Promise<void> {
+  if (isWails()) {
+    return Wails.DeleteImage(name)
+  }
+
+  await request('/api/images/delete', {
+    method: 'POST',
+    body: new URLSearchParams({ filename: name }),
+  })
+
+  const current = await getNexusConfig()
+  const paths = (current.ImagePaths as string[] | null) ?? []
+  if (paths.includes(name)) {
+    await patchNexusConfig({ ImagePaths: paths.filter(path => path !== name) })
+  }
+}
+
+export async function GetImagePreview(name: string): Promise<string> {
+  if (isWails()) {
+    return Wails.GetImagePreview(name)
+  }
+
+  const blob = await (await request(`/api/images/${encodeURIComponent(name)}`)).blob()
+  const dataURL = await new Promise<string>((resolve, reject) => {
+    const reader = new FileReader()
+    reader.onload = () => resolve(reader.result as string)
+    reader.onerror = () => reject(reader.error)
+    reader.readAsDataURL(blob)
+  })
+
+  // Match the Wails binding, which returns bare base64
+  return dataURL.slice(dataURL.indexOf(',') + 1)
+}
@@ -1,40 +1,152 @@
 package main
 
 import (
-	_ "embed"
+	"context"
+	"embed"
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
 	"nexus-open/nexus"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/logging"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 )
 
-// //go:embed icon.ico
-// var iconBytes []byte
+// assets holds the built frontend, served at / by the API server. Build it with
+// "npm run build" in frontend/ before building the binary.
+//
+//go:embed all:frontend/dist
+var assets embed.FS
 
-// func onReady() {
-// 	systray.SetIcon(iconBytes)
-// 	systray.SetTitle("Nexus Open")
-// 	systray.SetTooltip("Nexus Open Status")
+// mainLog is the logger of the main program.
+var mainLog = logging.Component("main")
 
-// 	quitOpenNexus := systray.AddMenuItem("Quit", "Quit the app")
+func main() {
+	os.Exit(run())
+}
 
-// 	go func() {
-// 		<-quitOpenNexus.ClickedCh
-// 		systray.Quit()
-// 	}()
+// run runs the command given on the command line, or Nexus without one, and
+// returns the exit status once the deferred cleanup, such as closing the log file
+// and releasing the instance lock, is done.
+func run() int {
+	configPath := flag.String("config", "", "configuration file (.yaml, .yml, .json or .toml), overrides config.yaml, config.yml, config.json and config.toml in the user config directory, looked for in that order")
+	listen := flag.String("listen", "", "API listen address (host:port, or \"none\" to only serve api.socket), overrides api.listen in the config")
+	logLevel := flag.String("log-level", "info", "minimum level of log messages: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "format of log messages: text or json")
+	logOutput := flag.String("log-output", nexus.LogOutputStderr, "where log messages are written: stderr, file (nexus-open/logs/nexus.log in the user config directory) or both")
+	logMaxSize := flag.Int("log-max-size", 10, "size in `MB` at which the log file is rotated")
+	logMaxAge := flag.Duration("log-max-age", 7*24*time.Hour, "age at which rotated log files are removed")
+	virtual := flag.Bool("virtual", false, "run without a device, rendering frames only for the preview, status and metrics")
+	readOnly := flag.Bool("read-only", false, "reject API requests that change the configuration, the images or the display, like read_only in the config")
+	service := flag.Bool("service", false, "run as a service: a systemd service reporting readiness and watchdog keep-alives with sd_notify, or a Windows service; the display is blanked when stopped")
+	takeover := flag.Bool("takeover", false, "stop a Nexus that is already running and take over the device")
+	tray := flag.Bool("tray", false, "show a status icon in the system tray with quick settings, pause and quit (needs a build with -tags systray)")
+	debug := flag.Bool("debug", false, "serve CPU, heap and goroutine profiles at /debug/pprof/ and runtime statistics at /debug/runtime of the API, to attach to bug reports")
+	setSecret := flag.String("set-secret", "", "store standard input in the OS keyring as the secret `name`, usable as secret://name in the config, and exit")
+	flag.Usage = usage
+	flag.Parse()
 
-// 	nexus.StartNexus()
-// }
+	// Flags may also follow the run command
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "run" {
+		flag.CommandLine.Parse(args[1:])
+		if args = flag.Args(); len(args) > 0 {
+			mainLog.Error("Unexpected arguments after run", "args", args)
+			return 1
+		}
+	}
 
-// func onExit() {
-// 	nexus.StopNexus()
-// }
+	configuration.SetConfigPath(*configPath)
+	if err := nexus.SetLogLevel(*logLevel); err != nil {
+		mainLog.Error("Invalid log level", "error", err)
+		return 1
+	}
+	if err := nexus.SetLogFormat(*logFormat); err != nil {
+		mainLog.Error("Invalid log format", "error", err)
+		return 1
+	}
+	closeLog, err := nexus.SetLogOutput(*logOutput, int64(*logMaxSize)<<20, *logMaxAge)
+	if err != nil {
+		mainLog.Error("Failed to open the log output", "error", err)
+		return 1
+	}
+	defer closeLog()
 
-func main() {
-	nexus.StartNexus()
-	// systray.Run(onReady, onExit)
+	if len(args) > 0 {
+		command, commandArgs := findCommand(args)
+		if command == nil {
+			mainLog.Error("Unknown command, see "+os.Args[0]+" -h", "command", strings.Join(args, " "))
+			return 1
+		}
+		if err := command.run(commandArgs); err != nil {
+			mainLog.Error("Command failed", "command", strings.Join(args, " "), "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	if *setSecret != "" {
+		value, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			mainLog.Error("Failed to read the secret", "error", err)
+			return 1
+		}
+		if err := configuration.SetSecret(*setSecret, strings.TrimRight(string(value), "\r\n")); err != nil {
+			mainLog.Error("Failed to store the secret", "name", *setSecret, "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	nexus.SetAPIListen(*listen)
+	nexus.SetVirtual(*virtual)
+	nexus.SetReadOnly(*readOnly)
+	nexus.SetDebug(*debug)
+	if ui, err := fs.Sub(assets, "frontend/dist"); err == nil {
+		if _, err := fs.Stat(ui, "index.html"); err == nil {
+			nexus.SetWebUI(ui)
+		}
+	}
+	// Two instances would fight over the USB interface of the device
+	lock, err := nexus.LockInstance(*takeover)
+	if errors.Is(err, nexus.ErrAlreadyRunning) {
+		mainLog.Error("Stop the running Nexus first or start with --takeover", "error", err)
+		return 1
+	} else if err != nil {
+		mainLog.Error("Failed to lock the instance", "error", err)
+		return 1
+	}
+	defer lock.Release()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *tray {
+		if err := runTray(ctx); err != nil {
+			mainLog.Error("Tray icon failed", "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	runNexus := nexus.Run
+	if *service {
+		runNexus = nexus.RunService
+	}
+	if err := runNexus(ctx); err != nil {
+		mainLog.Error("Nexus stopped", "error", err)
+		return 1
+	}
 	// Create an instance of the app structure
 	// app := NewApp()
 
 	// Start Nexus in a separate goroutine
-	// nexus.StartNexus()
+	// nexus.Run(ctx)
 
 	// // Create application with options
 	// err := wails.Run(&options.App{
@@ -56,4 +168,6 @@ func main() {
 	// if err != nil {
 	// 	println("Error:", err.Error())
 	// }
+
+	return 0
 }
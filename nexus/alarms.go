@@ -0,0 +1,130 @@
+package nexus
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"nexus-open/nexus/configuration"
+)
+
+// Alarm settings
+const (
+	alarmCheckInterval = 5 * time.Second        // How often runAlarms checks for due alarms
+	alarmTimeout       = 10 * time.Minute       // Ringing stops by itself after this time
+	alarmFlashPeriod   = 500 * time.Millisecond // Half a flash cycle of the banner
+)
+
+// alarmManager rings the configured alarms when they are due, until they are
+// dismissed. It is safe for concurrent use.
+type alarmManager struct {
+	mu      sync.Mutex
+	ringing *configuration.Alarm // Ringing alarm, nil if none
+	since   time.Time            // When the ringing alarm went off
+	checked time.Time            // Minute last checked, so alarms ring once
+	redraw  func()               // Asks the display loop to show a ringing alarm right away
+}
+
+// runAlarms rings the alarms of the configuration when they are due until ctx is
+// done, checking every alarmCheckInterval.
+func (n *Nexus) runAlarms(ctx context.Context) {
+	ticker := time.NewTicker(alarmCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if cfg := n.configs.Get(); cfg != nil {
+			n.alarms.check(cfg, time.Now().In(n.renderer.location()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check rings the first alarm of cfg due at now, which is in the clock's time
+// zone, unless the minute of now was checked already.
+func (m *alarmManager) check(cfg *configuration.NexusConfig, now time.Time) {
+	minute := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, now.Location())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if minute.Equal(m.checked) {
+		return
+	}
+	m.checked = minute
+
+	for _, alarm := range cfg.Alarms {
+		if alarm.Due(now) {
+			scheduleLog.Info("Alarm", "time", alarm.Time, "label", alarm.Label)
+			m.ringing, m.since = &alarm, now
+			m.redraw()
+			return
+		}
+	}
+}
+
+// current returns the ringing alarm at now, false if none is ringing or it rang
+// for longer than alarmTimeout.
+func (m *alarmManager) current(now time.Time) (configuration.Alarm, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ringing == nil {
+		return configuration.Alarm{}, false
+	}
+	if now.Sub(m.since) > alarmTimeout {
+		m.ringing = nil
+		return configuration.Alarm{}, false
+	}
+	return *m.ringing, true
+}
+
+// dismiss stops the ringing alarm and reports whether one was ringing.
+func (m *alarmManager) dismiss() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ringing := m.ringing != nil
+	m.ringing = nil
+	return ringing
+}
+
+// DrawAlarm fills the display with the banner of a ringing alarm: its label and
+// time centered, flashing between the text color on background and the inverse.
+func (r *Renderer) DrawAlarm(alarm configuration.Alarm, background color.RGBA) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok {
+		return
+	}
+
+	label := alarm.Label
+	if label == "" {
+		label = r.translate("Alarm")
+	}
+	text := " " + label + "  " + alarm.Time
+
+	src := r.d.Src
+	if (time.Now().UnixNano()/int64(alarmFlashPeriod))%2 == 0 {
+		draw.Draw(dst, dst.Bounds(), src, image.Point{}, draw.Src)
+		r.d.Src = image.NewUniform(background)
+	} else {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: (fixed.I(width) - (&font.Drawer{Face: r.face}).MeasureString(text)) / 2,
+		Y: fixed.I(height/2 + 5),
+	}
+	r.d.DrawString(text)
+	r.d.Src = src
+}
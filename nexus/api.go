@@ -1,27 +1,214 @@
 package nexus
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"nexus-open/nexus/api"
 	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
 )
 
-// SetupAPI registers HTTP endpoints for:
+// API server timeouts
+const (
+	apiReadHeaderTimeout = 10 * time.Second
+	apiReadTimeout       = 30 * time.Second
+	apiWriteTimeout      = 30 * time.Second
+	apiIdleTimeout       = 2 * time.Minute
+)
+
+// SetAPIListen overrides the api.listen configuration with addr (host:port).
+// An empty addr restores the configured address. It must be called before Run.
+func SetAPIListen(addr string) {
+	defaultNexus.apiListenOverride.Store(addr)
+}
+
+// apiListenAddress returns the address the API server of n binds to.
+func (n *Nexus) apiListenAddress(cfg *configuration.NexusConfig) string {
+	if addr, _ := n.apiListenOverride.Load().(string); addr != "" {
+		return addr
+	}
+	if cfg == nil {
+		return configuration.APIListen
+	}
+	return cfg.API.ListenAddress()
+}
+
+// setupAPI registers HTTP endpoints for:
 //  1. reading/updating configuration   (/api/config)
 //  2. uploading images                 (/api/images/upload)
 //  3. listing images                   (/api/images)
 //  4. deleting images                  (/api/images/delete)
-func SetupAPI() {
+//  5. querying instrument history       (/api/history)
+//  6. streaming a live display preview (/api/preview/ws)
+//  7. showing notification banners     (/api/notify)
+//  8. pushing frames from external renderers (/api/frame)
+//  9. describing the API as OpenAPI    (/api/openapi.json)
+//  10. pausing and resuming display updates (/api/display/pause, /api/display/resume)
+//  11. dimming and switching the display off (/api/display/brightness, /api/display/power)
+//  12. reporting the device and render loop status (/api/status)
+//  13. listing and switching pages     (/api/pages, /api/page)
+//  14. listing and activating themes   (/api/themes, /api/themes/activate)
+//  15. exporting Prometheus metrics    (/metrics)
+//  16. streaming instrument readings as Server-Sent Events (/api/instruments/stream)
+//  17. downloading uploaded images     (/api/images/{filename})
+//  18. streaming configuration changes as Server-Sent Events (/api/config/events)
+//  19. rendering a frame with a candidate configuration (/api/config/preview)
+//  20. serving the web UI set with SetWebUI (/)
+//
+// Every /api endpoint is also served under /api/v1, where /api/v1 describes the API
+// version and /api/v1/config exchanges a versioned configuration document. All
+// responses carry the API version in the X-Nexus-API-Version header. In read-only
+// mode the endpoints changing the configuration, the images or what the display
+// shows answer 403, reading stays possible.
+//
+// It binds the configured listen address and unix socket and serves the API in the
+// background. An error is returned if an address cannot be bound, for example because
+// it is already in use. Requests carry a context derived from ctx, so streaming
+// connections end once ctx is done. Use stopAPI to shut the server down.
+func (n *Nexus) setupAPI(ctx context.Context) error {
+	mux := http.NewServeMux()
+
 	// Single config endpoint handles both GET (read) and POST (update)
-	http.HandleFunc("/api/config", configHandler)
-	http.HandleFunc("/api/images/upload", uploadImageHandler)
-	http.HandleFunc("/api/images", listImagesHandler)
-	http.HandleFunc("/api/images/delete", deleteImageHandler)
-	http.ListenAndServe(":1985", nil)
+	mux.HandleFunc("/api/config", n.readOnlyGuard(configHandler))
+	mux.HandleFunc("/api/config/events", n.configEventsHandler)
+	mux.HandleFunc("/api/config/preview", n.configPreviewHandler)
+	mux.HandleFunc("/api/images/upload", n.readOnlyGuard(n.uploadImageHandler))
+	mux.HandleFunc("/api/images", listImagesHandler)
+	mux.HandleFunc("/api/images/delete", n.readOnlyGuard(n.deleteImageHandler))
+	mux.HandleFunc("/api/history", n.historyHandler)
+	mux.HandleFunc("/api/preview/ws", n.previewHandler)
+	mux.HandleFunc("/api/notify", n.readOnlyGuard(n.notifyHandler))
+	mux.HandleFunc("/api/frame", n.readOnlyGuard(n.frameHandler))
+	mux.HandleFunc("/api/openapi.json", openAPIHandler)
+	mux.HandleFunc("/api/display/pause", n.readOnlyGuard(n.pauseHandler))
+	mux.HandleFunc("/api/display/resume", n.readOnlyGuard(n.resumeHandler))
+	mux.HandleFunc("/api/display/brightness", n.readOnlyGuard(n.brightnessHandler))
+	mux.HandleFunc("/api/display/power", n.readOnlyGuard(n.powerHandler))
+	mux.HandleFunc("/api/timer", n.readOnlyGuard(n.timerHandler))
+	mux.HandleFunc("/api/status", n.statusHandler)
+	mux.HandleFunc("/api/pages", n.pagesHandler)
+	mux.HandleFunc("/api/page", n.readOnlyGuard(n.pageHandler))
+	mux.HandleFunc("/api/themes", n.themesHandler)
+	mux.HandleFunc("/api/themes/activate", n.readOnlyGuard(n.activateThemeHandler))
+	mux.HandleFunc("/metrics", n.metricsHandler)
+	mux.HandleFunc("/healthz", n.healthHandler)
+	mux.HandleFunc("/api/instruments/stream", n.instrumentStreamHandler)
+	mux.HandleFunc("/api/images/", imageHandler)
+	if n.debugEndpoints.Load() {
+		mountDebug(mux)
+	}
+	mux.HandleFunc("/", n.webUIHandler)
+
+	// Versioned API
+	mux.HandleFunc(apiV1Prefix, versionHandler)
+	mux.HandleFunc(apiV1Prefix+"/config", n.readOnlyGuard(configV1Handler))
+	mux.Handle(apiV1Prefix+"/", apiV1Handler(mux))
+
+	cfg := n.configs.Get()
+
+	var tlsConfig *tls.Config
+	if cfg != nil {
+		var err error
+		if tlsConfig, err = apiTLSConfig(cfg.API.TLS); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{
+		Handler:           apiVersionMiddleware(corsMiddleware(mux, n.corsOrigins)),
+		ReadHeaderTimeout: apiReadHeaderTimeout,
+		ReadTimeout:       apiReadTimeout,
+		WriteTimeout:      apiWriteTimeout,
+		IdleTimeout:       apiIdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	var listeners []net.Listener
+
+	if addr := n.apiListenAddress(cfg); addr != configuration.APIListenDisabled {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %v", addr, err)
+		}
+
+		scheme := "http"
+		if tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+			scheme = "https"
+		}
+
+		apiLog.Info("API server listening", "url", fmt.Sprintf("%s://%s", scheme, listener.Addr()))
+		listeners = append(listeners, listener)
+	}
+
+	if cfg != nil && cfg.API.Socket != "" {
+		listener, err := listenUnixSocket(cfg.API.Socket)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("failed to listen on %s: %v", cfg.API.Socket, err)
+		}
+
+		apiLog.Info("API server listening", "socket", cfg.API.Socket)
+		listeners = append(listeners, listener)
+	}
+
+	if len(listeners) == 0 {
+		return fmt.Errorf("no API listener configured")
+	}
+
+	n.server = server
+
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+				apiLog.Error("API server stopped", "error", err)
+			}
+		}(listener)
+	}
+
+	return nil
+}
+
+// StopAPI gracefully shuts down the API server, waiting for active requests to
+// finish until ctx is done. Streaming connections end with the context passed to
+// setupAPI.
+func StopAPI(ctx context.Context) error {
+	return defaultNexus.stopAPI(ctx)
+}
+
+// stopAPI is StopAPI for n.
+func (n *Nexus) stopAPI(ctx context.Context) error {
+	if n.server == nil {
+		return nil
+	}
+	return n.server.Shutdown(ctx)
+}
+
+// openAPIHandler returns the OpenAPI document describing the API (GET).
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.Spec())
 }
 
-// configHandler handles reading (GET) and updating (POST) configuration.
+// configHandler handles reading (GET), replacing (POST) and partially updating
+// (PATCH) configuration. PATCH takes a JSON merge patch and answers with the
+// updated configuration.
 func configHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -34,44 +221,157 @@ func configHandler(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(config)
 	case http.MethodPost:
 		var newConfig configuration.NexusConfig
-		if err := json.NewDecoder(r.Body).Decode(&newConfig); err != nil {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&newConfig); err != nil {
 			http.Error(w, "Bad request", http.StatusBadRequest)
 			return
 		}
+		if err := newConfig.Validate(); err != nil {
+			writeValidationError(w, err)
+			return
+		}
+		if err := GeocodeLocation(r.Context(), &newConfig); err != nil {
+			writeValidationError(w, err)
+			return
+		}
 		if err := configuration.SaveConfig(&newConfig, ""); err != nil {
 			http.Error(w, "Failed to save config", http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte(`{"status":"ok"}`))
+	case http.MethodPatch:
+		var patch map[string]interface{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&patch); err != nil || patch == nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		newConfig := patchConfig(w, r, patch)
+		if newConfig == nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newConfig)
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
-// uploadImageHandler processes image uploads via multipart form data.
-func uploadImageHandler(w http.ResponseWriter, r *http.Request) {
+// patchConfig applies a JSON merge patch to the saved configuration, validates
+// and geocodes the result and saves it. On failure it answers the request and
+// returns nil.
+func patchConfig(w http.ResponseWriter, r *http.Request, patch map[string]interface{}) *configuration.NexusConfig {
+	config, err := configuration.LoadConfig("")
+	if err != nil {
+		http.Error(w, "Failed to read config", http.StatusInternalServerError)
+		return nil
+	}
+
+	newConfig, err := config.Patch(patch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+	if err := newConfig.Validate(); err != nil {
+		writeValidationError(w, err)
+		return nil
+	}
+	if err := GeocodeLocation(r.Context(), newConfig); err != nil {
+		writeValidationError(w, err)
+		return nil
+	}
+	if err := configuration.SaveConfig(newConfig, ""); err != nil {
+		http.Error(w, "Failed to save config", http.StatusInternalServerError)
+		return nil
+	}
+	return newConfig
+}
+
+// writeValidationError answers with 422 Unprocessable Entity and the invalid
+// fields of a configuration.
+func writeValidationError(w http.ResponseWriter, err error) {
+	response := api.ValidationError{Error: err.Error()}
+
+	var validationErr *configuration.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Error = "invalid configuration"
+		response.Fields = validationErr.Fields
+	}
+
+	var ambiguousErr *AmbiguousLocationError
+	if errors.As(err, &ambiguousErr) {
+		response.Fields = []configuration.FieldError{{Field: "location", Message: ambiguousErr.Error()}}
+		for _, candidate := range ambiguousErr.Candidates {
+			response.Candidates = append(response.Candidates, api.LocationCandidate{Name: candidate.Name, Lat: candidate.Lat, Lon: candidate.Lon})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(response)
+}
+
+// uploadImageHandler processes image uploads via multipart form data. The image is
+// streamed to disk, limited to configuration.MaxImageSize bytes and its type is
+// detected from its content. Errors are answered with a JSON api.ImageUploadError.
+func (n *Nexus) uploadImageHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	file, header, err := r.FormFile("image")
+	// Allow for the multipart headers around the image
+	r.Body = http.MaxBytesReader(w, r.Body, configuration.MaxImageSize+64*1024)
 
+	reader, err := r.MultipartReader()
 	if err != nil {
-		http.Error(w, "Failed to read file form field", http.StatusBadRequest)
+		writeImageUploadError(w, http.StatusBadRequest, "", "Expected a multipart form")
 		return
 	}
-	defer file.Close()
 
-	err = configuration.SaveImage(header.Filename, file)
-	if err != nil {
-		http.Error(w, "Failed to save image", http.StatusInternalServerError)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeImageUploadError(w, http.StatusRequestEntityTooLarge, "", configuration.ErrImageTooLarge.Error())
+				return
+			}
+			writeImageUploadError(w, http.StatusBadRequest, "", "Missing image form field")
+			return
+		}
+		if part.FormName() != "image" {
+			part.Close()
+			continue
+		}
+
+		filename, err := configuration.SaveImage(part.FileName(), part)
+		part.Close()
+
+		switch {
+		case errors.Is(err, configuration.ErrImageTooLarge):
+			writeImageUploadError(w, http.StatusRequestEntityTooLarge, part.FileName(), err.Error())
+		case errors.Is(err, configuration.ErrUnsupportedImage):
+			writeImageUploadError(w, http.StatusUnsupportedMediaType, part.FileName(), err.Error())
+		case errors.Is(err, configuration.ErrInvalidImage):
+			writeImageUploadError(w, http.StatusBadRequest, part.FileName(), err.Error())
+		case err != nil:
+			apiLog.Error("Failed to save image", "file", part.FileName(), "error", err)
+			writeImageUploadError(w, http.StatusInternalServerError, part.FileName(), "Failed to save image")
+		default:
+			// The upload may replace the shown background
+			n.invalidateRenderers()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(api.ImageUploadResponse{Status: api.StatusOK.Status, Filename: filename})
+		}
 		return
 	}
+}
 
+// writeImageUploadError answers an image upload with status and a JSON error.
+func writeImageUploadError(w http.ResponseWriter, status int, filename, message string) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"ok"}`))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.ImageUploadError{Error: message, Filename: filename})
 }
 
 // listImagesHandler returns a list of available images (GET).
@@ -91,8 +391,31 @@ func listImagesHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(images)
 }
 
+// imageHandler returns an uploaded image (GET /api/images/{filename}).
+func imageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filename := strings.TrimPrefix(r.URL.Path, "/api/images/")
+	if filename == "" || filename != filepath.Base(filename) || strings.HasPrefix(filename, ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := configuration.ReadImage(filename)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(data))
+	w.Write(data)
+}
+
 // deleteImageHandler removes an image from the server (POST).
-func deleteImageHandler(w http.ResponseWriter, r *http.Request) {
+func (n *Nexus) deleteImageHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -109,7 +432,45 @@ func deleteImageHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to delete image", http.StatusInternalServerError)
 		return
 	}
+	n.invalidateRenderers()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"status":"ok"}`))
 }
+
+// historyHandler returns recorded instrument readings (GET).
+//
+// Query parameters:
+//   - instrument: name of the instrument; if omitted, the recorded instrument names are listed
+//   - since: Go duration to look back from now (default: the full retention window)
+func (n *Nexus) historyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("instrument")
+	if name == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(n.history.Names())
+		return
+	}
+
+	since := historyRetention
+	if value := r.URL.Query().Get("since"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			http.Error(w, "Invalid since duration", http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+
+	readings := n.history.Since(name, since)
+	if readings == nil {
+		readings = []instruments.Reading{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readings)
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of the API described by Spec.
+const Version = "1.0.0"
+
+// Document is an OpenAPI 3.0 document, limited to the parts the API uses.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem is a single operation on a path.
+type PathItem struct {
+	Summary     string               `json:"summary"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*ParameterSpec     `json:"parameters,omitempty"`
+	RequestBody *RequestBodySpec     `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// ParameterSpec is a query or header parameter of an operation.
+type ParameterSpec struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBodySpec is the body accepted by an operation.
+type RequestBodySpec struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a possible response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema as used by OpenAPI 3.0.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// Spec returns the OpenAPI document of the HTTP API. Schemas are generated from the
+// Go types the handlers encode and decode, so the document always matches the server.
+func Spec() *Document {
+	g := &schemaGenerator{schemas: make(map[string]*Schema)}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Nexus Open API",
+			Description: "Control and configure the Corsair iCUE Nexus display. Every /api endpoint is also served under /api/v1.",
+			Version:     Version,
+		},
+		Paths:      make(map[string]map[string]*PathItem),
+		Components: Components{Schemas: g.schemas},
+	}
+
+	for _, op := range Operations {
+		item := &PathItem{
+			Summary:     op.Summary,
+			Description: op.Description,
+			OperationID: op.ID,
+			Tags:        []string{op.Tag},
+			Responses:   make(map[string]*Response),
+		}
+
+		for _, p := range op.Parameters {
+			item.Parameters = append(item.Parameters, &ParameterSpec{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Description,
+				Required:    p.Required,
+				Schema:      g.schema(reflect.TypeOf(p.Type), p.Enum),
+			})
+		}
+
+		if op.Request != nil {
+			item.RequestBody = &RequestBodySpec{Required: true, Content: g.content(op.Request)}
+		}
+
+		for status, body := range op.Responses {
+			response := &Response{Description: http.StatusText(status)}
+			if body.Description != "" {
+				response.Description = body.Description
+			}
+			if body.Type != nil {
+				response.Content = g.content(&body)
+			}
+			item.Responses[strconv.Itoa(status)] = response
+		}
+
+		if doc.Paths[op.Path] == nil {
+			doc.Paths[op.Path] = make(map[string]*PathItem)
+		}
+		doc.Paths[op.Path][strings.ToLower(op.Method)] = item
+	}
+
+	return doc
+}
+
+// schemaGenerator converts Go types into schemas, collecting named struct types
+// as components.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+// content returns the media types of a body.
+func (g *schemaGenerator) content(body *Body) map[string]*MediaType {
+	contentTypes := body.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+
+	content := make(map[string]*MediaType, len(contentTypes))
+	for _, contentType := range contentTypes {
+		content[contentType] = &MediaType{Schema: g.schema(reflect.TypeOf(body.Type), nil)}
+	}
+	return content
+}
+
+// schema returns the schema of t, following encoding/json rules for field names.
+// Named structs are added to the components and referenced.
+func (g *schemaGenerator) schema(t reflect.Type, enum []string) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case reflect.TypeOf(File(nil)):
+		return &Schema{Type: "string", Format: "binary"}
+	case reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem(), enum)
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string", Enum: enum}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), enum)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), nil)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces hold any JSON value
+		return &Schema{}
+	}
+}
+
+// structSchema returns the object schema of a struct type.
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Untagged embedded structs are flattened like encoding/json does
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(field.Type)
+			for key, value := range embedded.Properties {
+				s.Properties[key] = value
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = g.schema(field.Type, nil)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	sort.Strings(s.Required)
+	return s
+}
@@ -0,0 +1,533 @@
+package api
+
+import (
+	"net/http"
+
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+)
+
+// Operation describes one endpoint of the HTTP API.
+type Operation struct {
+	ID          string // Unique operation id, e.g. "getConfig"
+	Method      string
+	Path        string
+	Tag         string // Group of the operation, e.g. "config" or "images"
+	Summary     string
+	Description string
+	Parameters  []Parameter
+	Request     *Body        // Request body, nil if none
+	Responses   map[int]Body // Keyed by HTTP status code
+}
+
+// Parameter is a query or header parameter.
+type Parameter struct {
+	Name        string
+	In          string // "query" or "header"
+	Description string
+	Required    bool
+	Type        interface{} // Zero value of the Go type of the parameter
+	Enum        []string
+}
+
+// Body is a request or response body.
+type Body struct {
+	Description  string
+	Type         interface{} // Zero value of the Go type of the body, nil for none
+	ContentTypes []string    // Defaults to application/json
+}
+
+// errorBody is the plain text body of error responses.
+func errorBody(description string) Body {
+	return Body{Description: description, Type: "", ContentTypes: []string{"text/plain"}}
+}
+
+// frameTokenParameter is the header holding the lock of an external renderer.
+var frameTokenParameter = Parameter{
+	Name:        "X-Frame-Token",
+	In:          "header",
+	Description: "Lock token returned by the first frame",
+	Type:        "",
+}
+
+// Operations lists every endpoint of the HTTP API in the order they are documented.
+var Operations = []Operation{
+	{
+		ID:      "getConfig",
+		Method:  http.MethodGet,
+		Path:    "/api/config",
+		Tag:     "config",
+		Summary: "Read the configuration",
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: configuration.NexusConfig{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be read"),
+		},
+	},
+	{
+		ID:          "updateConfig",
+		Method:      http.MethodPost,
+		Path:        "/api/config",
+		Tag:         "config",
+		Summary:     "Replace the configuration",
+		Description: "The configuration is validated, saved and applied within a second.",
+		Request:     &Body{Type: configuration.NexusConfig{}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: Status{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The body is not a JSON configuration"),
+			http.StatusUnprocessableEntity: {Description: "The configuration has invalid values or an unknown or ambiguous location", Type: ValidationError{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be saved"),
+		},
+	},
+	{
+		ID:          "patchConfig",
+		Method:      http.MethodPatch,
+		Path:        "/api/config",
+		Tag:         "config",
+		Summary:     "Update part of the configuration",
+		Description: "The body is a JSON merge patch (RFC 7396): objects are merged, null resets a key and other values replace it. Keys may be Go field names or configuration file keys. The result is validated and saved.",
+		Request:     &Body{Type: map[string]interface{}{}, ContentTypes: []string{"application/merge-patch+json", "application/json"}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Description: "The updated configuration", Type: configuration.NexusConfig{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The body is not a JSON object or has unknown keys"),
+			http.StatusUnprocessableEntity: {Description: "The updated configuration has invalid values or an unknown or ambiguous location", Type: ValidationError{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be saved"),
+		},
+	},
+	{
+		ID:          "streamConfig",
+		Method:      http.MethodGet,
+		Path:        "/api/config/events",
+		Tag:         "config",
+		Summary:     "Stream configuration changes",
+		Description: "Server-Sent Events stream. Each event is named \"config\" and its data is the configuration as JSON. The configuration is sent on connect and again whenever the configuration file is reloaded with changes.",
+		Responses: map[int]Body{
+			http.StatusOK: {Description: "Event stream of configurations", Type: configuration.NexusConfig{}, ContentTypes: []string{"text/event-stream"}},
+		},
+	},
+	{
+		ID:          "previewConfig",
+		Method:      http.MethodPost,
+		Path:        "/api/config/preview",
+		Tag:         "config",
+		Summary:     "Render a frame with a candidate configuration",
+		Description: "Settings missing from the body keep their current values. Nothing is saved and the device keeps showing the current configuration. The latest instrument readings are drawn, the weather in the current unit.",
+		Parameters: []Parameter{
+			{Name: "page", In: "query", Description: "Page to render (default: the active page)", Type: ""},
+		},
+		Request: &Body{Type: configuration.NexusConfig{}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Description: "The rendered frame", Type: File{}, ContentTypes: []string{"image/png"}},
+			http.StatusBadRequest:          errorBody("The body is not a configuration"),
+			http.StatusUnprocessableEntity: {Description: "The configuration has invalid values", Type: ValidationError{}},
+			http.StatusServiceUnavailable:  errorBody("No frame could be rendered"),
+		},
+	},
+	{
+		ID:      "getVersion",
+		Method:  http.MethodGet,
+		Path:    "/api/v1",
+		Tag:     "meta",
+		Summary: "Describe the API version and supported operations",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: VersionInfo{}},
+		},
+	},
+	{
+		ID:      "getConfigV1",
+		Method:  http.MethodGet,
+		Path:    "/api/v1/config",
+		Tag:     "config",
+		Summary: "Read the configuration as a versioned document",
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: ConfigDocument{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be read"),
+		},
+	},
+	{
+		ID:          "updateConfigV1",
+		Method:      http.MethodPost,
+		Path:        "/api/v1/config",
+		Tag:         "config",
+		Summary:     "Replace the configuration with a versioned document",
+		Description: "Keys are matched ignoring case and underscores, so the Go field names of /api/config are accepted as well. Unknown keys are rejected.",
+		Request:     &Body{Type: ConfigDocument{}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: ConfigDocument{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The document is malformed, has unknown keys or a newer version"),
+			http.StatusUnprocessableEntity: {Description: "The configuration has invalid values or an unknown or ambiguous location", Type: ValidationError{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be saved"),
+		},
+	},
+	{
+		ID:          "patchConfigV1",
+		Method:      http.MethodPatch,
+		Path:        "/api/v1/config",
+		Tag:         "config",
+		Summary:     "Update part of the configuration with a versioned document",
+		Description: "The config of the document is a JSON merge patch (RFC 7396) applied to the current configuration.",
+		Request:     &Body{Type: ConfigDocument{}, ContentTypes: []string{"application/merge-patch+json", "application/json"}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: ConfigDocument{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The document is malformed, has unknown keys or a newer version"),
+			http.StatusUnprocessableEntity: {Description: "The updated configuration has invalid values or an unknown or ambiguous location", Type: ValidationError{}},
+			http.StatusInternalServerError: errorBody("The configuration could not be saved"),
+		},
+	},
+	{
+		ID:      "listImages",
+		Method:  http.MethodGet,
+		Path:    "/api/images",
+		Tag:     "images",
+		Summary: "List uploaded images",
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: []string{}},
+			http.StatusInternalServerError: errorBody("The images could not be read"),
+		},
+	},
+	{
+		ID:          "uploadImage",
+		Method:      http.MethodPost,
+		Path:        "/api/images/upload",
+		Tag:         "images",
+		Summary:     "Upload an image",
+		Description: "The image is resized to the display and stored under its original filename, with the extension corrected to match its content.",
+		Request:     &Body{Type: ImageUpload{}, ContentTypes: []string{"multipart/form-data"}},
+		Responses: map[int]Body{
+			http.StatusOK:                    {Type: ImageUploadResponse{}},
+			http.StatusForbidden:             errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:            {Description: "The image form field is missing or the image cannot be decoded", Type: ImageUploadError{}},
+			http.StatusRequestEntityTooLarge: {Description: "The image exceeds 10 MiB", Type: ImageUploadError{}},
+			http.StatusUnsupportedMediaType:  {Description: "The image is not a GIF, PNG or JPEG", Type: ImageUploadError{}},
+			http.StatusInternalServerError:   {Description: "The image could not be saved", Type: ImageUploadError{}},
+		},
+	},
+	{
+		ID:      "getImage",
+		Method:  http.MethodGet,
+		Path:    "/api/images/{filename}",
+		Tag:     "images",
+		Summary: "Download an uploaded image",
+		Parameters: []Parameter{
+			{Name: "filename", In: "path", Description: "Name of the image", Required: true, Type: ""},
+		},
+		Responses: map[int]Body{
+			http.StatusOK:       {Description: "The resized image", Type: File{}, ContentTypes: []string{"image/png", "image/jpeg", "image/gif"}},
+			http.StatusNotFound: errorBody("No image has that name"),
+		},
+	},
+	{
+		ID:      "deleteImage",
+		Method:  http.MethodPost,
+		Path:    "/api/images/delete",
+		Tag:     "images",
+		Summary: "Delete an image",
+		Request: &Body{Type: ImageDelete{}, ContentTypes: []string{"application/x-www-form-urlencoded"}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: Status{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The filename is missing"),
+			http.StatusInternalServerError: errorBody("The image could not be deleted"),
+		},
+	},
+	{
+		ID:          "getHistory",
+		Method:      http.MethodGet,
+		Path:        "/api/history",
+		Tag:         "device",
+		Summary:     "Query recorded instrument readings",
+		Description: "Without an instrument, the names of the recorded instruments are returned instead.",
+		Parameters: []Parameter{
+			{Name: "instrument", In: "query", Description: "Name of the instrument", Type: ""},
+			{Name: "since", In: "query", Description: "Go duration to look back from now (default: the full retention window)", Type: ""},
+		},
+		Responses: map[int]Body{
+			http.StatusOK:         {Description: "Readings, or instrument names", Type: []instruments.Reading{}},
+			http.StatusBadRequest: errorBody("The since duration is invalid"),
+		},
+	},
+	{
+		ID:          "streamInstruments",
+		Method:      http.MethodGet,
+		Path:        "/api/instruments/stream",
+		Tag:         "device",
+		Summary:     "Stream instrument readings",
+		Description: "Server-Sent Events stream. Each event is named \"reading\" and its data is one reading as JSON. The latest reading of each instrument is sent on connect.",
+		Parameters: []Parameter{
+			{Name: "instrument", In: "query", Description: "Instruments to stream, repeated or comma separated (default: all)", Type: ""},
+		},
+		Responses: map[int]Body{
+			http.StatusOK: {Description: "Event stream of readings", Type: instruments.Reading{}, ContentTypes: []string{"text/event-stream"}},
+		},
+	},
+	{
+		ID:          "pushFrame",
+		Method:      http.MethodPost,
+		Path:        "/api/frame",
+		Tag:         "device",
+		Summary:     "Push a frame from an external renderer",
+		Description: "Sends a 640x48 frame as a PNG image or raw RGBA pixels. The first frame acquires an exclusive lock on the display; each frame renews it for ttl seconds.",
+		Parameters: []Parameter{
+			{Name: "ttl", In: "query", Description: "Seconds the lock is held without another frame (default 5, at most 60)", Type: float64(0)},
+			frameTokenParameter,
+		},
+		Request: &Body{Type: File{}, ContentTypes: []string{"image/png", "application/octet-stream"}},
+		Responses: map[int]Body{
+			http.StatusOK:                    {Type: FrameResponse{}},
+			http.StatusForbidden:             errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:            errorBody("The frame or ttl is invalid"),
+			http.StatusConflict:              errorBody("Another client holds the display lock"),
+			http.StatusRequestEntityTooLarge: errorBody("The frame is too large"),
+		},
+	},
+	{
+		ID:         "releaseFrame",
+		Method:     http.MethodDelete,
+		Path:       "/api/frame",
+		Tag:        "device",
+		Summary:    "Release the display lock of an external renderer",
+		Parameters: []Parameter{frameTokenParameter},
+		Responses: map[int]Body{
+			http.StatusOK:        {Type: Status{}},
+			http.StatusForbidden: errorBody("Read-only mode is enabled"),
+			http.StatusConflict:  errorBody("The lock is not held by this token"),
+		},
+	},
+	{
+		ID:          "getStatus",
+		Method:      http.MethodGet,
+		Path:        "/api/status",
+		Tag:         "device",
+		Summary:     "Read the device and render loop status",
+		Description: "Reports whether the device is attached, what it shows, the frame rate and the latest USB error.",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: RuntimeStatus{}},
+		},
+	},
+	{
+		ID:          "pauseDisplay",
+		Method:      http.MethodPost,
+		Path:        "/api/display/pause",
+		Tag:         "device",
+		Summary:     "Freeze display updates",
+		Description: "The internal renderer stops until resumed or the timeout passes. Frames pushed to /api/frame are still shown, so an external app can take over the device.",
+		Request:     &Body{Type: PauseRequest{}},
+		Responses: map[int]Body{
+			http.StatusOK:         {Type: PauseResponse{}},
+			http.StatusForbidden:  errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest: errorBody("The timeout is invalid"),
+		},
+	},
+	{
+		ID:      "resumeDisplay",
+		Method:  http.MethodPost,
+		Path:    "/api/display/resume",
+		Tag:     "device",
+		Summary: "Resume display updates",
+		Responses: map[int]Body{
+			http.StatusOK:        {Type: PauseResponse{}},
+			http.StatusForbidden: errorBody("Read-only mode is enabled"),
+		},
+	},
+	{
+		ID:      "getTimer",
+		Method:  http.MethodGet,
+		Path:    "/api/timer",
+		Tag:     "display",
+		Summary: "Read the countdown timer",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: Timer{}},
+		},
+	},
+	{
+		ID:          "startTimer",
+		Method:      http.MethodPost,
+		Path:        "/api/timer",
+		Tag:         "display",
+		Summary:     "Start the countdown timer",
+		Description: "Replaces a running countdown. Switches to the first page showing the timer widget, unless the active page shows it.",
+		Request:     &Body{Type: TimerRequest{}},
+		Responses: map[int]Body{
+			http.StatusOK:         {Type: Timer{}},
+			http.StatusForbidden:  errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest: errorBody("The duration is invalid"),
+		},
+	},
+	{
+		ID:      "cancelTimer",
+		Method:  http.MethodDelete,
+		Path:    "/api/timer",
+		Tag:     "display",
+		Summary: "Cancel the countdown timer",
+		Responses: map[int]Body{
+			http.StatusOK:        {Type: Timer{}},
+			http.StatusForbidden: errorBody("Read-only mode is enabled"),
+		},
+	},
+	{
+		ID:      "getBrightness",
+		Method:  http.MethodGet,
+		Path:    "/api/display/brightness",
+		Tag:     "device",
+		Summary: "Read the display brightness",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: Brightness{}},
+		},
+	},
+	{
+		ID:      "setBrightness",
+		Method:  http.MethodPut,
+		Path:    "/api/display/brightness",
+		Tag:     "device",
+		Summary: "Dim or brighten the display",
+		Request: &Body{Type: Brightness{}},
+		Responses: map[int]Body{
+			http.StatusOK:         {Type: Brightness{}},
+			http.StatusForbidden:  errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest: errorBody("The level is out of range"),
+		},
+	},
+	{
+		ID:      "getPower",
+		Method:  http.MethodGet,
+		Path:    "/api/display/power",
+		Tag:     "device",
+		Summary: "Read whether the display is on",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: Power{}},
+		},
+	},
+	{
+		ID:          "setPower",
+		Method:      http.MethodPut,
+		Path:        "/api/display/power",
+		Tag:         "device",
+		Summary:     "Switch the display on or off",
+		Description: "While off the display is black; tapping it switches it back on.",
+		Request:     &Body{Type: Power{}},
+		Responses: map[int]Body{
+			http.StatusOK:         {Type: Power{}},
+			http.StatusForbidden:  errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest: errorBody("The body is invalid"),
+		},
+	},
+	{
+		ID:      "listPages",
+		Method:  http.MethodGet,
+		Path:    "/api/pages",
+		Tag:     "display",
+		Summary: "List the pages and the active page",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: Pages{}},
+		},
+	},
+	{
+		ID:          "setPage",
+		Method:      http.MethodPost,
+		Path:        "/api/page",
+		Tag:         "display",
+		Summary:     "Switch the displayed page",
+		Description: "The display is redrawn immediately with the widgets of the page.",
+		Request:     &Body{Type: PageRequest{}},
+		Responses: map[int]Body{
+			http.StatusOK:         {Type: Pages{}},
+			http.StatusForbidden:  errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest: errorBody("The body is invalid"),
+			http.StatusNotFound:   errorBody("No page has that name"),
+		},
+	},
+	{
+		ID:      "listThemes",
+		Method:  http.MethodGet,
+		Path:    "/api/themes",
+		Tag:     "display",
+		Summary: "List the themes and the active theme",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: Themes{}},
+		},
+	},
+	{
+		ID:          "activateTheme",
+		Method:      http.MethodPost,
+		Path:        "/api/themes/activate",
+		Tag:         "display",
+		Summary:     "Activate a theme",
+		Description: "The colors of the theme are saved to the configuration and shown immediately.",
+		Request:     &Body{Type: ThemeRequest{}},
+		Responses: map[int]Body{
+			http.StatusOK:                  {Type: Themes{}},
+			http.StatusForbidden:           errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:          errorBody("The body is invalid"),
+			http.StatusNotFound:            errorBody("No theme has that name"),
+			http.StatusInternalServerError: errorBody("The configuration could not be saved"),
+		},
+	},
+	{
+		ID:          "getHealth",
+		Method:      http.MethodGet,
+		Path:        "/healthz",
+		Tag:         "meta",
+		Summary:     "Check the health of Nexus",
+		Description: "Checks that the device is attached, the render loop runs, a configuration is loaded and the instruments take samples. A missing device or stale samples only degrade the health, as restarting Nexus would not help.",
+		Responses: map[int]Body{
+			http.StatusOK:                 {Description: "Healthy or degraded", Type: Health{}},
+			http.StatusServiceUnavailable: {Description: "The render loop is stuck or no configuration is loaded", Type: Health{}},
+		},
+	},
+	{
+		ID:          "getMetrics",
+		Method:      http.MethodGet,
+		Path:        "/metrics",
+		Tag:         "meta",
+		Summary:     "Export metrics for Prometheus",
+		Description: "Frame, USB error, render duration and instrument sample age metrics in the Prometheus text format. The latest instrument values are included when api.metrics_instruments is enabled.",
+		Responses: map[int]Body{
+			http.StatusOK: {Description: "Prometheus text exposition format", Type: "", ContentTypes: []string{"text/plain"}},
+		},
+	},
+	{
+		ID:          "streamPreview",
+		Method:      http.MethodGet,
+		Path:        "/api/preview/ws",
+		Tag:         "preview",
+		Summary:     "Stream the rendered display",
+		Description: "Upgrades to a WebSocket on which every binary message holds one frame.",
+		Parameters: []Parameter{
+			{Name: "format", In: "query", Description: "Frame encoding (default png)", Type: "", Enum: []string{"png", "rgba"}},
+			{Name: "fps", In: "query", Description: "Maximum frames per second (default 10)", Type: 0},
+		},
+		Responses: map[int]Body{
+			http.StatusSwitchingProtocols: {Description: "WebSocket stream of frames"},
+			http.StatusBadRequest:         errorBody("The format or fps is invalid"),
+			http.StatusUpgradeRequired:    errorBody("The request is not a WebSocket upgrade"),
+		},
+	},
+	{
+		ID:          "notify",
+		Method:      http.MethodPost,
+		Path:        "/api/notify",
+		Tag:         "notify",
+		Summary:     "Show a notification banner",
+		Description: "Notifications are queued and shown one after another.",
+		Request:     &Body{Type: NotifyRequest{}},
+		Responses: map[int]Body{
+			http.StatusOK:              {Type: NotifyResponse{}},
+			http.StatusForbidden:       errorBody("Read-only mode is enabled"),
+			http.StatusBadRequest:      errorBody("The text is missing or a field is invalid"),
+			http.StatusTooManyRequests: errorBody("Too many notifications are pending"),
+		},
+	},
+	{
+		ID:      "getOpenAPI",
+		Method:  http.MethodGet,
+		Path:    "/api/openapi.json",
+		Tag:     "meta",
+		Summary: "Read this OpenAPI document",
+		Responses: map[int]Body{
+			http.StatusOK: {Type: map[string]interface{}{}},
+		},
+	},
+}
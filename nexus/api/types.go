@@ -0,0 +1,337 @@
+// Package api defines the request and response types of the Nexus Open HTTP API
+// and generates its OpenAPI description from them.
+//
+// The types are shared by the server handlers and the client package, so the
+// published document, the server and the client cannot drift apart.
+package api
+
+import (
+	"time"
+
+	"nexus-open/nexus/configuration"
+)
+
+// Status is the body of successful responses that carry no data.
+type Status struct {
+	// Status is always "ok"
+	Status string `json:"status"`
+}
+
+// StatusOK is the Status returned by successful requests.
+var StatusOK = Status{Status: "ok"}
+
+// ValidationError is returned with status 422 when a configuration has invalid
+// values.
+type ValidationError struct {
+	Error string `json:"error"`
+
+	// Fields lists every invalid value
+	Fields []configuration.FieldError `json:"fields"`
+
+	// Candidates lists the places an ambiguous location matches. Saving again with
+	// geocoded set to {query: <location>, lat, lon} of one of them picks it.
+	Candidates []LocationCandidate `json:"candidates,omitempty"`
+}
+
+// LocationCandidate is a place an ambiguous location matches.
+type LocationCandidate struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// VersionInfo is returned by GET /api/v1.
+type VersionInfo struct {
+	// Version is the version of the API, also sent in the X-Nexus-API-Version header
+	Version string `json:"version"`
+
+	// APIVersions lists the versioned path prefixes served, e.g. "v1" for /api/v1
+	APIVersions []string `json:"api_versions"`
+
+	// ConfigVersion is the newest configuration document version accepted
+	ConfigVersion int `json:"config_version"`
+
+	// Operations lists the IDs of the supported operations in the OpenAPI document
+	Operations []string `json:"operations"`
+}
+
+// ConfigDocument is the body of GET and POST /api/v1/config.
+type ConfigDocument struct {
+	// Version is the configuration schema version; documents of older versions are
+	// accepted and answered with the current version
+	Version int `json:"version"`
+
+	// Config is keyed like the configuration file, e.g. "time_format" or "api.listen"
+	// nested as {"api": {"listen": ...}}
+	Config map[string]interface{} `json:"config"`
+}
+
+// NotifyRequest is the body of POST /api/notify.
+type NotifyRequest struct {
+	// Text is the message to show
+	Text string `json:"text"`
+
+	// Color is a hex ("#RRGGBB") or named color of the banner (default: text color)
+	Color string `json:"color,omitempty"`
+
+	// Duration is how many seconds the banner is shown (default 5, at most 300)
+	Duration float64 `json:"duration,omitempty"`
+
+	// Icon is an icon name (info, warning, error, success, bell, build, mail) or a glyph
+	Icon string `json:"icon,omitempty"`
+}
+
+// NotifyResponse is returned when a notification was queued.
+type NotifyResponse struct {
+	Status string `json:"status"`
+
+	// Duration is the effective number of seconds the banner is shown
+	Duration float64 `json:"duration"`
+}
+
+// FrameResponse is returned when a frame pushed to /api/frame was accepted.
+type FrameResponse struct {
+	Status string `json:"status"`
+
+	// Token holds the display lock and must be sent with following frames
+	Token string `json:"token"`
+
+	// TTL is the number of seconds the lock is held without another frame
+	TTL float64 `json:"ttl"`
+}
+
+// PauseRequest is the optional body of POST /api/display/pause.
+type PauseRequest struct {
+	// Timeout is the number of seconds after which updates resume (default 300, at most 3600)
+	Timeout float64 `json:"timeout,omitempty"`
+}
+
+// PauseResponse reports whether display updates are paused.
+type PauseResponse struct {
+	Status string `json:"status"`
+	Paused bool   `json:"paused"`
+
+	// Until is when updates resume automatically, omitted while not paused
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// TimerRequest is the body of POST /api/timer.
+type TimerRequest struct {
+	// Duration is the number of seconds to count down (at most 86400)
+	Duration float64 `json:"duration"`
+}
+
+// Timer is the state of the countdown timer.
+type Timer struct {
+	// Duration is the number of seconds counted down, 0 while no countdown is set
+	Duration float64 `json:"duration"`
+
+	// Remaining is the number of seconds left
+	Remaining float64 `json:"remaining"`
+
+	// Running is false while no countdown is set, it is paused or it reached zero
+	Running bool `json:"running"`
+
+	// Done is true from the countdown reaching zero until the alert is dismissed
+	Done bool `json:"done"`
+}
+
+// Brightness is the body of GET and PUT /api/display/brightness.
+type Brightness struct {
+	// Level is the brightness in percent, 0-100
+	Level int `json:"level"`
+}
+
+// Power is the body of GET and PUT /api/display/power.
+type Power struct {
+	// On is false while the display is switched off
+	On bool `json:"on"`
+}
+
+// Page is a named set of widgets shown together on the display.
+type Page struct {
+	Name string `json:"name"`
+
+	// Widgets are drawn in order: temperatures, network, clock, weather, volume, media, ticker,
+	// keyboard, radar, temp_graph, net_graph, disks, vpn, or fill the display: pomodoro, timer, stopwatch
+	Widgets []string `json:"widgets"`
+
+	// BackgroundColor and TextColor override the configured colors, if set
+	BackgroundColor string `json:"background_color,omitempty"`
+	TextColor       string `json:"text_color,omitempty"`
+}
+
+// Pages is returned by GET /api/pages and POST /api/page.
+type Pages struct {
+	Active string `json:"active"`
+	Pages  []Page `json:"pages"`
+}
+
+// PageRequest is the body of POST /api/page.
+type PageRequest struct {
+	Name string `json:"name"`
+}
+
+// Theme is a named color scheme for the display.
+type Theme struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	TextColor       string `json:"text_color"`
+	BackgroundColor string `json:"background_color"`
+}
+
+// Themes is returned by GET /api/themes and POST /api/themes/activate.
+type Themes struct {
+	// Active is empty when the configured colors match no theme
+	Active string  `json:"active"`
+	Themes []Theme `json:"themes"`
+}
+
+// ThemeRequest is the body of POST /api/themes/activate.
+type ThemeRequest struct {
+	Name string `json:"name"`
+}
+
+// RuntimeStatus is returned by GET /api/status.
+type RuntimeStatus struct {
+	// Connected is true while the device is attached, and always with a virtual display
+	Connected bool `json:"connected"`
+
+	// Virtual is true if the display runs without a device (--virtual)
+	Virtual bool `json:"virtual"`
+
+	// ReadOnly is true if the configuration, the images and what the display shows
+	// cannot be changed through the API (read_only or --read-only)
+	ReadOnly bool `json:"read_only"`
+
+	// Serial is the USB serial number of the device, empty if unknown
+	Serial string `json:"serial"`
+
+	// Page is what the display shows: the active page, alarm, notification, alert, external,
+	// paused, idle or off
+	Page string `json:"page"`
+
+	// Schedule is the name of the active schedule, empty if none is
+	Schedule string `json:"schedule,omitempty"`
+
+	// FPS is the number of frames sent to the device per second
+	FPS float64 `json:"fps"`
+
+	// FramesSent counts the frames written to the device
+	FramesSent uint64 `json:"frames_sent"`
+
+	// DroppedFrames counts the frames that could not be written
+	DroppedFrames uint64 `json:"dropped_frames"`
+
+	// SkippedFrames counts the frames that were not drawn or sent because earlier
+	// frames took longer than the refresh interval
+	SkippedFrames uint64 `json:"skipped_frames"`
+
+	// LastUSBError is the latest USB error, empty if none occurred
+	LastUSBError string `json:"last_usb_error,omitempty"`
+
+	// LastUSBErrorAt is when the latest USB error occurred
+	LastUSBErrorAt *time.Time `json:"last_usb_error_at,omitempty"`
+
+	Paused     bool `json:"paused"`
+	Power      bool `json:"power"`
+	Brightness int  `json:"brightness"`
+
+	// StartedAt is when the process started
+	StartedAt time.Time `json:"started_at"`
+
+	// Uptime is the number of seconds since the process started
+	Uptime float64 `json:"uptime"`
+}
+
+// Health is returned by GET /healthz.
+type Health struct {
+	// Status is "ok" if every check passes, "degraded" if only the device or the
+	// instruments fail, and "unhealthy" if the render loop or the configuration fail
+	Status string `json:"status"`
+
+	Device      HealthCheck `json:"device"`
+	Render      HealthCheck `json:"render"`
+	Config      HealthCheck `json:"config"`
+	Instruments HealthCheck `json:"instruments"`
+}
+
+// HealthCheck is the result of one check of GET /healthz.
+type HealthCheck struct {
+	OK bool `json:"ok"`
+
+	// Detail explains the result, e.g. how long ago the latest sample was taken
+	Detail string `json:"detail"`
+}
+
+// ImageUpload is the multipart form of POST /api/images/upload.
+type ImageUpload struct {
+	// Image is a GIF, PNG or JPEG file of at most 10 MiB
+	Image File `json:"image"`
+}
+
+// ImageUploadResponse is returned by POST /api/images/upload.
+type ImageUploadResponse struct {
+	Status string `json:"status"`
+
+	// Filename is the name the image was stored under. The extension is corrected
+	// to match the image content.
+	Filename string `json:"filename"`
+}
+
+// ImageUploadError is returned when POST /api/images/upload rejects an image.
+type ImageUploadError struct {
+	Error string `json:"error"`
+
+	// Filename is the name of the uploaded file, if the image field was read
+	Filename string `json:"filename,omitempty"`
+}
+
+// ImageDelete is the form of POST /api/images/delete.
+type ImageDelete struct {
+	// Filename is the name of the image to delete
+	Filename string `json:"filename"`
+}
+
+// File is binary content such as an uploaded image or a raw frame.
+type File []byte
+
+// WebhookEvent is the JSON body posted to webhooks.
+type WebhookEvent struct {
+	// Event is the event name: touch, page, connect, disconnect or alert
+	Event string `json:"event"`
+
+	// Time is when the event occurred
+	Time time.Time `json:"time"`
+
+	// Data holds the event details: a TouchEvent, PageEvent, DeviceEvent or AlertEvent
+	Data interface{} `json:"data"`
+}
+
+// TouchEvent describes a tap on the display.
+type TouchEvent struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// PageEvent describes a change of the displayed page.
+type PageEvent struct {
+	Page string `json:"page"`
+}
+
+// DeviceEvent describes a change of the device connection.
+type DeviceEvent struct {
+	Connected bool `json:"connected"`
+}
+
+// AlertEvent describes a triggered or cleared threshold alert.
+type AlertEvent struct {
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Threshold float64 `json:"threshold"`
+	Value     float64 `json:"value"`
+	Action    string  `json:"action"`
+
+	// Active is true when the alert was triggered and false when it cleared
+	Active bool `json:"active"`
+}
@@ -0,0 +1,134 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+)
+
+// API versioning
+const (
+	apiV1Prefix      = "/api/v1"
+	apiVersionHeader = "X-Nexus-API-Version"
+)
+
+// apiVersionMiddleware reports the API version in a header on every response.
+func apiVersionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(apiVersionHeader, api.Version)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiV1Handler serves /api/v1/... by dispatching to the unversioned endpoint of
+// the same name on mux. Endpoints whose v1 shape differs are registered on mux
+// under their /api/v1 path and take precedence.
+func apiV1Handler(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unversioned := new(http.Request)
+		*unversioned = *r
+		unversioned.URL = new(url.URL)
+		*unversioned.URL = *r.URL
+		unversioned.URL.Path = "/api" + strings.TrimPrefix(r.URL.Path, apiV1Prefix)
+		unversioned.URL.RawPath = ""
+
+		mux.ServeHTTP(w, unversioned)
+	})
+}
+
+// versionHandler describes the API version and the operations it supports
+// (GET /api/v1).
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	info := api.VersionInfo{
+		Version:       api.Version,
+		APIVersions:   []string{"v1"},
+		ConfigVersion: configuration.SchemaVersion,
+		Operations:    make([]string, 0, len(api.Operations)),
+	}
+	for _, op := range api.Operations {
+		info.Operations = append(info.Operations, op.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// configV1Handler reads (GET), replaces (POST) or partially updates (PATCH) the
+// configuration as a versioned document keyed like the configuration file
+// (/api/v1/config). PATCH takes a document whose config is a JSON merge patch.
+func configV1Handler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		config, err := configuration.LoadConfig("")
+		if err != nil {
+			http.Error(w, "Failed to read config", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.ConfigDocument{Version: configuration.SchemaVersion, Config: config.Map()})
+	case http.MethodPost:
+		document, ok := decodeConfigDocument(w, r)
+		if !ok {
+			return
+		}
+
+		newConfig, err := configuration.DecodeMap(document.Config)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := newConfig.Validate(); err != nil {
+			writeValidationError(w, err)
+			return
+		}
+		if err := GeocodeLocation(r.Context(), newConfig); err != nil {
+			writeValidationError(w, err)
+			return
+		}
+		if err := configuration.SaveConfig(newConfig, ""); err != nil {
+			http.Error(w, "Failed to save config", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.ConfigDocument{Version: configuration.SchemaVersion, Config: newConfig.Map()})
+	case http.MethodPatch:
+		document, ok := decodeConfigDocument(w, r)
+		if !ok {
+			return
+		}
+
+		newConfig := patchConfig(w, r, document.Config)
+		if newConfig == nil {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.ConfigDocument{Version: configuration.SchemaVersion, Config: newConfig.Map()})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeConfigDocument reads a configuration document from the request body. On
+// failure it answers the request and returns false.
+func decodeConfigDocument(w http.ResponseWriter, r *http.Request) (api.ConfigDocument, bool) {
+	var document api.ConfigDocument
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&document); err != nil || document.Config == nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return document, false
+	}
+	if document.Version > configuration.SchemaVersion {
+		http.Error(w, fmt.Sprintf("Unsupported config version %d, at most %d is supported", document.Version, configuration.SchemaVersion), http.StatusBadRequest)
+		return document, false
+	}
+	return document, true
+}
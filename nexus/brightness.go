@@ -0,0 +1,73 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"nexus-open/nexus/api"
+)
+
+// MaxBrightness is the full brightness level of the display.
+const MaxBrightness = 100
+
+// SetBrightness dims the display to level percent (0-100) by scaling the color
+// channels of every frame sent to the device. 0 turns the display black.
+func SetBrightness(level int) error {
+	return defaultNexus.transport.SetBrightness(level)
+}
+
+// Brightness returns the current display brightness in percent.
+func Brightness() int {
+	return defaultNexus.transport.Brightness()
+}
+
+// SetBrightness dims the frames sent afterwards to level percent (0-100), see the
+// package function SetBrightness.
+func (t *Transport) SetBrightness(level int) error {
+	if level < 0 || level > MaxBrightness {
+		return fmt.Errorf("brightness must be between 0 and %d, got %d", MaxBrightness, level)
+	}
+
+	t.brightness.Store(int32(level))
+	if level == MaxBrightness {
+		t.dim.Store(nil)
+		return nil
+	}
+
+	var table [256]byte
+	for i := range table {
+		table[i] = byte(i * level / MaxBrightness)
+	}
+	t.dim.Store(&table)
+	return nil
+}
+
+// Brightness returns the brightness of t in percent.
+func (t *Transport) Brightness() int {
+	return int(t.brightness.Load())
+}
+
+// brightnessHandler reads (GET) or changes (PUT) the display brightness
+// (/api/display/brightness). The JSON body of both is {"level": 0-100}.
+func (n *Nexus) brightnessHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var request api.Brightness
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		if err := n.transport.SetBrightness(request.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.Brightness{Level: n.transport.Brightness()})
+}
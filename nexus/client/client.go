@@ -0,0 +1,511 @@
+// Package client is a typed Go client for the Nexus Open HTTP API.
+//
+// Example usage:
+//
+//	c := client.New("http://127.0.0.1:1985")
+//	if _, err := c.Notify(ctx, api.NotifyRequest{Text: "Build passed", Icon: "success"}); err != nil {
+//	    log.Fatal(err)
+//	}
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+)
+
+// Client calls the API of a running Nexus Open instance.
+type Client struct {
+	// BaseURL is the scheme, host and port of the API, e.g. "http://127.0.0.1:1985"
+	BaseURL string
+
+	// HTTPClient sends the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// Error is returned when the API answers with an error status.
+type Error struct {
+	StatusCode int
+	Message    string
+
+	// Fields lists the invalid values of a rejected configuration
+	Fields []configuration.FieldError
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("nexus api: %d %s", e.StatusCode, e.Message)
+}
+
+// New creates a client for the API at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// NewUnix creates a client for the API listening on the unix socket at path.
+func NewUnix(path string) *Client {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}
+
+	// The host is ignored, requests are sent over the socket
+	return &Client{BaseURL: "http://localhost", HTTPClient: &http.Client{Transport: transport}}
+}
+
+// Config reads the saved configuration.
+func (c *Client) Config(ctx context.Context) (*configuration.NexusConfig, error) {
+	var config configuration.NexusConfig
+	if err := c.do(ctx, http.MethodGet, "/api/config", nil, "", nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// SetConfig replaces the configuration. Fields left at their zero value are saved as such,
+// so config should start from the result of Config. An invalid configuration is rejected
+// with an *Error listing the invalid fields.
+func (c *Client) SetConfig(ctx context.Context, config *configuration.NexusConfig) error {
+	body, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/config", bytes.NewReader(body), "application/json", nil, nil)
+}
+
+// PatchConfig updates part of the configuration and returns the result. patch is a
+// JSON merge patch keyed like the configuration file, e.g.
+// {"text_color": "#00FF00"}; a nil value resets a key. An invalid result is rejected
+// with an *Error listing the invalid fields.
+func (c *Client) PatchConfig(ctx context.Context, patch map[string]interface{}) (*configuration.NexusConfig, error) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	var config configuration.NexusConfig
+	if err := c.do(ctx, http.MethodPatch, "/api/config", bytes.NewReader(body), "application/merge-patch+json", nil, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Version describes the API version and the operations the server supports.
+func (c *Client) Version(ctx context.Context) (*api.VersionInfo, error) {
+	var info api.VersionInfo
+	if err := c.do(ctx, http.MethodGet, "/api/v1", nil, "", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Images lists the uploaded images.
+func (c *Client) Images(ctx context.Context) ([]string, error) {
+	var images []string
+	err := c.do(ctx, http.MethodGet, "/api/images", nil, "", nil, &images)
+	return images, err
+}
+
+// UploadImage uploads an image under filename and returns the name it was stored
+// under. It is resized to the display.
+func (c *Client) UploadImage(ctx context.Context, filename string, image io.Reader) (string, error) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+
+	// Stream the image instead of buffering the whole form
+	go func() {
+		part, err := form.CreateFormFile("image", filename)
+		if err == nil {
+			_, err = io.Copy(part, image)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	var response api.ImageUploadResponse
+	if err := c.do(ctx, http.MethodPost, "/api/images/upload", body, form.FormDataContentType(), nil, &response); err != nil {
+		body.CloseWithError(err)
+		return "", err
+	}
+	return response.Filename, nil
+}
+
+// Image downloads an uploaded image.
+func (c *Client) Image(ctx context.Context, filename string) ([]byte, error) {
+	var image api.File
+	err := c.do(ctx, http.MethodGet, "/api/images/"+url.PathEscape(filename), nil, "", nil, &image)
+	return image, err
+}
+
+// DeleteImage deletes an uploaded image.
+func (c *Client) DeleteImage(ctx context.Context, filename string) error {
+	form := url.Values{"filename": {filename}}
+	return c.do(ctx, http.MethodPost, "/api/images/delete", strings.NewReader(form.Encode()),
+		"application/x-www-form-urlencoded", nil, nil)
+}
+
+// Instruments lists the instruments with recorded readings.
+func (c *Client) Instruments(ctx context.Context) ([]string, error) {
+	var names []string
+	err := c.do(ctx, http.MethodGet, "/api/history", nil, "", nil, &names)
+	return names, err
+}
+
+// History returns the readings of an instrument recorded within since, or the full
+// retention window if since is zero. Values are decoded as generic JSON values.
+func (c *Client) History(ctx context.Context, instrument string, since time.Duration) ([]instruments.Reading, error) {
+	query := url.Values{"instrument": {instrument}}
+	if since > 0 {
+		query.Set("since", since.String())
+	}
+
+	var readings []instruments.Reading
+	err := c.do(ctx, http.MethodGet, "/api/history?"+query.Encode(), nil, "", nil, &readings)
+	return readings, err
+}
+
+// StreamInstruments subscribes to the live readings of the named instruments, or of
+// every instrument if none are named. The latest reading of each instrument arrives
+// first. The channel is closed when ctx is done or the connection ends. Values are
+// decoded as generic JSON values.
+func (c *Client) StreamInstruments(ctx context.Context, names ...string) (<-chan instruments.Reading, error) {
+	path := "/api/instruments/stream"
+	if len(names) > 0 {
+		path += "?" + url.Values{"instrument": names}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	readings := make(chan instruments.Reading)
+	go func() {
+		defer close(readings)
+		defer resp.Body.Close()
+
+		var event, data string
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			case line == "":
+				var reading instruments.Reading
+				if event == "reading" && json.Unmarshal([]byte(data), &reading) == nil {
+					select {
+					case readings <- reading:
+					case <-ctx.Done():
+						return
+					}
+				}
+				event, data = "", ""
+			}
+		}
+	}()
+
+	return readings, nil
+}
+
+// Notify shows a notification banner.
+func (c *Client) Notify(ctx context.Context, notification api.NotifyRequest) (*api.NotifyResponse, error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.NotifyResponse
+	if err := c.do(ctx, http.MethodPost, "/api/notify", bytes.NewReader(body), "application/json", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// PushFrame sends a 640x48 frame, a PNG image or raw RGBA pixels, to the display.
+// token is empty for the first frame and the token of the response afterwards.
+// A zero ttl uses the server default.
+func (c *Client) PushFrame(ctx context.Context, token string, frame []byte, ttl time.Duration) (*api.FrameResponse, error) {
+	path := "/api/frame"
+	if ttl > 0 {
+		path += "?ttl=" + strconv.FormatFloat(ttl.Seconds(), 'f', -1, 64)
+	}
+
+	contentType := "application/octet-stream"
+	if bytes.HasPrefix(frame, []byte("\x89PNG")) {
+		contentType = "image/png"
+	}
+
+	var response api.FrameResponse
+	if err := c.do(ctx, http.MethodPost, path, bytes.NewReader(frame), contentType, frameToken(token), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ReleaseFrame releases the display lock held by token.
+func (c *Client) ReleaseFrame(ctx context.Context, token string) error {
+	return c.do(ctx, http.MethodDelete, "/api/frame", nil, "", frameToken(token), nil)
+}
+
+// Status reads the device and render loop status.
+func (c *Client) Status(ctx context.Context) (*api.RuntimeStatus, error) {
+	var status api.RuntimeStatus
+	if err := c.do(ctx, http.MethodGet, "/api/status", nil, "", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Health checks the health of Nexus. An unhealthy Nexus is reported with its
+// health and an *Error with status 503.
+func (c *Client) Health(ctx context.Context) (*api.Health, error) {
+	var health api.Health
+	err := c.do(ctx, http.MethodGet, "/healthz", nil, "", nil, &health)
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && json.Unmarshal([]byte(apiErr.Message), &health) == nil {
+		return &health, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Pause freezes display updates until Resume is called or timeout passes. A zero
+// timeout uses the server default.
+func (c *Client) Pause(ctx context.Context, timeout time.Duration) (*api.PauseResponse, error) {
+	body, err := json.Marshal(api.PauseRequest{Timeout: timeout.Seconds()})
+	if err != nil {
+		return nil, err
+	}
+
+	var response api.PauseResponse
+	if err := c.do(ctx, http.MethodPost, "/api/display/pause", bytes.NewReader(body), "application/json", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Resume resumes display updates.
+func (c *Client) Resume(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/display/resume", nil, "", nil, nil)
+}
+
+// Timer reads the state of the countdown timer.
+func (c *Client) Timer(ctx context.Context) (*api.Timer, error) {
+	var timer api.Timer
+	if err := c.do(ctx, http.MethodGet, "/api/timer", nil, "", nil, &timer); err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// StartTimer counts down from duration, replacing a running countdown.
+func (c *Client) StartTimer(ctx context.Context, duration time.Duration) (*api.Timer, error) {
+	body, err := json.Marshal(api.TimerRequest{Duration: duration.Seconds()})
+	if err != nil {
+		return nil, err
+	}
+
+	var timer api.Timer
+	if err := c.do(ctx, http.MethodPost, "/api/timer", bytes.NewReader(body), "application/json", nil, &timer); err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+// CancelTimer stops the countdown timer.
+func (c *Client) CancelTimer(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/api/timer", nil, "", nil, nil)
+}
+
+// Brightness reads the display brightness in percent.
+func (c *Client) Brightness(ctx context.Context) (int, error) {
+	var brightness api.Brightness
+	err := c.do(ctx, http.MethodGet, "/api/display/brightness", nil, "", nil, &brightness)
+	return brightness.Level, err
+}
+
+// SetBrightness dims or brightens the display to level percent (0-100).
+func (c *Client) SetBrightness(ctx context.Context, level int) error {
+	body, err := json.Marshal(api.Brightness{Level: level})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, "/api/display/brightness", bytes.NewReader(body), "application/json", nil, nil)
+}
+
+// Power reports whether the display is switched on.
+func (c *Client) Power(ctx context.Context) (bool, error) {
+	var power api.Power
+	err := c.do(ctx, http.MethodGet, "/api/display/power", nil, "", nil, &power)
+	return power.On, err
+}
+
+// SetPower switches the display on or off.
+func (c *Client) SetPower(ctx context.Context, on bool) error {
+	body, err := json.Marshal(api.Power{On: on})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, "/api/display/power", bytes.NewReader(body), "application/json", nil, nil)
+}
+
+// Pages lists the available pages and the active page.
+func (c *Client) Pages(ctx context.Context) (*api.Pages, error) {
+	var pages api.Pages
+	if err := c.do(ctx, http.MethodGet, "/api/pages", nil, "", nil, &pages); err != nil {
+		return nil, err
+	}
+	return &pages, nil
+}
+
+// SetPage switches the display to the named page.
+func (c *Client) SetPage(ctx context.Context, name string) error {
+	body, err := json.Marshal(api.PageRequest{Name: name})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/page", bytes.NewReader(body), "application/json", nil, nil)
+}
+
+// Themes lists the themes and the active theme.
+func (c *Client) Themes(ctx context.Context) (*api.Themes, error) {
+	var themes api.Themes
+	if err := c.do(ctx, http.MethodGet, "/api/themes", nil, "", nil, &themes); err != nil {
+		return nil, err
+	}
+	return &themes, nil
+}
+
+// ActivateTheme switches the display colors to the named theme.
+func (c *Client) ActivateTheme(ctx context.Context, name string) error {
+	body, err := json.Marshal(api.ThemeRequest{Name: name})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/api/themes/activate", bytes.NewReader(body), "application/json", nil, nil)
+}
+
+// PreviewURL returns the WebSocket URL of the live preview stream. format is
+// "png" or "rgba"; zero values use the server defaults.
+func (c *Client) PreviewURL(format string, fps int) string {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", format)
+	}
+	if fps > 0 {
+		query.Set("fps", strconv.Itoa(fps))
+	}
+
+	u := "ws" + strings.TrimPrefix(c.BaseURL, "http") + "/api/preview/ws"
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// Spec reads the OpenAPI document of the server.
+func (c *Client) Spec(ctx context.Context) (*api.Document, error) {
+	var doc api.Document
+	if err := c.do(ctx, http.MethodGet, "/api/openapi.json", nil, "", nil, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// frameToken returns the header carrying a frame lock token.
+func frameToken(token string) http.Header {
+	if token == "" {
+		return nil
+	}
+	return http.Header{"X-Frame-Token": {token}}
+}
+
+// do sends a request and decodes the JSON response into out, unless out is nil.
+// Error statuses are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, contentType string, header http.Header, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+		// Structured errors carry the message in "error" and invalid config fields in "fields"
+		var validationErr api.ValidationError
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") && json.Unmarshal(message, &validationErr) == nil && validationErr.Error != "" {
+			return &Error{StatusCode: resp.StatusCode, Message: validationErr.Error, Fields: validationErr.Fields}
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if file, ok := out.(*api.File); ok {
+		*file, err = io.ReadAll(resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("nexus api: invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nexus-open/nexus/configuration"
+)
+
+// configEventsHandler streams the configuration as Server-Sent Events (GET
+// /api/config/events). Every event is named "config" and holds the configuration
+// as JSON, like GET /api/config. It is sent on connect and whenever the
+// configuration file is reloaded with changes, whether saved through the API, the
+// desktop app or edited by hand.
+func (n *Nexus) configEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The stream outlives the server write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Slow clients skip intermediate versions rather than blocking reloads
+	configs, unsubscribe := n.events.Config.SubscribeLatest()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(config *configuration.NexusConfig) error {
+		data, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: config\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	if config := n.configs.Get(); config != nil {
+		if err := send(config); err != nil {
+			return
+		}
+	} else if err := controller.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case config := <-configs:
+			if err := send(config); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package nexus
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// initWeatherTimeout bounds the weather lookup tested by InitConfig
+const initWeatherTimeout = 30 * time.Second
+
+// ErrInitAborted is returned by InitConfig when the user declines to write the
+// configuration.
+var ErrInitAborted = errors.New("configuration not written")
+
+// InitConfig creates the configuration file interactively, reading answers from in
+// and writing prompts to out. It starts from the defaults, asks for the location,
+// units and time format, tests the weather lookup for them and writes the file.
+// An existing file is only replaced when confirmed, and kept as "<path>.bak".
+func InitConfig(in io.Reader, out io.Writer) error {
+	path, err := configuration.GetConfigPath()
+	if err != nil {
+		return err
+	}
+
+	answers := bufio.NewReader(in)
+	ask := func(question, defaultAnswer string, choices ...string) (string, error) {
+		for {
+			fmt.Fprintf(out, "%s [%s]: ", question, defaultAnswer)
+			line, err := answers.ReadString('\n')
+			if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+				return "", err
+			}
+
+			answer := strings.TrimSpace(line)
+			if answer == "" {
+				return defaultAnswer, nil
+			}
+			if len(choices) == 0 {
+				return answer, nil
+			}
+			if answer = strings.ToLower(answer); slices.Contains(choices, answer) {
+				return answer, nil
+			}
+			fmt.Fprintf(out, "Please answer %s.\n", strings.Join(choices, " or "))
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		answer, err := ask(fmt.Sprintf("%s exists, replace it?", path), "n", "y", "n")
+		if err != nil {
+			return err
+		}
+		if answer != "y" {
+			return ErrInitAborted
+		}
+	}
+
+	cfg := configuration.DefaultConfig()
+
+	if cfg.Location, err = ask(`Location (a city, "lat,lon" or "auto" to detect it)`, cfg.Location); err != nil {
+		return err
+	}
+	if cfg.Unit, err = ask("Units", cfg.Unit, configuration.UnitMetric, configuration.UnitImperial); err != nil {
+		return err
+	}
+	if cfg.TimeFormat, err = ask("Time format", cfg.TimeFormat, configuration.TimeFormat12Hour, configuration.TimeFormat24Hour); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Looking up the weather...")
+	if err := testWeather(out, cfg); err != nil {
+		fmt.Fprintf(out, "Weather lookup failed: %v\n", err)
+		answer, err := ask("Write the configuration anyway?", "n", "y", "n")
+		if err != nil {
+			return err
+		}
+		if answer != "y" {
+			return ErrInitAborted
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Replace rather than update the file, SaveConfig keeps the settings it has
+	if err := os.Rename(path, path+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := configuration.SaveConfig(cfg, path); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Wrote %s\n", path)
+	return nil
+}
+
+// testWeather resolves the configured location and fetches its current weather,
+// printing both to out.
+func testWeather(out io.Writer, cfg *configuration.NexusConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), initWeatherTimeout)
+	defer cancel()
+
+	location, err := instruments.ResolveLocation(ctx, cfg.Location)
+	if err != nil {
+		return err
+	}
+
+	weather, err := instruments.GetWeatherAt(ctx, location, cfg.Unit)
+	if err != nil {
+		return err
+	}
+
+	degreeSymbol, _ := measurementSymbols(cfg.Unit)
+	fmt.Fprintf(out, "%s (%.4f, %.4f): %.1f %s, %s\n", location.Name, location.Lat, location.Lon, weather.Temperature, degreeSymbol, newDisplayLocale(cfg.Locale, cfg.Language).translate(weather.Description))
+	return nil
+}
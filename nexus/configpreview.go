@@ -0,0 +1,136 @@
+package nexus
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"time"
+
+	"nexus-open/nexus/configuration"
+)
+
+// configPreviewTimeout bounds the wait for the display loop to render a preview
+const configPreviewTimeout = 5 * time.Second
+
+// configPreviewRequest asks the display loop to render a frame with a candidate
+// configuration.
+type configPreviewRequest struct {
+	config *configuration.NexusConfig
+	page   string           // Page to render, the active page if empty
+	frame  chan *image.RGBA // Receives the rendered frame
+}
+
+// renderPreview draws the named page, or the active one, with the latest values
+// of state and the settings of cfg, without sending or publishing the frame. It
+// draws with the preview renderer, so the drawing settings of the live
+// configuration are left alone. It must be called from the display loop.
+func (n *Nexus) renderPreview(state *displayState, cfg *configuration.NexusConfig, name string) *image.RGBA {
+	cfg = cfg.ForDevice(n.transport.Serial())
+
+	// Resolve the page among the candidate's pages
+	candidatePages := &pageManager{pages: builtinPages, active: n.pages.current().Name}
+	candidatePages.configure(cfg.Pages)
+	if name != "" {
+		candidatePages.activate(name)
+	}
+	page := candidatePages.current()
+
+	backgroundColor, pageTextColor := cfg.BackgroundColor, cfg.TextColor
+	if page.BackgroundColor != "" {
+		backgroundColor = page.BackgroundColor
+	}
+	if page.TextColor != "" {
+		pageTextColor = page.TextColor
+	}
+
+	r := n.previewRenderer
+	r.SetTextColor(pageTextColor)
+	r.SetTimeFormat(cfg.TimeFormat)
+	r.SetTimezone(cfg.Timezone, cfg.WorldClocks)
+	r.SetLocale(cfg.Locale, cfg.Language)
+
+	img := r.CreateImageContext(ImageConfig{
+		BackgroundImg: backgroundImage(cfg),
+		BgColor:       backgroundColor,
+		Font:          cfg.Font,
+		FontSize:      cfg.FontSize,
+	})
+	r.drawPage(page, n.screenConfig(state, cfg), cfg)
+	return img
+}
+
+// RenderPreview draws the named page, or the first one, with the settings of cfg
+// and without instrument values, for example to check a configuration without a
+// device. It returns an error if cfg has no such page. It must not be called while
+// Run runs.
+func RenderPreview(cfg *configuration.NexusConfig, page string) (*image.RGBA, error) {
+	if page != "" {
+		candidatePages := &pageManager{}
+		candidatePages.configure(cfg.Pages)
+		if _, err := candidatePages.activate(page); err != nil {
+			return nil, err
+		}
+	}
+	return defaultNexus.renderPreview(&displayState{}, cfg, page), nil
+}
+
+// configPreviewHandler renders one frame with a candidate configuration and
+// answers with it as PNG (POST /api/config/preview). The body is a configuration
+// like for POST /api/config, settings it leaves out keep their current values.
+// Nothing is saved and the device keeps showing the live configuration. The
+// latest instrument values are drawn, weather in the current unit.
+//
+// Query parameters:
+//   - page: page to render (default: the active page)
+func (n *Nexus) configPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	current := n.configs.Get()
+	if current == nil {
+		http.Error(w, "No configuration available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Decoding fills the maps and slices of the copy, which must not be the live ones
+	candidate, err := current.Clone()
+	if err != nil {
+		http.Error(w, "Failed to copy the configuration", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(candidate); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := candidate.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	request := configPreviewRequest{config: candidate, page: r.URL.Query().Get("page"), frame: make(chan *image.RGBA, 1)}
+	timeout := time.NewTimer(configPreviewTimeout)
+	defer timeout.Stop()
+
+	select {
+	case n.configPreviews <- request:
+	case <-timeout.C:
+		http.Error(w, "Display loop is not running", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	var frame *image.RGBA
+	select {
+	case frame = <-request.frame:
+	case <-r.Context().Done():
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	png.Encode(w, frame)
+}
@@ -0,0 +1,68 @@
+package nexus
+
+import (
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"nexus-open/nexus/configuration"
+)
+
+// TestConfigPreviewKeepsLiveConfig checks that a preview request, which decodes
+// into a copy of the live configuration, leaves its maps and slices alone.
+func TestConfigPreviewKeepsLiveConfig(t *testing.T) {
+	n := New()
+	live := configuration.DefaultConfig()
+	live.WidgetOffsets = map[string]configuration.WidgetOffset{configuration.WidgetClock: {X: 4}}
+	live.Feeds = []string{"https://example.com/a.xml", "https://example.com/b.xml"}
+	n.configs.Set(live)
+	want := live.Map()
+
+	// Answer for the display loop
+	go func() {
+		request := <-n.configPreviews
+		request.frame <- image.NewRGBA(image.Rect(0, 0, 1, 1))
+	}()
+
+	body := `{"WidgetOffsets": {"clock": {"X": 99}, "weather": {"X": 5}}, "Feeds": ["https://example.com/feed.xml"]}`
+	w := httptest.NewRecorder()
+	n.configPreviewHandler(w, httptest.NewRequest(http.MethodPost, "/api/config/preview", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("preview answered %d: %s", w.Code, w.Body)
+	}
+	if got := n.configs.Get().Map(); !reflect.DeepEqual(got, want) {
+		t.Errorf("live configuration changed to widget_offsets %v, feeds %v", got["widget_offsets"], got["feeds"])
+	}
+}
+
+// TestRenderPreviewKeepsSettings checks that rendering a preview leaves the
+// drawing settings of the live renderer alone.
+func TestRenderPreviewKeepsSettings(t *testing.T) {
+	n := New()
+	cfg := testConfig(t, configuration.WidgetTemperatures)
+	cfg.TextColor = "red"
+	cfg.TimeFormat = "24h"
+	cfg.Locale = "de-DE"
+	cfg.Timezone = "Asia/Tokyo"
+
+	n.renderPreview(testState(), cfg, "test")
+
+	if got, want := n.renderer.textColor.Load(), (color.RGBA{R: 255, G: 255, B: 255, A: 255}); got != want {
+		t.Errorf("text color = %v, want %v", got, want)
+	}
+	if got := n.renderer.timeFormat.Load(); got != "12h" {
+		t.Errorf("time format = %q, want 12h", got)
+	}
+	if got := n.renderer.formatDecimal(1.5, 1); got != "1.5" {
+		t.Errorf("formatDecimal(1.5, 1) = %q, want 1.5", got)
+	}
+	if got := n.renderer.location(); got != time.Local {
+		t.Errorf("time zone = %v, want local time", got)
+	}
+}
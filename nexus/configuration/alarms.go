@@ -0,0 +1,51 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Alarm takes over the display with a flashing banner at a time of day until it
+// is dismissed by touch, e.g. {Time: "07:30", Days: ["mon", "tue", "wed", "thu",
+// "fri"], Label: "Stand-up"}. Alarms use the clock's time zone.
+type Alarm struct {
+	// Time is the "HH:MM" time the alarm goes off
+	Time string `mapstructure:"time"`
+
+	// Days lists the days the alarm goes off: sun, mon, tue, wed, thu, fri or sat.
+	// Every day if empty.
+	Days []string `mapstructure:"days"`
+
+	// Label is shown on the banner (default "Alarm")
+	Label string `mapstructure:"label"`
+}
+
+// Validate checks the time and days.
+func (a Alarm) Validate() error {
+	if _, err := time.Parse(scheduleTimeLayout, a.Time); err != nil {
+		return fmt.Errorf("invalid time %q, expected HH:MM", a.Time)
+	}
+
+	for _, day := range a.Days {
+		if !slices.Contains(scheduleDays, strings.ToLower(day)) {
+			return fmt.Errorf("invalid day %q, expected one of %s", day, strings.Join(scheduleDays, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Due reports whether the alarm goes off in the minute of t. Invalid alarms are
+// never due.
+func (a Alarm) Due(t time.Time) bool {
+	at, err := time.Parse(scheduleTimeLayout, a.Time)
+	if err != nil || at.Hour() != t.Hour() || at.Minute() != t.Minute() {
+		return false
+	}
+
+	return len(a.Days) == 0 || slices.ContainsFunc(a.Days, func(d string) bool {
+		return strings.EqualFold(d, scheduleDays[t.Weekday()])
+	})
+}
@@ -0,0 +1,73 @@
+package configuration
+
+import "fmt"
+
+// Alert actions
+const (
+	AlertActionColor = "color" // Draw the value in the alert color
+	AlertActionFlash = "flash" // Flash the value in the alert color
+	AlertActionPage  = "page"  // Replace the display with a full-screen alert page
+
+	AlertColor = "#FF0000"
+)
+
+// AlertRule triggers an alert when an instrument metric crosses a threshold,
+// e.g. {Metric: "temperature.cpu", Operator: ">", Threshold: 85, Action: "flash"}.
+type AlertRule struct {
+	// Metric is the instrument metric to watch, written as "<instrument>.<metric>"
+	Metric string `mapstructure:"metric"`
+
+	// Operator is one of ">", ">=", "<" or "<="
+	Operator string `mapstructure:"operator"`
+
+	// Threshold is the value the metric is compared against
+	Threshold float64 `mapstructure:"threshold"`
+
+	// Action is one of "color", "flash" or "page" (default "color")
+	Action string `mapstructure:"action"`
+
+	// Color is the color used to draw the alert (default red)
+	Color string `mapstructure:"color"`
+}
+
+// Matches reports whether value crosses the rule's threshold.
+func (r AlertRule) Matches(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	default:
+		return false
+	}
+}
+
+// Validate checks that the rule names a metric and uses a known operator, action
+// and color.
+func (r AlertRule) Validate() error {
+	if r.Metric == "" {
+		return fmt.Errorf("alert rule is missing a metric")
+	}
+
+	switch r.Operator {
+	case ">", ">=", "<", "<=":
+	default:
+		return fmt.Errorf("alert rule for %s has invalid operator %q", r.Metric, r.Operator)
+	}
+
+	switch r.Action {
+	case "", AlertActionColor, AlertActionFlash, AlertActionPage:
+	default:
+		return fmt.Errorf("alert rule for %s has invalid action %q", r.Metric, r.Action)
+	}
+
+	if err := validateColor(r.Color); err != nil {
+		return fmt.Errorf("alert rule for %s has %w", r.Metric, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package configuration
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// APICORSOrigins are the origins allowed to call the API by default: the Vite and
+// Wails development servers and the Wails production origins.
+var APICORSOrigins = []string{
+	"http://localhost:5173",
+	"http://localhost:34115",
+	"wails://wails",
+	"http://wails.localhost",
+}
+
+// defaultTLSPath is the relative path to the directory holding generated certificates
+const defaultTLSPath = "nexus-open/tls"
+
+// APIListen is the default address of the HTTP API. It only accepts local
+// connections; bind to ":1985" or "0.0.0.0:1985" to expose it on the network.
+const APIListen = "127.0.0.1:1985"
+
+// APIListenDisabled as the listen address disables the TCP listener, so the API is
+// only reachable on its unix socket.
+const APIListenDisabled = "none"
+
+// APIConfig configures the HTTP API server
+type APIConfig struct {
+	// Listen is the host:port the API binds to, APIListen if empty.
+	// Changes apply after a restart.
+	Listen string `mapstructure:"listen"`
+
+	// Socket is the path of a unix domain socket the API listens on in addition to
+	// Listen. Only the user running nexus-open may connect to it. Changes apply after
+	// a restart.
+	Socket string `mapstructure:"socket"`
+
+	// TLS enables HTTPS for the API. Changes apply after a restart.
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// CORSOrigins lists the browser origins (scheme://host[:port]) allowed to call
+	// the API from another origin. "*" allows any origin.
+	CORSOrigins []string `mapstructure:"cors_origins"`
+
+	// MetricsInstruments adds the latest instrument values, such as temperatures and
+	// network throughput, to the Prometheus metrics served at /metrics
+	MetricsInstruments bool `mapstructure:"metrics_instruments"`
+}
+
+// TLSConfig configures HTTPS for the API server. Either a certificate and key pair
+// is given, or SelfSigned generates one on first start.
+type TLSConfig struct {
+	// CertFile and KeyFile are paths to a PEM encoded certificate and private key
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+
+	// SelfSigned generates and reuses a self-signed certificate when no
+	// certificate files are configured
+	SelfSigned bool `mapstructure:"self_signed"`
+}
+
+// Enabled reports whether the API is served over HTTPS.
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || t.SelfSigned
+}
+
+// ListenAddress returns the configured listen address or the default.
+func (a APIConfig) ListenAddress() string {
+	if a.Listen == "" {
+		return APIListen
+	}
+	return a.Listen
+}
+
+// Validate checks that Listen is empty, a valid host:port address or
+// APIListenDisabled, and that the API is reachable on at least one listener.
+func (a APIConfig) Validate() error {
+	if a.Listen == APIListenDisabled {
+		if a.Socket == "" {
+			return fmt.Errorf("api listen is %q but no api socket is configured", APIListenDisabled)
+		}
+	} else if a.Listen != "" {
+		if _, _, err := net.SplitHostPort(a.Listen); err != nil {
+			return fmt.Errorf("invalid api listen address %q: %w", a.Listen, err)
+		}
+	}
+
+	for _, origin := range a.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("invalid CORS origin %q", origin)
+		}
+	}
+
+	if (a.TLS.CertFile == "") != (a.TLS.KeyFile == "") {
+		return fmt.Errorf("api tls requires both cert_file and key_file")
+	}
+
+	return nil
+}
+
+// GetTLSDir returns the absolute path of the directory holding generated TLS
+// certificates. It ensures the directory exists, creating it if necessary.
+func GetTLSDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	tlsPath := filepath.Join(configDir, defaultTLSPath)
+	return tlsPath, os.MkdirAll(tlsPath, 0700)
+}
@@ -0,0 +1,58 @@
+package configuration
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	// Time zones must resolve on systems without a zoneinfo database, like Windows
+	_ "time/tzdata"
+)
+
+// TimezoneLocal shows the time of the system's time zone.
+const TimezoneLocal = "Local"
+
+// WorldClock is an additional clock shown next to the main time, e.g.
+// {Label: "NYC", Timezone: "America/New_York"}.
+type WorldClock struct {
+	// Label is shown before the time (default: the city of the time zone)
+	Label string `mapstructure:"label"`
+
+	// Timezone is an IANA time zone name like "Europe/Berlin" or "UTC"
+	Timezone string `mapstructure:"timezone"`
+}
+
+// Name returns the label of the clock, or the city of its time zone if it has
+// none, e.g. "New York" for "America/New_York".
+func (w WorldClock) Name() string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return strings.ReplaceAll(path.Base(w.Timezone), "_", " ")
+}
+
+// Validate checks the time zone exists.
+func (w WorldClock) Validate() error {
+	if w.Timezone == "" {
+		return fmt.Errorf("world clock is missing a timezone")
+	}
+	if _, err := LoadTimezone(w.Timezone); err != nil {
+		return err
+	}
+	return nil
+}
+
+// LoadTimezone returns the time zone with the given IANA name. An empty name or
+// TimezoneLocal is the system's time zone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, TimezoneLocal) {
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
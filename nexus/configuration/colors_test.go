@@ -0,0 +1,60 @@
+package configuration
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want color.RGBA
+		ok   bool
+	}{
+		{"#ff8800", color.RGBA{255, 136, 0, 255}, true},
+		{"#FF8800", color.RGBA{255, 136, 0, 255}, true},
+		{"#f80", color.RGBA{255, 136, 0, 255}, true},
+		{"#f808", color.RGBA{136, 73, 0, 136}, true},
+		{"#ff880080", color.RGBA{128, 68, 0, 128}, true},
+		{" #ff8800 ", color.RGBA{255, 136, 0, 255}, true},
+		{"#ff880", color.RGBA{}, false},
+		{"#gg8800", color.RGBA{}, false},
+		{"#", color.RGBA{}, false},
+		{"rgb(255, 136, 0)", color.RGBA{255, 136, 0, 255}, true},
+		{"rgb(255 136 0)", color.RGBA{255, 136, 0, 255}, true},
+		{"rgb(100%, 0%, 50%)", color.RGBA{255, 0, 128, 255}, true},
+		{"rgb(300, -20, 0)", color.RGBA{255, 0, 0, 255}, true},
+		{"rgba(255, 0, 0, 0.5)", color.RGBA{128, 0, 0, 128}, true},
+		{"rgba(255 0 0 / 50%)", color.RGBA{128, 0, 0, 128}, true},
+		{"rgb(255, 0)", color.RGBA{}, false},
+		{"rgb(255, 0, 0, 1, 1)", color.RGBA{}, false},
+		{"rgb(red, 0, 0)", color.RGBA{}, false},
+		{"rgb(nan, 0, 0)", color.RGBA{}, false},
+		{"rgb(255, 0, 0", color.RGBA{}, false},
+		{"hsl(0, 100%, 50%)", color.RGBA{255, 0, 0, 255}, true},
+		{"hsl(120deg 100% 25%)", color.RGBA{0, 128, 0, 255}, true},
+		{"hsl(-120, 100%, 50%)", color.RGBA{0, 0, 255, 255}, true},
+		{"hsl(480, 100%, 50%)", color.RGBA{0, 255, 0, 255}, true},
+		{"hsla(0, 0%, 100%, 0.5)", color.RGBA{128, 128, 128, 128}, true},
+		{"hsl(0, 100, 50%)", color.RGBA{}, false},
+		{"hsl(nan, 50%, 50%)", color.RGBA{}, false},
+		{"hsl(inf, 50%, 50%)", color.RGBA{}, false},
+		{"hsl(-Infinity, 50%, 50%)", color.RGBA{}, false},
+		{"hsl(0, nan%, 50%)", color.RGBA{}, false},
+		{"cmyk(0, 0, 0, 0)", color.RGBA{}, false},
+		{"red", color.RGBA{255, 0, 0, 255}, true},
+		{"RebeccaPurple", color.RGBA{102, 51, 153, 255}, true},
+		{"transparent", color.RGBA{}, true},
+		{"ultraviolet", color.RGBA{}, false},
+		{"", color.RGBA{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := ParseColor(tt.s)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseColor(%q) = %v, %v, want %v, %v", tt.s, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
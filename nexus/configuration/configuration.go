@@ -3,19 +3,47 @@
 package configuration
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	"nexus-open/nexus/logging"
 
 	"github.com/spf13/viper"
+	"golang.org/x/text/language"
 )
 
+// configLog logs loading and migrating configuration files.
+var configLog = logging.Component("config")
+
 const (
-	// defaultConfigPath is the relative path to the configuration file
+	// envPrefix starts the environment variables overriding settings
+	envPrefix = "NEXUS"
+
+	// defaultConfigDir is the relative path to the directory of the configuration file
+	defaultConfigDir = "nexus-open"
+	// defaultConfigPath is the relative path to the configuration file created on first start
 	defaultConfigPath = "nexus-open/config.yaml"
 	// defaultImagesPath is the relative path to the images directory
 	defaultImagesPath = "nexus-open/images"
+	// defaultPluginsPath is the relative path to the plugins directory
+	defaultPluginsPath = "nexus-open/plugins"
+	// defaultLockPath is the relative path to the file locked by the running instance
+	defaultLockPath = "nexus-open/nexus.lock"
+	// defaultLogPath is the relative path to the log file, see GetLogPath
+	defaultLogPath = "nexus-open/logs/nexus.log"
+
+	// LocationAuto opts in to detecting the location from the public IP address
+	LocationAuto = "auto"
 
 	// Configuration defaults and valid values
 	Location         = "Jersey City, NJ"
@@ -26,16 +54,56 @@ const (
 	TextColor        = "#FFFFFF"
 	BackgroundColor  = "#000000"
 	BackgroundImage  = "background.png"
+	Font             = "HackNerdFont-Regular.ttf"
+	FontSize         = 13.0
+
+	// MinFontSize and MaxFontSize bound the font size, in points at 72 DPI, i.e. pixels
+	MinFontSize = 6.0
+	MaxFontSize = 48.0
+
+	// GraphMinutes is how far back the graphs reach by default, bounded by
+	// MinGraphMinutes and MaxGraphMinutes
+	GraphMinutes    = 10
+	MinGraphMinutes = 10
+	MaxGraphMinutes = 60
+
+	// MinPollInterval and MaxPollInterval bound the polling interval of an instrument
+	MinPollInterval = time.Second
+	MaxPollInterval = 24 * time.Hour
 )
 
 // NexusConfig holds the application configuration
 type NexusConfig struct {
-	// Location represents the user's city
+	// Version is the SchemaVersion the configuration file was written for
+	Version int `mapstructure:"version"`
+
+	// Location represents the user's city, "lat,lon" coordinates, or "auto"
+	// to detect the location from the public IP address
 	Location string `mapstructure:"location"`
 
+	// Geocoded holds the coordinates of a place name Location, recorded when the
+	// configuration is saved through the API
+	Geocoded GeocodedLocation `mapstructure:"geocoded"`
+
 	// TimeFormat can be either "12h" or "24h"
 	TimeFormat string `mapstructure:"time_format"`
 
+	// Timezone is the IANA time zone of the clock, e.g. "UTC" (default: the
+	// system's time zone)
+	Timezone string `mapstructure:"timezone"`
+
+	// WorldClocks are additional clocks shown next to the main time
+	WorldClocks []WorldClock `mapstructure:"world_clocks"`
+
+	// Locale is the BCP 47 locale used to format numbers and dates on the display,
+	// e.g. "de-DE" (default "en-US")
+	Locale string `mapstructure:"locale"`
+
+	// Language is the BCP 47 language of the texts on the display, like day names,
+	// e.g. "de" (default: the language of Locale). Texts without a translation
+	// are shown in English.
+	Language string `mapstructure:"language"`
+
 	// Unit represents the temperature unit (metric/imperial)
 	Unit string `mapstructure:"unit"`
 
@@ -48,8 +116,334 @@ type NexusConfig struct {
 	// TextColor is a hex color string (e.g., "#FFFFFF")
 	TextColor string `mapstructure:"text_color"`
 
+	// Font is the file name of a font in the system font directories, or the
+	// path to a font file. Another system font is used if it cannot be loaded.
+	Font string `mapstructure:"font"`
+
+	// FontSize is the size of text in pixels
+	FontSize float64 `mapstructure:"font_size"`
+
+	// ShowTemps, ShowNetwork, ShowClock, ShowWeather, ShowVolume, ShowMedia and
+	// ShowTicker hide a widget on every page when false (default true)
+	ShowTemps   bool `mapstructure:"show_temps"`
+	ShowNetwork bool `mapstructure:"show_network"`
+	ShowClock   bool `mapstructure:"show_clock"`
+	ShowWeather bool `mapstructure:"show_weather"`
+	ShowVolume  bool `mapstructure:"show_volume"`
+	ShowMedia   bool `mapstructure:"show_media"`
+	ShowTicker  bool `mapstructure:"show_ticker"`
+
+	// ShowKeyboard shows the lock keys and keyboard layout (default false, the
+	// main page has little room left)
+	ShowKeyboard bool `mapstructure:"show_keyboard"`
+
+	// GraphMinutes is how many minutes of history the graph widgets plot
+	GraphMinutes int `mapstructure:"graph_minutes"`
+
+	// WidgetOffsets moves widgets from their built-in position, keyed by widget
+	// name, e.g. "clock": {x: -6, y: 2}
+	WidgetOffsets map[string]WidgetOffset `mapstructure:"widget_offsets"`
+
 	// ImagePaths contains the list of image filenames
 	ImagePaths []string `mapstructure:"image_paths"`
+
+	// Intervals overrides instrument polling intervals keyed by instrument name
+	// (e.g. "weather": "15m"). Values use Go duration syntax.
+	Intervals map[string]string `mapstructure:"intervals"`
+
+	// Alerts defines threshold rules evaluated against every instrument sample
+	Alerts []AlertRule `mapstructure:"alerts"`
+
+	// News configures the news headline ticker
+	News NewsConfig `mapstructure:"news"`
+
+	// Feeds lists RSS or Atom feed URLs whose headlines are shown in the ticker
+	Feeds []string `mapstructure:"feeds"`
+
+	// Stocks configures the stock quote ticker
+	Stocks StocksConfig `mapstructure:"stocks"`
+
+	// Calendar configures the next calendar event widget
+	Calendar CalendarConfig `mapstructure:"calendar"`
+
+	// Media configures the now-playing widget
+	Media MediaConfig `mapstructure:"media"`
+
+	// MQTT configures the MQTT broker connection, subscriptions and touch actions
+	MQTT MQTTConfig `mapstructure:"mqtt"`
+
+	// Prometheus configures the Prometheus query widget
+	Prometheus PrometheusConfig `mapstructure:"prometheus"`
+
+	// OctoPrint configures the 3D printer progress widget
+	OctoPrint OctoPrintConfig `mapstructure:"octoprint"`
+
+	// SMART configures the disk health widget
+	SMART SMARTConfig `mapstructure:"smart"`
+
+	// VPN configures the VPN status widget
+	VPN VPNConfig `mapstructure:"vpn"`
+
+	// Notifications relays the notifications of desktop apps to the display
+	Notifications DesktopNotificationsConfig `mapstructure:"notifications"`
+
+	// API configures the HTTP API server
+	API APIConfig `mapstructure:"api"`
+
+	// ReadOnly rejects API requests that change the configuration, the images or
+	// what the display shows, e.g. frames, notifications, brightness and pages, for
+	// panels in shared spaces. It can only be turned off in the file.
+	ReadOnly bool `mapstructure:"read_only"`
+
+	// Webhooks lists URLs notified about device, touch and alert events
+	Webhooks []Webhook `mapstructure:"webhooks"`
+
+	// Pages replaces the built-in pages when it is not empty
+	Pages []PageConfig `mapstructure:"pages"`
+
+	// Brightness is the display brightness in percent on start
+	Brightness int `mapstructure:"brightness"`
+
+	// Rotation turns the display by 0 or 180 degrees
+	Rotation int `mapstructure:"rotation"`
+
+	// Devices overrides the pages, brightness and rotation per device, keyed by
+	// USB serial number
+	Devices map[string]DeviceConfig `mapstructure:"devices"`
+
+	// Schedules switch the theme and page at times of day, the first active one wins
+	Schedules []Schedule `mapstructure:"schedules"`
+
+	// Idle blanks or slows the display while the user is away from the computer
+	Idle IdleConfig `mapstructure:"idle"`
+
+	// Shutdown sets the frame left on the display when Nexus stops
+	Shutdown ShutdownConfig `mapstructure:"shutdown"`
+
+	// Pomodoro sets the work and break durations of the pomodoro widget
+	Pomodoro PomodoroConfig `mapstructure:"pomodoro"`
+
+	// Alarms take over the display at times of day until dismissed by touch
+	Alarms []Alarm `mapstructure:"alarms"`
+}
+
+// Validate checks the configuration for values that cannot be applied. Every
+// invalid value is reported, the returned error is a *ValidationError.
+func (c *NexusConfig) Validate() error {
+	var errs ValidationError
+
+	if c.Version > SchemaVersion {
+		errs.add("version", fmt.Errorf("version %d is newer than the supported version %d", c.Version, SchemaVersion))
+	}
+
+	if err := oneOf(c.TimeFormat, TimeFormat12Hour, TimeFormat24Hour); err != nil {
+		errs.add("time_format", err)
+	}
+
+	if _, err := LoadTimezone(c.Timezone); err != nil {
+		errs.add("timezone", err)
+	}
+
+	for i, clock := range c.WorldClocks {
+		if err := clock.Validate(); err != nil {
+			errs.add(fmt.Sprintf("world_clocks[%d]", i), err)
+		}
+	}
+
+	if _, err := language.Parse(c.Locale); c.Locale != "" && err != nil {
+		errs.add("locale", fmt.Errorf("invalid locale %q, expected a BCP 47 tag like \"en-US\"", c.Locale))
+	}
+
+	if _, err := language.Parse(c.Language); c.Language != "" && err != nil {
+		errs.add("language", fmt.Errorf("invalid language %q, expected a BCP 47 tag like \"de\"", c.Language))
+	}
+
+	if err := oneOf(c.Unit, UnitMetric, UnitImperial); err != nil {
+		errs.add("unit", err)
+	}
+
+	if err := c.Geocoded.Validate(); err != nil {
+		errs.add("geocoded", err)
+	}
+
+	if err := validateColor(c.TextColor); err != nil {
+		errs.add("text_color", err)
+	}
+
+	if err := validateColor(c.BackgroundColor); err != nil {
+		errs.add("background_color", err)
+	}
+
+	if c.FontSize < MinFontSize || c.FontSize > MaxFontSize {
+		errs.add("font_size", fmt.Errorf("must be between %v and %v, got %v", MinFontSize, MaxFontSize, c.FontSize))
+	}
+
+	if c.GraphMinutes < MinGraphMinutes || c.GraphMinutes > MaxGraphMinutes {
+		errs.add("graph_minutes", fmt.Errorf("must be between %d and %d, got %d", MinGraphMinutes, MaxGraphMinutes, c.GraphMinutes))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Intervals)) {
+		if _, err := parsePollInterval(c.Intervals[name]); err != nil {
+			errs.add("intervals."+name, err)
+		}
+	}
+
+	if err := c.API.Validate(); err != nil {
+		errs.add("api", err)
+	}
+
+	for i, rule := range c.Alerts {
+		if err := rule.Validate(); err != nil {
+			errs.add(fmt.Sprintf("alerts[%d]", i), err)
+		}
+	}
+
+	if err := c.News.Validate(); err != nil {
+		errs.add("news", err)
+	}
+
+	if err := c.Stocks.Validate(); err != nil {
+		errs.add("stocks", err)
+	}
+
+	if err := c.Calendar.Validate(); err != nil {
+		errs.add("calendar", err)
+	}
+
+	if err := c.Media.Validate(); err != nil {
+		errs.add("media", err)
+	}
+
+	if err := c.MQTT.Validate(); err != nil {
+		errs.add("mqtt", err)
+	}
+
+	if err := c.Prometheus.Validate(); err != nil {
+		errs.add("prometheus", err)
+	}
+
+	if err := c.OctoPrint.Validate(); err != nil {
+		errs.add("octoprint", err)
+	}
+
+	if err := c.SMART.Validate(); err != nil {
+		errs.add("smart", err)
+	}
+
+	if err := c.VPN.Validate(); err != nil {
+		errs.add("vpn", err)
+	}
+
+	if err := c.Notifications.Validate(); err != nil {
+		errs.add("notifications", err)
+	}
+
+	for i, webhook := range c.Webhooks {
+		if err := webhook.Validate(); err != nil {
+			errs.add(fmt.Sprintf("webhooks[%d]", i), err)
+		}
+	}
+
+	for widget, offset := range c.WidgetOffsets {
+		if !KnownWidget(widget) {
+			errs.add("widget_offsets."+widget, fmt.Errorf("unknown widget %q", widget))
+		} else if err := offset.Validate(); err != nil {
+			errs.add("widget_offsets."+widget, err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, page := range c.Pages {
+		if err := page.Validate(); err != nil {
+			errs.add(fmt.Sprintf("pages[%d]", i), err)
+		} else if names[page.Name] {
+			errs.add(fmt.Sprintf("pages[%d]", i), fmt.Errorf("duplicate page %q", page.Name))
+		}
+		names[page.Name] = true
+	}
+
+	if err := validateBrightness(c.Brightness); err != nil {
+		errs.add("brightness", err)
+	}
+
+	if err := validateRotation(c.Rotation); err != nil {
+		errs.add("rotation", err)
+	}
+
+	for serial, device := range c.Devices {
+		if err := device.Validate(); err != nil {
+			errs.add("devices."+serial, err)
+		}
+	}
+
+	scheduleNames := make(map[string]bool)
+	for i, schedule := range c.Schedules {
+		switch err := schedule.Validate(); {
+		case err != nil:
+			errs.add(fmt.Sprintf("schedules[%d]", i), err)
+		case scheduleNames[schedule.Name]:
+			errs.add(fmt.Sprintf("schedules[%d]", i), fmt.Errorf("duplicate schedule %q", schedule.Name))
+		case schedule.Page != "" && len(c.Pages) > 0 && !names[schedule.Page]:
+			errs.add(fmt.Sprintf("schedules[%d]", i), fmt.Errorf("schedule %s has unknown page %q", schedule.Name, schedule.Page))
+		}
+		scheduleNames[schedule.Name] = true
+	}
+
+	for i, feedURL := range c.Feeds {
+		if u, err := url.Parse(feedURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs.add(fmt.Sprintf("feeds[%d]", i), fmt.Errorf("invalid feed URL %q", feedURL))
+		}
+	}
+
+	if err := c.Idle.Validate(); err != nil {
+		errs.add("idle", err)
+	}
+
+	if err := c.Shutdown.Validate(); err != nil {
+		errs.add("shutdown", err)
+	}
+
+	if err := c.Pomodoro.Validate(); err != nil {
+		errs.add("pomodoro", err)
+	}
+
+	for i, alarm := range c.Alarms {
+		if err := alarm.Validate(); err != nil {
+			errs.add(fmt.Sprintf("alarms[%d]", i), err)
+		}
+	}
+
+	if len(errs.Fields) == 0 {
+		return nil
+	}
+	return &errs
+}
+
+// PollIntervals parses and validates the configured instrument polling intervals.
+// It returns an error if an interval is not a valid duration or is outside
+// MinPollInterval and MaxPollInterval.
+func (c *NexusConfig) PollIntervals() (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration, len(c.Intervals))
+	for name, value := range c.Intervals {
+		interval, err := parsePollInterval(value)
+		if err != nil {
+			return nil, fmt.Errorf("interval for %s: %w", name, err)
+		}
+		intervals[name] = interval
+	}
+	return intervals, nil
+}
+
+// parsePollInterval parses a polling interval and checks that it is within range.
+func parsePollInterval(value string) (time.Duration, error) {
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	if interval < MinPollInterval || interval > MaxPollInterval {
+		return 0, fmt.Errorf("must be between %v and %v, got %v", MinPollInterval, MaxPollInterval, interval)
+	}
+	return interval, nil
 }
 
 // Configuration state
@@ -59,6 +453,60 @@ var (
 	unit     string // Current unit setting
 )
 
+// configTypes maps the extensions of supported configuration files to their
+// viper config type.
+var configTypes = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+	".toml": "toml",
+}
+
+// configFileNames are the configuration files looked for in the configuration
+// directory, in order of precedence.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json", "config.toml"}
+
+// configPathOverride is the configuration file set with SetConfigPath.
+var configPathOverride string
+
+// SetConfigPath sets the configuration file used instead of the default one,
+// e.g. from a --config flag. Its extension selects the format: .yaml, .yml,
+// .json or .toml. It must be called before the configuration is first loaded.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+// GetConfigPath returns the absolute path to the configuration file. It is the
+// file set with SetConfigPath if any; otherwise the first of config.yaml,
+// config.yml, config.json and config.toml present in the nexus-open configuration
+// directory, or config.yaml if there is none yet.
+func GetConfigPath() (string, error) {
+	if configPathOverride != "" {
+		return filepath.Abs(configPathOverride)
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range configFileNames {
+		path := filepath.Join(configDir, defaultConfigDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return filepath.Join(configDir, defaultConfigPath), nil
+}
+
+// configType returns the viper config type of the configuration file at path.
+func configType(path string) (string, error) {
+	if configType, ok := configTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return configType, nil
+	}
+	return "", fmt.Errorf("unsupported config file %s, expected a .yaml, .yml, .json or .toml file", path)
+}
+
 // GetImagesDir returns the absolute path to the application's images directory.
 // It ensures the directory exists, creating it if necessary.
 func GetImagesDir() (string, error) {
@@ -70,36 +518,116 @@ func GetImagesDir() (string, error) {
 	return imagesPath, os.MkdirAll(imagesPath, 0755)
 }
 
-// createDefaultConfig creates a new configuration file with default values
-func createDefaultConfig(path string) error {
-	defaultConfig := &NexusConfig{
+// GetPluginsDir returns the absolute path to the directory plugin executables are
+// discovered in. It ensures the directory exists, creating it if necessary.
+func GetPluginsDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	pluginsPath := filepath.Join(configDir, defaultPluginsPath)
+	return pluginsPath, os.MkdirAll(pluginsPath, 0755)
+}
+
+// GetLockPath returns the absolute path to the file locked by the running instance,
+// which holds its process ID. It ensures the directory exists, creating it if
+// necessary.
+func GetLockPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	lockPath := filepath.Join(configDir, defaultLockPath)
+	return lockPath, os.MkdirAll(filepath.Dir(lockPath), 0755)
+}
+
+// GetLogPath returns the absolute path to the log file written with --log-output
+// file. Rotated files are kept next to it. It ensures the directory exists,
+// creating it if necessary.
+func GetLogPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	logPath := filepath.Join(configDir, defaultLogPath)
+	return logPath, os.MkdirAll(filepath.Dir(logPath), 0755)
+}
+
+// DefaultConfig returns the configuration written on first start.
+func DefaultConfig() *NexusConfig {
+	return &NexusConfig{
+		Version:         SchemaVersion,
 		Location:        Location,
 		TimeFormat:      TimeFormat12Hour,
+		WorldClocks:     []WorldClock{},
 		Unit:            UnitImperial,
 		BackgroundColor: BackgroundColor,
 		BackgroundImage: BackgroundImage,
 		TextColor:       TextColor,
+		Font:            Font,
+		FontSize:        FontSize,
+		ShowTemps:       true,
+		ShowNetwork:     true,
+		ShowClock:       true,
+		ShowWeather:     true,
+		ShowVolume:      true,
+		ShowMedia:       true,
+		ShowTicker:      true,
+		ShowKeyboard:    false,
+		GraphMinutes:    GraphMinutes,
+		WidgetOffsets:   map[string]WidgetOffset{},
 		ImagePaths:      []string{},
+		Intervals:       map[string]string{},
+		Alerts:          []AlertRule{},
+		News:            NewsConfig{Country: "us"},
+		Feeds:           []string{},
+		Stocks:          StocksConfig{Symbols: []string{}},
+		Calendar:        CalendarConfig{ICS: []string{}},
+		MQTT:            MQTTConfig{Topics: []MQTTTopic{}, Actions: []MQTTAction{}},
+		Prometheus:      PrometheusConfig{Queries: []PrometheusQuery{}},
+		SMART:           SMARTConfig{Drives: []string{}},
+		Notifications:   DesktopNotificationsConfig{Apps: []string{}, Ignore: []string{}},
+		API:             APIConfig{Listen: APIListen, CORSOrigins: APICORSOrigins},
+		Webhooks:        []Webhook{},
+		Schedules:       []Schedule{},
+		Brightness:      MaxBrightness,
+		Devices:         map[string]DeviceConfig{},
+		Idle:            IdleConfig{Action: IdleActionBlank},
+		Shutdown:        ShutdownConfig{Screen: ShutdownScreenBlack, Text: ShutdownText},
+		Pomodoro:        PomodoroConfig{Work: PomodoroWork, Break: PomodoroBreak},
+		Alarms:          []Alarm{},
 	}
+}
 
+// createDefaultConfig creates a new configuration file with default values
+func createDefaultConfig(path string) error {
 	// Ensure the directory exists
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
-	return SaveConfig(defaultConfig, path)
+	return SaveConfig(DefaultConfig(), path)
 }
 
-// LoadConfig reads configuration from a YAML file or environment variables.
-// If path is empty, it uses the default configuration location.
+// LoadConfig reads configuration from a YAML, JSON or TOML file, chosen by its
+// extension, or environment variables. If path is empty, it uses GetConfigPath.
+// Every setting can be overridden by the environment variable named by EnvVar,
+// e.g. NEXUS_LOCATION or NEXUS_API_LISTEN; lists are comma separated.
 // The function also ensures the images directory exists during initial setup.
+// Invalid values are reported together as a *ValidationError whose fields carry
+// their line in the file. Unknown keys, e.g. of newer versions or plugins, are
+// only logged as warnings.
 func LoadConfig(path string) (*NexusConfig, error) {
 	if path == "" {
-		configDir, err := os.UserConfigDir()
-		if err != nil {
+		var err error
+		if path, err = GetConfigPath(); err != nil {
 			return nil, err
 		}
-		path = filepath.Join(configDir, defaultConfigPath)
+	}
+
+	fileType, err := configType(path)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create default config if file doesn't exist
@@ -114,17 +642,94 @@ func LoadConfig(path string) (*NexusConfig, error) {
 		return nil, err
 	}
 
+	// Upgrade files written by older versions
+	if err := migrateConfigFile(path); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
 	viper.SetConfigFile(path)
-	viper.SetConfigType("yaml")
+	viper.SetConfigType(fileType)
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
+	viper.SetDefault("version", SchemaVersion)
 	viper.SetDefault("location", Location)
+	viper.SetDefault("geocoded", GeocodedLocation{})
 	viper.SetDefault("time_format", TimeFormat24Hour)
+	viper.SetDefault("timezone", "")
+	viper.SetDefault("world_clocks", []WorldClock{})
+	viper.SetDefault("locale", "")
+	viper.SetDefault("language", "")
 	viper.SetDefault("unit", UnitMetric)
 	viper.SetDefault("background_color", BackgroundColor)
 	viper.SetDefault("background_image", BackgroundImage)
 	viper.SetDefault("text_color", TextColor)
+	viper.SetDefault("font", Font)
+	viper.SetDefault("font_size", FontSize)
+	viper.SetDefault("show_temps", true)
+	viper.SetDefault("show_network", true)
+	viper.SetDefault("show_clock", true)
+	viper.SetDefault("show_weather", true)
+	viper.SetDefault("show_volume", true)
+	viper.SetDefault("show_media", true)
+	viper.SetDefault("show_ticker", true)
+	viper.SetDefault("show_keyboard", false)
+	viper.SetDefault("graph_minutes", GraphMinutes)
+	viper.SetDefault("widget_offsets", map[string]WidgetOffset{})
 	viper.SetDefault("image_paths", []string{})
+	viper.SetDefault("intervals", map[string]string{})
+	viper.SetDefault("alerts", []AlertRule{})
+	viper.SetDefault("news.provider", NewsProviderNone)
+	viper.SetDefault("news.api_key", "")
+	viper.SetDefault("news.country", "us")
+	viper.SetDefault("feeds", []string{})
+	viper.SetDefault("stocks.provider", StocksProviderNone)
+	viper.SetDefault("stocks.api_key", "")
+	viper.SetDefault("stocks.symbols", []string{})
+	viper.SetDefault("calendar.ics", []string{})
+	viper.SetDefault("calendar.caldav.url", "")
+	viper.SetDefault("calendar.caldav.username", "")
+	viper.SetDefault("calendar.caldav.password", "")
+	viper.SetDefault("media.spotify.client_id", "")
+	viper.SetDefault("media.spotify.client_secret", "")
+	viper.SetDefault("media.spotify.refresh_token", "")
+	viper.SetDefault("mqtt.broker", "")
+	viper.SetDefault("mqtt.username", "")
+	viper.SetDefault("mqtt.password", "")
+	viper.SetDefault("mqtt.client_id", "")
+	viper.SetDefault("mqtt.topics", []MQTTTopic{})
+	viper.SetDefault("mqtt.actions", []MQTTAction{})
+	viper.SetDefault("prometheus.url", "")
+	viper.SetDefault("prometheus.queries", []PrometheusQuery{})
+	viper.SetDefault("octoprint.url", "")
+	viper.SetDefault("octoprint.api_key", "")
+	viper.SetDefault("smart.drives", []string{})
+	viper.SetDefault("vpn.interface", "")
+	viper.SetDefault("notifications.enabled", false)
+	viper.SetDefault("notifications.apps", []string{})
+	viper.SetDefault("notifications.ignore", []string{})
+	viper.SetDefault("api.listen", APIListen)
+	viper.SetDefault("api.socket", "")
+	viper.SetDefault("api.cors_origins", APICORSOrigins)
+	viper.SetDefault("api.tls.cert_file", "")
+	viper.SetDefault("api.tls.key_file", "")
+	viper.SetDefault("api.tls.self_signed", false)
+	viper.SetDefault("api.metrics_instruments", false)
+	viper.SetDefault("read_only", false)
+	viper.SetDefault("webhooks", []Webhook{})
+	viper.SetDefault("pages", []PageConfig{})
+	viper.SetDefault("schedules", []Schedule{})
+	viper.SetDefault("brightness", MaxBrightness)
+	viper.SetDefault("rotation", 0)
+	viper.SetDefault("devices", map[string]DeviceConfig{})
+	viper.SetDefault("idle.timeout", "")
+	viper.SetDefault("idle.action", IdleActionBlank)
+	viper.SetDefault("shutdown.screen", ShutdownScreenBlack)
+	viper.SetDefault("shutdown.text", ShutdownText)
+	viper.SetDefault("pomodoro.work", PomodoroWork)
+	viper.SetDefault("pomodoro.break", PomodoroBreak)
+	viper.SetDefault("alarms", []Alarm{})
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
@@ -136,41 +741,175 @@ func LoadConfig(path string) (*NexusConfig, error) {
 		return nil, err
 	}
 
-	fmt.Printf("Loaded configuration from %s\n", path)
+	if err := checkConfigFile(path, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	configLog.Info("Loaded configuration", "path", path)
 
 	return &config, nil
 }
 
-// SaveConfig writes the current configuration to a YAML file.
-// If path is empty, it uses the default configuration location
+// checkConfigFile validates config, loaded from the file at path, and warns about
+// the keys of the file that match no setting. Both point at their line in the file.
+func checkConfigFile(path string, config *NexusConfig) error {
+	var errs ValidationError
+
+	file, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, unknown := range file.unknownKeys() {
+		configLog.Warn("Ignoring unknown key of the configuration file", "path", path, "line", unknown.Line, "key", unknown.Field, "message", unknown.Message)
+	}
+
+	var validationErr *ValidationError
+	if err := config.Validate(); errors.As(err, &validationErr) {
+		for _, field := range validationErr.Fields {
+			field.Line = file.line(field.Field)
+			errs.Fields = append(errs.Fields, field)
+		}
+	}
+
+	if len(errs.Fields) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(errs.Fields, func(a, b FieldError) int {
+		return a.Line - b.Line
+	})
+	return &errs
+}
+
+// EnvVar returns the environment variable overriding the setting with the given
+// key, e.g. "NEXUS_API_LISTEN" for "api.listen".
+func EnvVar(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// SaveConfig writes the current configuration to a YAML, JSON or TOML file.
+// Settings overridden by environment variables keep their value in the file.
+// The file is updated rather than rewritten: keys it has beyond the settings are
+// kept, and YAML files also keep their comments, key order and formatting of
+// unchanged settings. If path is empty, it uses the configuration file of GetConfigPath
 // and ensures the directory structure exists.
 func SaveConfig(config *NexusConfig, path string) error {
 	if path == "" {
-		configDir, err := os.UserConfigDir()
-		if err != nil {
+		var err error
+		if path, err = GetConfigPath(); err != nil {
 			return err
 		}
-		path = filepath.Join(configDir, defaultConfigPath)
 
 		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 			return err
 		}
 	}
 
-	viper.SetConfigFile(path)
-	viper.SetConfigType("yaml")
+	fileType, err := configType(path)
+	if err != nil {
+		return err
+	}
 
-	for key, value := range map[string]interface{}{
-		"location":         config.Location,
-		"time_format":      config.TimeFormat,
-		"unit":             config.Unit,
-		"background_color": config.BackgroundColor,
-		"background_image": config.BackgroundImage,
-		"text_color":       config.TextColor,
-		"image_paths":      config.ImagePaths,
-	} {
-		viper.Set(key, value)
+	settings := map[string]interface{}{
+		"version":                     SchemaVersion,
+		"location":                    config.Location,
+		"geocoded":                    toMapValue(reflect.ValueOf(config.Geocoded)),
+		"time_format":                 config.TimeFormat,
+		"timezone":                    config.Timezone,
+		"world_clocks":                config.WorldClocks,
+		"locale":                      config.Locale,
+		"language":                    config.Language,
+		"unit":                        config.Unit,
+		"background_color":            config.BackgroundColor,
+		"background_image":            config.BackgroundImage,
+		"text_color":                  config.TextColor,
+		"font":                        config.Font,
+		"font_size":                   config.FontSize,
+		"show_temps":                  config.ShowTemps,
+		"show_network":                config.ShowNetwork,
+		"show_clock":                  config.ShowClock,
+		"show_weather":                config.ShowWeather,
+		"show_volume":                 config.ShowVolume,
+		"show_media":                  config.ShowMedia,
+		"show_ticker":                 config.ShowTicker,
+		"show_keyboard":               config.ShowKeyboard,
+		"graph_minutes":               config.GraphMinutes,
+		"widget_offsets":              toMapValue(reflect.ValueOf(config.WidgetOffsets)),
+		"image_paths":                 config.ImagePaths,
+		"intervals":                   config.Intervals,
+		"alerts":                      config.Alerts,
+		"news.provider":               config.News.Provider,
+		"news.api_key":                config.News.APIKey,
+		"news.country":                config.News.Country,
+		"feeds":                       config.Feeds,
+		"stocks.provider":             config.Stocks.Provider,
+		"stocks.api_key":              config.Stocks.APIKey,
+		"stocks.symbols":              config.Stocks.Symbols,
+		"calendar.ics":                config.Calendar.ICS,
+		"calendar.caldav.url":         config.Calendar.CalDAV.URL,
+		"calendar.caldav.username":    config.Calendar.CalDAV.Username,
+		"calendar.caldav.password":    config.Calendar.CalDAV.Password,
+		"media.spotify.client_id":     config.Media.Spotify.ClientID,
+		"media.spotify.client_secret": config.Media.Spotify.ClientSecret,
+		"media.spotify.refresh_token": config.Media.Spotify.RefreshToken,
+		"mqtt.broker":                 config.MQTT.Broker,
+		"mqtt.username":               config.MQTT.Username,
+		"mqtt.password":               config.MQTT.Password,
+		"mqtt.client_id":              config.MQTT.ClientID,
+		"mqtt.topics":                 config.MQTT.Topics,
+		"mqtt.actions":                config.MQTT.Actions,
+		"prometheus.url":              config.Prometheus.URL,
+		"prometheus.queries":          config.Prometheus.Queries,
+		"octoprint.url":               config.OctoPrint.URL,
+		"octoprint.api_key":           config.OctoPrint.APIKey,
+		"smart.drives":                config.SMART.Drives,
+		"vpn.interface":               config.VPN.Interface,
+		"notifications.enabled":       config.Notifications.Enabled,
+		"notifications.apps":          config.Notifications.Apps,
+		"notifications.ignore":        config.Notifications.Ignore,
+		"api.listen":                  config.API.Listen,
+		"api.socket":                  config.API.Socket,
+		"api.cors_origins":            config.API.CORSOrigins,
+		"api.tls.cert_file":           config.API.TLS.CertFile,
+		"api.tls.key_file":            config.API.TLS.KeyFile,
+		"api.tls.self_signed":         config.API.TLS.SelfSigned,
+		"api.metrics_instruments":     config.API.MetricsInstruments,
+		"read_only":                   config.ReadOnly,
+		"webhooks":                    config.Webhooks,
+		"pages":                       toMapValue(reflect.ValueOf(config.Pages)), // Keyed like the file, the YAML encoder would drop the underscores
+		"schedules":                   config.Schedules,
+		"brightness":                  config.Brightness,
+		"rotation":                    config.Rotation,
+		"devices":                     toMapValue(reflect.ValueOf(config.Devices)),
+		"idle.timeout":                config.Idle.Timeout,
+		"idle.action":                 config.Idle.Action,
+		"shutdown.screen":             config.Shutdown.Screen,
+		"shutdown.text":               config.Shutdown.Text,
+		"pomodoro.work":               config.Pomodoro.Work,
+		"pomodoro.break":              config.Pomodoro.Break,
+		"alarms":                      config.Alarms,
 	}
 
-	return viper.WriteConfig()
+	// Keep the file's value of settings overridden by the environment
+	for key := range settings {
+		if _, ok := os.LookupEnv(EnvVar(key)); ok {
+			delete(settings, key)
+		}
+	}
+
+	if fileType == "yaml" {
+		return updateYAMLFile(path, settings)
+	}
+
+	// Start from the file rather than the loaded settings, which include the
+	// environment
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType(fileType)
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for key, value := range settings {
+		file.Set(key, value)
+	}
+	return file.WriteConfig()
 }
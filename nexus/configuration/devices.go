@@ -0,0 +1,101 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxBrightness is the full display brightness in percent
+const MaxBrightness = 100
+
+// Rotations lists the accepted display rotations in degrees. The display is a
+// strip, so it can only be turned upside down.
+var Rotations = []int{0, 180}
+
+// DeviceConfig overrides settings for the device with a USB serial number, e.g.
+// devices: {"A1B2C3": {rotation: 180, brightness: 60}}. Settings it leaves out
+// fall back to the global ones.
+type DeviceConfig struct {
+	// Pages replaces the global pages when it is not empty
+	Pages []PageConfig `mapstructure:"pages"`
+
+	// Brightness overrides brightness when set
+	Brightness *int `mapstructure:"brightness"`
+
+	// Rotation overrides rotation when set
+	Rotation *int `mapstructure:"rotation"`
+}
+
+// Validate checks the brightness, rotation and pages.
+func (d DeviceConfig) Validate() error {
+	if d.Brightness != nil {
+		if err := validateBrightness(*d.Brightness); err != nil {
+			return err
+		}
+	}
+	if d.Rotation != nil {
+		if err := validateRotation(*d.Rotation); err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, page := range d.Pages {
+		if err := page.Validate(); err != nil {
+			return fmt.Errorf("pages[%d]: %w", i, err)
+		}
+		if names[page.Name] {
+			return fmt.Errorf("pages[%d]: duplicate page %q", i, page.Name)
+		}
+		names[page.Name] = true
+	}
+	return nil
+}
+
+// ForDevice returns the configuration for the device with the given serial
+// number: c with the settings of its devices entry applied, or c itself if it
+// has none.
+func (c *NexusConfig) ForDevice(serial string) *NexusConfig {
+	if serial == "" {
+		return c
+	}
+	device, ok := c.Devices[serial]
+	if !ok {
+		// Keys are lowercased when the file is read
+		for key, d := range c.Devices {
+			if strings.EqualFold(key, serial) {
+				device, ok = d, true
+			}
+		}
+	}
+	if !ok {
+		return c
+	}
+
+	resolved := *c
+	if len(device.Pages) > 0 {
+		resolved.Pages = device.Pages
+	}
+	if device.Brightness != nil {
+		resolved.Brightness = *device.Brightness
+	}
+	if device.Rotation != nil {
+		resolved.Rotation = *device.Rotation
+	}
+	return &resolved
+}
+
+func validateBrightness(brightness int) error {
+	if brightness < 0 || brightness > MaxBrightness {
+		return fmt.Errorf("brightness must be between 0 and %d, got %d", MaxBrightness, brightness)
+	}
+	return nil
+}
+
+func validateRotation(rotation int) error {
+	if !slices.Contains(Rotations, rotation) {
+		return fmt.Errorf("invalid rotation %d, expected one of %v", rotation, Rotations)
+	}
+	return nil
+}
@@ -1,8 +1,8 @@
 package configuration
 
 import (
-	"bytes"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"image"
 	"image/draw"
@@ -12,6 +12,8 @@ import (
 	"image/png"
 	_ "image/png" // Register PNG format
 	"io"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,6 +21,13 @@ import (
 	"github.com/nfnt/resize"
 )
 
+// Image content types accepted for upload and the extension each is stored with
+var imageTypes = map[string]string{
+	"image/gif":  ".gif",
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+}
+
 var allowedExtensions = map[string]bool{
 	".gif":  true,
 	".png":  true,
@@ -29,6 +38,20 @@ var allowedExtensions = map[string]bool{
 const (
 	targetWidth  = 640
 	targetHeight = 48 // Changed from 480 to match display dimensions
+
+	// MaxImageSize is the largest image file accepted for upload, in bytes
+	MaxImageSize = 10 << 20
+
+	// maxImagePixels bounds the decoded size of an upload, so small files that
+	// expand to huge images are rejected before decoding
+	maxImagePixels = 8192 * 8192
+)
+
+// Image upload errors
+var (
+	ErrImageTooLarge    = fmt.Errorf("image exceeds %d MiB", MaxImageSize>>20)
+	ErrUnsupportedImage = errors.New("unsupported image type, expected GIF, PNG or JPEG")
+	ErrInvalidImage     = errors.New("invalid image")
 )
 
 // GenerateUniqueFileName creates a unique filename with original extension
@@ -39,39 +62,118 @@ func GenerateUniqueFileName(originalName string) string {
 	return fmt.Sprintf("%x%s", hash[:8], ext)
 }
 
-// SaveImage saves and resizes an uploaded image to the images directory
-func SaveImage(filename string, data io.Reader) error {
-	ext := strings.ToLower(filepath.Ext(filename))
-	if !allowedExtensions[ext] {
-		return fmt.Errorf("unsupported file type: %s", ext)
+// SaveImage resizes an uploaded image to the display and saves it to the images
+// directory. The upload is spooled to disk rather than held in memory, and its type
+// is detected from its content: an extension that does not match the content is
+// replaced. It returns the name the image was stored under.
+//
+// The returned error wraps ErrImageTooLarge, ErrUnsupportedImage or ErrInvalidImage
+// if the upload is rejected.
+func SaveImage(filename string, data io.Reader) (string, error) {
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return "", fmt.Errorf("%w: missing filename", ErrInvalidImage)
 	}
 
 	// Ensure images directory exists
 	imagesDir, err := GetImagesDir()
 	if err != nil {
-		return fmt.Errorf("failed to get/create images directory: %w", err)
+		return "", fmt.Errorf("failed to get/create images directory: %w", err)
+	}
+
+	// Spool the upload next to its destination
+	upload, err := os.CreateTemp(imagesDir, ".upload-*")
+	if err != nil {
+		return "", err
 	}
+	defer os.Remove(upload.Name())
+	defer upload.Close()
 
-	// Check if file exists and remove it
-	destPath := filepath.Join(imagesDir, filename)
-	if _, err := os.Stat(destPath); err == nil {
-		if err := os.Remove(destPath); err != nil {
-			return fmt.Errorf("failed to remove existing file: %w", err)
+	n, err := io.Copy(upload, io.LimitReader(data, MaxImageSize+1))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return "", ErrImageTooLarge
 		}
+		return "", fmt.Errorf("failed to read image data: %w", err)
+	}
+	if n > MaxImageSize {
+		return "", ErrImageTooLarge
 	}
 
-	// Read the image data
-	imgData, err := io.ReadAll(data)
+	// Detect the type from the content rather than trusting the extension
+	head := make([]byte, 512)
+	m, _ := upload.ReadAt(head, 0)
+	storedExt, ok := imageTypes[http.DetectContentType(head[:m])]
+	if !ok {
+		return "", ErrUnsupportedImage
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !allowedExtensions[ext] || imageTypes[mime.TypeByExtension(ext)] != storedExt {
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + storedExt
+	}
+
+	if _, err := upload.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	imgConfig, _, err := image.DecodeConfig(upload)
 	if err != nil {
-		return fmt.Errorf("failed to read image data: %w", err)
+		return "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if imgConfig.Width*imgConfig.Height > maxImagePixels {
+		return "", fmt.Errorf("%w: %dx%d pixels is too large", ErrInvalidImage, imgConfig.Width, imgConfig.Height)
 	}
 
 	// Decode the image
-	img, format, err := image.Decode(bytes.NewReader(imgData))
+	if _, err := upload.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	img, format, err := image.Decode(upload)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	finalImg := FitImage(img)
+
+	// Write to a temporary file and rename it, so an existing image is only
+	// replaced once the new one is complete
+	out, err := os.CreateTemp(imagesDir, ".resized-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	// Encode the resized image in the original format
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(out, finalImg, &jpeg.Options{Quality: 85})
+	case "png":
+		err = png.Encode(out, finalImg)
+	case "gif":
+		err = gif.Encode(out, finalImg, &gif.Options{NumColors: 256})
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImage, format)
+	}
+
 	if err != nil {
-		return fmt.Errorf("failed to decode image: %w", err)
+		return "", err
 	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(out.Name(), filepath.Join(imagesDir, filename)); err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
 
+// FitImage scales img to the display, keeping its aspect ratio, and centers it on
+// black.
+func FitImage(img image.Image) *image.RGBA {
 	// Calculate resize dimensions maintaining aspect ratio
 	bounds := img.Bounds()
 	ratio := float64(bounds.Dx()) / float64(bounds.Dy())
@@ -100,30 +202,7 @@ func SaveImage(filename string, data io.Reader) error {
 	draw.Draw(finalImg, finalImg.Bounds(), image.Black, image.Point{}, draw.Src)
 	draw.Draw(finalImg, image.Rect(x, y, x+newWidth, y+newHeight), resized, image.Point{}, draw.Over)
 
-	// Create the output file
-	out, err := os.Create(destPath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	// Encode the resized image in the original format
-	switch format {
-	case "jpeg":
-		err = jpeg.Encode(out, finalImg, &jpeg.Options{Quality: 85})
-	case "png":
-		err = png.Encode(out, finalImg)
-	case "gif":
-		err = gif.Encode(out, finalImg, &gif.Options{NumColors: 256})
-	default:
-		return fmt.Errorf("unsupported image format: %s", format)
-	}
-
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return finalImg
 }
 
 // DeleteImage removes an image from the images directory
@@ -169,7 +248,8 @@ func GetImages() ([]string, error) {
 
 	var images []string
 	for _, file := range files {
-		if !file.IsDir() {
+		// Hidden files are uploads in progress
+		if !file.IsDir() && !strings.HasPrefix(file.Name(), ".") {
 			images = append(images, file.Name())
 		}
 	}
@@ -0,0 +1,53 @@
+package configuration
+
+import (
+	"fmt"
+	"time"
+)
+
+// Idle actions
+const (
+	IdleActionBlank = "blank" // Show black until the user is back
+	IdleActionSlow  = "slow"  // Redraw the display once per second
+)
+
+// IdleConfig changes the display while the user has not used the keyboard or mouse
+// of the computer for a while. Touches on the display do not count as activity.
+type IdleConfig struct {
+	// Timeout is how long the user has to be away, in Go duration syntax, e.g.
+	// "10m". Empty disables idle detection.
+	Timeout string `mapstructure:"timeout"`
+
+	// Action is "blank" or "slow" (default "blank")
+	Action string `mapstructure:"action"`
+}
+
+// TimeoutDuration returns the idle timeout, 0 if idle detection is disabled or
+// the timeout is invalid.
+func (i IdleConfig) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(i.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0
+	}
+	return timeout
+}
+
+// Validate checks the timeout and action.
+func (i IdleConfig) Validate() error {
+	if i.Timeout != "" {
+		timeout, err := time.ParseDuration(i.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %v", i.Timeout, err)
+		}
+		if timeout < time.Minute {
+			return fmt.Errorf("timeout must be at least 1m, got %s", timeout)
+		}
+	}
+
+	if i.Action != "" {
+		if err := oneOf(i.Action, IdleActionBlank, IdleActionSlow); err != nil {
+			return fmt.Errorf("action %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// News providers
+const (
+	NewsProviderNone    = ""        // News ticker disabled
+	NewsProviderNewsAPI = "newsapi" // https://newsapi.org
+)
+
+// NewsConfig configures the news headline ticker
+type NewsConfig struct {
+	// Provider selects the headline source ("newsapi"), empty disables the ticker
+	Provider string `mapstructure:"provider"`
+
+	// APIKey is the provider API key
+	APIKey string `mapstructure:"api_key"`
+
+	// Country is the two-letter country code of the headlines (default "us")
+	Country string `mapstructure:"country"`
+}
+
+// Validate checks that a known provider is selected and has an API key.
+func (n NewsConfig) Validate() error {
+	switch n.Provider {
+	case NewsProviderNone:
+		return nil
+	case NewsProviderNewsAPI:
+		if n.APIKey == "" {
+			return fmt.Errorf("news provider %s requires an api_key", n.Provider)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown news provider %q", n.Provider)
+	}
+}
+
+// Stock quote providers
+const (
+	StocksProviderNone    = ""        // Stock ticker disabled
+	StocksProviderFinnhub = "finnhub" // https://finnhub.io
+)
+
+// StocksConfig configures the stock quote ticker
+type StocksConfig struct {
+	// Provider selects the quote source ("finnhub"), empty disables the ticker
+	Provider string `mapstructure:"provider"`
+
+	// APIKey is the provider API key
+	APIKey string `mapstructure:"api_key"`
+
+	// Symbols lists the ticker symbols to show (e.g. "AAPL", "MSFT")
+	Symbols []string `mapstructure:"symbols"`
+}
+
+// Validate checks that a known provider is selected and has an API key.
+func (s StocksConfig) Validate() error {
+	switch s.Provider {
+	case StocksProviderNone:
+		return nil
+	case StocksProviderFinnhub:
+		if s.APIKey == "" {
+			return fmt.Errorf("stocks provider %s requires an api_key", s.Provider)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown stocks provider %q", s.Provider)
+	}
+}
+
+// CalendarConfig configures the next calendar event widget
+type CalendarConfig struct {
+	// ICS lists iCalendar (.ics) URLs to read events from
+	ICS []string `mapstructure:"ics"`
+
+	// CalDAV is an optional CalDAV calendar collection to read events from
+	CalDAV CalDAVConfig `mapstructure:"caldav"`
+}
+
+// CalDAVConfig describes a CalDAV calendar collection
+type CalDAVConfig struct {
+	// URL of the calendar collection, empty disables CalDAV
+	URL string `mapstructure:"url"`
+
+	// Username and Password are sent using HTTP basic authentication
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// Validate checks that all calendar URLs are http or https URLs.
+func (c CalendarConfig) Validate() error {
+	urls := c.ICS
+	if c.CalDAV.URL != "" {
+		urls = append(urls[:len(urls):len(urls)], c.CalDAV.URL)
+	}
+
+	for _, calendarURL := range urls {
+		if u, err := url.Parse(calendarURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid calendar URL %q", calendarURL)
+		}
+	}
+
+	return nil
+}
+
+// MediaConfig configures the now-playing widget
+type MediaConfig struct {
+	// Spotify optionally reads playback from the Spotify Web API when no local
+	// player is active
+	Spotify SpotifyConfig `mapstructure:"spotify"`
+}
+
+// SpotifyConfig holds the credentials of a Spotify app and the refresh token of an
+// account that granted it the user-read-playback-state and
+// user-modify-playback-state scopes
+type SpotifyConfig struct {
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	RefreshToken string `mapstructure:"refresh_token"`
+}
+
+// Enabled reports whether Spotify credentials are configured.
+func (s SpotifyConfig) Enabled() bool {
+	return s.ClientID != "" || s.ClientSecret != "" || s.RefreshToken != ""
+}
+
+// Validate checks that the Spotify credentials are either complete or absent.
+func (m MediaConfig) Validate() error {
+	s := m.Spotify
+	if s.Enabled() && (s.ClientID == "" || s.ClientSecret == "" || s.RefreshToken == "") {
+		return fmt.Errorf("spotify requires client_id, client_secret and refresh_token")
+	}
+	return nil
+}
+
+// MQTTConfig configures the MQTT integration
+type MQTTConfig struct {
+	// Broker is the broker URL, e.g. "tcp://localhost:1883" or "ssl://host:8883".
+	// Empty disables MQTT.
+	Broker string `mapstructure:"broker"`
+
+	// Username and Password authenticate with the broker (optional)
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// ClientID identifies the connection, a random ID is used if empty
+	ClientID string `mapstructure:"client_id"`
+
+	// Topics lists subscriptions whose messages are shown in the ticker
+	Topics []MQTTTopic `mapstructure:"topics"`
+
+	// Actions lists touch areas that publish a message when tapped
+	Actions []MQTTAction `mapstructure:"actions"`
+}
+
+// MQTTTopic maps a subscribed topic to a ticker entry
+type MQTTTopic struct {
+	// Topic is the topic filter to subscribe to; MQTT wildcards are allowed
+	Topic string `mapstructure:"topic"`
+
+	// Template is a Go text/template rendering the message. It receives .Topic,
+	// .Value (the payload as text) and .JSON (the decoded payload, if it is JSON).
+	// Defaults to "{{.Value}}".
+	Template string `mapstructure:"template"`
+}
+
+// MQTTAction publishes Payload to Topic when the display area at X, Y of the given
+// Width and Height is tapped
+type MQTTAction struct {
+	Topic   string `mapstructure:"topic"`
+	Payload string `mapstructure:"payload"`
+	Retain  bool   `mapstructure:"retain"`
+	X       int    `mapstructure:"x"`
+	Y       int    `mapstructure:"y"`
+	Width   int    `mapstructure:"width"`
+	Height  int    `mapstructure:"height"`
+}
+
+// Validate checks the broker URL, topic templates and action areas.
+func (m MQTTConfig) Validate() error {
+	if m.Broker == "" {
+		return nil
+	}
+
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid MQTT broker URL %q", m.Broker)
+	}
+
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts":
+	default:
+		return fmt.Errorf("unsupported MQTT broker scheme %q", u.Scheme)
+	}
+
+	for _, topic := range m.Topics {
+		if topic.Topic == "" {
+			return fmt.Errorf("MQTT topic must not be empty")
+		}
+		if _, err := template.New(topic.Topic).Parse(topic.Template); err != nil {
+			return fmt.Errorf("invalid template for MQTT topic %s: %w", topic.Topic, err)
+		}
+	}
+
+	for _, action := range m.Actions {
+		if action.Topic == "" || strings.ContainsAny(action.Topic, "+#") {
+			return fmt.Errorf("invalid MQTT action topic %q", action.Topic)
+		}
+		if action.Width <= 0 || action.Height <= 0 {
+			return fmt.Errorf("MQTT action for %s needs a positive width and height", action.Topic)
+		}
+	}
+
+	return nil
+}
+
+// PrometheusConfig configures the Prometheus query widget
+type PrometheusConfig struct {
+	// URL of the Prometheus server, e.g. "http://localhost:9090". Empty disables it.
+	URL string `mapstructure:"url"`
+
+	// Queries lists the PromQL instant queries whose results are shown in the ticker
+	Queries []PrometheusQuery `mapstructure:"queries"`
+}
+
+// PrometheusQuery is a named PromQL instant query
+type PrometheusQuery struct {
+	// Name labels the result on the display and names its alert metric
+	Name string `mapstructure:"name"`
+
+	// Query is the PromQL expression; the first sample of the result is shown
+	Query string `mapstructure:"query"`
+
+	// Format is a printf format for the value (default "%.2f")
+	Format string `mapstructure:"format"`
+}
+
+// Validate checks the server URL and that every query has a name and expression.
+func (p PrometheusConfig) Validate() error {
+	if p.URL == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(p.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid Prometheus URL %q", p.URL)
+	}
+
+	for _, query := range p.Queries {
+		if query.Name == "" || query.Query == "" {
+			return fmt.Errorf("Prometheus queries need a name and a query")
+		}
+	}
+
+	return nil
+}
+
+// OctoPrintConfig configures the 3D printer progress widget
+type OctoPrintConfig struct {
+	// URL of the OctoPrint server, e.g. "http://octopi.local". Empty disables it.
+	URL string `mapstructure:"url"`
+
+	// APIKey is an OctoPrint application or user API key
+	APIKey string `mapstructure:"api_key"`
+}
+
+// Validate checks the server URL and that an API key is set.
+func (o OctoPrintConfig) Validate() error {
+	if o.URL == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(o.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid OctoPrint URL %q", o.URL)
+	}
+
+	if o.APIKey == "" {
+		return fmt.Errorf("OctoPrint requires an api_key")
+	}
+
+	return nil
+}
+
+// SMARTConfig configures the disk health widget
+type SMARTConfig struct {
+	// Drives lists the devices to check, e.g. "/dev/sda" or "/dev/nvme0". Reading
+	// them needs smartctl and administrator rights; NVMe drives are read without
+	// smartctl on Linux.
+	Drives []string `mapstructure:"drives"`
+}
+
+// Validate checks that no drive is empty.
+func (s SMARTConfig) Validate() error {
+	for _, drive := range s.Drives {
+		if strings.TrimSpace(drive) == "" {
+			return fmt.Errorf("SMART drives must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// VPNConfig configures the VPN status widget
+type VPNConfig struct {
+	// Interface is the network interface of the tunnel, e.g. "wg0" for WireGuard
+	// or "tun0" for OpenVPN. Empty disables it.
+	Interface string `mapstructure:"interface"`
+}
+
+// Validate checks that the interface name has no spaces.
+func (v VPNConfig) Validate() error {
+	if strings.ContainsAny(v.Interface, " \t") {
+		return fmt.Errorf("invalid VPN interface %q", v.Interface)
+	}
+
+	return nil
+}
+
+// DesktopNotificationsConfig relays the notifications shown by apps on the desktop
+// to the display as banners
+type DesktopNotificationsConfig struct {
+	// Enabled turns the relay on. On Linux it listens on the session bus, on
+	// Windows it needs notification access granted in the privacy settings.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Apps lists the app names whose notifications are shown, all if empty
+	Apps []string `mapstructure:"apps"`
+
+	// Ignore lists app names whose notifications are never shown
+	Ignore []string `mapstructure:"ignore"`
+}
+
+// Relays reports whether notifications of app are shown. App names are compared
+// ignoring case.
+func (d DesktopNotificationsConfig) Relays(app string) bool {
+	for _, ignored := range d.Ignore {
+		if strings.EqualFold(ignored, app) {
+			return false
+		}
+	}
+	if len(d.Apps) == 0 {
+		return true
+	}
+	for _, allowed := range d.Apps {
+		if strings.EqualFold(allowed, app) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that no app name is empty.
+func (d DesktopNotificationsConfig) Validate() error {
+	for _, app := range append(d.Apps[:len(d.Apps):len(d.Apps)], d.Ignore...) {
+		if strings.TrimSpace(app) == "" {
+			return fmt.Errorf("app names must not be empty")
+		}
+	}
+	return nil
+}
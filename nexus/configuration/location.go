@@ -0,0 +1,33 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GeocodedLocation records the coordinates a location name was geocoded to when
+// the configuration was saved, so the weather is not looked up for another place
+// of the same name later.
+type GeocodedLocation struct {
+	// Query is the location the coordinates are for
+	Query string  `mapstructure:"query"`
+	Lat   float64 `mapstructure:"lat"`
+	Lon   float64 `mapstructure:"lon"`
+}
+
+// For returns the coordinates recorded for location. It returns false if they
+// were recorded for another location, e.g. after the file was edited by hand.
+func (g GeocodedLocation) For(location string) (float64, float64, bool) {
+	if g.Query == "" || !strings.EqualFold(strings.TrimSpace(g.Query), strings.TrimSpace(location)) {
+		return 0, 0, false
+	}
+	return g.Lat, g.Lon, true
+}
+
+// Validate checks that the coordinates are on the globe.
+func (g GeocodedLocation) Validate() error {
+	if g.Lat < -90 || g.Lat > 90 || g.Lon < -180 || g.Lon > 180 {
+		return fmt.Errorf("invalid coordinates %v,%v", g.Lat, g.Lon)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// migration upgrades a configuration file by one version, e.g. by renaming keys.
+// It works on the decoded file, where nested sections are maps keyed like the
+// file in lower case.
+type migration struct {
+	description string
+	apply       func(m map[string]interface{})
+}
+
+// migrations[i] upgrades a configuration file from version i to i+1, so there is
+// one migration per SchemaVersion. Files without a version key are version 0.
+var migrations = []migration{
+	{description: "add the version key", apply: func(map[string]interface{}) {}},
+}
+
+// migrateConfigFile rewrites the configuration file at path to SchemaVersion if
+// it has an older version, keeping the original next to it as
+// "<path>.v<version>.bak". The file keeps its format. Files of a newer version are
+// an error.
+func migrateConfigFile(path string) error {
+	fileType, err := configType(path)
+	if err != nil {
+		return err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(fileType)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	version := 0
+	if v.InConfig("version") {
+		if version, err = cast.ToIntE(v.Get("version")); err != nil || version < 0 {
+			return fmt.Errorf("invalid config version %v", v.Get("version"))
+		}
+	}
+
+	if version > SchemaVersion {
+		return fmt.Errorf("config version %d is newer than the supported version %d", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return nil
+	}
+
+	m := v.AllSettings()
+	for from := version; from < SchemaVersion; from++ {
+		migrations[from].apply(m)
+	}
+	m["version"] = SchemaVersion
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	// Replace the file atomically so a crash never leaves half a configuration.
+	// The temporary file has the same extension so it is written in the same format.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	migrated := viper.New()
+	if err := migrated.MergeConfigMap(m); err != nil {
+		return err
+	}
+	if err := migrated.WriteConfigAs(tmp.Name()); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	configLog.Info("Migrated configuration", "path", path, "from_version", version, "to_version", SchemaVersion, "backup", backup)
+	return nil
+}
@@ -0,0 +1,82 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		version string // Version in the file afterwards, empty if it is unchanged
+		backup  string // Expected backup, empty if none is written
+		err     string // Expected error, empty if the file migrates
+	}{
+		{"without version", "unit: imperial\n", "version: 1", "config.yaml.v0.bak", ""},
+		{"version 0", "version: 0\nunit: imperial\n", "version: 1", "config.yaml.v0.bak", ""},
+		{"current version", "version: 1\nunit: imperial\n", "", "", ""},
+		{"newer version", "version: 2\n", "", "", "newer than the supported version"},
+		{"negative version", "version: -1\n", "", "", "invalid config version"},
+		{"version not a number", "version: latest\n", "", "", "invalid config version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := migrateConfigFile(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("migrateConfigFile() = %v, want an error containing %q", err, tt.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.version == "" {
+				if string(data) != tt.file {
+					t.Errorf("file changed to %q", data)
+				}
+			} else if !strings.Contains(string(data), tt.version) || !strings.Contains(string(data), "unit: imperial") {
+				t.Errorf("migrated file = %q, want %s and the settings kept", data, tt.version)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var backups []string
+			for _, entry := range entries {
+				if entry.Name() != "config.yaml" {
+					backups = append(backups, entry.Name())
+				}
+			}
+			if tt.backup == "" && len(backups) > 0 || tt.backup != "" && (len(backups) != 1 || backups[0] != tt.backup) {
+				t.Fatalf("files next to the configuration = %q, want backup %q", backups, tt.backup)
+			}
+			if tt.backup != "" {
+				backup, err := os.ReadFile(filepath.Join(dir, tt.backup))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(backup) != tt.file {
+					t.Errorf("backup = %q, want the original file", backup)
+				}
+				if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+					t.Errorf("migrated file has mode %v, want the mode of the original", info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
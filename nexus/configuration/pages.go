@@ -0,0 +1,163 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Widgets that can be placed on a page
+const (
+	WidgetTemperatures = "temperatures" // CPU and GPU temperatures
+	WidgetNetwork      = "network"      // Network throughput
+	WidgetClock        = "clock"        // Current time
+	WidgetWeather      = "weather"      // Weather, forecast and severe weather alerts
+	WidgetVolume       = "volume"       // Audio volume
+	WidgetMedia        = "media"        // Now playing track or 3D print progress
+	WidgetTicker       = "ticker"       // Scrolling news, feeds, stocks and calendar ticker
+	WidgetKeyboard     = "keyboard"     // Caps Lock and Num Lock state and keyboard layout
+)
+
+// Widgets lists every widget in drawing order.
+var Widgets = []string{
+	WidgetTemperatures,
+	WidgetNetwork,
+	WidgetClock,
+	WidgetWeather,
+	WidgetVolume,
+	WidgetMedia,
+	WidgetTicker,
+	WidgetKeyboard,
+}
+
+// Widgets that fill the whole display. They are not part of the main page and
+// have built-in pages of their own.
+const (
+	WidgetPomodoro  = "pomodoro"  // Pomodoro timer, tap to start or pause, hold to reset
+	WidgetTimer     = "timer"     // Countdown timer set on its numpad or through the API, hold to cancel
+	WidgetStopwatch = "stopwatch" // Stopwatch, tap to start or stop, hold to reset
+)
+
+// FullWidgets lists every widget that fills the whole display.
+var FullWidgets = []string{
+	WidgetPomodoro,
+	WidgetTimer,
+	WidgetStopwatch,
+}
+
+// Widgets sharing the space of widgets of the main page. They are not part of the
+// main page and only shown on the pages listing them.
+const (
+	WidgetRadar     = "radar"      // Precipitation radar map around the location, on the left
+	WidgetTempGraph = "temp_graph" // CPU and GPU temperatures of the last graph_minutes, in the middle
+	WidgetNetGraph  = "net_graph"  // Network throughput of the last graph_minutes, in the middle
+	WidgetDisks     = "disks"      // SMART health and temperature of the drives, bottom right
+	WidgetVPN       = "vpn"        // VPN tunnel state, handshake age and endpoint, on the left
+)
+
+// ExtraWidgets lists every widget that is only shown on the pages listing it.
+var ExtraWidgets = []string{
+	WidgetRadar,
+	WidgetTempGraph,
+	WidgetNetGraph,
+	WidgetDisks,
+	WidgetVPN,
+}
+
+// WidgetPluginPrefix starts the names of widgets drawing the tile of a plugin,
+// e.g. "plugin:weather-radar" for the executable weather-radar in the plugins
+// directory. The instrument running the plugin has the same name.
+const WidgetPluginPrefix = "plugin:"
+
+// KnownWidget reports whether widget is one of Widgets, FullWidgets or
+// ExtraWidgets or names a plugin.
+func KnownWidget(widget string) bool {
+	return slices.Contains(Widgets, widget) || slices.Contains(FullWidgets, widget) || slices.Contains(ExtraWidgets, widget) ||
+		(strings.HasPrefix(widget, WidgetPluginPrefix) && len(widget) > len(WidgetPluginPrefix))
+}
+
+// MaxWidgetOffset bounds each coordinate of a widget offset, in pixels
+const MaxWidgetOffset = 640
+
+// WidgetOffset moves a widget from its built-in position, e.g. {X: 4, Y: -2}
+// draws it 4 pixels further right and 2 pixels higher. Parts moved off the
+// display are cut off.
+type WidgetOffset struct {
+	X int `mapstructure:"x"`
+	Y int `mapstructure:"y"`
+}
+
+// Validate checks that both coordinates are within MaxWidgetOffset.
+func (o WidgetOffset) Validate() error {
+	if max(o.X, -o.X, o.Y, -o.Y) > MaxWidgetOffset {
+		return fmt.Errorf("offset %d,%d exceeds %d pixels", o.X, o.Y, MaxWidgetOffset)
+	}
+	return nil
+}
+
+// ShowsWidget reports whether widget is enabled by its show_* setting. Plugin
+// widgets have no setting and are always enabled.
+func (c *NexusConfig) ShowsWidget(widget string) bool {
+	switch widget {
+	case WidgetTemperatures:
+		return c.ShowTemps
+	case WidgetNetwork:
+		return c.ShowNetwork
+	case WidgetClock:
+		return c.ShowClock
+	case WidgetWeather:
+		return c.ShowWeather
+	case WidgetVolume:
+		return c.ShowVolume
+	case WidgetMedia:
+		return c.ShowMedia
+	case WidgetTicker:
+		return c.ShowTicker
+	case WidgetKeyboard:
+		return c.ShowKeyboard
+	}
+	return KnownWidget(widget)
+}
+
+// PageMain is the name of the built-in page shown on start, which shows every
+// widget of Widgets. Configured pages replace the built-in pages and start on the first one.
+const PageMain = "main"
+
+// PageConfig defines a page in the configuration file, e.g.
+// {Name: "work", Widgets: ["clock", "ticker"], TextColor: "#FFB000"}.
+type PageConfig struct {
+	// Name identifies the page in the API and D-Bus interface
+	Name string `mapstructure:"name"`
+
+	// Widgets are the widgets shown on the page, see Widgets, FullWidgets,
+	// ExtraWidgets and WidgetPluginPrefix
+	Widgets []string `mapstructure:"widgets"`
+
+	// BackgroundColor overrides the background color while the page is shown
+	BackgroundColor string `mapstructure:"background_color"`
+
+	// TextColor overrides the text color while the page is shown
+	TextColor string `mapstructure:"text_color"`
+}
+
+// Validate checks the page has a name, known widgets and valid colors.
+func (p PageConfig) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("page is missing a name")
+	}
+
+	for _, widget := range p.Widgets {
+		if !KnownWidget(widget) {
+			return fmt.Errorf("page %s has unknown widget %q", p.Name, widget)
+		}
+	}
+
+	if err := validateColor(p.BackgroundColor); err != nil {
+		return fmt.Errorf("page %s has %w", p.Name, err)
+	}
+	if err := validateColor(p.TextColor); err != nil {
+		return fmt.Errorf("page %s has %w", p.Name, err)
+	}
+
+	return nil
+}
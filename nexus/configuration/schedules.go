@@ -0,0 +1,112 @@
+package configuration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// scheduleDays are the day names accepted in Schedule.Days, indexed by time.Weekday
+var scheduleDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// scheduleTimeLayout is the time layout of Schedule.Start and Schedule.End
+const scheduleTimeLayout = "15:04"
+
+// Schedule switches the theme and page during a daily time range, e.g.
+// {Name: "gaming", Days: ["mon", "tue", "wed", "thu", "fri"], Start: "18:00",
+// End: "23:00", Page: "system"}. Schedules use the clock's time zone.
+type Schedule struct {
+	// Name identifies the schedule in logs and the status
+	Name string `mapstructure:"name"`
+
+	// Days lists the days the range starts on: sun, mon, tue, wed, thu, fri or
+	// sat. Every day if empty.
+	Days []string `mapstructure:"days"`
+
+	// Start and End are the "HH:MM" times the schedule is active between. An End
+	// before Start spans midnight, equal times the whole day.
+	Start string `mapstructure:"start"`
+	End   string `mapstructure:"end"`
+
+	// Theme is the built-in theme shown, the configured colors if empty
+	Theme string `mapstructure:"theme"`
+
+	// Page is the page shown, the current page if empty
+	Page string `mapstructure:"page"`
+}
+
+// Validate checks the days, times and theme, and that the schedule changes
+// something.
+func (s Schedule) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("schedule name is required")
+	}
+
+	for _, day := range s.Days {
+		if !slices.Contains(scheduleDays, strings.ToLower(day)) {
+			return fmt.Errorf("schedule %s has invalid day %q, expected one of %s", s.Name, day, strings.Join(scheduleDays, ", "))
+		}
+	}
+
+	for _, t := range []string{s.Start, s.End} {
+		if _, err := time.Parse(scheduleTimeLayout, t); err != nil {
+			return fmt.Errorf("schedule %s has invalid time %q, expected HH:MM", s.Name, t)
+		}
+	}
+
+	if s.Theme == "" && s.Page == "" {
+		return fmt.Errorf("schedule %s needs a theme or a page", s.Name)
+	}
+	if _, ok := FindTheme(s.Theme); s.Theme != "" && !ok {
+		return fmt.Errorf("schedule %s has unknown theme %q", s.Name, s.Theme)
+	}
+
+	return nil
+}
+
+// Active reports whether the schedule is active at t. Invalid schedules are
+// never active.
+func (s Schedule) Active(t time.Time) bool {
+	start, err := time.Parse(scheduleTimeLayout, s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse(scheduleTimeLayout, s.End)
+	if err != nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+
+	// The day a range spanning midnight started on
+	day := t.Weekday()
+	switch {
+	case from == to:
+	case from < to:
+		if minute < from || minute >= to {
+			return false
+		}
+	case minute >= from:
+	case minute < to:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	return len(s.Days) == 0 || slices.ContainsFunc(s.Days, func(d string) bool {
+		return strings.EqualFold(d, scheduleDays[day])
+	})
+}
+
+// ActiveSchedule returns the first schedule active at t.
+func (c *NexusConfig) ActiveSchedule(t time.Time) (Schedule, bool) {
+	for _, schedule := range c.Schedules {
+		if schedule.Active(t) {
+			return schedule, true
+		}
+	}
+	return Schedule{}, false
+}
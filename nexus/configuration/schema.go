@@ -0,0 +1,145 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// SchemaVersion is the version of the configuration file and of the configuration
+// document exchanged by the versioned API. It is increased, together with a new
+// entry in migrations, when keys are renamed or change meaning; older files are
+// migrated when loaded and documents of older versions are still accepted.
+const SchemaVersion = 1
+
+// Map returns the configuration keyed like the configuration file, e.g.
+// {"time_format": "24h", "api": {"listen": "127.0.0.1:1985"}}.
+func (c *NexusConfig) Map() map[string]interface{} {
+	return structToMap(reflect.ValueOf(c).Elem())
+}
+
+// structToMap converts a struct to a map keyed by the mapstructure tags of its
+// fields, converting nested structs recursively.
+func structToMap(v reflect.Value) map[string]interface{} {
+	m := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		if v.Field(i).Kind() == reflect.Pointer && v.Field(i).IsNil() {
+			continue // Unset optional setting
+		}
+		m[key] = toMapValue(v.Field(i))
+	}
+	return m
+}
+
+// toMapValue converts structs in v, also inside slices and maps, to maps.
+func toMapValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Struct:
+		return structToMap(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return []interface{}{}
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = toMapValue(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return map[string]interface{}{}
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = toMapValue(iter.Value())
+		}
+		return m
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return toMapValue(v.Elem())
+	default:
+		return v.Interface()
+	}
+}
+
+// DecodeMap builds a configuration from a map keyed like the configuration file,
+// as returned by Map. Keys are matched ignoring case and underscores, so documents
+// using the Go field names of the unversioned API ("TimeFormat") are accepted too.
+// Unknown keys are an error. The configuration is not validated.
+func DecodeMap(m map[string]interface{}) (*NexusConfig, error) {
+	var config NexusConfig
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:      &config,
+		TagName:     "mapstructure",
+		ErrorUnused: true,
+		MatchName: func(mapKey, fieldName string) bool {
+			return normalizeKey(mapKey) == normalizeKey(fieldName)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(m); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// normalizeKey lowercases key and removes underscores.
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", ""))
+}
+
+// Clone returns a deep copy of the configuration, which shares no maps or slices
+// with it and can be changed, or decoded into, while c is in use.
+func (c *NexusConfig) Clone() (*NexusConfig, error) {
+	return DecodeMap(c.Map())
+}
+
+// Patch returns a copy of the configuration with a JSON merge patch (RFC 7396)
+// applied: objects are merged recursively, null resets a key to its zero value and
+// any other value replaces the key. Keys are matched like DecodeMap does. The
+// result is not validated.
+func (c *NexusConfig) Patch(patch map[string]interface{}) (*NexusConfig, error) {
+	return DecodeMap(mergePatch(c.Map(), patch))
+}
+
+// mergePatch applies patch to target in place and returns it. A patched key
+// replaces the existing key it matches, so the map never holds two spellings of
+// the same key.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	for key, value := range patch {
+		for existing := range target {
+			if normalizeKey(existing) == normalizeKey(key) {
+				key = existing
+				break
+			}
+		}
+
+		switch value := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]interface{}:
+			object, ok := target[key].(map[string]interface{})
+			if !ok {
+				object = make(map[string]interface{})
+			}
+			target[key] = mergePatch(object, value)
+		default:
+			target[key] = value
+		}
+	}
+	return target
+}
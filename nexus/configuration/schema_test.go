@@ -0,0 +1,92 @@
+package configuration
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"replace", `{"unit": "metric"}`, `{"unit": "imperial"}`, `{"unit": "imperial"}`},
+		{"add", `{"unit": "metric"}`, `{"locale": "de-DE"}`, `{"unit": "metric", "locale": "de-DE"}`},
+		{"null removes", `{"unit": "metric", "locale": "de-DE"}`, `{"locale": null}`, `{"unit": "metric"}`},
+		{"null of a missing key", `{"unit": "metric"}`, `{"locale": null}`, `{"unit": "metric"}`},
+		{"nested merge", `{"api": {"listen": ":1985", "socket": ""}}`, `{"api": {"socket": "/run/nexus.sock"}}`, `{"api": {"listen": ":1985", "socket": "/run/nexus.sock"}}`},
+		{"object replaces a value", `{"idle": "off"}`, `{"idle": {"timeout": "5m"}}`, `{"idle": {"timeout": "5m"}}`},
+		{"arrays are replaced", `{"feeds": ["a", "b"]}`, `{"feeds": ["c"]}`, `{"feeds": ["c"]}`},
+		{"go field names", `{"time_format": "12h"}`, `{"TimeFormat": "24h"}`, `{"time_format": "24h"}`},
+		{"empty patch", `{"unit": "metric"}`, `{}`, `{"unit": "metric"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePatch(decodeJSON(t, tt.target), decodeJSON(t, tt.patch))
+			if want := decodeJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("mergePatch() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	config := DefaultConfig()
+	config.Feeds = []string{"https://example.com/feed.xml"}
+
+	patched, err := config.Patch(decodeJSON(t, `{"unit": "imperial", "api": {"read_only": null}, "feeds": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.Unit != UnitImperial || len(patched.Feeds) != 0 || patched.API.Listen != config.API.Listen {
+		t.Errorf("Patch() = unit %q, feeds %q, api listen %q", patched.Unit, patched.Feeds, patched.API.Listen)
+	}
+	if config.Unit != DefaultConfig().Unit || len(config.Feeds) != 1 {
+		t.Error("Patch() modified the configuration it was called on")
+	}
+
+	if _, err := config.Patch(decodeJSON(t, `{"no_such_setting": 1}`)); err == nil {
+		t.Error("Patch() with an unknown key succeeded")
+	}
+}
+
+// decodeJSON decodes a JSON object.
+func decodeJSON(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestClone(t *testing.T) {
+	config := DefaultConfig()
+	config.WidgetOffsets = map[string]WidgetOffset{WidgetClock: {X: 4}}
+	config.Intervals = map[string]string{"weather": "10m"}
+	config.Feeds = []string{"a", "b"}
+	config.Pages = []PageConfig{{Name: "main", Widgets: []string{WidgetClock}}}
+	config.Devices = map[string]DeviceConfig{"abc": {Pages: []PageConfig{{Name: "other"}}}}
+
+	clone, err := config.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(clone.Map(), config.Map()) {
+		t.Fatalf("Clone() = %v, want %v", clone.Map(), config.Map())
+	}
+
+	want := config.Map()
+	clone.WidgetOffsets[WidgetClock] = WidgetOffset{X: 99}
+	clone.Intervals["news"] = "1h"
+	clone.Feeds[0] = "zzz"
+	clone.Pages[0].Widgets[0] = WidgetWeather
+	clone.Devices["abc"].Pages[0].Name = "changed"
+	if !reflect.DeepEqual(config.Map(), want) {
+		t.Errorf("changing the clone changed the configuration to %v", config.Map())
+	}
+}
@@ -0,0 +1,179 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// SecretScheme prefixes setting values that name a secret in the OS keyring
+	// instead of holding it, e.g. "secret://newsapi" for news.api_key.
+	SecretScheme = "secret://"
+
+	secretService = "nexus-open" // keyring service the secrets are stored under
+)
+
+// ErrSecretNotFound is returned when the keyring has no secret of the given name.
+var ErrSecretNotFound = errors.New("secret not found in keyring")
+
+// ResolveSecret returns value, or the secret it names if it is a secret://
+// reference. Secrets are looked up when used, so neither the configuration file
+// nor the API ever holds them in plain text.
+func ResolveSecret(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, SecretScheme)
+	if !ok {
+		return value, nil
+	}
+
+	secret, err := GetSecret(name)
+	if err != nil {
+		return "", fmt.Errorf("%s%s: %w", SecretScheme, name, err)
+	}
+	return secret, nil
+}
+
+// GetSecret reads the secret called name from the OS keyring: the Secret Service
+// through secret-tool on Linux, the login keychain on macOS and the Credential
+// Manager on Windows.
+func GetSecret(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("secret name is empty")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", secretService, "name", name)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", secretService, "-a", name, "-w")
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsCredentialPrelude+windowsCredentialReadScript)
+		cmd.Env = append(os.Environ(), "NEXUS_SECRET_TARGET="+secretService+":"+name)
+	default:
+		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr) && stderr.Len() == 0:
+		// secret-tool fails silently, and the Windows script without output, for
+		// missing secrets
+		return "", ErrSecretNotFound
+	case errors.As(err, &exitErr) && runtime.GOOS == "darwin" && exitErr.ExitCode() == 44:
+		return "", ErrSecretNotFound
+	case err != nil:
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if runtime.GOOS == "darwin" {
+		out = bytes.TrimSuffix(out, []byte("\n"))
+	}
+	return string(out), nil
+}
+
+// SetSecret stores value as the secret called name in the OS keyring, replacing
+// any secret of that name. See GetSecret for the keyrings used.
+func SetSecret(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("secret name is empty")
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=Nexus Open: "+name, "service", secretService, "name", name)
+		cmd.Stdin = strings.NewReader(value)
+	case "darwin":
+		// An argument would show the password to ps, so the command is passed to the
+		// interactive mode of security on stdin instead
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("secret must not contain line breaks")
+		}
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+			securityQuote(secretService), securityQuote(name), securityQuote(value)))
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsCredentialPrelude+windowsCredentialWriteScript)
+		cmd.Env = append(os.Environ(), "NEXUS_SECRET_TARGET="+secretService+":"+name)
+		cmd.Stdin = strings.NewReader(value)
+	default:
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// The interactive mode of security succeeds even if its command failed
+	if runtime.GOOS == "darwin" && stderr.Len() > 0 {
+		return errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for a command line read by "security -i".
+func securityQuote(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// windowsCredentialPrelude declares the Credential Manager functions of advapi32.
+const windowsCredentialPrelude = `
+[Console]::InputEncoding = [Text.Encoding]::UTF8
+[Console]::OutputEncoding = [Text.Encoding]::UTF8
+Add-Type -TypeDefinition @'
+using System;
+using System.Runtime.InteropServices;
+using System.Runtime.InteropServices.ComTypes;
+public static class NexusCredentials {
+  [StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+  public struct Credential {
+    public int Flags; public int Type; public string TargetName; public string Comment;
+    public FILETIME LastWritten; public int CredentialBlobSize; public IntPtr CredentialBlob;
+    public int Persist; public int AttributeCount; public IntPtr Attributes;
+    public string TargetAlias; public string UserName;
+  }
+  [DllImport("advapi32.dll", CharSet = CharSet.Unicode, SetLastError = true)]
+  public static extern bool CredReadW(string target, int type, int flags, out IntPtr credential);
+  [DllImport("advapi32.dll", CharSet = CharSet.Unicode, SetLastError = true)]
+  public static extern bool CredWriteW(ref Credential credential, int flags);
+  [DllImport("advapi32.dll")]
+  public static extern void CredFree(IntPtr credential);
+}
+'@
+`
+
+// windowsCredentialReadScript prints the generic credential named by
+// $env:NEXUS_SECRET_TARGET, exiting silently with status 1 if there is none.
+const windowsCredentialReadScript = `
+$ptr = [IntPtr]::Zero
+if (-not [NexusCredentials]::CredReadW($env:NEXUS_SECRET_TARGET, 1, 0, [ref]$ptr)) { exit 1 }
+$credential = [Runtime.InteropServices.Marshal]::PtrToStructure($ptr, [type][NexusCredentials+Credential])
+[Console]::Out.Write([Runtime.InteropServices.Marshal]::PtrToStringUni($credential.CredentialBlob, $credential.CredentialBlobSize / 2))
+[NexusCredentials]::CredFree($ptr)
+`
+
+// windowsCredentialWriteScript stores standard input as the generic credential
+// named by $env:NEXUS_SECRET_TARGET, persisted for the local user.
+const windowsCredentialWriteScript = `
+$value = [Console]::In.ReadToEnd()
+$credential = New-Object NexusCredentials+Credential
+$credential.Type = 1
+$credential.TargetName = $env:NEXUS_SECRET_TARGET
+$credential.Persist = 2
+$credential.CredentialBlobSize = $value.Length * 2
+$credential.CredentialBlob = [Runtime.InteropServices.Marshal]::StringToCoTaskMemUni($value)
+$ok = [NexusCredentials]::CredWriteW([ref]$credential, 0)
+[Runtime.InteropServices.Marshal]::FreeCoTaskMem($credential.CredentialBlob)
+if (-not $ok) { Write-Error ([ComponentModel.Win32Exception][Runtime.InteropServices.Marshal]::GetLastWin32Error()).Message; exit 1 }
+`
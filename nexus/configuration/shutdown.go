@@ -0,0 +1,35 @@
+package configuration
+
+import "fmt"
+
+// Shutdown screens
+const (
+	ShutdownScreenBlack = "black" // Switch the display to black
+	ShutdownScreenText  = "text"  // Show ShutdownConfig.Text
+	ShutdownScreenKeep  = "keep"  // Leave the last frame on the display
+)
+
+// ShutdownText is the default text of the "text" shutdown screen
+const ShutdownText = "Nexus offline"
+
+// ShutdownConfig sets the final frame left on the display when Nexus stops, so it
+// does not show stale readings until it is started again
+type ShutdownConfig struct {
+	// Screen is "black", "text" or "keep" (default "black")
+	Screen string `mapstructure:"screen"`
+
+	// Text is shown centered in the text color by the "text" screen (default
+	// "Nexus offline")
+	Text string `mapstructure:"text"`
+}
+
+// Validate checks the screen.
+func (s ShutdownConfig) Validate() error {
+	if s.Screen == "" {
+		return nil
+	}
+	if err := oneOf(s.Screen, ShutdownScreenBlack, ShutdownScreenText, ShutdownScreenKeep); err != nil {
+		return fmt.Errorf("screen %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package configuration
+
+import "strings"
+
+// Theme is a named color scheme for the display.
+type Theme struct {
+	Name        string
+	Description string
+
+	// TextColor and BackgroundColor replace the configured colors when the theme
+	// is activated
+	TextColor       string
+	BackgroundColor string
+}
+
+// Themes lists the built-in themes. The first matches the default colors.
+var Themes = []Theme{
+	{Name: "classic", Description: "White on black", TextColor: TextColor, BackgroundColor: BackgroundColor},
+	{Name: "amber", Description: "Amber monochrome terminal", TextColor: "#FFB000", BackgroundColor: "#000000"},
+	{Name: "phosphor", Description: "Green phosphor terminal", TextColor: "#33FF66", BackgroundColor: "#000000"},
+	{Name: "ocean", Description: "Pale cyan on deep blue", TextColor: "#E0F7FA", BackgroundColor: "#003B5C"},
+	{Name: "solarized", Description: "Solarized dark", TextColor: "#93A1A1", BackgroundColor: "#002B36"},
+	{Name: "paper", Description: "Dark gray on off-white", TextColor: "#202020", BackgroundColor: "#F5F5F0"},
+}
+
+// FindTheme returns the built-in theme called name.
+func FindTheme(name string) (Theme, bool) {
+	for _, theme := range Themes {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return Theme{}, false
+}
+
+// ActiveTheme returns the name of the theme whose colors the configuration uses,
+// or an empty string if the colors were customized.
+func (c *NexusConfig) ActiveTheme() string {
+	for _, theme := range Themes {
+		if strings.EqualFold(theme.TextColor, c.TextColor) && strings.EqualFold(theme.BackgroundColor, c.BackgroundColor) {
+			return theme.Name
+		}
+	}
+	return ""
+}
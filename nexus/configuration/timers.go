@@ -0,0 +1,64 @@
+package configuration
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default pomodoro durations
+const (
+	PomodoroWork  = "25m"
+	PomodoroBreak = "5m"
+)
+
+// PomodoroConfig sets the interval lengths of the pomodoro widget, in Go duration
+// syntax, e.g. "25m"
+type PomodoroConfig struct {
+	// Work is the length of a work interval (default "25m")
+	Work string `mapstructure:"work"`
+
+	// Break is the length of the break after each work interval (default "5m")
+	Break string `mapstructure:"break"`
+}
+
+// Durations returns the work and break durations, falling back to the defaults
+// for invalid values.
+func (p PomodoroConfig) Durations() (work, rest time.Duration) {
+	return timerDuration(p.Work, PomodoroWork), timerDuration(p.Break, PomodoroBreak)
+}
+
+// Validate checks that both durations are at least a second and at most a day.
+func (p PomodoroConfig) Validate() error {
+	if err := validateTimerDuration(p.Work); err != nil {
+		return fmt.Errorf("work %w", err)
+	}
+	if err := validateTimerDuration(p.Break); err != nil {
+		return fmt.Errorf("break %w", err)
+	}
+	return nil
+}
+
+// timerDuration parses value, or fallback if value is not a valid timer duration.
+func timerDuration(value, fallback string) time.Duration {
+	if validateTimerDuration(value) != nil || value == "" {
+		value = fallback
+	}
+	duration, _ := time.ParseDuration(value)
+	return duration
+}
+
+// validateTimerDuration checks that value is empty or a duration between a second
+// and a day.
+func validateTimerDuration(value string) error {
+	if value == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("is not a valid duration: %q", value)
+	}
+	if duration < time.Second || duration > 24*time.Hour {
+		return fmt.Errorf("must be between 1s and 24h, got %s", duration)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateColor returns an error unless s is empty or a valid color.
+func validateColor(s string) error {
+	if _, ok := ParseColor(s); s != "" && !ok {
+		return fmt.Errorf("invalid color %q, expected a hex color like #RRGGBB, rgb(), hsl() or a CSS color name", s)
+	}
+	return nil
+}
+
+// FieldError describes an invalid configuration value.
+type FieldError struct {
+	// Field is the configuration key of the value, e.g. "text_color" or "alerts[1]"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	// Line is the line of the value in the configuration file, 0 if unknown
+	Line int `json:"line,omitempty"`
+}
+
+func (e FieldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError lists every invalid value found by NexusConfig.Validate.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// add records an invalid value of field.
+func (e *ValidationError) add(field string, err error) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: err.Error()})
+}
+
+// oneOf returns an error unless value is one of valid.
+func oneOf(value string, valid ...string) error {
+	for _, v := range valid {
+		if value == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %q, got %q", valid, value)
+}
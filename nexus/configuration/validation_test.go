@@ -0,0 +1,100 @@
+package configuration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// isolateConfigDir points the user configuration directory, where LoadConfig
+// creates the images directory, at a temporary directory.
+func isolateConfigDir(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*NexusConfig)
+		fields []string // Invalid fields expected, in order
+	}{
+		{"defaults", func(*NexusConfig) {}, nil},
+		{"newer version", func(c *NexusConfig) { c.Version = SchemaVersion + 1 }, []string{"version"}},
+		{"time format", func(c *NexusConfig) { c.TimeFormat = "25h" }, []string{"time_format"}},
+		{"timezone", func(c *NexusConfig) { c.Timezone = "Mars/Olympus_Mons" }, []string{"timezone"}},
+		{"locale", func(c *NexusConfig) { c.Locale = "not a locale" }, []string{"locale"}},
+		{"unit", func(c *NexusConfig) { c.Unit = "kelvin" }, []string{"unit"}},
+		{"named color", func(c *NexusConfig) { c.TextColor = "rebeccapurple" }, nil},
+		{"text color", func(c *NexusConfig) { c.TextColor = "#12345" }, []string{"text_color"}},
+		{"background color", func(c *NexusConfig) { c.BackgroundColor = "hsl(nan, 50%, 50%)" }, []string{"background_color"}},
+		{"font size", func(c *NexusConfig) { c.FontSize = MaxFontSize + 1 }, []string{"font_size"}},
+		{"graph minutes", func(c *NexusConfig) { c.GraphMinutes = MinGraphMinutes - 1 }, []string{"graph_minutes"}},
+		{"interval", func(c *NexusConfig) { c.Intervals = map[string]string{"weather": "soon"} }, []string{"intervals.weather"}},
+		{"api listen", func(c *NexusConfig) { c.API.Listen = "localhost" }, []string{"api"}},
+		{"alert operator", func(c *NexusConfig) {
+			c.Alerts = []AlertRule{{Metric: "temperature.cpu", Operator: ">", Threshold: 80}, {Metric: "temperature.gpu", Operator: "=>"}}
+		}, []string{"alerts[1]"}},
+		{"several", func(c *NexusConfig) {
+			c.TimeFormat = ""
+			c.Unit = ""
+		}, []string{"time_format", "unit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(config)
+
+			var fields []string
+			var validationErr *ValidationError
+			if err := config.Validate(); errors.As(err, &validationErr) {
+				for _, field := range validationErr.Fields {
+					fields = append(fields, field.Field)
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("invalid fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		err  string // Expected error, empty if the file loads
+	}{
+		{"valid", "version: 1\nunit: imperial\n", ""},
+		{"unknown key", "version: 1\nmy_plugin_setting: 3\n", ""},
+		{"unknown nested key", "version: 1\nidle:\n  timeout: 5m\n  dim_to: 10\n", ""},
+		{"invalid enum", "version: 1\nunit: kelvin\n", "line 2: unit"},
+		{"invalid nested enum", "version: 1\nshutdown:\n  screen: fireworks\n", "line 2: shutdown"},
+		{"invalid color", "version: 1\npages:\n  - name: main\n    text_color: ultraviolet\n", "line 3: pages[0]"},
+		{"unknown key and invalid value", "version: 1\nmy_plugin_setting: 3\ntime_format: 25h\n", "line 3: time_format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateConfigDir(t)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadConfig(path)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("LoadConfig() = %v, want no error", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("LoadConfig() = %v, want an error containing %q", err, tt.err)
+			}
+		})
+	}
+}
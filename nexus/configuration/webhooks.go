@@ -0,0 +1,68 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Webhook events
+const (
+	WebhookEventTouch      = "touch"      // The display was tapped
+	WebhookEventPage       = "page"       // The displayed page changed
+	WebhookEventConnect    = "connect"    // The device was connected
+	WebhookEventDisconnect = "disconnect" // The device was disconnected
+	WebhookEventAlert      = "alert"      // A threshold alert was triggered or cleared
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventTouch,
+	WebhookEventPage,
+	WebhookEventConnect,
+	WebhookEventDisconnect,
+	WebhookEventAlert,
+}
+
+// Webhook posts a JSON payload to a URL whenever one of its events occurs.
+type Webhook struct {
+	// URL receives the POST requests
+	URL string `mapstructure:"url"`
+
+	// Events lists the events to send; empty sends every event
+	Events []string `mapstructure:"events"`
+
+	// Secret signs the payload with HMAC-SHA256 in the X-Nexus-Signature header
+	Secret string `mapstructure:"secret"`
+}
+
+// Subscribes reports whether the webhook is sent for event.
+func (w Webhook) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the webhook has an http(s) URL and only known events.
+func (w Webhook) Validate() error {
+	if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", w.URL)
+	}
+
+	for _, event := range w.Events {
+		known := false
+		for _, e := range WebhookEvents {
+			known = known || e == event
+		}
+		if !known {
+			return fmt.Errorf("webhook %s has unknown event %q", w.URL, event)
+		}
+	}
+
+	return nil
+}
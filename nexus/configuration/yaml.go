@@ -0,0 +1,313 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the parsed YAML of a configuration file, used to point errors at
+// the lines they come from. JSON is parsed as YAML; TOML files are converted and
+// have no line numbers.
+type configFile struct {
+	root *yaml.Node
+}
+
+// readConfigFile parses the configuration file at path.
+func readConfigFile(path string) (*configFile, error) {
+	var document yaml.Node
+
+	if fileType, err := configType(path); err != nil {
+		return nil, err
+	} else if fileType == "toml" {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		if err := document.Encode(v.AllSettings()); err != nil {
+			return nil, err
+		}
+		return &configFile{root: &document}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	file := &configFile{root: &document}
+	if len(document.Content) > 0 {
+		file.root = document.Content[0]
+	}
+	return file, nil
+}
+
+// unknownKeys reports the keys of the file that match no configuration setting,
+// suggesting the closest setting for likely typos.
+func (f *configFile) unknownKeys() []FieldError {
+	var errs []FieldError
+	checkKeys(f.root, reflect.TypeOf(NexusConfig{}), "", &errs)
+	return errs
+}
+
+// checkKeys walks node alongside the Go type t and records mapping keys that t
+// has no field for. prefix is the field path of node, e.g. "api.tls".
+func checkKeys(node *yaml.Node, t reflect.Type, prefix string, errs *[]FieldError) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		var keys []string
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if key := field.Tag.Get("mapstructure"); key != "" && key != "-" {
+				keys = append(keys, key)
+				fields[key] = field.Type
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := strings.ToLower(keyNode.Value)
+
+			fieldType, ok := fields[key]
+			if !ok {
+				message := "unknown key"
+				if suggestion := closestKey(key, keys); suggestion != "" {
+					message = fmt.Sprintf("unknown key, did you mean %q?", suggestion)
+				}
+				*errs = append(*errs, FieldError{Field: joinField(prefix, keyNode.Value), Message: message, Line: keyNode.Line})
+				continue
+			}
+			checkKeys(valueNode, fieldType, joinField(prefix, key), errs)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			checkKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", prefix, i), errs)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkKeys(node.Content[i+1], t.Elem(), joinField(prefix, node.Content[i].Value), errs)
+		}
+	case reflect.Pointer:
+		checkKeys(node, t.Elem(), prefix, errs)
+	}
+}
+
+// line returns the line of the value at field, a path like "alerts[1]" or
+// "api.tls.cert_file", or of its closest parent present in the file. It returns 0
+// if no part of field is in the file, e.g. when it comes from a default.
+func (f *configFile) line(field string) int {
+	node, line := f.root, 0
+	for _, part := range strings.Split(field, ".") {
+		name, index, _ := strings.Cut(part, "[")
+
+		if name != "" {
+			keyNode, valueNode := mappingValue(node, name)
+			if valueNode == nil {
+				return line
+			}
+			node, line = valueNode, keyNode.Line
+		}
+
+		for index != "" {
+			var i string
+			i, index, _ = strings.Cut(index, "]")
+			index = strings.TrimPrefix(index, "[")
+
+			n, err := strconv.Atoi(i)
+			if err != nil || node.Kind != yaml.SequenceNode || n < 0 || n >= len(node.Content) {
+				return line
+			}
+			node = node.Content[n]
+			line = node.Line
+		}
+	}
+	return line
+}
+
+// mappingValue returns the key and value nodes of key in the mapping node, nil if
+// it has no such key. Keys are matched ignoring case like viper does.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if strings.EqualFold(node.Content[i].Value, key) {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// joinField appends key to the field path prefix.
+func joinField(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// closestKey returns the candidate within two edits of key, "" if there is none.
+func closestKey(key string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := editDistance(key, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// updateYAMLFile sets the settings, keyed by dotted path like "api.tls.cert_file",
+// in the YAML file at path, creating it if needed. Values that did not change are
+// left as they are, so the rest of the document keeps its comments, key order and
+// formatting. The file is replaced atomically.
+func updateYAMLFile(path string, settings map[string]interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping of settings", path)
+	}
+
+	// New keys are appended in a stable order
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		if err := setYAMLValue(root, strings.Split(key, "."), settings[key]); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(yamlIndent(root))
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*"+filepath.Ext(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(out.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// setYAMLValue sets the value at the key path in the mapping node, adding the
+// keys that are missing. An existing value is only replaced if it differs, and
+// the replacement keeps its comments.
+func setYAMLValue(node *yaml.Node, path []string, value interface{}) error {
+	keyNode, valueNode := mappingValue(node, path[0])
+	if valueNode == nil {
+		keyNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+		valueNode = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+
+	if len(path) > 1 {
+		if valueNode.Kind != yaml.MappingNode {
+			*valueNode = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: valueNode.HeadComment, LineComment: valueNode.LineComment}
+		}
+		return setYAMLValue(valueNode, path[1:], value)
+	}
+
+	var newNode yaml.Node
+	if err := newNode.Encode(value); err != nil {
+		return err
+	}
+
+	var oldValue, newValue interface{}
+	if valueNode.Decode(&oldValue) == nil && newNode.Decode(&newValue) == nil && reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	newNode.HeadComment, newNode.LineComment, newNode.FootComment = valueNode.HeadComment, valueNode.LineComment, valueNode.FootComment
+	*valueNode = newNode
+	return nil
+}
+
+// yamlIndent returns the indentation of the nested mappings of root, 4 like the
+// YAML encoder if it has none.
+func yamlIndent(root *yaml.Node) int {
+	for i := 1; i < len(root.Content); i += 2 {
+		if value := root.Content[i]; value.Kind == yaml.MappingNode && len(value.Content) > 0 && value.Content[0].Column > root.Column {
+			return value.Content[0].Column - root.Column
+		}
+	}
+	return 4
+}
@@ -1,98 +1,59 @@
 package nexus
 
 import (
-	"log"
+	"context"
 	"time"
 
-	"github.com/google/gousb"
+	"nexus-open/nexus/api"
 )
 
-func InitializeDevice() {
-	device = ConnectNexus()
-	if device != nil {
-		connected = true
-		log.Println("iCUE Nexus: Connected")
+// initializeDevice connects to the device, or marks the virtual display as
+// connected, and keeps reconnecting in the background until ctx is done.
+func (n *Nexus) initializeDevice(ctx context.Context) {
+	if n.transport.Virtual() {
+		n.setConnected(true)
+		usbLog.Info("Virtual display, frames are only rendered for the preview")
+		return
 	}
 
-	RetryConnectNexus()
-}
-
-// ConnectNexus initializes a USB connection to the iCUE Nexus device.
-// It creates a new USB context, searches for devices matching the specified vendor and product IDs,
-// and establishes a connection with the first matching device found.
-//
-// The function performs the following steps:
-// 1. Creates a new USB context
-// 2. Searches for devices matching VID/PID
-// 3. Sets auto detach for kernel driver
-// 4. Configures the device
-//
-// Returns:
-//   - *gousb.Device: A pointer to the connected USB device
-//
-// The function will log.Fatal in the following cases:
-//   - No matching devices found
-//   - Failed to open devices
-//   - Failed to set auto detach
-//   - Failed to get device configuration
-var (
-	usbContext *gousb.Context
-)
-
-func ConnectNexus() *gousb.Device {
-	if usbContext == nil {
-		usbContext = gousb.NewContext()
+	if n.transport.Open() {
+		n.setConnected(true)
+		usbLog.Info("Connected")
 	}
 
-	devices, err := usbContext.OpenDevices(func(desc *gousb.DeviceDesc) bool {
-		return desc.Vendor == gousb.ID(vid) && desc.Product == gousb.ID(pid)
-	})
-
-	if err != nil {
-		log.Fatalf("Failed to open devices: %v", err)
-	}
-
-	if len(devices) == 0 {
-		return nil
-	}
-
-	device = devices[0]
+	n.supervise(ctx, "connection monitor", func() { n.monitorConnection(ctx) })
+}
 
-	if err := device.SetAutoDetach(true); err != nil {
-		log.Fatalf("Failed to set auto detach: %v", err)
+// setConnected updates the connection status and opens or closes the connection
+// gate so that instruments sleep while the device is unavailable. Changes of the
+// status are published on the event bus.
+func (n *Nexus) setConnected(value bool) {
+	if n.transport.setConnected(value) {
+		if value {
+			// The connected device may have settings of its own
+			n.applyDeviceConfig(n.configs.Get())
+		}
+		n.events.Device.Publish(api.DeviceEvent{Connected: value})
 	}
 
-	config, err := device.Config(1)
-
-	if err != nil {
-		log.Fatalf("Failed to get config: %v", err)
+	if value {
+		n.gate.Open()
+	} else {
+		n.gate.Close()
 	}
-
-	intf, err := config.Interface(0, 0)
-
-	if err != nil {
-		log.Fatalf("Failed to get interface: %v", err)
-		return nil
-	}
-
-	usbintf = intf // Set global interface
-
-	return device
 }
 
-// RetryConnectNexus initiates a concurrent monitoring of the Nexus connection.
-// It launches the monitorConnection function as a goroutine, which handles
-// connection retries and maintenance in the background.
-func RetryConnectNexus() {
-	go monitorConnection()
+// Connected reports whether the device, or the virtual display, is connected.
+func Connected() bool {
+	return defaultNexus.transport.Connected()
 }
 
 // monitorConnection continuously monitors the connection status and device health.
 // It attempts to reconnect if the connection is lost, with a fixed interval of 5 seconds
 // between attempts and a maximum of 10 retries. It also performs periodic health checks
 // on the connected device, closing the connection if the device becomes unhealthy.
-// The function runs indefinitely until the program terminates.
-func monitorConnection() {
+// The function runs until ctx is done.
+func (n *Nexus) monitorConnection(ctx context.Context) {
 	const (
 		reconnectInterval = 5 * time.Second
 		maxRetries        = 10
@@ -101,65 +62,49 @@ func monitorConnection() {
 	ticker := time.NewTicker(reconnectInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if !connected {
-			attemptReconnection(maxRetries)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !n.transport.Connected() {
+			n.attemptReconnection(ctx, maxRetries)
 			continue
 		}
 
-		if !checkDeviceHealth() {
-			connected = false
-			if device != nil {
-				device.Close()
-			}
+		if !n.transport.healthy() {
+			n.resetDevice()
 		}
 	}
 }
 
 // attemptReconnection tries to re-establish connection with the Nexus device using exponential backoff.
-// It attempts to connect up to maxRetries times. On successful connection, it closes any existing
-// device connection before establishing the new one. Between retry attempts, it waits with exponential
-// backoff starting at 1 second and doubling each time.
+// It attempts to connect up to maxRetries times. On successful connection, any existing
+// device connection is replaced by the new one. Between retry attempts, it waits with exponential
+// backoff starting at 1 second and doubling each time. It gives up early when ctx is done.
 //
 // Parameters:
+//   - ctx: stops the attempts when done
 //   - maxRetries: maximum number of reconnection attempts before giving up
-func attemptReconnection(maxRetries int) {
+func (n *Nexus) attemptReconnection(ctx context.Context, maxRetries int) {
 	for i := 0; i < maxRetries; i++ {
-		if newDevice := ConnectNexus(); newDevice != nil {
-			if device != nil {
-				device.Close()
-			}
-			device = newDevice
-			connected = true
-			log.Println("iCUE Nexus: Successfully reconnected")
+		if n.transport.Open() {
+			n.setConnected(true)
+			usbLog.Info("Successfully reconnected")
 			return
 		}
 
 		if i < maxRetries-1 {
 			backoff := time.Duration(1<<uint(i)) * time.Second
-			log.Printf("iCUE Nexus: Reconnection attempt %d failed, waiting %v", i+1, backoff)
-			time.Sleep(backoff)
+			usbLog.Warn("Reconnection attempt failed", "attempt", i+1, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
 		}
 	}
-	log.Println("iCUE Nexus: Failed all reconnection attempts")
-}
-
-// checkDeviceHealth verifies that both the device handle and USB interface are available and accessible.
-// It performs basic validation of device connectivity status.
-//
-// Returns:
-//   - true if both device handle and interface are valid and accessible
-//   - false if either device handle or interface is nil/invalid
-func checkDeviceHealth() bool {
-	if device == nil {
-		log.Println("iCUE Nexus: Device handle is not available")
-		return false
-	}
-
-	if usbintf == nil {
-		log.Println("iCUE Nexus: Default interface is not accessible")
-		return false
-	}
-
-	return true
+	usbLog.Error("Failed all reconnection attempts")
 }
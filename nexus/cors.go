@@ -0,0 +1,67 @@
+package nexus
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS settings
+const (
+	corsAllowMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsMaxAge       = 10 * time.Minute
+)
+
+// corsAllowHeaders are the request headers browsers may send cross-origin.
+var corsAllowHeaders = []string{"Content-Type", "Authorization", frameTokenHeader}
+
+// corsMiddleware adds CORS headers for requests from allowed origins and answers
+// preflight requests. The allowed origins are read from the current configuration
+// on every request, so changes apply without restarting the server.
+//
+// Parameters:
+//   - next: Handler serving the actual requests
+//   - origins: Function returning the allowed origins; "*" allows any origin
+func corsMiddleware(next http.Handler, origins func() []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		allowed := origins()
+		if !slices.Contains(allowed, "*") && !slices.Contains(allowed, origin) {
+			// Let the browser enforce the same-origin policy
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		// Answer preflight requests without invoking the handler
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsAllowHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", frameTokenHeader)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsOrigins returns the allowed CORS origins of the current configuration.
+func (n *Nexus) corsOrigins() []string {
+	cfg := n.configs.Get()
+	if cfg == nil {
+		return nil
+	}
+	return cfg.API.CORSOrigins
+}
@@ -0,0 +1,188 @@
+package nexus
+
+import (
+	"context"
+	"runtime"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/dbus"
+)
+
+// D-Bus service names
+const (
+	dbusServiceName = "org.nexusopen.Display"
+	dbusObjectPath  = dbus.ObjectPath("/org/nexusopen/Display")
+	dbusInterface   = "org.nexusopen.Display"
+)
+
+// dbusIntrospection describes the exported object to D-Bus tooling such as busctl.
+const dbusIntrospection = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+<node>
+  <interface name="org.nexusopen.Display">
+    <method name="Notify">
+      <arg name="text" type="s" direction="in"/>
+      <arg name="icon" type="s" direction="in"/>
+      <arg name="color" type="s" direction="in"/>
+      <arg name="duration" type="d" direction="in"/>
+      <arg name="shown" type="d" direction="out"/>
+    </method>
+    <method name="SetPage">
+      <arg name="name" type="s" direction="in"/>
+    </method>
+    <method name="SetBrightness">
+      <arg name="level" type="u" direction="in"/>
+    </method>
+    <method name="GetBrightness">
+      <arg name="level" type="u" direction="out"/>
+    </method>
+    <signal name="Touch">
+      <arg name="x" type="i"/>
+      <arg name="y" type="i"/>
+    </signal>
+  </interface>
+  <interface name="org.freedesktop.DBus.Introspectable">
+    <method name="Introspect">
+      <arg name="data" type="s" direction="out"/>
+    </method>
+  </interface>
+  <interface name="org.freedesktop.DBus.Peer">
+    <method name="Ping"/>
+  </interface>
+</node>`
+
+// startDBusService exports the org.nexusopen.Display service on the session bus so
+// desktop tooling and scripts can show notifications, switch pages and change the
+// brightness, and receive touch events as signals. The service stops when ctx is
+// done. It is only available on Linux; if no session bus is reachable, for example
+// when running as a system service, the failure is logged and the service is skipped.
+func (n *Nexus) startDBusService(ctx context.Context) {
+	if runtime.GOOS != "linux" {
+		return
+	}
+
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		dbusLog.Warn("D-Bus service disabled", "error", err)
+		return
+	}
+
+	conn.Handle(n.handleDBusCall)
+
+	code, err := conn.RequestName(dbusServiceName, 0)
+	if err != nil || (code != dbus.RequestNamePrimaryOwner && code != dbus.RequestNameAlreadyOwner) {
+		dbusLog.Warn("D-Bus service disabled, name is not available", "name", dbusServiceName)
+		conn.Close()
+		return
+	}
+
+	dbusLog.Info("D-Bus service registered", "name", dbusServiceName)
+
+	// Closed apart from the loop below, which is restarted if it panics
+	n.spawn(func() {
+		select {
+		case <-ctx.Done():
+		case <-conn.Done():
+		}
+		conn.Close()
+	})
+
+	n.supervise(ctx, "dbus", func() {
+		touches, unsubscribe := n.events.Touch.Subscribe(eventBuffer)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-conn.Done():
+				dbusLog.Warn("D-Bus connection lost")
+				return
+			case evt := <-touches:
+				emitTouchSignal(conn, evt)
+			}
+		}
+	})
+}
+
+// handleDBusCall answers method calls to the exported object.
+func (n *Nexus) handleDBusCall(call *dbus.Message) ([]interface{}, error) {
+	if call.Path != dbusObjectPath {
+		return nil, &dbus.Error{Name: dbus.ErrorUnknownMethod, Message: "unknown object " + string(call.Path)}
+	}
+
+	switch call.Interface + "." + call.Member {
+	case "org.freedesktop.DBus.Introspectable.Introspect":
+		return []interface{}{dbusIntrospection}, nil
+	case "org.freedesktop.DBus.Peer.Ping":
+		return nil, nil
+	case dbusInterface + ".Notify", ".Notify":
+		var request api.NotifyRequest
+		if !dbusArgs(call, &request.Text, &request.Icon, &request.Color, &request.Duration) {
+			return nil, invalidDBusArgs("sssd")
+		}
+		duration, err := n.queueNotification(request)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{duration.Seconds()}, nil
+	case dbusInterface + ".SetPage", ".SetPage":
+		var name string
+		if !dbusArgs(call, &name) {
+			return nil, invalidDBusArgs("s")
+		}
+		if err := n.setPage(name); err != nil {
+			return nil, &dbus.Error{Name: dbus.ErrorInvalidArgs, Message: err.Error()}
+		}
+		return nil, nil
+	case dbusInterface + ".SetBrightness", ".SetBrightness":
+		var level uint32
+		if !dbusArgs(call, &level) {
+			return nil, invalidDBusArgs("u")
+		}
+		if err := n.transport.SetBrightness(int(min(level, MaxBrightness+1))); err != nil {
+			return nil, &dbus.Error{Name: dbus.ErrorInvalidArgs, Message: err.Error()}
+		}
+		return nil, nil
+	case dbusInterface + ".GetBrightness", ".GetBrightness":
+		return []interface{}{uint32(n.transport.Brightness())}, nil
+	default:
+		return nil, &dbus.Error{Name: dbus.ErrorUnknownMethod, Message: "unknown method " + call.Member}
+	}
+}
+
+// dbusArgs copies the body of call into targets, returning false if the number or
+// types of the arguments do not match.
+func dbusArgs(call *dbus.Message, targets ...interface{}) bool {
+	if len(call.Body) != len(targets) {
+		return false
+	}
+
+	for i, target := range targets {
+		var ok bool
+		switch t := target.(type) {
+		case *string:
+			*t, ok = call.Body[i].(string)
+		case *float64:
+			*t, ok = call.Body[i].(float64)
+		case *uint32:
+			*t, ok = call.Body[i].(uint32)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// invalidDBusArgs returns the error for arguments not matching signature.
+func invalidDBusArgs(signature string) error {
+	return &dbus.Error{Name: dbus.ErrorInvalidArgs, Message: "expected arguments of type " + signature}
+}
+
+// emitTouchSignal broadcasts a touch on the display as a Touch signal on conn.
+func emitTouchSignal(conn *dbus.Conn, evt TouchEvent) {
+	if err := conn.Emit(dbusObjectPath, dbusInterface, "Touch", int32(evt.X), int32(evt.Y)); err != nil {
+		dbusLog.Warn("D-Bus touch signal failed", "error", err)
+	}
+}
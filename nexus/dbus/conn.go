@@ -0,0 +1,457 @@
+// Package dbus implements a minimal D-Bus client: connecting to the session bus,
+// calling methods, exporting objects that answer method calls, emitting signals
+// and monitoring messages sent to other connections.
+//
+// Only unix socket transports and the EXTERNAL authentication mechanism are
+// supported, which is what the session bus of every Linux desktop offers.
+package dbus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Well-known names of the message bus itself
+const (
+	busName      = "org.freedesktop.DBus"
+	busPath      = ObjectPath("/org/freedesktop/DBus")
+	busInterface = "org.freedesktop.DBus"
+
+	monitoringInterface = "org.freedesktop.DBus.Monitoring"
+)
+
+// RequestName reply codes
+const (
+	RequestNamePrimaryOwner = 1
+	RequestNameInQueue      = 2
+	RequestNameExists       = 3
+	RequestNameAlreadyOwner = 4
+)
+
+// Standard error names
+const (
+	ErrorUnknownMethod = "org.freedesktop.DBus.Error.UnknownMethod"
+	ErrorInvalidArgs   = "org.freedesktop.DBus.Error.InvalidArgs"
+	ErrorFailed        = "org.freedesktop.DBus.Error.Failed"
+)
+
+// Error is a D-Bus error reply.
+type Error struct {
+	Name    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Handler answers method calls. It returns the reply body, or an error which is sent
+// as an error reply; errors other than *Error are sent as ErrorFailed.
+type Handler func(call *Message) ([]interface{}, error)
+
+// Conn is a connection to a message bus. It is safe for concurrent use.
+type Conn struct {
+	conn net.Conn
+	name string // Unique name assigned by the bus
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	serial  uint32
+	pending map[uint32]chan *Message
+	handler Handler
+	signals []chan<- *Message
+	closed  chan struct{}
+
+	monitoring bool              // Set by BecomeMonitor
+	monitors   []chan<- *Message // Receive monitored messages
+}
+
+// SessionBus connects to the session bus named by DBUS_SESSION_BUS_ADDRESS.
+func SessionBus() (*Conn, error) {
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if address == "" {
+		return nil, errors.New("dbus: DBUS_SESSION_BUS_ADDRESS is not set")
+	}
+	return Dial(address)
+}
+
+// Dial connects to the bus at address, authenticates and registers with the bus.
+// address is a D-Bus server address list such as "unix:path=/run/user/1000/bus".
+func Dial(address string) (*Conn, error) {
+	var lastErr error
+	for _, addr := range strings.Split(address, ";") {
+		conn, err := dialAddress(addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		c := &Conn{
+			conn:    conn,
+			pending: make(map[uint32]chan *Message),
+			closed:  make(chan struct{}),
+		}
+
+		reader := bufio.NewReader(conn)
+		if err := authenticate(conn, reader); err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+
+		go c.readLoop(reader)
+
+		reply, err := c.Call(busName, busPath, busInterface, "Hello")
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		if len(reply) > 0 {
+			c.name, _ = reply[0].(string)
+		}
+		return c, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("dbus: no usable address in %q", address)
+	}
+	return nil, lastErr
+}
+
+// dialAddress opens the socket of a single server address.
+func dialAddress(address string) (net.Conn, error) {
+	transport, params, ok := strings.Cut(address, ":")
+	if !ok || transport != "unix" {
+		return nil, fmt.Errorf("dbus: unsupported transport in %q", address)
+	}
+
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(param, "=")
+		switch key {
+		case "path":
+			return net.Dial("unix", unescapeAddress(value))
+		case "abstract":
+			return net.Dial("unix", "@"+unescapeAddress(value))
+		}
+	}
+	return nil, fmt.Errorf("dbus: unsupported unix address %q", address)
+}
+
+// unescapeAddress decodes %XX escapes in an address value.
+func unescapeAddress(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if n, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// authenticate runs the SASL EXTERNAL handshake with the bus.
+func authenticate(conn net.Conn, reader *bufio.Reader) error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("dbus: authentication rejected: %s", strings.TrimSpace(line))
+	}
+
+	_, err = conn.Write([]byte("BEGIN\r\n"))
+	return err
+}
+
+// Name returns the unique name of the connection on the bus.
+func (c *Conn) Name() string {
+	return c.name
+}
+
+// RequestName asks the bus to assign a well-known name to the connection and
+// returns the reply code, e.g. RequestNamePrimaryOwner.
+func (c *Conn) RequestName(name string, flags uint32) (uint32, error) {
+	reply, err := c.Call(busName, busPath, busInterface, "RequestName", name, flags)
+	if err != nil {
+		return 0, err
+	}
+	if len(reply) == 0 {
+		return 0, errors.New("dbus: empty RequestName reply")
+	}
+	code, _ := reply[0].(uint32)
+	return code, nil
+}
+
+// AddMatch asks the bus to route messages matching rule to the connection, e.g.
+// "type='signal',interface='org.freedesktop.Notifications'".
+func (c *Conn) AddMatch(rule string) error {
+	_, err := c.Call(busName, busPath, busInterface, "AddMatch", rule)
+	return err
+}
+
+// Handle sets the handler answering method calls to the connection.
+func (c *Conn) Handle(handler Handler) {
+	c.mu.Lock()
+	c.handler = handler
+	c.mu.Unlock()
+}
+
+// Signals registers ch to receive incoming signals. Signals are dropped when ch is full.
+func (c *Conn) Signals(ch chan<- *Message) {
+	c.mu.Lock()
+	c.signals = append(c.signals, ch)
+	c.mu.Unlock()
+}
+
+// Monitor registers ch to receive the messages seen by a monitoring connection,
+// see BecomeMonitor. Messages are dropped when ch is full.
+func (c *Conn) Monitor(ch chan<- *Message) {
+	c.mu.Lock()
+	c.monitors = append(c.monitors, ch)
+	c.mu.Unlock()
+}
+
+// BecomeMonitor turns the connection into a monitor receiving copies of the
+// messages matching any of rules, whoever they are sent to, e.g.
+// "type='method_call',interface='org.freedesktop.Notifications'". The messages are
+// delivered to the channels registered with Monitor. A monitor cannot send
+// messages afterwards, so the connection is only good for monitoring.
+func (c *Conn) BecomeMonitor(rules ...string) error {
+	c.mu.Lock()
+	c.monitoring = true
+	c.mu.Unlock()
+
+	_, err := c.Call(busName, busPath, monitoringInterface, "BecomeMonitor", rules, uint32(0))
+	if err != nil {
+		c.mu.Lock()
+		c.monitoring = false
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// Call invokes a method and waits for the reply body.
+func (c *Conn) Call(destination string, path ObjectPath, iface, member string, args ...interface{}) ([]interface{}, error) {
+	reply := make(chan *Message, 1)
+
+	msg := &Message{
+		Type:        TypeMethodCall,
+		Path:        path,
+		Interface:   iface,
+		Member:      member,
+		Destination: destination,
+		Body:        args,
+	}
+
+	serial, err := c.send(msg, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case m := <-reply:
+		if m.Type == TypeError {
+			e := &Error{Name: m.ErrorName}
+			if len(m.Body) > 0 {
+				e.Message, _ = m.Body[0].(string)
+			}
+			return nil, e
+		}
+		return m.Body, nil
+	case <-c.closed:
+		c.mu.Lock()
+		delete(c.pending, serial)
+		c.mu.Unlock()
+		return nil, io.ErrClosedPipe
+	}
+}
+
+// Emit sends a signal.
+func (c *Conn) Emit(path ObjectPath, iface, member string, args ...interface{}) error {
+	_, err := c.send(&Message{
+		Type:      TypeSignal,
+		Path:      path,
+		Interface: iface,
+		Member:    member,
+		Body:      args,
+	}, nil)
+	return err
+}
+
+// Done returns a channel closed when the connection is lost.
+func (c *Conn) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// send writes msg with a new serial. If reply is not nil, the reply to the message is
+// delivered to it.
+func (c *Conn) send(msg *Message, reply chan *Message) (uint32, error) {
+	c.mu.Lock()
+	c.serial++
+	serial := c.serial
+	if reply != nil {
+		c.pending[serial] = reply
+	}
+	c.mu.Unlock()
+
+	data, err := msg.marshal(serial)
+	if err == nil {
+		c.writeMu.Lock()
+		_, err = c.conn.Write(data)
+		c.writeMu.Unlock()
+	}
+
+	if err != nil && reply != nil {
+		c.mu.Lock()
+		delete(c.pending, serial)
+		c.mu.Unlock()
+	}
+	return serial, err
+}
+
+// readLoop reads messages until the connection fails and dispatches replies,
+// method calls and signals.
+func (c *Conn) readLoop(reader *bufio.Reader) {
+	defer close(c.closed)
+
+	for {
+		msg, err := readMessage(reader)
+		if err != nil {
+			return
+		}
+
+		c.mu.Lock()
+		monitoring := c.monitoring
+		reply, ok := c.pending[msg.ReplySerial]
+		isReply := msg.Type == TypeMethodReturn || msg.Type == TypeError
+		// A monitor also sees the replies to other connections
+		if ok && isReply && (!monitoring || msg.Destination == c.name) {
+			delete(c.pending, msg.ReplySerial)
+		} else {
+			ok = false
+		}
+		c.mu.Unlock()
+
+		switch {
+		case ok:
+			reply <- msg
+		case monitoring:
+			// Messages of other connections, which must not be answered
+			c.mu.Lock()
+			for _, ch := range c.monitors {
+				select {
+				case ch <- msg:
+				default:
+				}
+			}
+			c.mu.Unlock()
+		case msg.Type == TypeMethodCall:
+			go c.dispatch(msg)
+		case msg.Type == TypeSignal:
+			c.mu.Lock()
+			for _, ch := range c.signals {
+				select {
+				case ch <- msg:
+				default:
+				}
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// dispatch answers a method call with the registered handler.
+func (c *Conn) dispatch(call *Message) {
+	c.mu.Lock()
+	handler := c.handler
+	c.mu.Unlock()
+
+	var (
+		body []interface{}
+		err  error
+	)
+	if handler == nil {
+		err = &Error{Name: ErrorUnknownMethod, Message: "no object exported"}
+	} else {
+		body, err = handler(call)
+	}
+
+	if call.Flags&FlagNoReplyExpected != 0 {
+		return
+	}
+
+	reply := &Message{
+		Type:        TypeMethodReturn,
+		ReplySerial: call.Serial,
+		Destination: call.Sender,
+		Body:        body,
+	}
+	if err != nil {
+		var dbusErr *Error
+		if !errors.As(err, &dbusErr) {
+			dbusErr = &Error{Name: ErrorFailed, Message: err.Error()}
+		}
+		reply.Type = TypeError
+		reply.ErrorName = dbusErr.Name
+		reply.Body = []interface{}{dbusErr.Message}
+	}
+
+	c.send(reply, nil)
+}
+
+// readMessage reads one complete message.
+func readMessage(reader *bufio.Reader) (*Message, error) {
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(reader, head); err != nil {
+		return nil, err
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if head[0] == 'B' {
+		order = binary.BigEndian
+	}
+
+	bodyLength := int(order.Uint32(head[4:8]))
+	fieldsLength := int(order.Uint32(head[12:16]))
+	headerLength := 16 + fieldsLength
+	headerLength += (8 - headerLength%8) % 8
+
+	total := headerLength + bodyLength
+	if total > maxMessageSize {
+		return nil, fmt.Errorf("dbus: message of %d bytes too large", total)
+	}
+
+	buf := make([]byte, total)
+	copy(buf, head)
+	if _, err := io.ReadFull(reader, buf[16:]); err != nil {
+		return nil, err
+	}
+
+	return unmarshal(buf)
+}
@@ -0,0 +1,581 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Message types
+const (
+	TypeMethodCall   = 1
+	TypeMethodReturn = 2
+	TypeError        = 3
+	TypeSignal       = 4
+)
+
+// Message flags
+const (
+	FlagNoReplyExpected = 0x1
+)
+
+// Header field codes
+const (
+	fieldPath        = 1
+	fieldInterface   = 2
+	fieldMember      = 3
+	fieldErrorName   = 4
+	fieldReplySerial = 5
+	fieldDestination = 6
+	fieldSender      = 7
+	fieldSignature   = 8
+)
+
+// maxMessageSize is the largest message accepted from the bus (the protocol limit is 128 MiB)
+const maxMessageSize = 16 << 20
+
+// ObjectPath is a D-Bus object path, marshalled with signature "o".
+type ObjectPath string
+
+// Signature is a D-Bus type signature, marshalled with signature "g".
+type Signature string
+
+// Variant is a value together with its signature, marshalled with signature "v".
+type Variant struct {
+	Signature Signature
+	Value     interface{}
+}
+
+// Message is a D-Bus message. Body values are Go values of the types accepted by
+// SignatureOf; decoded arrays are []interface{}, structs and dict entries are
+// []interface{} and dictionaries with string keys are map[string]interface{}.
+type Message struct {
+	Type        byte
+	Flags       byte
+	Serial      uint32
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	ReplySerial uint32
+	Destination string
+	Sender      string
+	Body        []interface{}
+}
+
+// Signature returns the signature of the message body.
+func (m *Message) Signature() (Signature, error) {
+	var sig Signature
+	for _, value := range m.Body {
+		s, err := SignatureOf(value)
+		if err != nil {
+			return "", err
+		}
+		sig += s
+	}
+	return sig, nil
+}
+
+// SignatureOf returns the signature of a Go value.
+func SignatureOf(value interface{}) (Signature, error) {
+	switch v := value.(type) {
+	case byte:
+		return "y", nil
+	case bool:
+		return "b", nil
+	case int16:
+		return "n", nil
+	case uint16:
+		return "q", nil
+	case int32:
+		return "i", nil
+	case uint32:
+		return "u", nil
+	case int64:
+		return "x", nil
+	case uint64:
+		return "t", nil
+	case float64:
+		return "d", nil
+	case string:
+		return "s", nil
+	case ObjectPath:
+		return "o", nil
+	case Signature:
+		return "g", nil
+	case Variant:
+		return "v", nil
+	case []string:
+		return "as", nil
+	case map[string]Variant:
+		return "a{sv}", nil
+	default:
+		return "", fmt.Errorf("dbus: unsupported type %T", v)
+	}
+}
+
+// encoder marshals values in little endian byte order.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) signature(s Signature) {
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// array writes the length prefixed elements produced by write, aligned to align.
+func (e *encoder) array(align int, write func()) {
+	e.uint32(0)
+	lengthAt := len(e.buf) - 4
+	e.align(align)
+	start := len(e.buf)
+	write()
+	binary.LittleEndian.PutUint32(e.buf[lengthAt:], uint32(len(e.buf)-start))
+}
+
+func (e *encoder) value(value interface{}) error {
+	switch v := value.(type) {
+	case byte:
+		e.buf = append(e.buf, v)
+	case bool:
+		if v {
+			e.uint32(1)
+		} else {
+			e.uint32(0)
+		}
+	case int16:
+		e.align(2)
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, uint16(v))
+	case uint16:
+		e.align(2)
+		e.buf = binary.LittleEndian.AppendUint16(e.buf, v)
+	case int32:
+		e.uint32(uint32(v))
+	case uint32:
+		e.uint32(v)
+	case int64:
+		e.align(8)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, uint64(v))
+	case uint64:
+		e.align(8)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+	case float64:
+		e.align(8)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case string:
+		e.string(v)
+	case ObjectPath:
+		e.string(string(v))
+	case Signature:
+		e.signature(v)
+	case Variant:
+		sig := v.Signature
+		if sig == "" {
+			var err error
+			if sig, err = SignatureOf(v.Value); err != nil {
+				return err
+			}
+		}
+		e.signature(sig)
+		return e.value(v.Value)
+	case []string:
+		e.array(4, func() {
+			for _, s := range v {
+				e.string(s)
+			}
+		})
+	case map[string]Variant:
+		var err error
+		e.array(8, func() {
+			for key, item := range v {
+				e.align(8)
+				e.string(key)
+				if err == nil {
+					err = e.value(item)
+				}
+			}
+		})
+		return err
+	default:
+		return fmt.Errorf("dbus: unsupported type %T", v)
+	}
+	return nil
+}
+
+// marshal encodes m with the given serial.
+func (m *Message) marshal(serial uint32) ([]byte, error) {
+	sig, err := m.Signature()
+	if err != nil {
+		return nil, err
+	}
+
+	body := &encoder{}
+	for _, value := range m.Body {
+		if err := body.value(value); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &encoder{buf: []byte{'l', m.Type, m.Flags, 1}}
+	e.uint32(uint32(len(body.buf)))
+	e.uint32(serial)
+
+	field := func(code byte, value interface{}) {
+		e.align(8)
+		e.buf = append(e.buf, code)
+		e.value(Variant{Value: value})
+	}
+
+	e.array(8, func() {
+		if m.Path != "" {
+			field(fieldPath, m.Path)
+		}
+		if m.Interface != "" {
+			field(fieldInterface, m.Interface)
+		}
+		if m.Member != "" {
+			field(fieldMember, m.Member)
+		}
+		if m.ErrorName != "" {
+			field(fieldErrorName, m.ErrorName)
+		}
+		if m.ReplySerial != 0 {
+			field(fieldReplySerial, m.ReplySerial)
+		}
+		if m.Destination != "" {
+			field(fieldDestination, m.Destination)
+		}
+		if sig != "" {
+			field(fieldSignature, sig)
+		}
+	})
+	e.align(8)
+
+	return append(e.buf, body.buf...), nil
+}
+
+// decoder unmarshals values from a message buffer.
+type decoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+var errShortMessage = errors.New("dbus: message too short")
+
+func (d *decoder) align(n int) error {
+	for d.pos%n != 0 {
+		d.pos++
+	}
+	if d.pos > len(d.buf) {
+		return errShortMessage
+	}
+	return nil
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if d.pos+n > len(d.buf) {
+		return nil, errShortMessage
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(int(n) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:n]), nil
+}
+
+func (d *decoder) signature() (Signature, error) {
+	n, err := d.next(1)
+	if err != nil {
+		return "", err
+	}
+	b, err := d.next(int(n[0]) + 1)
+	if err != nil {
+		return "", err
+	}
+	return Signature(b[:n[0]]), nil
+}
+
+// values decodes all values of sig.
+func (d *decoder) values(sig Signature) ([]interface{}, error) {
+	var values []interface{}
+	for len(sig) > 0 {
+		typ, rest, err := splitType(sig)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value(typ)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		sig = rest
+	}
+	return values, nil
+}
+
+// value decodes a single complete type.
+func (d *decoder) value(typ Signature) (interface{}, error) {
+	switch typ[0] {
+	case 'y':
+		b, err := d.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		v, err := d.uint32()
+		return v != 0, err
+	case 'n', 'q':
+		if err := d.align(2); err != nil {
+			return nil, err
+		}
+		b, err := d.next(2)
+		if err != nil {
+			return nil, err
+		}
+		if typ[0] == 'n' {
+			return int16(d.order.Uint16(b)), nil
+		}
+		return d.order.Uint16(b), nil
+	case 'i':
+		v, err := d.uint32()
+		return int32(v), err
+	case 'u', 'h':
+		return d.uint32()
+	case 'x', 't', 'd':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		b, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		v := d.order.Uint64(b)
+		switch typ[0] {
+		case 'x':
+			return int64(v), nil
+		case 't':
+			return v, nil
+		default:
+			return math.Float64frombits(v), nil
+		}
+	case 's':
+		return d.string()
+	case 'o':
+		s, err := d.string()
+		return ObjectPath(s), err
+	case 'g':
+		return d.signature()
+	case 'v':
+		sig, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		if _, rest, err := splitType(sig); err != nil || rest != "" {
+			return nil, fmt.Errorf("dbus: invalid variant signature %q", sig)
+		}
+		value, err := d.value(sig)
+		return Variant{Signature: sig, Value: value}, err
+	case '(', '{':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		return d.values(typ[1 : len(typ)-1])
+	case 'a':
+		return d.array(typ[1:])
+	default:
+		return nil, fmt.Errorf("dbus: unsupported type %q", typ)
+	}
+}
+
+// array decodes an array with elements of type elem. Dictionaries with string keys
+// are returned as map[string]interface{}.
+func (d *decoder) array(elem Signature) (interface{}, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.align(alignment(elem[0])); err != nil {
+		return nil, err
+	}
+
+	end := d.pos + int(n)
+	if end > len(d.buf) {
+		return nil, errShortMessage
+	}
+
+	if elem[0] == '{' && elem[1] == 's' {
+		dict := make(map[string]interface{})
+		for d.pos < end {
+			entry, err := d.value(elem)
+			if err != nil {
+				return nil, err
+			}
+			pair := entry.([]interface{})
+			dict[pair[0].(string)] = pair[1]
+		}
+		return dict, nil
+	}
+
+	items := []interface{}{}
+	for d.pos < end {
+		item, err := d.value(elem)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// alignment returns the alignment of a type code.
+func alignment(code byte) int {
+	switch code {
+	case 'n', 'q':
+		return 2
+	case 'b', 'i', 'u', 'h', 's', 'o', 'a':
+		return 4
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	default:
+		return 1
+	}
+}
+
+// splitType splits the first complete type off sig.
+func splitType(sig Signature) (Signature, Signature, error) {
+	if len(sig) == 0 {
+		return "", "", errors.New("dbus: empty signature")
+	}
+
+	switch sig[0] {
+	case 'a':
+		elem, rest, err := splitType(sig[1:])
+		if err != nil {
+			return "", "", err
+		}
+		return "a" + elem, rest, nil
+	case '(', '{':
+		closing := map[byte]byte{'(': ')', '{': '}'}[sig[0]]
+		inner := sig[1:]
+		for len(inner) > 0 && inner[0] != closing {
+			var err error
+			if _, inner, err = splitType(inner); err != nil {
+				return "", "", err
+			}
+		}
+		if len(inner) == 0 {
+			return "", "", fmt.Errorf("dbus: unterminated signature %q", sig)
+		}
+		n := len(sig) - len(inner) + 1
+		return sig[:n], sig[n:], nil
+	default:
+		return sig[:1], sig[1:], nil
+	}
+}
+
+// unmarshal decodes a complete message.
+func unmarshal(buf []byte) (*Message, error) {
+	d := &decoder{buf: buf, order: binary.LittleEndian}
+	switch buf[0] {
+	case 'l':
+	case 'B':
+		d.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("dbus: invalid byte order %q", buf[0])
+	}
+
+	m := &Message{Type: buf[1], Flags: buf[2]}
+	d.pos = 4
+	bodyLength, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if m.Serial, err = d.uint32(); err != nil {
+		return nil, err
+	}
+
+	fields, err := d.value("a(yv)")
+	if err != nil {
+		return nil, err
+	}
+
+	var sig Signature
+	for _, f := range fields.([]interface{}) {
+		pair := f.([]interface{})
+		value := pair[1].(Variant).Value
+		switch pair[0].(byte) {
+		case fieldPath:
+			m.Path, _ = value.(ObjectPath)
+		case fieldInterface:
+			m.Interface, _ = value.(string)
+		case fieldMember:
+			m.Member, _ = value.(string)
+		case fieldErrorName:
+			m.ErrorName, _ = value.(string)
+		case fieldReplySerial:
+			m.ReplySerial, _ = value.(uint32)
+		case fieldDestination:
+			m.Destination, _ = value.(string)
+		case fieldSender:
+			m.Sender, _ = value.(string)
+		case fieldSignature:
+			sig, _ = value.(Signature)
+		}
+	}
+
+	if err := d.align(8); err != nil {
+		return nil, err
+	}
+	if len(buf)-d.pos != int(bodyLength) {
+		return nil, errShortMessage
+	}
+
+	// Body alignment is relative to the start of the body, which is 8-aligned
+	if m.Body, err = d.values(sig); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,86 @@
+package nexus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// SetDebug serves the net/http/pprof profiles under /debug/pprof/ and runtime
+// statistics at /debug/runtime when enabled, e.g. from a command line flag. It must
+// be called before Run.
+func SetDebug(enabled bool) {
+	defaultNexus.debugEndpoints.Store(enabled)
+}
+
+// runtimeStats is returned by GET /debug/runtime.
+type runtimeStats struct {
+	Goroutines int    `json:"goroutines"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+	NumCPU     int    `json:"num_cpu"`
+	GoVersion  string `json:"go_version"`
+
+	HeapAlloc     uint64  `json:"heap_alloc_bytes"`  // Bytes of allocated heap objects
+	HeapInuse     uint64  `json:"heap_inuse_bytes"`  // Bytes in in-use heap spans
+	HeapObjects   uint64  `json:"heap_objects"`      // Allocated heap objects
+	TotalAlloc    uint64  `json:"total_alloc_bytes"` // Bytes allocated since the start, also freed ones
+	Sys           uint64  `json:"sys_bytes"`         // Bytes obtained from the OS
+	NumGC         uint32  `json:"num_gc"`            // Completed GC cycles
+	GCPauseTotal  float64 `json:"gc_pause_total_seconds"`
+	GCCPUFraction float64 `json:"gc_cpu_fraction"` // Share of the CPU time used by the GC since the start
+
+	Uptime float64 `json:"uptime"`
+}
+
+// mountDebug adds the debug endpoints to mux. They are not part of the API
+// description, as they are only meant for troubleshooting.
+func mountDebug(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", debugHandler(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", debugHandler(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", debugHandler(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", debugHandler(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", debugHandler(pprof.Trace))
+	mux.HandleFunc("/debug/runtime", runtimeHandler)
+}
+
+// debugHandler lifts the server write timeout for next, as CPU profiles and traces
+// take 30 seconds by default.
+func debugHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		next(w, r)
+	}
+}
+
+// runtimeHandler reports goroutine and heap statistics (GET /debug/runtime). The
+// stacks of the goroutines are at /debug/pprof/goroutine?debug=2.
+func runtimeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := runtimeStats{
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		GoVersion:     runtime.Version(),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		TotalAlloc:    mem.TotalAlloc,
+		Sys:           mem.Sys,
+		NumGC:         mem.NumGC,
+		GCPauseTotal:  time.Duration(mem.PauseTotalNs).Seconds(),
+		GCCPUFraction: mem.GCCPUFraction,
+		Uptime:        time.Since(startTime).Seconds(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
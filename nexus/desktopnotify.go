@@ -0,0 +1,222 @@
+package nexus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/dbus"
+)
+
+// desktopNotifyRetry is how long the relay waits before listening again after the
+// notification source failed.
+const desktopNotifyRetry = 30 * time.Second
+
+// freedesktopNotifyRule matches the calls of apps to the notification daemon of the
+// desktop. The arguments of Notify are app_name, replaces_id, app_icon, summary,
+// body, actions, hints and expire_timeout.
+const freedesktopNotifyRule = "type='method_call',interface='org.freedesktop.Notifications',member='Notify'"
+
+// windowsNotifyScript prints "<app>\t<title>" for every toast notification shown
+// after it started, polling the notification center every two seconds. It exits
+// with an error if the user did not grant notification access.
+const windowsNotifyScript = `
+[Console]::OutputEncoding = [Text.Encoding]::UTF8
+Add-Type -AssemblyName System.Runtime.WindowsRuntime
+$asTask = ([System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object {
+  $_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -eq 'IAsyncOperation` + "`" + `1'
+})[0]
+function Await($op, [Type]$type) {
+  $task = $asTask.MakeGenericMethod($type).Invoke($null, @($op))
+  $task.Wait(-1) | Out-Null
+  $task.Result
+}
+$listenerType = [Windows.UI.Notifications.Management.UserNotificationListener, Windows.UI.Notifications, ContentType = WindowsRuntime]
+$notificationType = [Windows.UI.Notifications.UserNotification, Windows.UI.Notifications, ContentType = WindowsRuntime]
+$listener = $listenerType::Current
+$access = Await ($listener.RequestAccessAsync()) ([Windows.UI.Notifications.Management.UserNotificationListenerAccessStatus])
+if ($access -ne 'Allowed') {
+  [Console]::Error.WriteLine("notification access is $access")
+  exit 1
+}
+$listType = [System.Collections.Generic.IReadOnlyList` + "`" + `1].MakeGenericType($notificationType)
+$seen = $null
+while ($true) {
+  $current = @{}
+  foreach ($n in (Await ($listener.GetNotificationsAsync([Windows.UI.Notifications.NotificationKinds]::Toast)) ($listType))) {
+    $current[$n.Id] = $true
+    if ($seen -eq $null -or $seen.ContainsKey($n.Id)) { continue }
+    $binding = $n.Notification.Visual.GetBinding([Windows.UI.Notifications.KnownNotificationBindings]::ToastGeneric)
+    $title = ''
+    if ($binding -ne $null) {
+      $texts = @($binding.GetTextElements())
+      if ($texts.Count -gt 0) { $title = $texts[0].Text }
+    }
+    [Console]::Out.WriteLine(("{0}` + "`t" + `{1}" -f $n.AppInfo.DisplayInfo.DisplayName, ($title -replace '\s+', ' ')))
+    [Console]::Out.Flush()
+  }
+  $seen = $current
+  Start-Sleep -Seconds 2
+}
+`
+
+// desktopNotification is a notification an app showed on the desktop.
+type desktopNotification struct {
+	app     string
+	summary string
+}
+
+// relayDesktopNotifications shows the notifications of desktop apps as banners
+// while the relay is enabled in the configuration, until ctx is done. Listening
+// starts and stops as the configuration changes.
+func (n *Nexus) relayDesktopNotifications(ctx context.Context) {
+	configUpdate, unsubscribe := n.events.Config.SubscribeLatest()
+	defer unsubscribe()
+
+	var stop context.CancelFunc
+	defer func() {
+		if stop != nil {
+			stop()
+		}
+	}()
+
+	cfg := n.configs.Get()
+	for {
+		enabled := cfg != nil && cfg.Notifications.Enabled
+		if enabled && stop == nil {
+			listenCtx, cancel := context.WithCancel(ctx)
+			stop = cancel
+			n.spawn(func() { n.listenDesktopNotifications(listenCtx) })
+		} else if !enabled && stop != nil {
+			stop()
+			stop = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case cfg = <-configUpdate:
+		}
+	}
+}
+
+// listenDesktopNotifications relays notifications until ctx is done, listening
+// again after desktopNotifyRetry if the notification source fails.
+func (n *Nexus) listenDesktopNotifications(ctx context.Context) {
+	for {
+		err := watchDesktopNotifications(ctx, n.showDesktopNotification)
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errors.ErrUnsupported) {
+			notifyLog.Warn("Desktop notifications are not supported", "os", runtime.GOOS)
+			return
+		}
+		notifyLog.Warn("Desktop notifications unavailable", "error", err, "retry", desktopNotifyRetry)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(desktopNotifyRetry):
+		}
+	}
+}
+
+// showDesktopNotification queues a banner with the app name and summary of
+// notification, if the configuration relays the app.
+func (n *Nexus) showDesktopNotification(notification desktopNotification) {
+	cfg := n.configs.Get()
+	if cfg == nil || !cfg.Notifications.Relays(notification.app) {
+		return
+	}
+
+	text := notification.app
+	if notification.summary != "" {
+		text += ": " + notification.summary
+	}
+	if _, err := n.queueNotification(api.NotifyRequest{Text: text, Icon: "bell"}); err != nil {
+		notifyLog.Debug("Dropped desktop notification", "app", notification.app, "error", err)
+	}
+}
+
+// watchDesktopNotifications calls show for every notification shown on the
+// desktop until ctx is done or the notification source fails. It returns an error
+// wrapping errors.ErrUnsupported on platforms without a notification source.
+func watchDesktopNotifications(ctx context.Context, show func(desktopNotification)) error {
+	switch runtime.GOOS {
+	case "linux":
+		return watchFreedesktopNotifications(ctx, show)
+	case "windows":
+		return watchWindowsNotifications(ctx, show)
+	default:
+		return fmt.Errorf("desktop notifications: %w", errors.ErrUnsupported)
+	}
+}
+
+// watchFreedesktopNotifications monitors the session bus for calls of apps to
+// org.freedesktop.Notifications, so any notification daemon keeps showing them.
+func watchFreedesktopNotifications(ctx context.Context, show func(desktopNotification)) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	calls := make(chan *dbus.Message, eventBuffer)
+	conn.Monitor(calls)
+	if err := conn.BecomeMonitor(freedesktopNotifyRule); err != nil {
+		return fmt.Errorf("failed to monitor the session bus: %v", err)
+	}
+	notifyLog.Info("Relaying desktop notifications")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Done():
+			return errors.New("D-Bus connection lost")
+		case call := <-calls:
+			if call.Type != dbus.TypeMethodCall || call.Member != "Notify" || len(call.Body) < 5 {
+				continue
+			}
+			app, _ := call.Body[0].(string)
+			summary, _ := call.Body[3].(string)
+			show(desktopNotification{app: app, summary: strings.Join(strings.Fields(summary), " ")})
+		}
+	}
+}
+
+// watchWindowsNotifications reads the notifications printed by windowsNotifyScript.
+func watchWindowsNotifications(ctx context.Context, show func(desktopNotification)) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsNotifyScript)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start notification listener: %v", err)
+	}
+	notifyLog.Info("Relaying desktop notifications")
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		app, summary, _ := strings.Cut(scanner.Text(), "\t")
+		if app != "" {
+			show(desktopNotification{app: app, summary: strings.TrimSpace(summary)})
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("notification listener failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return errors.New("notification listener stopped")
+}
@@ -0,0 +1,43 @@
+package nexus
+
+import (
+	"sync"
+
+	"nexus-open/nexus/configuration"
+)
+
+// appliedDevice remembers the brightness last applied from the configuration, so
+// that reloads keep a brightness set through the API unless it was reconfigured.
+type appliedDevice struct {
+	mu         sync.Mutex
+	serial     string
+	brightness int
+	applied    bool
+}
+
+// applyDeviceConfig applies the pages, brightness and rotation of cfg for the
+// connected device, falling back to the global settings. It is called on start,
+// when a device connects and when the configuration is reloaded.
+func (n *Nexus) applyDeviceConfig(cfg *configuration.NexusConfig) {
+	if cfg == nil {
+		return
+	}
+
+	serial := n.transport.Serial()
+
+	resolved := cfg.ForDevice(serial)
+	n.pages.configure(resolved.Pages)
+	n.transport.SetRotation(resolved.Rotation)
+
+	n.applied.mu.Lock()
+	defer n.applied.mu.Unlock()
+
+	if n.applied.applied && n.applied.serial == serial && n.applied.brightness == resolved.Brightness {
+		return
+	}
+	if err := n.transport.SetBrightness(resolved.Brightness); err != nil {
+		usbLog.Warn("Invalid device settings", "serial", serial, "error", err)
+		return
+	}
+	n.applied.serial, n.applied.brightness, n.applied.applied = serial, resolved.Brightness, true
+}
@@ -17,12 +17,8 @@
 // The screen refresh rate is maintained at 24 Hz for optimal performance.
 // Thread safety is ensured through mutex locks when accessing shared device resources.
 //
-// USB Protocol Details:
-// The device communicates using a custom protocol with:
-// - 1024*4 byte buffer size
-// - 120 chunks of image data
-// - RGBA color format (8 bits per channel)
-// - Custom header format for each chunk
+// Frames are sent to the device by the nexusdisplay package, which implements
+// its chunked USB protocol.
 //
 // Note: The device automatically handles disconnection events and will attempt
 // to gracefully handle connection loss without throwing errors.
@@ -30,11 +26,16 @@
 package nexus
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"image"
+	"image/color"
+	"image/draw"
+	"nexus-open/nexus/configuration"
 	"nexus-open/nexus/instruments"
-	"sync"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -43,148 +44,245 @@ type CreateScreenConfig struct {
 	gputemp         float64
 	network         instruments.NetworkStats
 	weather         *instruments.WeatherInfo
+	volume          *instruments.VolumeState
+	keyboard        *instruments.KeyboardState
+	weatherAlerts   instruments.WeatherAlerts
+	radar           *instruments.RadarMap
+	news            instruments.NewsHeadlines
+	feeds           instruments.FeedHeadlines
+	stocks          instruments.StockQuotes
+	nextEvent       *instruments.UpcomingEvent
+	nowPlaying      *instruments.NowPlaying
+	mqtt            instruments.MQTTMessages
+	prometheus      instruments.PrometheusResults
+	printJob        *instruments.PrintJob
+	disks           instruments.DiskHealth
+	vpn             *instruments.VPNStatus
+	plugins         map[string]*instruments.PluginOutput
+	pomodoro        pomodoroState
+	countdown       countdownState
+	stopwatch       stopwatchState
+	graphSpan       time.Duration
+	unit            string // Unit system of the weather
+	font            string
 	timeFormat      string
 	textColor       string
 	backgroundColor string
 }
 
-var deviceMutex sync.Mutex
+// displayState holds the latest instrument values received by the display loop.
+type displayState struct {
+	cpu           float64
+	gpu           float64
+	network       instruments.NetworkStats
+	weather       *instruments.WeatherInfo
+	volume        *instruments.VolumeState   // nil until the first volume reading
+	keyboard      *instruments.KeyboardState // nil until the first keyboard reading
+	weatherAlerts instruments.WeatherAlerts
+	radar         *instruments.RadarMap // nil until the first radar map
+	news          instruments.NewsHeadlines
+	feeds         instruments.FeedHeadlines
+	stocks        instruments.StockQuotes
+	nextEvent     *instruments.UpcomingEvent // nil when no event is upcoming
+	nowPlaying    *instruments.NowPlaying    // nil when no player is active
+	mqtt          instruments.MQTTMessages
+	prometheus    instruments.PrometheusResults
+	disks         instruments.DiskHealth
+	vpn           *instruments.VPNStatus               // nil while no VPN interface is configured
+	printJob      *instruments.PrintJob                // nil while the printer is idle
+	plugins       map[string]*instruments.PluginOutput // Latest output by plugin name
+}
 
-// StartDisplayUpdate initiates a goroutine that manages the display updates for system metrics.
-// It receives data from three channels:
-//   - tempChan: provides CPU and GPU temperature readings
-//   - networkChan: provides network statistics
-//   - weatherChan: provides weather information updates
-//
-// The function maintains an internal state that is updated whenever new data arrives from any
-// of the input channels. The display is refreshed at a rate defined by screenRefreshRate (24Hz).
-// If a display update fails, it logs the error and attempts to reset the display device.
+// runDisplay manages the display updates for system metrics until ctx is done.
+// It receives instrument readings from the scheduler and dispatches them by value type:
+//   - instruments.SystemTemperature: CPU and GPU temperature readings
+//   - instruments.NetworkStats: network statistics
+//   - *instruments.WeatherInfo: weather information updates
+//   - instruments.VolumeState: audio volume and mute state
+//   - instruments.KeyboardState: lock keys and keyboard layout
+//   - instruments.WeatherAlerts: active severe weather alerts
+//   - *instruments.RadarMap: the precipitation radar around the location
+//   - instruments.NewsHeadlines: top news headlines for the ticker
+//   - instruments.FeedHeadlines: RSS/Atom feed headlines for the ticker
+//   - instruments.StockQuotes: stock quotes for the ticker
+//   - *instruments.UpcomingEvent: the next calendar event for the ticker
+//   - *instruments.NowPlaying: the track of the active media player
+//   - instruments.MQTTMessages: rendered MQTT messages for the ticker
+//   - instruments.PrometheusResults: PromQL query results for the ticker
+//   - *instruments.PrintJob: the running OctoPrint print job
+//   - instruments.DiskHealth: the SMART health of the configured drives
+//   - *instruments.VPNStatus: the state of the configured VPN interface
+//   - *instruments.PluginOutput: the values, text and tile of a plugin
 //
-// This function is non-blocking as it launches the update loop in a separate goroutine.
-func StartDisplayUpdate(
-	tempChan <-chan instruments.SystemTemperature,
-	networkChan <-chan instruments.NetworkStats,
-	weatherChan <-chan *instruments.WeatherInfo,
-	configUpdate <-chan struct{},
-	weatherUpdate chan<- struct{}, // Add weather update trigger
+// The function maintains an internal state that is updated whenever a new reading arrives.
+// The display is refreshed at a rate defined by screenRefreshRate (24Hz). Frames are
+// written to the device by sendFrames, which resets the device if writing fails.
+func (n *Nexus) runDisplay(
+	ctx context.Context,
+	readings <-chan instruments.Reading,
 ) {
-	go func() {
-		state := struct {
-			cpu               float64
-			gpu               float64
-			network           instruments.NetworkStats
-			weather           *instruments.WeatherInfo
-			lastWeatherUpdate time.Time
-		}{}
-
-		refreshRate := time.NewTicker(time.Second / screenRefreshRate) // 24 Hz (~0.042s)
-
-		defer refreshRate.Stop()
-
-		for {
-			select {
-			case temps := <-tempChan:
-				state.cpu, state.gpu = temps.CPU, temps.GPU // Fix: Change GPU to temps.GPU
-			case network := <-networkChan:
-				state.network = network
-			case weather := <-weatherChan:
-				if weather != nil {
-					state.weather = weather
-					state.lastWeatherUpdate = time.Now()
-					if err := updateDisplay(&state); err != nil {
-						log.Printf("Weather update display failed: %v", err)
-					}
+	state := displayState{}
+
+	configUpdate, unsubscribe := n.events.Config.SubscribeLatest()
+	defer unsubscribe()
+
+	refreshRate := newFramePacer(time.Second/screenRefreshRate, n.stats) // 24 Hz (~0.042s)
+
+	defer refreshRate.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reading := <-readings:
+			n.history.Record(reading)
+			n.alerts.Evaluate(reading)
+			n.events.Readings.Publish(reading)
+
+			switch value := reading.Value.(type) {
+			case instruments.SystemTemperature:
+				state.cpu, state.gpu = value.CPU, value.GPU
+			case instruments.NetworkStats:
+				state.network = value
+			case instruments.VolumeState:
+				state.volume = &value
+			case instruments.KeyboardState:
+				state.keyboard = &value
+			case instruments.WeatherAlerts:
+				state.weatherAlerts = value
+			case *instruments.RadarMap:
+				state.radar = value
+			case instruments.NewsHeadlines:
+				state.news = value
+			case instruments.FeedHeadlines:
+				state.feeds = value
+			case instruments.StockQuotes:
+				state.stocks = value
+			case *instruments.UpcomingEvent:
+				state.nextEvent = value
+			case *instruments.NowPlaying:
+				state.nowPlaying = value
+			case instruments.MQTTMessages:
+				state.mqtt = value
+			case instruments.PrometheusResults:
+				state.prometheus = value
+			case *instruments.PrintJob:
+				state.printJob = value
+			case instruments.DiskHealth:
+				state.disks = value
+			case *instruments.VPNStatus:
+				state.vpn = value
+			case *instruments.PluginOutput:
+				if state.plugins == nil {
+					state.plugins = make(map[string]*instruments.PluginOutput)
 				}
-			case <-configUpdate:
-				// Update display settings immediately without blocking
-				if cfg := GetConfig(); cfg != nil {
-					SetTimeFormat(cfg.TimeFormat)
-					SetTextColor(cfg.TextColor)
-					// Trigger weather update
-					select {
-					case weatherUpdate <- struct{}{}:
-					default:
-					}
-					// Force weather update if it's been more than 30 seconds
-					if time.Since(state.lastWeatherUpdate) > 30*time.Second {
-						if weather := instruments.GetWeatherData(cfg.Location, &cfg.Unit); weather != nil {
-							state.weather = weather
-							state.lastWeatherUpdate = time.Now()
-						}
-					}
-					// Immediate display update
-					if err := updateDisplay(&state); err != nil {
-						log.Printf("Config update display failed: %v", err)
+				state.plugins[reading.Name] = value
+			case *instruments.WeatherInfo:
+				if value != nil {
+					state.weather = value
+					if err := n.updateDisplay(ctx, &state); err != nil {
+						renderLog.Warn("Weather update display failed", "error", err)
 					}
 				}
-			case <-refreshRate.C:
-				if err := updateDisplay(&state); err != nil {
-					log.Printf("Screen update failed: %v", err)
-					resetDevice()
+			}
+		case <-configUpdate:
+			// Update display settings immediately without blocking
+			if cfg := n.configs.Get(); cfg != nil {
+				n.renderer.SetTimeFormat(cfg.TimeFormat)
+				n.renderer.SetTimezone(cfg.Timezone, cfg.WorldClocks)
+				n.renderer.SetLocale(cfg.Locale, cfg.Language)
+				n.renderer.SetTextColor(cfg.TextColor)
+				// Trigger weather update; the result arrives as a reading
+				n.triggerWeatherUpdate()
+				// Immediate display update
+				if err := n.updateDisplay(ctx, &state); err != nil {
+					renderLog.Warn("Config update display failed", "error", err)
 				}
 			}
+		case request := <-n.configPreviews:
+			request.frame <- n.renderPreview(&state, request.config, request.page)
+		case <-n.redraw:
+			if err := n.updateDisplay(ctx, &state); err != nil {
+				renderLog.Warn("Redraw failed", "error", err)
+			}
+		case <-refreshRate.C():
+			n.displayTick.Store(time.Now().UnixNano())
+			if err := n.updateDisplay(ctx, &state); err != nil {
+				renderLog.Error("Screen update failed", "error", err)
+				n.resetDevice()
+			}
+			refreshRate.advance()
 		}
-	}()
+	}
 }
 
 // updateDisplay updates the device's screen with system and weather information.
-// It takes a pointer to a struct containing CPU temperature, GPU temperature,
-// network statistics and weather information.
+// It takes a pointer to the display state containing CPU temperature, GPU temperature,
+// network statistics, weather and volume information.
 //
-// The function ensures thread-safety by using deviceMutex when checking device connectivity.
-// If the device is not connected or nil, the function returns early without error.
+// If no device is attached, the function returns early without error.
 //
 // The function creates a screen configuration with the provided state data and
 // calls DrawScreen to update the physical display.
 //
 // Returns an error if the screen drawing operation fails, nil otherwise.
-func updateDisplay(state *struct {
-	cpu               float64
-	gpu               float64
-	network           instruments.NetworkStats
-	weather           *instruments.WeatherInfo
-	lastWeatherUpdate time.Time
-}) error {
-	deviceMutex.Lock()
-
-	if !connected || device == nil {
-		deviceMutex.Unlock()
+func (n *Nexus) updateDisplay(ctx context.Context, state *displayState) error {
+	if !n.transport.Attached() {
 		return nil
 	}
 
-	deviceMutex.Unlock()
-
-	cfg := GetConfig()
+	cfg := n.configs.Get()
 	if cfg == nil {
 		return nil
 	}
 
-	config := CreateScreenConfig{
+	return n.drawDisplay(ctx, n.screenConfig(state, cfg))
+}
+
+// screenConfig returns the values of state to draw with the settings of cfg.
+func (n *Nexus) screenConfig(state *displayState, cfg *configuration.NexusConfig) CreateScreenConfig {
+	return CreateScreenConfig{
 		cputemp:         state.cpu,
 		gputemp:         state.gpu,
 		network:         state.network,
 		weather:         state.weather,
+		volume:          state.volume,
+		keyboard:        state.keyboard,
+		weatherAlerts:   state.weatherAlerts,
+		radar:           state.radar,
+		news:            state.news,
+		feeds:           state.feeds,
+		stocks:          state.stocks,
+		nextEvent:       state.nextEvent,
+		nowPlaying:      state.nowPlaying,
+		mqtt:            state.mqtt,
+		prometheus:      state.prometheus,
+		printJob:        state.printJob,
+		disks:           state.disks,
+		vpn:             state.vpn,
+		plugins:         state.plugins,
+		pomodoro:        n.pomodoro.state(cfg.Pomodoro, time.Now()),
+		countdown:       n.countdown.state(time.Now()),
+		stopwatch:       n.stopwatch.state(time.Now()),
+		graphSpan:       time.Duration(cfg.GraphMinutes) * time.Minute,
+		unit:            cfg.Unit,
+		font:            cfg.Font,
 		backgroundColor: cfg.BackgroundColor,
 	}
-
-	return drawDisplay(config)
 }
 
-// resetDevice safely closes and resets the current device connection.
-// It acquires a device mutex lock to ensure thread-safe access,
-// closes any existing device connection, and resets device state
-// variables to their zero values. The mutex is automatically unlocked
-// when the function returns.
-func resetDevice() {
-	deviceMutex.Lock()
-	defer deviceMutex.Unlock()
-
-	if device != nil {
-		device.Close()
-	}
+// resetDevice closes the current device connection and marks the device as
+// disconnected.
+func (n *Nexus) resetDevice() {
+	n.transport.Close()
+	n.setConnected(false)
+}
 
-	device = nil
-	connected = false
+// invalidateRenderers makes the live and the preview renderer load the background
+// image and the font again, see Renderer.Invalidate.
+func (n *Nexus) invalidateRenderers() {
+	n.renderer.Invalidate()
+	n.previewRenderer.Invalidate()
 }
 
 // DrawScreen updates the display with various system information and weather data.
@@ -198,117 +296,229 @@ func resetDevice() {
 //   - error: nil if successful, error if display update fails
 //
 // If the display device is not initialized (nil), the function returns without error.
-// On failed display updates, it marks the connection as disconnected and returns an error.
-func drawDisplay(config CreateScreenConfig) error {
-	if device == nil {
+// The frame is submitted to sendFrames, or skipped while the device is still busy
+// with the previous frames.
+func (n *Nexus) drawDisplay(ctx context.Context, config CreateScreenConfig) error {
+	if !n.transport.Attached() {
 		return nil
 	}
 
-	// Get current config
-	cfg := GetConfig()
+	// A ringing alarm takes over the display, even while it is switched off
+	alarm, ringing := n.alarms.current(time.Now())
 
-	if cfg == nil {
-		return fmt.Errorf("no configuration available")
+	// Keep the display black while it is switched off
+	if !n.power() && !ringing {
+		n.stats.setPage(pageOff)
+		n.preview.publish(blackFrame)
+		n.frames.submit(outgoingFrame{pix: blackFrame, start: time.Now()})
+		return nil
 	}
 
-	// Create image with current background
-	imageBuffer := InitImageBuffer(width, height)
-
-	img := CreateImageContext(ImageConfig{
-		BackgroundImg: "background.gif",
-		BgColor:       cfg.BackgroundColor,
-	})
-
-	// Always update text settings before drawing
-	SetTextColor(cfg.TextColor)
-	SetTimeFormat(cfg.TimeFormat)
+	// Frames pushed by an external renderer bypass the internal renderer
+	if frame, ok := n.externalFrame.current(); ok && !ringing {
+		n.stats.setPage(pageExternal)
+		n.preview.publish(frame)
+		n.frames.submit(outgoingFrame{pix: frame, start: time.Now()})
+		return nil
+	}
 
-	// Draw all elements
-	DrawSystemTemperatures(config.cputemp, config.gputemp)
-	DrawNetworkStats(config.network)
-	DrawTime()
-	DrawWeather(config.weather)
+	// Keep the last frame on the device while updates are paused
+	if paused, _ := n.pause.active(); paused && !ringing {
+		n.stats.setPage(pagePaused)
+		return nil
+	}
 
-	copy(imageBuffer, img.Pix)
+	// Get current config
+	cfg := n.configs.Get()
 
-	// Send to device
-	if err := sendImageDataInChunks(imageBuffer); err != nil {
-		connected = false
-		return fmt.Errorf("failed to update display: %v", err)
+	if cfg == nil {
+		return fmt.Errorf("no configuration available")
 	}
 
-	return nil
-}
+	// Blank the display, or redraw it once a second, while the user is away
+	if n.idle.away.Load() && !ringing {
+		if cfg.Idle.Action != configuration.IdleActionSlow {
+			n.stats.setPage(pageIdle)
+			n.preview.publish(blackFrame)
+			n.frames.submit(outgoingFrame{pix: blackFrame, start: time.Now()})
+			return nil
+		}
+		if !n.idle.frameDue() {
+			return nil
+		}
+	}
 
-func sendImageDataInChunks(imageData []byte) error {
-	if !connected {
-		fmt.Println("iCUE Nexus: not connected.")
+	// Skip the frame while the device is still busy with the previous ones
+	img := n.frames.acquire()
+	if img == nil {
 		return nil
 	}
+	start := time.Now()
 
-	if len(imageData) != width*height*4 {
-		return fmt.Errorf("incoming image data length mismatch")
+	// The active schedule and page may override the configured colors
+	page := n.pages.current()
+	textColor, backgroundColor := n.schedules.colors(cfg.TextColor, cfg.BackgroundColor)
+	if page.BackgroundColor != "" {
+		backgroundColor = page.BackgroundColor
+	}
+	if page.TextColor != "" {
+		textColor = page.TextColor
 	}
 
-	// Get output endpoint from USB interface
-	// libusb: endpoint 2 is not an OUT endpoint
-	ep, err := usbintf.OutEndpoint(2)
+	// Always update text settings before drawing
+	n.renderer.SetTextColor(textColor)
+	n.renderer.SetTimeFormat(cfg.TimeFormat)
+
+	// Draw into the framebuffer, starting with the current background
+	r := n.renderer
+	r.BeginFrame(img, ImageConfig{
+		BackgroundImg: r.backgroundImage(cfg),
+		BgColor:       backgroundColor,
+		Font:          cfg.Font,
+		FontSize:      cfg.FontSize,
+	})
 
-	if err != nil {
-		return fmt.Errorf("OutEndpoint(2): %v", err)
+	// Draw all elements, or the ringing alarm, a notification or the alert page if
+	// one is active
+	if ringing {
+		n.stats.setPage(pageAlarm)
+		r.DrawAlarm(alarm, parseColor(backgroundColor, color.RGBA{A: 255}))
+	} else if notification, ok := n.notifications.current(); ok {
+		n.stats.setPage(pageNotification)
+		r.DrawNotification(notification, parseColor(backgroundColor, color.RGBA{A: 255}))
+	} else if alert, ok := n.alerts.PageAlert(); ok {
+		n.stats.setPage(pageAlert)
+		r.DrawAlertPage(alert)
+	} else {
+		n.stats.setPage(page.Name)
+		r.drawPage(page, config, cfg)
 	}
 
-	data := make([]byte, 1024*4) // 1024*4 byte buffer size
-	data[0] = 2
-	data[1] = 5
-	data[2] = 31
-	data[3] = 0
-	data[4] = 0
-	data[5] = 0
-	data[6] = 248
-	data[7] = 3
-
-	writer := bufio.NewWriterSize(ep, 1024*4)
-
-	// Split the image data into 120 chunks and send them sequentially
-	for i := 0; i <= 120; i++ {
-		data[4] = byte(i)
-		if i != 120 {
-			data[3] = 0
-			data[6] = 248
-		} else {
-			data[3] = 1
-			data[6] = 192
+	n.preview.publish(img.Pix)
+
+	// Sent by sendFrames while the next frame is drawn
+	n.frames.submit(outgoingFrame{pix: img.Pix, buffer: img, start: start})
+	return nil
+}
+
+// drawPage draws the widgets of page that are not hidden by cfg, moved by their
+// configured offsets.
+func (r *Renderer) drawPage(page Page, config CreateScreenConfig, cfg *configuration.NexusConfig) {
+	for _, widget := range page.Widgets {
+		if !cfg.ShowsWidget(widget) {
+			continue
 		}
 
-		num2 := i * 254
+		offset, ok := cfg.WidgetOffsets[widget]
+		if !ok || offset == (configuration.WidgetOffset{}) {
+			r.drawWidget(widget, config)
+			continue
+		}
+
+		// Draw the widget on the transparent layer and blend it in at the offset
+		dst := r.d.Dst.(draw.Image)
+		if r.layer == nil || r.layer.Bounds() != dst.Bounds() {
+			r.layer = image.NewRGBA(dst.Bounds())
+		} else {
+			clear(r.layer.Pix)
+		}
+		r.d.Dst = r.layer
+		r.drawWidget(widget, config)
+		r.d.Dst = dst
+		draw.Draw(dst, r.layer.Bounds().Add(image.Pt(offset.X, offset.Y)), r.layer, r.layer.Bounds().Min, draw.Over)
+	}
+}
 
-		// Iterate through the image data and set the pixel values
-		for num := 0; num < 255 && num2 < 30720; num++ {
-			data[8+num*4] = imageData[num2*4+2]   // B
-			data[8+num*4+1] = imageData[num2*4+1] // G
-			data[8+num*4+2] = imageData[num2*4]   // R
-			data[8+num*4+3] = 255                 // A
-			num2++
+// drawWidget draws widget at its built-in position. Plugin widgets are drawn at
+// the top left.
+func (r *Renderer) drawWidget(widget string, config CreateScreenConfig) {
+	switch widget {
+	case configuration.WidgetTemperatures:
+		r.DrawSystemTemperatures(config.cputemp, config.gputemp)
+	case configuration.WidgetNetwork:
+		r.DrawNetworkStats(config.network)
+	case configuration.WidgetClock:
+		r.DrawTime()
+	case configuration.WidgetWeather:
+		if !r.DrawWeatherAlerts(config.weatherAlerts) {
+			r.DrawWeather(config.weather, config.unit)
 		}
+	case configuration.WidgetVolume:
+		r.DrawVolume(config.volume)
+	case configuration.WidgetKeyboard:
+		r.DrawKeyboard(config.keyboard)
+	case configuration.WidgetMedia:
+		if !r.DrawPrintJob(config.printJob) {
+			r.DrawNowPlaying(config.nowPlaying)
+		}
+	case configuration.WidgetTicker:
+		r.DrawTicker(r.tickerItems(config))
+	case configuration.WidgetRadar:
+		r.DrawRadar(config.radar)
+	case configuration.WidgetTempGraph:
+		r.DrawTemperatureGraph(r.history.Since(instruments.TemperatureInstrumentName, config.graphSpan), config.graphSpan)
+	case configuration.WidgetDisks:
+		r.DrawDiskHealth(config.disks)
+	case configuration.WidgetVPN:
+		r.DrawVPN(config.vpn)
+	case configuration.WidgetNetGraph:
+		r.DrawNetworkGraph(r.history.Since(instruments.NetworkInstrumentName, config.graphSpan), config.graphSpan)
+	case configuration.WidgetPomodoro:
+		r.DrawPomodoro(config.pomodoro)
+	case configuration.WidgetTimer:
+		r.DrawCountdown(config.countdown, config.font)
+	case configuration.WidgetStopwatch:
+		r.DrawStopwatch(config.stopwatch, config.font)
+	default:
+		r.DrawPluginTile(config.plugins[widget])
+	}
+}
 
-		// Write the data to the USB device using buffered writer
-		_, err = writer.Write(data)
+// backgroundImage returns the background image of cfg in the images directory: the
+// configured background_image if it exists, otherwise the first of image_paths, the
+// latest upload. It returns "" for the embedded background if there is neither.
+func backgroundImage(cfg *configuration.NexusConfig) string {
+	imagesDir, err := configuration.GetImagesDir()
+	if err != nil {
+		return ""
+	}
 
-		// Check for errors during data transfer
-		if err != nil {
-			connected = false
-			if err.Error() == "libusb: device was disconnected" {
-				return nil // Device disconnection is expected, don't report as error
-			}
-			return fmt.Errorf("failed to write data: %v", err)
+	if cfg.BackgroundImage != "" {
+		if _, err := os.Stat(filepath.Join(imagesDir, filepath.Base(cfg.BackgroundImage))); err == nil {
+			return filepath.Base(cfg.BackgroundImage)
 		}
 	}
+	if len(cfg.ImagePaths) > 0 {
+		return filepath.Base(cfg.ImagePaths[0])
+	}
+	return ""
+}
+
+// backgroundImage returns backgroundImage(cfg), which checks the images directory.
+// It is only resolved again when the configuration is replaced or the renderer is
+// invalidated, e.g. after an upload, rather than for every frame.
+func (r *Renderer) backgroundImage(cfg *configuration.NexusConfig) string {
+	generation := r.generation.Load()
+	if cfg != r.bgConfig || generation != r.bgGeneration {
+		r.bgConfig, r.bgGeneration, r.bgName = cfg, generation, backgroundImage(cfg)
+	}
+	return r.bgName
+}
 
-	// Flush the buffered writer to ensure all data is sent
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("failed to flush data: %v", err)
+// sendFrame sends a complete RGBA frame to the device, marking the device as
+// disconnected if the transfer fails. Sent and dropped frames are counted for the
+// status endpoint.
+func (n *Nexus) sendFrame(ctx context.Context, frame []byte) error {
+	err := n.transport.Send(ctx, frame)
+	if errors.Is(err, errDeviceDisconnected) || ctx.Err() != nil {
+		err = nil // Device disconnection and shutdown are expected, don't report as error
+	}
+	if err != nil {
+		n.stats.frameDropped(err)
+		n.setConnected(false)
+		return fmt.Errorf("failed to update display: %v", err)
 	}
 
+	n.stats.frameSent()
 	return nil
 }
@@ -10,90 +10,175 @@ Key features:
   - Animated background support with GIF processing
   - Time display with configurable 12/24-hour format and blinking colon
   - System temperature display for CPU and GPU
+  - Graph of the CPU and GPU temperatures over the last minutes
   - Network statistics visualization with automatic unit conversion
+  - Graph of the network throughput over the last minutes
   - Weather information display with configurable units (metric/imperial)
+  - Multi-day weather forecast alternating with current conditions
+  - Precipitation radar map around the location
+  - Flashing severe weather alert banner
+  - Drive temperatures with flashing SMART failures
+  - VPN tunnel state with the WireGuard endpoint and handshake age
+  - Audio volume and mute state display
+  - Caps Lock, Num Lock and keyboard layout indicator
   - Custom font support with fallback to basic system font
-  - Thread-safe color and time format management using atomic values
+  - Thread-safe color, time format and locale settings of each Renderer
 
 The package uses a combination of standard Go image packages and custom drawing routines
 to create a flexible display system. It maintains thread safety through sync.Once and
 atomic operations for shared resources.
 
-Global variables:
-  - d: Text drawing context
-  - face: Current font face
-  - background: Slice of background image frames for animation
-  - getBackgroundOnce: Ensures single background loading
-  - speedSymbol: Unit for wind speed display
-  - degreeSymbol: Unit for temperature display
-  - currentTextColor: Thread-safe storage for text color
-  - currentTimeFormat: Thread-safe storage for time format
-
-The package automatically initializes with white text color and 24-hour time format
-by default. Background images should be properly sized GIF files that match the
-display dimensions.
+Frames are drawn by a Renderer, which holds the text drawing context, the drawing
+settings and the RenderContext: the background image frames and font face, loaded
+once and reused until the configuration changes or Invalidate is called.
+
+Drawing settings of a Renderer, stored in atomic values:
+  - text color, see SetTextColor
+  - time format, see SetTimeFormat
+  - time zones of the clocks, see SetTimezone
+  - locale and language, see SetLocale
+
+A Renderer starts with white text, the 12-hour time format, local time and the
+"en-US" locale. Background images are read from the images directory and should match the
+display dimensions; without one the embedded animated GIF is shown.
 */
 package nexus
 
 import (
 	"bytes"
+	"cmp"
 	"embed"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/gif"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 
+	"nexus-open/nexus/configuration"
 	"nexus-open/nexus/instruments"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/basicfont"
 	"golang.org/x/image/math/fixed"
 )
 
+// forecastRotation is how long the weather row shows current conditions before
+// switching to the forecast, and vice versa.
+const forecastRotation = 10 * time.Second
+
 type ImageConfig struct {
-	BackgroundImg string
+	BackgroundImg string // File in the images directory, "" for the embedded background
 	BgColor       string
+	Font          string  // Name or path of the font, see LoadSystemFont
+	FontSize      float64 // Font size in pixels
 }
 
+// defaultBackground is the embedded background shown when no image is configured
+const defaultBackground = "background.gif"
+
 //go:embed images/*
 var images embed.FS
 
-var (
-	d                 *font.Drawer  // Text drawing context
-	face              font.Face     // Font face
-	background        []*image.RGBA // Background image frames
-	getBackgroundOnce sync.Once     // Ensures background is loaded only once
-	speedSymbol       string        // Unit for wind speed
-	degreeSymbol      string        // Unit for temperature
-	currentTextColor  atomic.Value  // stores color.RGBA
-	currentTimeFormat atomic.Value  // stores string
-)
+// Renderer draws the widgets of a frame onto the image of CreateImageContext. It
+// is owned by the display loop and is not safe for concurrent use, except for
+// Invalidate and the drawing settings.
+type Renderer struct {
+	d    *font.Drawer // Text drawing context, reused across frames
+	face font.Face    // Font face
 
-// init initializes the default text color as white (RGBA: 255,255,255,255)
-// and sets the default time format to "24h". This function is automatically
-// called when the package is imported.
-func init() {
-	currentTextColor.Store(color.RGBA{R: 255, G: 255, B: 255, A: 255}) // Default text color: white
-	currentTimeFormat.Store("12h")                                     // Default time format: 12-hour
+	history *instruments.History     // Readings drawn by the graph widgets
+	alerts  *instruments.AlertEngine // Active alerts drawn by drawMetric
+
+	rc         *RenderContext // Reused until the ImageConfig changes or it is invalidated
+	generation atomic.Uint64  // Incremented by Invalidate
+
+	bgConfig     *configuration.NexusConfig // Configuration bgName was resolved for
+	bgGeneration uint64                     // Generation bgName was resolved in
+	bgName       string                     // Cached result of backgroundImage
+
+	layer *image.RGBA // Widgets with an offset are drawn here, cleared for each one
+
+	textColor  atomic.Value                  // stores color.RGBA, see SetTextColor
+	timeFormat atomic.Value                  // stores string, see SetTimeFormat
+	clocks     atomic.Value                  // stores clockSettings, see SetTimezone
+	locale     atomic.Pointer[displayLocale] // see SetLocale
 }
 
-// InitImageBuffer creates and returns a new byte slice to be used as an RGBA image buffer.
-// The buffer size is calculated as width * height * 4, where 4 represents the RGBA channels
-// (Red, Green, Blue, Alpha) per pixel. Each channel uses 1 byte.
-//
-// Parameters:
-//   - width: The width of the image in pixels
-//   - height: The height of the image in pixels
-//
-// Returns:
-//   - []byte: A zeroed byte slice with size width * height * 4
-func InitImageBuffer(width, height int) []byte {
-	return make([]byte, width*height*4)
+// NewRenderer returns a Renderer that draws the readings of history and the active
+// alerts of alerts, ready once CreateImageContext was called.
+func NewRenderer(history *instruments.History, alerts *instruments.AlertEngine) *Renderer {
+	r := &Renderer{d: &font.Drawer{}, history: history, alerts: alerts}
+	r.textColor.Store(color.RGBA{R: 255, G: 255, B: 255, A: 255}) // Default text color: white
+	r.timeFormat.Store("12h")                                     // Default time format: 12-hour
+	r.clocks.Store(clockSettings{location: time.Local})           // Default time zone: local
+	r.SetLocale("", "")
+	return r
+}
+
+// RenderContext holds what frames are drawn with that only changes with the
+// configuration: the frames of the background image, the fallback background color
+// and the font face. The Renderer builds it once for an ImageConfig and reuses it
+// for every frame.
+type RenderContext struct {
+	config     ImageConfig
+	generation uint64        // Generation of the Renderer the context was built in
+	frames     []*image.RGBA // Background image frames, nil to fill with bgColor
+	bgColor    color.RGBA
+	face       font.Face
+}
+
+// newRenderContext loads the background image and the font of config. The
+// background frames of prev, which may be nil, are reused if it was built in the
+// same generation with the same background image.
+func newRenderContext(config ImageConfig, generation uint64, prev *RenderContext) *RenderContext {
+	rc := &RenderContext{
+		config:     config,
+		generation: generation,
+		bgColor:    parseColor(config.BgColor, color.RGBA{R: 0, G: 0, B: 0, A: 255}),
+		face:       LoadSystemFont(config.Font, config.FontSize),
+	}
+
+	if prev != nil && prev.generation == generation && prev.config.BackgroundImg == config.BackgroundImg {
+		rc.frames = prev.frames
+	} else {
+		rc.frames = loadBackground(config.BackgroundImg)
+	}
+	return rc
+}
+
+// Invalidate makes the next frame load the background image and the font again,
+// e.g. after the configuration or an image file changed. It is safe for concurrent
+// use.
+func (r *Renderer) Invalidate() {
+	r.generation.Add(1)
+}
+
+// renderContext returns the render context of config, building it if config
+// differs from the one of the current context or the context was invalidated.
+func (r *Renderer) renderContext(config ImageConfig) *RenderContext {
+	generation := r.generation.Load()
+	if r.rc == nil || r.rc.config != config || r.rc.generation != generation {
+		r.rc = newRenderContext(config, generation, r.rc)
+	}
+	return r.rc
+}
+
+// clockSettings holds the time zone of the main clock and the world clocks.
+type clockSettings struct {
+	location *time.Location
+	world    []worldClock
+}
+
+// worldClock is a labelled clock of another time zone.
+type worldClock struct {
+	label    string
+	location *time.Location
 }
 
 // CreateImageContext creates and returns a new RGBA image context with the specified configuration.
@@ -103,98 +188,135 @@ func InitImageBuffer(width, height int) []byte {
 // Parameters:
 //   - config: ImageConfig containing background image and color settings
 //   - customFace: Optional variadic parameter for custom font face. If not provided or nil,
-//     defaults to basicfont.Face7x13
+//     defaults to the configured font of config, see LoadSystemFont
 //
 // The function performs the following operations:
-//  1. Loads background image (if specified) using a singleton pattern
+//  1. Loads the background image and font of config into the RenderContext, only when
+//     config changes or the context was invalidated
 //  2. Creates fallback solid color background if image loading fails
 //  3. Handles animated backgrounds by selecting appropriate frame based on current time
-//  4. Sets up font face and text drawing context
-//  5. Configures text color from atomic storage
+//  4. Resets the text drawing context to the new image
+//  5. Configures the text color set with SetTextColor
 //
 // Returns:
 //
 //	*image.RGBA: New image context ready for drawing operations
-func CreateImageContext(config ImageConfig, customFace ...font.Face) *image.RGBA {
-	var err error
-
-	getBackgroundOnce.Do(func() {
-		background, err = convertBackgroundImage(config.BackgroundImg)
-	})
+func (r *Renderer) CreateImageContext(config ImageConfig, customFace ...font.Face) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	r.beginFrame(img, config, customFace...)
+	return img
+}
 
-	if err != nil {
-		// Fallback to solid color if background image fails to load
-		img := image.NewRGBA(image.Rect(0, 0, width, height))
-		bgColor := parseColor(config.BgColor, color.RGBA{R: 0, G: 0, B: 0, A: 255})
-		draw.Draw(img, img.Bounds(), &image.Uniform{bgColor}, image.Point{}, draw.Src)
-	}
+// BeginFrame is like CreateImageContext, but draws into img, a framebuffer reused
+// across frames, instead of a new image.
+func (r *Renderer) BeginFrame(img *image.RGBA, config ImageConfig) {
+	r.beginFrame(img, config)
+}
 
-	// Use the first frame of the animated background
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
+// beginFrame draws the background of config onto img and points the text drawing
+// context at it.
+func (r *Renderer) beginFrame(img *image.RGBA, config ImageConfig, customFace ...font.Face) {
+	rc := r.renderContext(config)
 
-	if len(background) > 0 {
+	if len(rc.frames) > 0 {
 		// Convert to 24 Hz by dividing by 41.666667ms (1000/24)
-		frameIndex := (time.Now().UnixNano() / 41666667) % int64(len(background))
-		draw.Draw(img, img.Bounds(), background[int(frameIndex)], image.Point{}, draw.Src)
+		frameIndex := (time.Now().UnixNano() / 41666667) % int64(len(rc.frames))
+		draw.Draw(img, img.Bounds(), rc.frames[int(frameIndex)], image.Point{}, draw.Src)
+	} else {
+		// Fallback to solid color if background image fails to load
+		draw.Draw(img, img.Bounds(), &image.Uniform{rc.bgColor}, image.Point{}, draw.Src)
 	}
 
 	// Set up font and text drawing context
 	if len(customFace) > 0 && customFace[0] != nil {
-		face = customFace[0]
+		r.face = customFace[0]
 	} else {
-		face = basicfont.Face7x13 // default font
+		r.face = rc.face
 	}
 
-	face = LoadSystemFont("HackNerdFont-Regular.ttf")
-
-	// Always use current text color from atomic storage
-	textColor := currentTextColor.Load().(color.RGBA)
-
-	d = &font.Drawer{
-		Dst:  img,
-		Src:  image.NewUniform(textColor),
-		Face: face,
-		Dot: fixed.Point26_6{
-			X: fixed.I(width / 2),
-			Y: fixed.I(height / 2),
-		},
+	// Always use the current text color
+	textColor := r.textColor.Load().(color.RGBA)
+	if src, ok := r.d.Src.(*image.Uniform); !ok || src.C != textColor {
+		r.d.Src = image.NewUniform(textColor)
 	}
 
-	return img
+	r.d.Dst = img
+	r.d.Face = r.face
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(width / 2),
+		Y: fixed.I(height / 2),
+	}
 }
 
 // SetTextColor updates the current text color used for drawing operations.
-// It accepts a color string which can be in hex format (e.g. "#FF0000") or a named color.
+// It accepts a color string which can be in hex format (e.g. "#FF0000"), rgb()/hsl() or a named color.
 // If an empty string is provided, the function returns without changing the current color.
 // The color is parsed and stored in an atomic value for thread-safe access.
-// If a drawer exists, its source color is updated to reflect the new text color.
+// It applies to images created afterwards by CreateImageContext.
 // Default color is white (RGBA{255,255,255,255}) if parsing fails.
 func SetTextColor(colorStr string) {
+	defaultNexus.renderer.SetTextColor(colorStr)
+}
+
+// SetTextColor sets the text color of r, see the package function SetTextColor.
+func (r *Renderer) SetTextColor(colorStr string) {
 	if colorStr == "" {
 		return // Don't change color if empty string
 	}
 
 	textColor := parseColor(colorStr, color.RGBA{R: 255, G: 255, B: 255, A: 255})
-	currentTextColor.Store(textColor)
-
-	// Update drawer if it exists
-	if d != nil {
-		d.Src = image.NewUniform(textColor)
-	}
+	r.textColor.Store(textColor)
 }
 
 // SetTimeFormat sets the time format string used for time-related formatting operations.
 // The format string must follow Go's time formatting conventions.
 // This function is safe for concurrent use.
 func SetTimeFormat(format string) {
-	currentTimeFormat.Store(format)
+	defaultNexus.renderer.SetTimeFormat(format)
+}
+
+// SetTimeFormat sets the time format of r, see the package function SetTimeFormat.
+func (r *Renderer) SetTimeFormat(format string) {
+	r.timeFormat.Store(format)
+}
+
+// SetTimezone sets the time zone of the main clock and the world clocks drawn
+// next to it. Unknown time zones fall back to local time for the main clock and
+// are skipped for world clocks. This function is safe for concurrent use.
+func SetTimezone(timezone string, worldClocks []configuration.WorldClock) {
+	defaultNexus.renderer.SetTimezone(timezone, worldClocks)
+}
+
+// SetTimezone sets the time zones of the clocks of r, see the package function
+// SetTimezone.
+func (r *Renderer) SetTimezone(timezone string, worldClocks []configuration.WorldClock) {
+	settings := clockSettings{location: time.Local}
+	if location, err := configuration.LoadTimezone(timezone); err == nil {
+		settings.location = location
+	}
+
+	for _, clock := range worldClocks {
+		if location, err := configuration.LoadTimezone(clock.Timezone); err == nil {
+			settings.world = append(settings.world, worldClock{label: clock.Name(), location: location})
+		}
+	}
+
+	r.clocks.Store(settings)
+}
+
+// location returns the time zone of the main clock of r.
+func (r *Renderer) location() *time.Location {
+	return r.clocks.Load().(clockSettings).location
 }
 
 // DrawTime draws the current time on the display with a blinking colon
-// The time is right-aligned and positioned at the top of the screen
-func DrawTime() {
-	currentTime := time.Now()
-	timeFormat := currentTimeFormat.Load().(string)
+// The time is right-aligned and positioned at the top of the screen, with the
+// world clocks to its left
+func (r *Renderer) DrawTime() {
+	settings := r.clocks.Load().(clockSettings)
+	now := time.Now()
+	currentTime := now.In(settings.location)
+	timeFormat := r.timeFormat.Load().(string)
 	var timeStr string
 
 	if timeFormat == "12h" {
@@ -208,33 +330,55 @@ func DrawTime() {
 		timeStr = strings.Replace(timeStr, ":", " ", 1)
 	}
 
-	timeTextWidth := (&font.Drawer{Face: face}).MeasureString(timeStr)
+	timeTextWidth := (&font.Drawer{Face: r.face}).MeasureString(timeStr)
 
-	d.Dot = fixed.Point26_6{
-		X: fixed.I(width) - timeTextWidth - fixed.I(10),
+	x := fixed.I(width) - timeTextWidth - fixed.I(10)
+	r.d.Dot = fixed.Point26_6{
+		X: x,
 		Y: fixed.I(15),
 	}
 
-	d.DrawString(timeStr)
+	r.d.DrawString(timeStr)
+
+	// World clocks are drawn right to left in their configured order
+	for i := len(settings.world) - 1; i >= 0; i-- {
+		clock := settings.world[i]
+		clockStr := clock.label + " " + now.In(clock.location).Format(worldClockFormat(timeFormat))
+		x -= (&font.Drawer{Face: r.face}).MeasureString(clockStr) + fixed.I(12)
+
+		r.d.Dot = fixed.Point26_6{
+			X: x,
+			Y: fixed.I(15),
+		}
+		r.d.DrawString(clockStr)
+	}
+}
+
+// worldClockFormat returns the compact layout of world clocks for a time format.
+func worldClockFormat(timeFormat string) string {
+	if timeFormat == "12h" {
+		return "3:04PM"
+	}
+	return "15:04"
 }
 
 // DrawSystemTemperatures renders CPU and GPU temperatures with icons
 // at the left side of the display. Each temperature is shown with a
 // corresponding hardware icon and formatted to one decimal place.
-func DrawSystemTemperatures(cpuTemp, gpuTemp float64) {
+func (r *Renderer) DrawSystemTemperatures(cpuTemp, gpuTemp float64) {
 	// Draw CPU temperature with icon
-	d.Dot = fixed.Point26_6{
+	r.d.Dot = fixed.Point26_6{
 		X: fixed.I(10),
 		Y: fixed.I(15),
 	}
-	d.DrawString(fmt.Sprintf("\uf4bc %.1f °C", cpuTemp))
+	r.drawMetric("temperature.cpu", "\uf4bc "+r.formatDecimal(cpuTemp, 1)+" °C")
 
 	// Draw GPU temperature with icon
-	d.Dot = fixed.Point26_6{
+	r.d.Dot = fixed.Point26_6{
 		X: fixed.I(10),
 		Y: fixed.I(40),
 	}
-	d.DrawString(fmt.Sprintf("\ueabe %.1f °C", gpuTemp))
+	r.drawMetric("temperature.gpu", "\ueabe "+r.formatDecimal(gpuTemp, 1)+" °C")
 }
 
 // DrawNetworkStats renders network statistics on the display.
@@ -245,114 +389,673 @@ func DrawSystemTemperatures(cpuTemp, gpuTemp float64) {
 //
 // Parameters:
 //   - currentNetwork: instruments.NetworkStats containing the current sent/received bytes
-func DrawNetworkStats(currentNetwork instruments.NetworkStats) {
+func (r *Renderer) DrawNetworkStats(currentNetwork instruments.NetworkStats) {
 	// Network sent text (left-aligned)
-	sentText := formatNetworkRate("\uf093", int64(currentNetwork.Sent))
+	sentText := r.formatNetworkRate("\uf093", int64(currentNetwork.Sent))
 
-	d.Dot = fixed.Point26_6{
+	r.d.Dot = fixed.Point26_6{
 		X: fixed.I(width / 4),
 		Y: fixed.I(15),
 	}
 
-	d.DrawString(sentText)
+	r.drawMetric("network.sent", sentText)
 
 	// Network received text (left-aligned)
-	recvText := formatNetworkRate("\uf019", int64(currentNetwork.Received))
+	recvText := r.formatNetworkRate("\uf019", int64(currentNetwork.Received))
 
-	d.Dot = fixed.Point26_6{
+	r.d.Dot = fixed.Point26_6{
 		X: fixed.I(width / 4),
 		Y: fixed.I(40),
 	}
 
-	d.DrawString(recvText)
+	r.drawMetric("network.received", recvText)
+}
+
+// graphRegion is the area in the middle of the display in which the temperature
+// and network graphs are plotted, followed by their annotations.
+var graphRegion = image.Rect(width/2-50, 4, width/2+110, height-4)
+
+// graphX returns the column of graphRegion at which a reading taken at t is
+// plotted in a graph of the last span up to now.
+func graphX(t, now time.Time, span time.Duration) int {
+	age := min(max(now.Sub(t), 0), span)
+	return graphRegion.Max.X - 1 - int(float64(graphRegion.Dx()-1)*age.Seconds()/span.Seconds())
+}
+
+// minGraphRange is the smallest temperature range in °C spanned by the graph, so
+// that small fluctuations stay flat.
+const minGraphRange = 10.0
+
+// DrawTemperatureGraph plots the CPU temperature as a line in the text color and
+// the GPU temperature as a dimmer line over the last span in graphRegion, with
+// the newest reading at the right. The highest and lowest temperature of both are
+// shown right of the graph. If there are no readings, nothing is drawn.
+//
+// Parameters:
+//   - readings: Temperature readings of the history, oldest first
+//   - span: How far back the graph reaches
+func (r *Renderer) DrawTemperatureGraph(readings []instruments.Reading, span time.Duration) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok || span <= 0 {
+		return
+	}
+
+	var times []time.Time
+	var cpu, gpu []float64
+	for _, reading := range readings {
+		if temps, ok := reading.Value.(instruments.SystemTemperature); ok {
+			times = append(times, reading.Time)
+			cpu = append(cpu, temps.CPU)
+			gpu = append(gpu, temps.GPU)
+		}
+	}
+	if len(times) == 0 {
+		return
+	}
+
+	low := min(slices.Min(cpu), slices.Min(gpu))
+	high := max(slices.Max(cpu), slices.Max(gpu))
+
+	// Center small ranges in a range of minGraphRange
+	bottom, top := low, high
+	if top-bottom < minGraphRange {
+		bottom = (low + high - minGraphRange) / 2
+		top = bottom + minGraphRange
+	}
+
+	now := time.Now()
+	plot := func(values []float64) []image.Point {
+		points := make([]image.Point, len(values))
+		for i, value := range values {
+			points[i] = image.Pt(
+				graphX(times[i], now, span),
+				graphRegion.Max.Y-1-int(math.Round(float64(graphRegion.Dy()-1)*(value-bottom)/(top-bottom))),
+			)
+		}
+		return points
+	}
+
+	draw.DrawMask(dst, graphRegion, r.d.Src, image.Point{}, image.NewUniform(color.Alpha{A: 32}), image.Point{}, draw.Over)
+	drawGraphLine(dst, plot(gpu), r.d.Src, image.NewUniform(color.Alpha{A: 112}))
+	drawGraphLine(dst, plot(cpu), r.d.Src, image.NewUniform(color.Alpha{A: 255}))
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(graphRegion.Max.X + 6),
+		Y: fixed.I(15),
+	}
+	r.d.DrawString("\uf062 " + r.formatDecimal(high, 0) + "°")
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(graphRegion.Max.X + 6),
+		Y: fixed.I(40),
+	}
+	r.d.DrawString("\uf063 " + r.formatDecimal(low, 0) + "°")
+}
+
+// drawGraphLine draws a one pixel wide line from src through points, blended with
+// mask. Points are expected from left to right; steps between neighbouring
+// columns are filled so the line has no gaps.
+func drawGraphLine(dst draw.Image, points []image.Point, src, mask image.Image) {
+	for i, point := range points {
+		prev := point
+		if i > 0 {
+			prev = points[i-1]
+		}
+
+		for x := prev.X; x <= point.X; x++ {
+			// Interpolate between the points and join each column to the previous one
+			y, prevY := point.Y, prev.Y
+			if point.X > prev.X {
+				y = prev.Y + (point.Y-prev.Y)*(x-prev.X)/(point.X-prev.X)
+				if x > prev.X {
+					prevY = prev.Y + (point.Y-prev.Y)*(x-1-prev.X)/(point.X-prev.X)
+				}
+			}
+			column := image.Rect(x, min(y, prevY), x+1, max(y, prevY)+1)
+			draw.DrawMask(dst, column, src, image.Point{}, mask, image.Point{}, draw.Over)
+		}
+	}
+}
+
+// DrawNetworkGraph plots the network throughput over the last span in graphRegion
+// as two areas around its middle: the sent rate dimmer above and the received rate
+// below, with the newest reading at the right. Each area is scaled to its own
+// peak, which is shown right of the graph. If there are no readings, nothing is
+// drawn.
+//
+// Parameters:
+//   - readings: Network readings of the history, oldest first
+//   - span: How far back the graph reaches
+func (r *Renderer) DrawNetworkGraph(readings []instruments.Reading, span time.Duration) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok || span <= 0 {
+		return
+	}
+
+	// Highest rates per column. A reading covers the time since the previous one, so
+	// its rate fills the columns back to the previous reading.
+	sent := make([]int, graphRegion.Dx())
+	received := make([]int, graphRegion.Dx())
+	peakSent, peakReceived := 0, 0
+	now := time.Now()
+	prevX := -1
+	for _, reading := range readings {
+		stats, ok := reading.Value.(instruments.NetworkStats)
+		if !ok {
+			continue
+		}
+
+		x := graphX(reading.Time, now, span) - graphRegion.Min.X
+		for column := min(prevX+1, x); column <= x; column++ {
+			sent[column] = max(sent[column], stats.Sent)
+			received[column] = max(received[column], stats.Received)
+		}
+		prevX = x
+		peakSent, peakReceived = max(peakSent, stats.Sent), max(peakReceived, stats.Received)
+	}
+	if prevX < 0 {
+		return
+	}
+
+	middle := graphRegion.Min.Y + graphRegion.Dy()/2
+	draw.DrawMask(dst, graphRegion, r.d.Src, image.Point{}, image.NewUniform(color.Alpha{A: 32}), image.Point{}, draw.Over)
+	for column := range sent {
+		x := graphRegion.Min.X + column
+		if peakSent > 0 {
+			up := image.Rect(x, middle-(middle-graphRegion.Min.Y)*sent[column]/peakSent, x+1, middle)
+			draw.DrawMask(dst, up, r.d.Src, image.Point{}, image.NewUniform(color.Alpha{A: 112}), image.Point{}, draw.Over)
+		}
+		if peakReceived > 0 {
+			down := image.Rect(x, middle, x+1, middle+(graphRegion.Max.Y-middle)*received[column]/peakReceived)
+			draw.Draw(dst, down, r.d.Src, image.Point{}, draw.Over)
+		}
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(graphRegion.Max.X + 6),
+		Y: fixed.I(15),
+	}
+	r.d.DrawString(r.formatNetworkRate("\uf093", int64(peakSent)))
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(graphRegion.Max.X + 6),
+		Y: fixed.I(40),
+	}
+	r.d.DrawString(r.formatNetworkRate("\uf019", int64(peakReceived)))
 }
 
 // DrawWeather renders the current weather information on the screen.
-// It displays temperature, weather condition, and wind speed in the top right corner
-// using the configured measurement units and font settings.
+// It displays temperature, weather condition, and wind speed in the bottom right corner
+// using the configured measurement units and font settings. Stale data is prefixed with a
+// clock icon. When a forecast is available
+// the row alternates between current conditions and the forecast every forecastRotation.
 // If weatherInfo is nil, the function returns without drawing anything.
 //
 // Parameters:
 //   - weatherInfo: Pointer to WeatherInfo struct containing weather data to display
-func DrawWeather(weatherInfo *instruments.WeatherInfo) {
+//   - unit: Unit system of the configuration, "metric" or "imperial"
+func (r *Renderer) DrawWeather(weatherInfo *instruments.WeatherInfo, unit string) {
 	if weatherInfo == nil {
 		return
 	}
 
-	setMeasurementUnits(unit)
+	if len(weatherInfo.Forecast) > 0 && (time.Now().Unix()/int64(forecastRotation.Seconds()))%2 == 1 {
+		r.DrawForecast(weatherInfo.Forecast, unit)
+		return
+	}
+
+	degreeSymbol, speedSymbol := measurementSymbols(unit)
+
+	weatherText := fmt.Sprintf("%s %s %s%s %s %s", weatherInfo.Location, weatherInfo.Condition, r.formatDecimal(weatherInfo.Temperature, 1), degreeSymbol, weatherInfo.WindSpeed, speedSymbol)
 
-	weatherText := fmt.Sprintf("%s %s %.1f%s %s %s", weatherInfo.Location, weatherInfo.Condition, weatherInfo.Temperature, degreeSymbol, weatherInfo.WindSpeed, speedSymbol)
-	weatherTextWidth := (&font.Drawer{Face: face}).MeasureString(weatherText)
+	// Mark last known data shown after failed updates
+	if weatherInfo.Stale {
+		weatherText = "\uf017 " + weatherText
+	}
+	weatherTextWidth := (&font.Drawer{Face: r.face}).MeasureString(weatherText)
 
-	d.Dot = fixed.Point26_6{
+	r.d.Dot = fixed.Point26_6{
 		X: fixed.I(width) - weatherTextWidth - fixed.I(10),
 		Y: fixed.I(40),
 	}
 
-	d.DrawString(weatherText)
+	r.drawMetric("weather.temperature", weatherText)
+}
+
+// radarRegion is the area at the left edge in which the radar map is shown.
+var radarRegion = image.Rect(10, 0, 10+instruments.RadarWidth, instruments.RadarHeight)
+
+// DrawRadar renders the precipitation radar map in radarRegion on a dim backdrop
+// in the text color, with a cross marking the location in the center. If radar
+// is nil, nothing is drawn.
+//
+// Parameters:
+//   - radar: Pointer to RadarMap containing the latest radar image
+func (r *Renderer) DrawRadar(radar *instruments.RadarMap) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok || radar == nil || radar.Image == nil {
+		return
+	}
+
+	draw.DrawMask(dst, radarRegion, r.d.Src, image.Point{}, image.NewUniform(color.Alpha{A: 48}), image.Point{}, draw.Over)
+	draw.Draw(dst, radarRegion, radar.Image, image.Point{}, draw.Over)
+
+	center := image.Pt((radarRegion.Min.X+radarRegion.Max.X)/2, (radarRegion.Min.Y+radarRegion.Max.Y)/2)
+	draw.Draw(dst, image.Rect(center.X-3, center.Y, center.X+4, center.Y+1), r.d.Src, image.Point{}, draw.Over)
+	draw.Draw(dst, image.Rect(center.X, center.Y-3, center.X+1, center.Y+4), r.d.Src, image.Point{}, draw.Over)
+}
+
+// volumeRegion is the area of the top row between the network statistics and the
+// ticker in which the volume is shown. Tapping it toggles mute.
+var volumeRegion = image.Rect(width/2, 0, width/2+60, 22)
+
+// DrawVolume renders the audio output volume in the top center of the display
+// above a bar filled up to the level, or a mute indicator when the output is
+// muted. If volume is nil, nothing is drawn.
+//
+// Parameters:
+//   - volume: Pointer to VolumeState containing the volume level and mute state
+func (r *Renderer) DrawVolume(volume *instruments.VolumeState) {
+	if volume == nil {
+		return
+	}
+
+	volumeText := fmt.Sprintf("\uf028 %d%%", volume.Level)
+	if volume.Muted {
+		volumeText = "\uf026 " + r.translate("Muted")
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(volumeRegion.Min.X),
+		Y: fixed.I(15),
+	}
+
+	r.drawMetric("volume.level", volumeText)
+
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok || volume.Muted {
+		return
+	}
+
+	// Level bar: a dim track in the text color, filled up to the level
+	bar := image.Rect(volumeRegion.Min.X, 18, volumeRegion.Max.X, 20)
+	filled := bar
+	filled.Max.X = bar.Min.X + bar.Dx()*min(max(volume.Level, 0), 100)/100
+
+	draw.DrawMask(dst, bar, r.d.Src, image.Point{}, image.NewUniform(color.Alpha{A: 64}), image.Point{}, draw.Over)
+	draw.Draw(dst, filled, r.d.Src, image.Point{}, draw.Over)
 }
 
-func setMeasurementUnits(unit string) {
+// keyboardX is the left edge of the keyboard widget in the bottom row, between the
+// network statistics and the now playing track.
+const keyboardX = width/2 - 65
+
+// DrawKeyboard renders a keyboard icon with the keyboard layout in the bottom row,
+// followed by a key cap for each lock key that is on: "A" for Caps Lock and "1"
+// for Num Lock. If keyboard is nil, nothing is drawn.
+//
+// Parameters:
+//   - keyboard: Pointer to KeyboardState containing the lock keys and layout
+func (r *Renderer) DrawKeyboard(keyboard *instruments.KeyboardState) {
+	if keyboard == nil {
+		return
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(keyboardX),
+		Y: fixed.I(40),
+	}
+	r.d.DrawString(strings.TrimSpace("\uf11c " + keyboard.Layout))
+
+	if keyboard.CapsLock {
+		r.drawKeyCap("A")
+	}
+	if keyboard.NumLock {
+		r.drawKeyCap("1")
+	}
+}
+
+// drawKeyCap draws label in a box outlined in the text color, after the dot.
+func (r *Renderer) drawKeyCap(label string) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok {
+		return
+	}
+
+	metrics := r.face.Metrics()
+	left := r.d.Dot.X.Round() + 3
+	right := left + (&font.Drawer{Face: r.face}).MeasureString(label).Ceil() + 5
+	top := r.d.Dot.Y.Round() - metrics.Ascent.Ceil() - 1
+	bottom := r.d.Dot.Y.Round() + 3
+
+	for _, edge := range []image.Rectangle{
+		image.Rect(left, top, right, top+1),
+		image.Rect(left, bottom-1, right, bottom),
+		image.Rect(left, top, left+1, bottom),
+		image.Rect(right-1, top, right, bottom),
+	} {
+		draw.Draw(dst, edge, r.d.Src, image.Point{}, draw.Over)
+	}
+
+	r.d.Dot.X = fixed.I(left + 3)
+	r.d.DrawString(label)
+	r.d.Dot.X = fixed.I(right)
+}
+
+// nowPlayingRegion is the area of the bottom row between the network statistics and
+// the weather in which the current track scrolls. Tapping it toggles playback.
+var nowPlayingRegion = image.Rect(width/2, 24, width/2+100, height)
+
+// albumArtRegion is the area left of nowPlayingRegion, across both rows, in which
+// the album art of the current track is shown. Tapping it toggles playback.
+var albumArtRegion = image.Rect(nowPlayingRegion.Min.X-instruments.AlbumArtSize-4, 0, nowPlayingRegion.Min.X-4, instruments.AlbumArtSize)
+
+// DrawNowPlaying renders a play or pause icon followed by the artist and title of the
+// current track, scrolling inside nowPlayingRegion when it does not fit, and the
+// album art in albumArtRegion if there is any. If playing is nil, nothing is drawn.
+//
+// Parameters:
+//   - playing: Pointer to NowPlaying containing the current track and playback state
+func (r *Renderer) DrawNowPlaying(playing *instruments.NowPlaying) {
+	if playing == nil {
+		return
+	}
+
+	icon := "\uf04b"
+	if playing.Status == instruments.PlaybackPaused {
+		icon = "\uf04c"
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(nowPlayingRegion.Min.X),
+		Y: fixed.I(40),
+	}
+	r.d.DrawString(icon + " ")
+
+	if dst, ok := r.d.Dst.(draw.Image); ok && playing.Art != nil {
+		draw.Draw(dst, albumArtRegion, playing.Art, image.Point{}, draw.Over)
+	}
+
+	track := playing.Title
+	if playing.Artist != "" {
+		track = playing.Artist + " - " + playing.Title
+	}
+
+	region := nowPlayingRegion
+	region.Min.X = r.d.Dot.X.Ceil()
+	r.drawMarquee(region, 40, []TickerItem{{Text: track}})
+}
+
+// DrawPrintJob renders the progress of a 3D print in nowPlayingRegion: a progress bar
+// along the bottom edge and a text line alternating every forecastRotation between
+// progress with remaining time and the hotend/bed temperatures.
+// It returns false without drawing anything if job is nil.
+//
+// Parameters:
+//   - job: Pointer to PrintJob containing the job progress and temperatures
+//
+// Returns:
+//   - bool: true if the print job was drawn
+func (r *Renderer) DrawPrintJob(job *instruments.PrintJob) bool {
+	if job == nil {
+		return false
+	}
+
+	text := fmt.Sprintf("\uf02f %s%%", r.formatDecimal(job.Progress, 0))
+	if job.Remaining > 0 {
+		text += fmt.Sprintf(" %d:%02d", int(job.Remaining.Hours()), int(job.Remaining.Minutes())%60)
+	}
+	if (time.Now().Unix()/int64(forecastRotation.Seconds()))%2 == 1 {
+		text = fmt.Sprintf("\uf2c9 %s° %s°", r.formatDecimal(job.Hotend, 0), r.formatDecimal(job.Bed, 0))
+	}
+	if strings.HasPrefix(job.State, "Paus") {
+		text = "\uf04c " + text
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(nowPlayingRegion.Min.X),
+		Y: fixed.I(40),
+	}
+	r.drawMetric("octoprint.progress", text)
+
+	// Progress bar: outline in the text color, filled up to the completion
+	if dst, ok := r.d.Dst.(draw.Image); ok {
+		bar := image.Rect(nowPlayingRegion.Min.X, 43, nowPlayingRegion.Max.X, 47)
+		filled := bar
+		filled.Max.X = bar.Min.X + int(float64(bar.Dx())*min(max(job.Progress, 0), 100)/100)
+
+		draw.Draw(dst, image.Rect(bar.Min.X, bar.Min.Y, bar.Max.X, bar.Min.Y+1), r.d.Src, image.Point{}, draw.Over)
+		draw.Draw(dst, image.Rect(bar.Min.X, bar.Max.Y-1, bar.Max.X, bar.Max.Y), r.d.Src, image.Point{}, draw.Over)
+		draw.Draw(dst, filled, r.d.Src, image.Point{}, draw.Over)
+	}
+
+	return true
+}
+
+// DrawAlertPage replaces the regular layout with a full-screen alert showing the
+// metric, its current value and the threshold it crossed, centered in the alert color.
+func (r *Renderer) DrawAlertPage(alert instruments.Alert) {
+	alertText := fmt.Sprintf("\uf071 %s %s %s %s", alert.Rule.Metric, r.formatDecimal(alert.Value, 1), alert.Rule.Operator, r.formatDecimal(alert.Rule.Threshold, 1))
+	alertTextWidth := (&font.Drawer{Face: r.face}).MeasureString(alertText)
+
+	r.d.Dot = fixed.Point26_6{
+		X: (fixed.I(width) - alertTextWidth) / 2,
+		Y: fixed.I(height/2 + 5),
+	}
+
+	src := r.d.Src
+	r.d.Src = image.NewUniform(alertColor(alert.Rule))
+	r.d.DrawString(alertText)
+	r.d.Src = src
+}
+
+// drawMetric draws text at the current dot position, applying any active alert for
+// the given metric. Alerts draw the text in the alert color; alerts with the "flash"
+// action additionally blink the text at 2 Hz.
+func (r *Renderer) drawMetric(metric, text string) {
+	alert, ok := r.alerts.Active(metric)
+	if !ok {
+		r.d.DrawString(text)
+		return
+	}
+
+	if alert.Rule.Action == configuration.AlertActionFlash && (time.Now().UnixMilli()/250)%2 == 0 {
+		return
+	}
+
+	src := r.d.Src
+	r.d.Src = image.NewUniform(alertColor(alert.Rule))
+	r.d.DrawString(text)
+	r.d.Src = src
+}
+
+// alertColor returns the configured color of an alert rule, defaulting to red.
+func alertColor(rule configuration.AlertRule) color.RGBA {
+	colorStr := rule.Color
+	if colorStr == "" {
+		colorStr = configuration.AlertColor
+	}
+	return parseColor(colorStr, color.RGBA{R: 255, G: 0, B: 0, A: 255})
+}
+
+// DrawWeatherAlerts takes over the weather row with a flashing banner for the most
+// severe active weather alert, alternating between the alert color and the text color
+// at 1 Hz. Additional alerts are indicated by a count.
+//
+// Parameters:
+//   - weatherAlerts: Active alerts, most severe first
+//
+// Returns:
+//   - bool: true if a banner was drawn, false if there are no active alerts
+func (r *Renderer) DrawWeatherAlerts(weatherAlerts instruments.WeatherAlerts) bool {
+	if len(weatherAlerts) == 0 {
+		return false
+	}
+
+	alert := weatherAlerts[0]
+	alertText := "\uf071 " + alert.Event
+	if !alert.Expires.IsZero() {
+		alertText += " " + r.translate("until %s", alert.Expires.Local().Format("3:04 PM"))
+	}
+	if len(weatherAlerts) > 1 {
+		alertText += fmt.Sprintf(" (+%d)", len(weatherAlerts)-1)
+	}
+
+	alertTextWidth := (&font.Drawer{Face: r.face}).MeasureString(alertText)
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(width) - alertTextWidth - fixed.I(10),
+		Y: fixed.I(40),
+	}
+
+	if (time.Now().UnixMilli()/500)%2 == 0 {
+		src := r.d.Src
+		r.d.Src = image.NewUniform(parseColor(configuration.AlertColor, color.RGBA{R: 255, G: 0, B: 0, A: 255}))
+		r.d.DrawString(alertText)
+		r.d.Src = src
+	} else {
+		r.d.DrawString(alertText)
+	}
+
+	return true
+}
+
+// DrawDiskHealth renders the temperatures of the drives right-aligned in the bottom
+// row. While a drive has failing attributes, its name and first failing attribute
+// flash in the alert color instead. If disks is empty, nothing is drawn.
+//
+// Parameters:
+//   - disks: DiskHealth containing the SMART health of the configured drives
+func (r *Renderer) DrawDiskHealth(disks instruments.DiskHealth) {
+	if len(disks) == 0 {
+		return
+	}
+
+	// Show the first drive with failing attributes, otherwise the temperatures
+	var temps []string
+	failing := false
+	for _, drive := range disks {
+		if len(drive.Failing) > 0 {
+			temps = []string{drive.Name(), drive.Failing[0]}
+			failing = true
+			break
+		}
+		if drive.Temperature > 0 {
+			temps = append(temps, r.formatDecimal(drive.Temperature, 0)+" °C")
+		}
+	}
+
+	diskText := "\uf0a0 " + strings.Join(temps, " ")
+	if failing {
+		diskText = "\uf071 " + strings.Join(temps, " ")
+	} else if len(temps) == 0 {
+		diskText = "\uf0a0 \uf00c"
+	}
+
+	diskTextWidth := (&font.Drawer{Face: r.face}).MeasureString(diskText)
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(width) - diskTextWidth - fixed.I(10),
+		Y: fixed.I(40),
+	}
+
+	if failing && (time.Now().UnixMilli()/500)%2 == 0 {
+		src := r.d.Src
+		r.d.Src = image.NewUniform(parseColor(configuration.AlertColor, color.RGBA{R: 255, G: 0, B: 0, A: 255}))
+		r.d.DrawString(diskText)
+		r.d.Src = src
+	} else {
+		r.d.DrawString(diskText)
+	}
+}
+
+// vpnRegion is the area at the left edge in which the VPN status is shown.
+var vpnRegion = image.Rect(10, 0, width/4-10, height)
+
+// DrawVPN renders a lock with the interface name and the age of the last WireGuard
+// handshake in the top row, and the endpoint below, scrolling when it does not fit
+// into vpnRegion. While the tunnel is not connected an open lock is drawn in the
+// alert color. If vpn is nil, nothing is drawn.
+//
+// Parameters:
+//   - vpn: Pointer to VPNStatus containing the state of the VPN interface
+func (r *Renderer) DrawVPN(vpn *instruments.VPNStatus) {
+	if vpn == nil {
+		return
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(vpnRegion.Min.X),
+		Y: fixed.I(15),
+	}
+
+	if !vpn.Connected() {
+		src := r.d.Src
+		r.d.Src = image.NewUniform(parseColor(configuration.AlertColor, color.RGBA{R: 255, G: 0, B: 0, A: 255}))
+		r.d.DrawString("\uf09c " + vpn.Interface)
+		r.d.Src = src
+	} else {
+		vpnText := "\uf023 " + vpn.Interface
+		if !vpn.LastHandshake.IsZero() {
+			vpnText += " " + formatTimer(time.Since(vpn.LastHandshake))
+		}
+		r.d.DrawString(vpnText)
+	}
+
+	if vpn.Endpoint != "" {
+		r.drawMarquee(vpnRegion, 40, []TickerItem{{Text: vpn.Endpoint}})
+	}
+}
+
+// DrawForecast renders the upcoming days' forecast right-aligned in the weather row,
+// showing the weekday, condition icon and min/max temperature of each day.
+//
+// Parameters:
+//   - forecast: Slice of DailyForecast entries, starting with tomorrow
+//   - unit: Unit system of the configuration, "metric" or "imperial"
+func (r *Renderer) DrawForecast(forecast []instruments.DailyForecast, unit string) {
+	degreeSymbol, _ := measurementSymbols(unit)
+
+	days := make([]string, 0, len(forecast))
+	for _, day := range forecast {
+		days = append(days, fmt.Sprintf("%s %s %s/%s%s", r.weekdayName(day.Date), day.Condition, r.formatDecimal(day.Min, 0), r.formatDecimal(day.Max, 0), degreeSymbol))
+	}
+
+	forecastText := strings.Join(days, "  ")
+	forecastTextWidth := (&font.Drawer{Face: r.face}).MeasureString(forecastText)
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(width) - forecastTextWidth - fixed.I(10),
+		Y: fixed.I(40),
+	}
+
+	r.d.DrawString(forecastText)
+}
+
+// measurementSymbols returns the temperature and wind speed symbols of unit.
+func measurementSymbols(unit string) (degreeSymbol, speedSymbol string) {
 	if unit == "metric" {
-		degreeSymbol = "°C"
-		speedSymbol = "km/h"
+		return "°C", "km/h"
 	} else if unit == "imperial" {
-		degreeSymbol = "°F"
-		speedSymbol = "mph"
-	} else {
-		degreeSymbol = "K"
-		speedSymbol = "m/s"
-	}
-}
-
-// colorMap returns a map of predefined color names to their corresponding RGBA values.
-// The map includes basic colors (black, white, red, green, blue) and additional colors
-// like yellow, cyan, magenta, purple, orange, pink, gray, brown, teal, and silver.
-// All colors are defined with full opacity (A: 255).
-func colorMap() map[string]color.RGBA {
-	return map[string]color.RGBA{
-		"black":   {R: 0, G: 0, B: 0, A: 255},
-		"red":     {R: 255, G: 0, B: 0, A: 255},
-		"green":   {R: 0, G: 255, B: 0, A: 255},
-		"blue":    {R: 0, G: 0, B: 255, A: 255},
-		"white":   {R: 255, G: 255, B: 255, A: 255},
-		"yellow":  {R: 255, G: 255, B: 0, A: 255},
-		"cyan":    {R: 0, G: 255, B: 255, A: 255},
-		"magenta": {R: 255, G: 0, B: 255, A: 255},
-		"purple":  {R: 128, G: 0, B: 128, A: 255},
-		"orange":  {R: 255, G: 165, B: 0, A: 255},
-		"pink":    {R: 255, G: 192, B: 203, A: 255},
-		"gray":    {R: 128, G: 128, B: 128, A: 255},
-		"brown":   {R: 165, G: 42, B: 42, A: 255},
-		"teal":    {R: 0, G: 128, B: 128, A: 255},
-		"silver":  {R: 192, G: 192, B: 192, A: 255},
-	}
-}
-
-// parseColor converts a color string to color.RGBA. It accepts either a hex color string
-// in the format "#RRGGBB" or a named color string. If the input string is not a valid color
-// format, it returns the provided default color.
+		return "°F", "mph"
+	}
+	return "K", "m/s"
+}
+
+// parseColor converts a color string to color.RGBA. It accepts the CSS colors of
+// configuration.ParseColor: hex colors with or without alpha, rgb()/hsl() and color names.
+// If the input string is not a valid color format, it returns the provided default color.
 //
 // Parameters:
-//   - colorStr: A string representing the color in either hex format ("#RRGGBB") or as a named color
+//   - colorStr: A string representing the color, e.g. "#FF8800", "#F808", "rgb(255, 128, 0)" or "orange"
 //   - defaultColor: The fallback color.RGBA to use if parsing fails
 //
 // Returns:
 //   - color.RGBA: The parsed color, or defaultColor if parsing fails
 func parseColor(colorStr string, defaultColor color.RGBA) color.RGBA {
-	// Check if hex color
-	if len(colorStr) == 7 && colorStr[0] == '#' {
-		var r, g, b uint8
-		if _, err := fmt.Sscanf(colorStr[1:], "%02x%02x%02x", &r, &g, &b); err == nil {
-			return color.RGBA{R: r, G: g, B: b, A: 255}
-		}
-	}
-
-	// Check named color
-	if color, exists := colorMap()[colorStr]; exists {
-		return color
+	if c, ok := configuration.ParseColor(colorStr); ok {
+		return c
 	}
-
 	return defaultColor
 }
 
@@ -360,31 +1063,53 @@ func parseColor(colorStr string, defaultColor color.RGBA) color.RGBA {
 // It takes a label string and a rate in Kbps (kilobits per second) as input.
 // For rates above 1000 Kbps, it converts to Mbps (megabits per second) with one decimal place.
 // For rates below or equal to 1000 Kbps, it keeps the original Kbps unit.
-// Returns a formatted string combining the label and the rate with proper units.
-func formatNetworkRate(label string, rate int64) string {
+// Returns a formatted string combining the label and the rate with proper units,
+// using the separators of the display locale.
+func (r *Renderer) formatNetworkRate(label string, rate int64) string {
 	if rate > 1000 {
-		return fmt.Sprintf("%s %.1f Mbps", label, float64(rate)/1024)
+		return fmt.Sprintf("%s %s Mbps", label, r.formatDecimal(float64(rate)/1024, 1))
 	}
-	return fmt.Sprintf("%s %d Kbps", label, rate)
+	return fmt.Sprintf("%s %s Kbps", label, r.formatDecimal(float64(rate), 0))
 }
 
-// convertBackgroundImage takes a path to an image file and converts it into a slice of RGBA images.
+// loadBackground returns the frames of the background image name in the images
+// directory, or of the embedded background if name is "". It returns nil if the
+// image cannot be loaded, so a solid color is drawn instead.
+func loadBackground(name string) []*image.RGBA {
+	var data []byte
+	var err error
+	if name == "" {
+		data, err = images.ReadFile("images/" + defaultBackground)
+	} else {
+		var imagesDir string
+		if imagesDir, err = configuration.GetImagesDir(); err == nil {
+			data, err = os.ReadFile(filepath.Join(imagesDir, name))
+		}
+	}
+
+	var frames []*image.RGBA
+	if err == nil {
+		frames, err = convertBackgroundImage(cmp.Or(name, defaultBackground), data)
+	}
+	if err != nil {
+		renderLog.Warn("Failed to load background image", "image", name, "error", err)
+		return nil
+	}
+	return frames
+}
+
+// convertBackgroundImage takes the contents of an image file and converts it into a slice of RGBA images.
 // For GIF files, it returns all frames as separate RGBA images.
 // For JPEG and PNG files, it returns a single RGBA image in a slice.
 //
 // Parameters:
-//   - imgPath: string representing the path to the image file
+//   - fileName: string representing the name of the image file, used to detect GIFs
+//   - imgFile: the contents of the image file
 //
 // Returns:
 //   - []*image.RGBA: a slice of RGBA images (multiple frames for GIFs, single frame for JPEG/PNG)
 //   - error: nil if successful, otherwise an error describing what went wrong
-func convertBackgroundImage(fileName string) ([]*image.RGBA, error) {
-	// Get the embedded image file
-	imgFile, err := images.ReadFile("images/" + fileName)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to read embedded image: %v", err)
-	}
+func convertBackgroundImage(fileName string, imgFile []byte) ([]*image.RGBA, error) {
 
 	// For GIF images, handle multiple frames
 	if strings.HasSuffix(strings.ToLower(fileName), ".gif") {
@@ -0,0 +1,99 @@
+package nexus
+
+import (
+	"sync"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+)
+
+// eventBuffer is the number of events buffered per subscriber before dropping.
+const eventBuffer = 16
+
+// Topic fans events of type T out to subscribers. Publishing never blocks: a
+// subscriber whose buffer is full misses the event, so slow consumers cannot hold
+// up the display loop or the touch monitor. It is safe for concurrent use.
+type Topic[T any] struct {
+	mu          sync.Mutex
+	subscribers map[chan T]bool // Latest-only subscribers map to true
+}
+
+// NewTopic returns a topic without subscribers.
+func NewTopic[T any]() *Topic[T] {
+	return &Topic[T]{subscribers: make(map[chan T]bool)}
+}
+
+// Publish sends event to every subscriber with room in its buffer. Latest-only
+// subscribers get event in place of one they have not received yet.
+func (t *Topic[T]) Publish(event T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for ch, latest := range t.subscribers {
+		if latest {
+			select {
+			case <-ch:
+			default:
+			}
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber buffering up to buffer events and returns the
+// channel receiving them and a function to unsubscribe.
+func (t *Topic[T]) Subscribe(buffer int) (<-chan T, func()) {
+	return t.subscribe(make(chan T, buffer), false)
+}
+
+// SubscribeLatest registers a subscriber that only receives the latest event it
+// has not received yet, skipping intermediate ones. It returns the channel
+// receiving events and a function to unsubscribe.
+func (t *Topic[T]) SubscribeLatest() (<-chan T, func()) {
+	return t.subscribe(make(chan T, 1), true)
+}
+
+func (t *Topic[T]) subscribe(ch chan T, latest bool) (<-chan T, func()) {
+	t.mu.Lock()
+	t.subscribers[ch] = latest
+	t.mu.Unlock()
+
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.subscribers, ch)
+		t.mu.Unlock()
+	}
+}
+
+// EventBus holds the topics of events that reach several consumers, like the
+// display loop, webhooks, D-Bus and the API streams.
+type EventBus struct {
+	Touch    *Topic[TouchEvent]                 // Starts of touches on the display
+	Device   *Topic[api.DeviceEvent]            // Device connects and disconnects
+	Page     *Topic[api.PageEvent]              // Page switches
+	Alert    *Topic[api.AlertEvent]             // Triggered and cleared alerts
+	Config   *Topic[*configuration.NexusConfig] // Reloaded configurations that changed
+	Readings *Topic[instruments.Reading]        // Instrument readings
+}
+
+// NewEventBus returns an event bus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		Touch:    NewTopic[TouchEvent](),
+		Device:   NewTopic[api.DeviceEvent](),
+		Page:     NewTopic[api.PageEvent](),
+		Alert:    NewTopic[api.AlertEvent](),
+		Config:   NewTopic[*configuration.NexusConfig](),
+		Readings: NewTopic[instruments.Reading](),
+	}
+}
+
+// Events returns the event bus of the Nexus run by Run, for example for a tray
+// icon following the device status. Subscriptions outlive restarts of Run.
+func Events() *EventBus {
+	return defaultNexus.events
+}
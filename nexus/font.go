@@ -12,9 +12,15 @@ import (
 	"golang.org/x/image/font/basicfont"
 )
 
+// fontKey identifies a font face in the font cache.
+type fontKey struct {
+	name string
+	size float64
+}
+
 var (
-	systemFont     font.Face
-	systemFontOnce sync.Once
+	fontCache   = make(map[fontKey]font.Face) // Loaded font faces
+	fontCacheMu sync.Mutex                    // Guards fontCache
 
 	fontDirs = map[string][]string{
 		"windows": {"C:\\Windows\\Fonts"},
@@ -68,19 +74,36 @@ var (
 )
 
 // LoadSystemFont loads and caches a system font specified by the preferredFont parameter.
-// It uses sync.Once to ensure the font is loaded only once, making it safe for concurrent use.
+// Faces are cached by name and size, so changing either loads the new font on the next
+// frame. It is safe for concurrent use.
 // The function returns a font.Face that can be used for text rendering.
 //
 // Parameters:
 //   - preferredFont: The name or path of the preferred system font to load
+//   - size: The font size in pixels
 //
 // Returns:
 //   - font.Face: The loaded font face instance that can be used for text rendering
-func LoadSystemFont(preferredFont string) font.Face {
-	systemFontOnce.Do(func() {
-		systemFont = loadFont(preferredFont)
-	})
-	return systemFont
+func LoadSystemFont(preferredFont string, size float64) font.Face {
+	fontCacheMu.Lock()
+	defer fontCacheMu.Unlock()
+
+	key := fontKey{name: preferredFont, size: size}
+	if face, ok := fontCache[key]; ok {
+		return face
+	}
+
+	face := loadFont(preferredFont, size)
+	fontCache[key] = face
+	return face
+}
+
+// resetFontCache drops the cached font faces, so fonts are loaded from disk again.
+func resetFontCache() {
+	fontCacheMu.Lock()
+	defer fontCacheMu.Unlock()
+
+	clear(fontCache)
 }
 
 // loadFont attempts to load a font face based on the provided preferred font name.
@@ -91,21 +114,22 @@ func LoadSystemFont(preferredFont string) font.Face {
 //
 // Parameters:
 //   - preferredFont: The name of the preferred font to try first. If empty, skips to system fonts.
+//   - size: The font size in pixels, ignored by the basic font
 //
 // Returns:
 //   - font.Face: The loaded font face. Will never return nil as it falls back to basicfont.Face7x13.
-func loadFont(preferredFont string) font.Face {
+func loadFont(preferredFont string, size float64) font.Face {
 	osType := runtime.GOOS
 
 	// Try preferred font first
 	if preferredFont != "" {
-		if f := tryLoadFont(preferredFont, osType); f != nil {
+		if f := tryLoadFont(preferredFont, osType, size); f != nil {
 			return f
 		}
 	}
 
 	// Try system fonts
-	if f := tryLoadSystemFonts(osType); f != nil {
+	if f := tryLoadSystemFonts(osType, size); f != nil {
 		return f
 	}
 
@@ -114,24 +138,29 @@ func loadFont(preferredFont string) font.Face {
 }
 
 // tryLoadFont attempts to load a font from the specified path based on the operating system.
-// It iterates through system font directories to find and create a font face.
-// For Windows systems, the font path is converted to lowercase.
+// An absolute path is loaded directly; otherwise it iterates through system font directories
+// to find and create a font face. For Windows systems, the font path is converted to lowercase.
 //
 // Parameters:
-//   - fontPath: The name or relative path of the font file to load
+//   - fontPath: The name, relative or absolute path of the font file to load
 //   - osType: The operating system type ("windows", "darwin", "linux", etc.)
+//   - size: The font size in pixels
 //
 // Returns:
 //   - font.Face: A valid font face if found, nil otherwise
-func tryLoadFont(fontPath, osType string) font.Face {
+func tryLoadFont(fontPath, osType string, size float64) font.Face {
+	if filepath.IsAbs(fontPath) {
+		return createFontFace(fontPath, size)
+	}
+
 	if osType == "windows" {
 		fontPath = strings.ToLower(fontPath)
 	}
 
 	for _, dir := range fontDirs[osType] {
 		path := filepath.Join(dir, fontPath)
-		if face := createFontFace(path); face != nil {
-			println("Using font:", path)
+		if face := createFontFace(path, size); face != nil {
+			renderLog.Info("Using font", "path", path)
 			return face
 		}
 	}
@@ -144,6 +173,7 @@ func tryLoadFont(fontPath, osType string) font.Face {
 //
 // Parameters:
 //   - osType: String identifying the operating system (e.g., "windows", "darwin", "linux")
+//   - size: The font size in pixels
 //
 // Returns:
 //   - font.Face: A valid font face if found, nil otherwise
@@ -152,12 +182,12 @@ func tryLoadFont(fontPath, osType string) font.Face {
 // and tries to load fonts in the following order:
 //  1. Popular fonts defined in popularFonts[osType]
 //  2. Any .ttf or .otf files found in the system font directories
-func tryLoadSystemFonts(osType string) font.Face {
+func tryLoadSystemFonts(osType string, size float64) font.Face {
 	// Try popular fonts first
 	for _, fontName := range popularFonts[osType] {
 		for _, dir := range fontDirs[osType] {
 			path := filepath.Join(dir, fontName)
-			if face := createFontFace(path); face != nil {
+			if face := createFontFace(path, size); face != nil {
 				return face
 			}
 		}
@@ -165,6 +195,7 @@ func tryLoadSystemFonts(osType string) font.Face {
 
 	// Scan directories for any available fonts
 	extensions := []string{".ttf", ".otf"}
+	var found font.Face
 	for _, dir := range fontDirs[osType] {
 		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 			if err != nil || info.IsDir() {
@@ -173,22 +204,25 @@ func tryLoadSystemFonts(osType string) font.Face {
 			ext := strings.ToLower(filepath.Ext(path))
 			for _, validExt := range extensions {
 				if ext == validExt {
-					if face := createFontFace(path); face != nil {
+					if found = createFontFace(path, size); found != nil {
 						return filepath.SkipAll
 					}
 				}
 			}
 			return nil
 		})
+		if found != nil {
+			return found
+		}
 	}
 	return nil
 }
 
 // createFontFace creates and returns a new font.Face from a TrueType font file.
-// It takes a file path as input and returns the created font face.
-// The font is rendered with a size of 13pt at 72 DPI.
+// It takes a file path and a size in pixels as input and returns the created font face.
+// The font is rendered at 72 DPI, so a point is a pixel.
 // If there are any errors reading the file or parsing the font, it returns nil.
-func createFontFace(path string) font.Face {
+func createFontFace(path string, size float64) font.Face {
 	fontBytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil
@@ -200,7 +234,7 @@ func createFontFace(path string) font.Face {
 	}
 
 	return truetype.NewFace(f, &truetype.Options{
-		Size: 13,
+		Size: size,
 		DPI:  72,
 	})
 }
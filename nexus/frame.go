@@ -0,0 +1,164 @@
+package nexus
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"nexus-open/nexus/api"
+)
+
+// External frame settings
+const (
+	frameDefaultTTL  = 5 * time.Second
+	frameMaxTTL      = time.Minute
+	frameTokenHeader = "X-Frame-Token"
+)
+
+// externalFrame holds a frame pushed by an external renderer. While its lock is held
+// the internal renderer is bypassed and the pushed frame is sent to the device.
+type externalFrame struct {
+	mu      sync.Mutex
+	token   string    // Token of the client holding the lock, empty if unlocked
+	expires time.Time // When the lock lapses unless renewed by another frame
+	frame   []byte    // Latest RGBA frame, width*height*4 bytes
+}
+
+// current returns the pushed frame while the lock is held.
+func (e *externalFrame) current() ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token == "" || time.Now().After(e.expires) {
+		e.token, e.frame = "", nil
+		return nil, false
+	}
+	return e.frame, true
+}
+
+// push stores frame if token holds the lock, or acquires the lock if it is free,
+// and extends the lock by ttl. It returns the lock token, or false if another
+// client holds the lock.
+func (e *externalFrame) push(token string, frame []byte, ttl time.Duration) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	locked := e.token != "" && time.Now().Before(e.expires)
+	if locked && token != e.token {
+		return "", false
+	}
+
+	if !locked {
+		id := make([]byte, 16)
+		rand.Read(id)
+		e.token = hex.EncodeToString(id)
+	}
+
+	e.frame = frame
+	e.expires = time.Now().Add(ttl)
+	return e.token, true
+}
+
+// release drops the lock if token holds it.
+func (e *externalFrame) release(token string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token == "" || token != e.token {
+		return false
+	}
+	e.token, e.frame = "", nil
+	return true
+}
+
+// frameHandler lets external renderers drive the display (/api/frame).
+//
+// POST sends a 640x48 frame, either as a PNG image or as raw RGBA pixels
+// (exactly 640*48*4 bytes). The first frame acquires an exclusive lock and the
+// response contains its token, which must be sent in the X-Frame-Token header
+// with every following frame. Each frame renews the lock for ttl seconds
+// (query parameter, default 5, at most 60); once it lapses the regular display
+// returns. Frames from other clients are rejected with 409 while the lock is held.
+//
+// DELETE with the X-Frame-Token header releases the lock immediately.
+func (n *Nexus) frameHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		ttl := frameDefaultTTL
+		if value := r.URL.Query().Get("ttl"); value != "" {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds <= 0 {
+				http.Error(w, "Invalid ttl", http.StatusBadRequest)
+				return
+			}
+			ttl = min(time.Duration(seconds*float64(time.Second)), frameMaxTTL)
+		}
+
+		// Allow the raw frame size plus headroom for PNG metadata
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, width*height*4+64*1024))
+		if err != nil {
+			http.Error(w, "Frame too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		frame, err := decodeFrame(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		token, ok := n.externalFrame.push(r.Header.Get(frameTokenHeader), frame, ttl)
+		if !ok {
+			http.Error(w, "Display is locked by another client", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.FrameResponse{
+			Status: api.StatusOK.Status,
+			Token:  token,
+			TTL:    ttl.Seconds(),
+		})
+	case http.MethodDelete:
+		if !n.externalFrame.release(r.Header.Get(frameTokenHeader)) {
+			http.Error(w, "Lock not held", http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeFrame converts a PNG image or raw RGBA pixels of the display size into an
+// RGBA frame buffer.
+func decodeFrame(body []byte) ([]byte, error) {
+	if len(body) == width*height*4 && !bytes.HasPrefix(body, []byte("\x89PNG")) {
+		return body, nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("frame must be a PNG image or raw RGBA pixels (%dx%dx4 bytes)", width, height)
+	}
+
+	if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+		return nil, fmt.Errorf("frame must be %dx%d pixels", width, height)
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba.Pix, nil
+}
@@ -0,0 +1,69 @@
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+)
+
+// geocodeTimeout bounds the lookup of the location when the configuration is saved
+const geocodeTimeout = 10 * time.Second
+
+// AmbiguousLocationError is returned by GeocodeLocation when the location names
+// several places. Saving the configuration again with Geocoded set to one of the
+// candidates, or with a more precise location, resolves it.
+type AmbiguousLocationError struct {
+	Location   string
+	Candidates []instruments.Location
+}
+
+func (e *AmbiguousLocationError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, candidate := range e.Candidates {
+		names[i] = candidate.Name
+	}
+	return fmt.Sprintf("location %q is ambiguous, it matches %s", e.Location, strings.Join(names, "; "))
+}
+
+// GeocodeLocation checks that the location of cfg is a known place and records
+// its coordinates in cfg.Geocoded, before cfg is saved. Coordinates already
+// recorded for the location are kept, so a place picked from the candidates of an
+// AmbiguousLocationError sticks. Unknown places are reported as a
+// *configuration.ValidationError. When the geocoding service cannot be reached
+// the location is saved unchecked and geocoded when the weather is fetched.
+func GeocodeLocation(ctx context.Context, cfg *configuration.NexusConfig) error {
+	if !instruments.IsPlaceName(cfg.Location) {
+		cfg.Geocoded = configuration.GeocodedLocation{}
+		return nil
+	}
+	if _, _, ok := cfg.Geocoded.For(cfg.Location); ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, geocodeTimeout)
+	defer cancel()
+
+	candidates, ambiguous, err := instruments.SearchLocations(ctx, cfg.Location)
+	if err != nil {
+		configLog.Warn("Failed to geocode location, saving it unchecked", "location", cfg.Location, "error", err)
+		cfg.Geocoded = configuration.GeocodedLocation{}
+		return nil
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return &configuration.ValidationError{Fields: []configuration.FieldError{{
+			Field:   "location",
+			Message: fmt.Sprintf("no place called %q was found", cfg.Location),
+		}}}
+	case ambiguous:
+		return &AmbiguousLocationError{Location: cfg.Location, Candidates: candidates}
+	}
+
+	cfg.Geocoded = configuration.GeocodedLocation{Query: cfg.Location, Lat: candidates[0].Lat, Lon: candidates[0].Lon}
+	return nil
+}
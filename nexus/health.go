@@ -0,0 +1,116 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nexus-open/nexus/api"
+)
+
+// Limits of the health checks
+const (
+	healthRenderTimeout = 5 * time.Second // Since the latest display refresh
+	healthSampleTimeout = 2 * time.Minute // Since the latest instrument sample
+)
+
+// health checks the components of n. The render loop and the configuration are
+// critical: without them Nexus needs a restart. A missing device or stale samples
+// only degrade the health.
+func (n *Nexus) health() api.Health {
+	health := api.Health{
+		Device:      n.deviceHealth(),
+		Render:      n.renderHealth(),
+		Config:      api.HealthCheck{OK: n.configs.Get() != nil, Detail: "loaded"},
+		Instruments: n.instrumentsHealth(),
+	}
+	if !health.Config.OK {
+		health.Config.Detail = "no configuration loaded"
+	}
+
+	switch {
+	case !health.Render.OK || !health.Config.OK:
+		health.Status = "unhealthy"
+	case !health.Device.OK || !health.Instruments.OK:
+		health.Status = "degraded"
+	default:
+		health.Status = "ok"
+	}
+	return health
+}
+
+// deviceHealth reports whether frames reach the device.
+func (n *Nexus) deviceHealth() api.HealthCheck {
+	switch {
+	case !n.transport.Attached():
+		return api.HealthCheck{Detail: "no device attached"}
+	case n.transport.Virtual():
+		return api.HealthCheck{OK: true, Detail: "virtual display"}
+	default:
+		return api.HealthCheck{OK: true, Detail: "attached"}
+	}
+}
+
+// renderHealth reports whether the display loop refreshed recently. It passes
+// while Nexus starts.
+func (n *Nexus) renderHealth() api.HealthCheck {
+	tick := n.displayTick.Load()
+	if tick == 0 {
+		if time.Since(startTime) < healthRenderTimeout {
+			return api.HealthCheck{OK: true, Detail: "starting"}
+		}
+		return api.HealthCheck{Detail: "render loop not started"}
+	}
+
+	age := time.Since(time.Unix(0, tick))
+	if age > healthRenderTimeout {
+		return api.HealthCheck{Detail: fmt.Sprintf("no refresh for %s", age.Round(time.Second))}
+	}
+	return api.HealthCheck{OK: true, Detail: "running"}
+}
+
+// instrumentsHealth reports whether any instrument took a sample recently. It
+// passes while Nexus starts and while sampling waits for the device.
+func (n *Nexus) instrumentsHealth() api.HealthCheck {
+	if !n.gate.IsOpen() {
+		return api.HealthCheck{OK: true, Detail: "paused while no device is attached"}
+	}
+
+	var latest time.Time
+	for _, name := range n.history.Names() {
+		if reading, ok := n.history.Latest(name); ok && reading.Time.After(latest) {
+			latest = reading.Time
+		}
+	}
+
+	if latest.IsZero() {
+		if time.Since(startTime) < healthSampleTimeout {
+			return api.HealthCheck{OK: true, Detail: "starting"}
+		}
+		return api.HealthCheck{Detail: "no samples taken"}
+	}
+
+	age := time.Since(latest)
+	if age > healthSampleTimeout {
+		return api.HealthCheck{Detail: fmt.Sprintf("no sample for %s", age.Round(time.Second))}
+	}
+	return api.HealthCheck{OK: true, Detail: fmt.Sprintf("latest sample %s ago", age.Round(time.Second))}
+}
+
+// healthHandler reports the health of Nexus (GET /healthz), with status 503 if it
+// is unhealthy.
+func (n *Nexus) healthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	health := n.health()
+
+	w.Header().Set("Content-Type", "application/json")
+	if health.Status == "unhealthy" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
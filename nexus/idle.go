@@ -0,0 +1,82 @@
+package nexus
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Idle detection settings
+const (
+	idlePollInterval  = 5 * time.Second
+	idleFrameInterval = time.Second // Redraw interval of the "slow" idle action
+)
+
+// userIdle tracks whether the user is away from the computer, going by the time
+// since the last keyboard or mouse input reported by the operating system.
+type userIdle struct {
+	away      atomic.Bool
+	lastFrame time.Time // When the display loop last drew while away
+}
+
+// set marks the user as away or back, logging the change.
+func (u *userIdle) set(away bool) {
+	if u.away.Swap(away) == away {
+		return
+	}
+	if away {
+		idleLog.Info("User is idle")
+	} else {
+		idleLog.Info("User is back")
+	}
+}
+
+// frameDue reports whether a frame should be drawn while the user is away with the
+// "slow" idle action. It is only called by the display loop.
+func (u *userIdle) frameDue() bool {
+	now := time.Now()
+	if now.Sub(u.lastFrame) < idleFrameInterval {
+		return false
+	}
+	u.lastFrame = now
+	return true
+}
+
+// monitorIdle polls the time since the last input every idlePollInterval and marks
+// the user as away once it reaches the configured idle timeout. Touches on the
+// display are not input of the computer, so they do not end the idle period.
+// Without a timeout, or if the idle time cannot be read, the user counts as present.
+func (n *Nexus) monitorIdle(ctx context.Context) {
+	ticker := time.NewTicker(idlePollInterval)
+	defer ticker.Stop()
+
+	var unavailable bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var timeout time.Duration
+		if cfg := n.configs.Get(); cfg != nil {
+			timeout = cfg.Idle.TimeoutDuration()
+		}
+		if timeout == 0 {
+			n.idle.set(false)
+			continue
+		}
+
+		since, err := systemIdleTime(ctx)
+		if err != nil {
+			if !unavailable {
+				idleLog.Warn("Idle time is not available", "error", err)
+			}
+			unavailable = true
+			n.idle.set(false)
+			continue
+		}
+		unavailable = false
+		n.idle.set(since >= timeout)
+	}
+}
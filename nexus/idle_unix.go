@@ -0,0 +1,107 @@
+//go:build !windows
+
+package nexus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/dbus"
+)
+
+// idleBus is the session bus connection used to ask Mutter for the idle time. It
+// is only used by monitorIdle.
+var idleBus *dbus.Conn
+
+// systemIdleTime returns the time since the last keyboard or mouse input.
+// For Linux: Asks the Mutter idle monitor over D-Bus, which covers GNOME on Wayland
+// and X11, with a fallback to xprintidle for other X11 desktops
+// For macOS: Reads HIDIdleTime of IOHIDSystem with ioreg
+func systemIdleTime(ctx context.Context) (time.Duration, error) {
+	if runtime.GOOS == "darwin" {
+		return macIdleTime(ctx)
+	}
+
+	idle, mutterErr := mutterIdleTime()
+	if mutterErr == nil {
+		return idle, nil
+	}
+	idle, err := x11IdleTime(ctx)
+	if err != nil {
+		return 0, errors.Join(mutterErr, err)
+	}
+	return idle, nil
+}
+
+// mutterIdleTime asks the Mutter idle monitor of the session for the idle time.
+func mutterIdleTime() (time.Duration, error) {
+	if idleBus != nil {
+		select {
+		case <-idleBus.Done():
+			idleBus = nil
+		default:
+		}
+	}
+	if idleBus == nil {
+		conn, err := dbus.SessionBus()
+		if err != nil {
+			return 0, err
+		}
+		idleBus = conn
+	}
+
+	reply, err := idleBus.Call("org.gnome.Mutter.IdleMonitor", "/org/gnome/Mutter/IdleMonitor/Core",
+		"org.gnome.Mutter.IdleMonitor", "GetIdletime")
+	if err != nil {
+		return 0, fmt.Errorf("mutter idle monitor: %w", err)
+	}
+	if len(reply) != 1 {
+		return 0, fmt.Errorf("mutter idle monitor: invalid reply")
+	}
+	ms, ok := reply[0].(uint64)
+	if !ok {
+		return 0, fmt.Errorf("mutter idle monitor: invalid reply")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// x11IdleTime reads the idle time of the X server with xprintidle.
+func x11IdleTime(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "xprintidle").Output()
+	if err != nil {
+		return 0, fmt.Errorf("xprintidle: %v", err)
+	}
+
+	ms, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("xprintidle: invalid output format")
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+var hidIdleTimePattern = regexp.MustCompile(`"HIDIdleTime" = (\d+)`)
+
+// macIdleTime reads the idle time in nanoseconds from the HID system.
+func macIdleTime(ctx context.Context) (time.Duration, error) {
+	out, err := exec.CommandContext(ctx, "ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
+	if err != nil {
+		return 0, fmt.Errorf("ioreg: %v", err)
+	}
+
+	match := hidIdleTimePattern.FindSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("ioreg: HIDIdleTime not found")
+	}
+	ns, err := strconv.ParseInt(string(match[1]), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ioreg: invalid HIDIdleTime")
+	}
+	return time.Duration(ns), nil
+}
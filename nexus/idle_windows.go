@@ -0,0 +1,37 @@
+//go:build windows
+
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procGetLastInputInfo = windows.NewLazySystemDLL("user32.dll").NewProc("GetLastInputInfo")
+	procGetTickCount     = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetTickCount")
+)
+
+// lastInputInfo is the LASTINPUTINFO structure of GetLastInputInfo.
+type lastInputInfo struct {
+	size uint32
+	time uint32 // Tick count of the last input
+}
+
+// systemIdleTime returns the time since the last keyboard or mouse input of the
+// session, read with GetLastInputInfo. A service runs outside the user's session
+// and only sees its own input.
+func systemIdleTime(ctx context.Context) (time.Duration, error) {
+	info := lastInputInfo{size: uint32(unsafe.Sizeof(lastInputInfo{}))}
+	if ok, _, err := procGetLastInputInfo.Call(uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0, fmt.Errorf("GetLastInputInfo: %w", err)
+	}
+
+	// Both are milliseconds since boot that wrap around after 49.7 days
+	now, _, _ := procGetTickCount.Call()
+	return time.Duration(uint32(now)-info.time) * time.Millisecond, nil
+}
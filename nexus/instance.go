@@ -0,0 +1,84 @@
+package nexus
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"nexus-open/nexus/configuration"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// instanceTakeoverTimeout bounds how long LockInstance waits for the running
+// instance to stop when taking over.
+const instanceTakeoverTimeout = 10 * time.Second
+
+// ErrAlreadyRunning is returned by LockInstance while another instance runs.
+var ErrAlreadyRunning = errors.New("nexus is already running")
+
+// InstanceLock is held by the running instance, so that two instances do not fight
+// over the USB interface of the device.
+type InstanceLock struct {
+	file    *os.File
+	release func() error // Releases the lock of the platform
+}
+
+// LockInstance takes the instance lock, see configuration.GetLockPath. While
+// another instance holds it, LockInstance returns an error wrapping
+// ErrAlreadyRunning with its process ID, or with takeover asks that instance to stop
+// and waits until it released the lock.
+func LockInstance(takeover bool) (*InstanceLock, error) {
+	path, err := configuration.GetLockPath()
+	if err != nil {
+		return nil, err
+	}
+
+	lock, pid, err := tryLockInstance(path)
+	if !errors.Is(err, ErrAlreadyRunning) || !takeover || pid == 0 {
+		return lock, err
+	}
+
+	slog.Info("Taking over from the running instance", "pid", pid)
+	if err := stopInstance(pid); err != nil {
+		return nil, fmt.Errorf("failed to stop pid %d: %v", pid, err)
+	}
+
+	deadline := time.Now().Add(instanceTakeoverTimeout)
+	for errors.Is(err, ErrAlreadyRunning) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		lock, _, err = tryLockInstance(path)
+	}
+	return lock, err
+}
+
+// Release releases the lock, letting another instance start.
+func (l *InstanceLock) Release() error {
+	l.file.Truncate(0)
+	l.file.Close()
+	return l.release()
+}
+
+// writePID replaces the contents of the lock file with the process ID.
+func (l *InstanceLock) writePID() error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := l.file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+// alreadyRunning returns ErrAlreadyRunning with the process ID in the lock file
+// at path, and the process ID, 0 if it cannot be read.
+func alreadyRunning(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, ErrAlreadyRunning
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, ErrAlreadyRunning
+	}
+	return pid, fmt.Errorf("%w (pid %d)", ErrAlreadyRunning, pid)
+}
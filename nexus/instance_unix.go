@@ -0,0 +1,40 @@
+//go:build !windows
+
+package nexus
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockInstance takes an exclusive lock of the file at path. The lock is released
+// by the system if the process dies. While another process holds it, it returns its
+// process ID and an error wrapping ErrAlreadyRunning.
+func tryLockInstance(path string) (*InstanceLock, int, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			pid, err := alreadyRunning(path)
+			return nil, pid, err
+		}
+		return nil, 0, err
+	}
+
+	lock := &InstanceLock{file: file, release: func() error { return nil }}
+	if err := lock.writePID(); err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return lock, 0, nil
+}
+
+// stopInstance asks the instance with process ID pid to shut down gracefully.
+func stopInstance(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
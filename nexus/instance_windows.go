@@ -0,0 +1,57 @@
+//go:build windows
+
+package nexus
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// instanceMutex is the named mutex held by the running instance of the session.
+const instanceMutex = `Local\NexusOpen`
+
+// tryLockInstance creates the named mutex of the instance and writes the process ID
+// to the file at path. The mutex is released by the system if the process dies.
+// While another process holds it, it returns the process ID in the file and an error
+// wrapping ErrAlreadyRunning.
+func tryLockInstance(path string) (*InstanceLock, int, error) {
+	name, err := windows.UTF16PtrFromString(instanceMutex)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	mutex, err := windows.CreateMutex(nil, false, name)
+	if errors.Is(err, windows.ERROR_ALREADY_EXISTS) {
+		windows.CloseHandle(mutex)
+		pid, err := alreadyRunning(path)
+		return nil, pid, err
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		windows.CloseHandle(mutex)
+		return nil, 0, err
+	}
+
+	lock := &InstanceLock{file: file, release: func() error { return windows.CloseHandle(mutex) }}
+	if err := lock.writePID(); err != nil {
+		lock.Release()
+		return nil, 0, err
+	}
+	return lock, 0, nil
+}
+
+// stopInstance stops the instance with process ID pid. Windows has no termination
+// signal for console-less processes, so it is killed.
+func stopInstance(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
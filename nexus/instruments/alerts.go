@@ -0,0 +1,161 @@
+package instruments
+
+import (
+	"nexus-open/nexus/configuration"
+	"sync"
+	"time"
+)
+
+// Metered is implemented by values that expose numeric metrics for alerting.
+// Metric names are combined with the instrument name as "<instrument>.<metric>".
+type Metered interface {
+	Metrics() map[string]float64
+}
+
+// Alert is a rule that is currently triggered together with the value that triggered it.
+type Alert struct {
+	Rule  configuration.AlertRule
+	Value float64
+	Since time.Time
+}
+
+// AlertEngine evaluates alert rules against instrument readings and tracks which
+// metrics are currently in an alert state. It is safe for concurrent use.
+type AlertEngine struct {
+	mu       sync.RWMutex
+	rules    []configuration.AlertRule
+	active   map[string]Alert // Keyed by metric name
+	onChange func(alert Alert, active bool)
+}
+
+// NewAlertEngine creates an alert engine with the given rules.
+func NewAlertEngine(rules []configuration.AlertRule) *AlertEngine {
+	e := &AlertEngine{}
+	e.SetRules(rules)
+	return e
+}
+
+// SetRules replaces the rule set and clears all active alerts.
+func (e *AlertEngine) SetRules(rules []configuration.AlertRule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = append([]configuration.AlertRule(nil), rules...)
+	e.active = make(map[string]Alert)
+}
+
+// OnChange sets a function called whenever an alert is triggered (active is true)
+// or cleared. It is called outside the engine lock and must not block for long.
+func (e *AlertEngine) OnChange(fn func(alert Alert, active bool)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.onChange = fn
+}
+
+// alertChange is a triggered or cleared alert reported to the OnChange function.
+type alertChange struct {
+	alert  Alert
+	active bool
+}
+
+// Evaluate checks every rule against the metrics of a reading. A metric is in an
+// alert state while the first rule matching it is triggered; the alert clears
+// as soon as no rule for the metric matches.
+func (e *AlertEngine) Evaluate(reading Reading) {
+	metered, ok := reading.Value.(Metered)
+	if !ok {
+		return
+	}
+
+	var changes []alertChange
+
+	e.mu.Lock()
+	onChange := e.onChange
+	defer func() {
+		e.mu.Unlock()
+		if onChange != nil {
+			for _, change := range changes {
+				onChange(change.alert, change.active)
+			}
+		}
+	}()
+
+	for key, value := range metered.Metrics() {
+		metric := reading.Name + "." + key
+
+		triggered := false
+		for _, rule := range e.rules {
+			if rule.Metric != metric || !rule.Matches(value) {
+				continue
+			}
+
+			alert, wasActive := e.active[metric]
+			started := !wasActive || alert.Rule != rule
+			if started {
+				alert = Alert{Rule: rule, Since: reading.Time}
+			}
+			alert.Value = value
+			e.active[metric] = alert
+
+			if started {
+				changes = append(changes, alertChange{alert: alert, active: true})
+			}
+
+			triggered = true
+			break
+		}
+
+		if alert, wasActive := e.active[metric]; !triggered && wasActive {
+			alert.Value = value
+			changes = append(changes, alertChange{alert: alert, active: false})
+			delete(e.active, metric)
+		}
+	}
+}
+
+// Active returns the active alert for a metric, if any.
+func (e *AlertEngine) Active(metric string) (Alert, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	alert, ok := e.active[metric]
+	return alert, ok
+}
+
+// PageAlert returns the longest-running active alert whose action is to switch
+// to the alert page, if any.
+func (e *AlertEngine) PageAlert() (Alert, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var (
+		page  Alert
+		found bool
+	)
+	for _, alert := range e.active {
+		if alert.Rule.Action != configuration.AlertActionPage {
+			continue
+		}
+		if !found || alert.Since.Before(page.Since) {
+			page, found = alert, true
+		}
+	}
+
+	return page, found
+}
+
+// Metrics exposes the CPU and GPU temperatures as "cpu" and "gpu".
+func (t SystemTemperature) Metrics() map[string]float64 {
+	return map[string]float64{"cpu": t.CPU, "gpu": t.GPU}
+}
+
+// Metrics exposes the network throughput in Kbps as "sent" and "received".
+func (n NetworkStats) Metrics() map[string]float64 {
+	return map[string]float64{"sent": float64(n.Sent), "received": float64(n.Received)}
+}
+
+// Metrics exposes the current temperature as "temperature".
+func (w *WeatherInfo) Metrics() map[string]float64 {
+	return map[string]float64{"temperature": w.Temperature}
+}
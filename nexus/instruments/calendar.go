@@ -0,0 +1,376 @@
+package instruments
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"nexus-open/nexus/configuration"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CalendarInstrumentName = "calendar"
+
+	calendarUpdateInterval = 5 * time.Minute
+	calendarLookahead      = 30 * 24 * time.Hour // Events further away are ignored
+	calendarMaxOccurrences = 10000               // Bound on expanding a single recurring event
+)
+
+// UpcomingEvent is the next calendar event that has not started yet.
+type UpcomingEvent struct {
+	Title  string
+	Start  time.Time
+	AllDay bool
+}
+
+// Metrics exposes the minutes until the event starts as "minutes".
+func (e *UpcomingEvent) Metrics() map[string]float64 {
+	if e == nil {
+		return nil
+	}
+	return map[string]float64{"minutes": time.Until(e.Start).Minutes()}
+}
+
+// CalendarInstrument finds the next event across the configured ICS URLs and
+// CalDAV calendar. Its value is an *UpcomingEvent, nil if there is none.
+type CalendarInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewCalendarInstrument creates a calendar instrument that reads the calendar sources
+// from the configuration returned by getConfig. getConfig must not be nil.
+func NewCalendarInstrument(getConfig func() *configuration.NexusConfig) *CalendarInstrument {
+	if getConfig == nil {
+		panic("instruments: NewCalendarInstrument getConfig is nil")
+	}
+
+	return &CalendarInstrument{getConfig: getConfig}
+}
+
+func (c *CalendarInstrument) Name() string { return CalendarInstrumentName }
+
+func (c *CalendarInstrument) Interval() time.Duration { return calendarUpdateInterval }
+
+// Sample fetches every configured calendar and returns the earliest event starting
+// after now. Calendars that fail are logged and skipped; an error is only returned
+// if every calendar failed.
+func (c *CalendarInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := c.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	now := time.Now()
+	end := now.Add(calendarLookahead)
+
+	var (
+		next     *UpcomingEvent
+		sources  int
+		failures int
+	)
+
+	consider := func(events []calendarEvent) {
+		for _, event := range events {
+			if start, ok := event.nextStart(now, end); ok && (next == nil || start.Before(next.Start)) {
+				next = &UpcomingEvent{Title: event.summary, Start: start, AllDay: event.allDay}
+			}
+		}
+	}
+
+	for _, icsURL := range cfg.Calendar.ICS {
+		sources++
+		events, err := getICSEvents(ctx, icsURL)
+		if err != nil {
+			calendarLog.Warn("Calendar failed", "url", icsURL, "error", err)
+			failures++
+			continue
+		}
+		consider(events)
+	}
+
+	if caldav := cfg.Calendar.CalDAV; caldav.URL != "" {
+		sources++
+		events, err := getCalDAVEvents(ctx, caldav, now, end)
+		if err != nil {
+			calendarLog.Warn("Calendar failed", "url", caldav.URL, "error", err)
+			failures++
+		} else {
+			consider(events)
+		}
+	}
+
+	if failures > 0 && failures == sources {
+		return nil, fmt.Errorf("all %d calendars failed", failures)
+	}
+
+	return next, nil
+}
+
+// calendarEvent is a VEVENT parsed from iCalendar data.
+type calendarEvent struct {
+	summary string
+	start   time.Time
+	allDay  bool
+	rrule   string
+}
+
+// getICSEvents downloads an iCalendar file and parses its events.
+func getICSEvents(ctx context.Context, icsURL string) ([]calendarEvent, error) {
+	// webcal:// is a common alias for an ICS subscription over http
+	if strings.HasPrefix(icsURL, "webcal://") {
+		icsURL = "https://" + strings.TrimPrefix(icsURL, "webcal://")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", icsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return parseICS(resp.Body)
+}
+
+// getCalDAVEvents queries a CalDAV calendar collection for the events between
+// start and end using a calendar-query REPORT.
+func getCalDAVEvents(ctx context.Context, caldav configuration.CalDAVConfig, start, end time.Time) ([]calendarEvent, error) {
+	const stamp = "20060102T150405Z"
+	query := `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="` + start.UTC().Format(stamp) + `" end="` + end.UTC().Format(stamp) + `"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", caldav.URL, strings.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if caldav.Username != "" {
+		password, err := configuration.ResolveSecret(caldav.Password)
+		if err != nil {
+			return nil, err
+		}
+		req.SetBasicAuth(caldav.Username, password)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus && resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Responses []struct {
+			CalendarData []string `xml:"propstat>prop>calendar-data"`
+		} `xml:"DAV: response"`
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse CalDAV response: %w", err)
+	}
+
+	var events []calendarEvent
+	for _, response := range result.Responses {
+		for _, data := range response.CalendarData {
+			parsed, err := parseICS(strings.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, parsed...)
+		}
+	}
+
+	return events, nil
+}
+
+// parseICS extracts the VEVENT components of iCalendar data. Only the fields
+// needed to find the next event are read: SUMMARY, DTSTART and RRULE.
+func parseICS(r io.Reader) ([]calendarEvent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	// Unfold continuation lines, which start with a space or tab
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+
+	var (
+		events  []calendarEvent
+		current *calendarEvent
+		valid   bool
+	)
+
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current, valid = &calendarEvent{}, false
+		case name == "END" && value == "VEVENT":
+			if current != nil && valid {
+				events = append(events, *current)
+			}
+			current = nil
+		case current == nil:
+			continue
+		case name == "SUMMARY":
+			current.summary = unescapeICSText(value)
+		case name == "RRULE":
+			current.rrule = value
+		case name == "DTSTART":
+			start, allDay, err := parseICSTime(value, params)
+			if err != nil {
+				calendarLog.Warn("Skipping event", "error", err)
+				continue
+			}
+			current.start, current.allDay, valid = start, allDay, true
+		}
+	}
+
+	return events, nil
+}
+
+// parseICSTime parses a DATE or DATE-TIME value with its property parameters.
+// Floating times and all-day dates are interpreted in the local time zone.
+func parseICSTime(value, params string) (time.Time, bool, error) {
+	loc := time.Local
+	allDay := false
+
+	for _, param := range strings.Split(params, ";") {
+		key, val, _ := strings.Cut(param, "=")
+		switch key {
+		case "TZID":
+			if tz, err := time.LoadLocation(strings.Trim(val, `"`)); err == nil {
+				loc = tz
+			}
+		case "VALUE":
+			allDay = val == "DATE"
+		}
+	}
+
+	switch {
+	case len(value) == 8:
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, allDay, err
+	default:
+		t, err := time.ParseInLocation("20060102T150405", value, loc)
+		return t, allDay, err
+	}
+}
+
+// unescapeICSText reverses the escaping of iCalendar TEXT values.
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// nextStart returns the first start of the event after now and before end.
+// Recurring events are expanded using the FREQ, INTERVAL, COUNT and UNTIL parts
+// of their RRULE; other rule parts such as BYDAY are not supported.
+func (e calendarEvent) nextStart(now, end time.Time) (time.Time, bool) {
+	if e.rrule == "" {
+		return e.start, e.start.After(now) && e.start.Before(end)
+	}
+
+	var (
+		freq     string
+		interval = 1
+		count    = -1
+		until    time.Time
+	)
+
+	for _, part := range strings.Split(e.rrule, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			freq = val
+		case "INTERVAL":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				interval = n
+			}
+		case "COUNT":
+			if n, err := strconv.Atoi(val); err == nil {
+				count = n
+			}
+		case "UNTIL":
+			if t, _, err := parseICSTime(val, ""); err == nil {
+				until = t
+			}
+		}
+	}
+
+	step := func(t time.Time) time.Time {
+		switch freq {
+		case "DAILY":
+			return t.AddDate(0, 0, interval)
+		case "WEEKLY":
+			return t.AddDate(0, 0, 7*interval)
+		case "MONTHLY":
+			return t.AddDate(0, interval, 0)
+		case "YEARLY":
+			return t.AddDate(interval, 0, 0)
+		}
+		return time.Time{}
+	}
+
+	start := e.start
+	for i := 0; i < calendarMaxOccurrences && !start.IsZero() && start.Before(end); i++ {
+		if count >= 0 && i >= count {
+			break
+		}
+		if !until.IsZero() && start.After(until) {
+			break
+		}
+		if start.After(now) {
+			return start, true
+		}
+		start = step(start)
+	}
+
+	return time.Time{}, false
+}
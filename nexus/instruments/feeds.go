@@ -0,0 +1,213 @@
+package instruments
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"nexus-open/nexus/configuration"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FeedsInstrumentName = "feeds"
+
+	feedsUpdateInterval = 15 * time.Minute
+	feedMaxItems        = 15 // Headlines kept across all feeds
+)
+
+// FeedItem is a single entry of an RSS or Atom feed.
+type FeedItem struct {
+	Title     string
+	Link      string
+	Source    string // Title of the feed the item came from
+	Published time.Time
+}
+
+// FeedHeadlines is the deduplicated list of the newest feed entries across all
+// configured feeds, newest first.
+type FeedHeadlines []FeedItem
+
+// FeedsInstrument polls the configured RSS and Atom feeds.
+type FeedsInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewFeedsInstrument creates a feeds instrument that reads the feed URLs from the
+// configuration returned by getConfig. getConfig must not be nil.
+func NewFeedsInstrument(getConfig func() *configuration.NexusConfig) *FeedsInstrument {
+	if getConfig == nil {
+		panic("instruments: NewFeedsInstrument getConfig is nil")
+	}
+
+	return &FeedsInstrument{getConfig: getConfig}
+}
+
+func (f *FeedsInstrument) Name() string { return FeedsInstrumentName }
+
+func (f *FeedsInstrument) Interval() time.Duration { return feedsUpdateInterval }
+
+// Sample fetches every configured feed and merges their entries. Feeds that fail
+// are logged and skipped; an error is only returned if every feed failed.
+func (f *FeedsInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := f.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	var (
+		items    []FeedItem
+		failures int
+	)
+	for _, feedURL := range cfg.Feeds {
+		feedItems, err := GetFeed(ctx, feedURL)
+		if err != nil {
+			feedsLog.Warn("Feed failed", "url", feedURL, "error", err)
+			failures++
+			continue
+		}
+		items = append(items, feedItems...)
+	}
+
+	if failures > 0 && failures == len(cfg.Feeds) {
+		return nil, fmt.Errorf("all %d feeds failed", failures)
+	}
+
+	return mergeFeedItems(items), nil
+}
+
+// mergeFeedItems removes duplicate entries (by link, or title when there is no link),
+// sorts the remainder newest first and keeps at most feedMaxItems.
+func mergeFeedItems(items []FeedItem) FeedHeadlines {
+	seen := make(map[string]bool, len(items))
+	headlines := FeedHeadlines{}
+
+	for _, item := range items {
+		key := item.Link
+		if key == "" {
+			key = strings.ToLower(item.Title)
+		}
+		if item.Title == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		headlines = append(headlines, item)
+	}
+
+	sort.SliceStable(headlines, func(i, j int) bool {
+		return headlines[i].Published.After(headlines[j].Published)
+	})
+
+	if len(headlines) > feedMaxItems {
+		headlines = headlines[:feedMaxItems]
+	}
+
+	return headlines
+}
+
+// GetFeed downloads and parses an RSS 2.0 or Atom feed.
+func GetFeed(ctx context.Context, feedURL string) ([]FeedItem, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Nexus Next/1.0")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Both formats are decoded in one pass: RSS nests items in <channel>,
+	// Atom places <entry> elements directly under <feed>.
+	var feed struct {
+		XMLName xml.Name
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title   string `xml:"title"`
+				Link    string `xml:"link"`
+				GUID    string `xml:"guid"`
+				PubDate string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+		Title   string `xml:"title"`
+		Entries []struct {
+			Title string `xml:"title"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+		} `xml:"entry"`
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	var items []FeedItem
+	switch feed.XMLName.Local {
+	case "rss":
+		for _, item := range feed.Channel.Items {
+			link := item.Link
+			if link == "" {
+				link = item.GUID
+			}
+			items = append(items, FeedItem{
+				Title:     strings.TrimSpace(item.Title),
+				Link:      link,
+				Source:    strings.TrimSpace(feed.Channel.Title),
+				Published: parseFeedTime(item.PubDate),
+			})
+		}
+	case "feed":
+		for _, entry := range feed.Entries {
+			link := entry.ID
+			for _, l := range entry.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = l.Href
+					break
+				}
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			items = append(items, FeedItem{
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      link,
+				Source:    strings.TrimSpace(feed.Title),
+				Published: parseFeedTime(published),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format %q", feed.XMLName.Local)
+	}
+
+	return items, nil
+}
+
+// parseFeedTime parses the date formats commonly found in RSS and Atom feeds,
+// returning the zero time if none match.
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
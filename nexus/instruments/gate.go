@@ -0,0 +1,70 @@
+package instruments
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate pauses goroutines while it is closed and releases them when it opens.
+// It is used to stop sampling instruments while the device is disconnected
+// without spinning.
+type Gate struct {
+	mu   sync.Mutex
+	open bool
+	ch   chan struct{} // Closed while the gate is open
+}
+
+// NewGate creates a gate in the given initial state.
+func NewGate(open bool) *Gate {
+	g := &Gate{open: open, ch: make(chan struct{})}
+	if open {
+		close(g.ch)
+	}
+	return g
+}
+
+// Open releases all goroutines blocked in Wait. Opening an open gate is a no-op.
+func (g *Gate) Open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.open {
+		g.open = true
+		close(g.ch)
+	}
+}
+
+// Close causes subsequent calls to Wait to block until the gate is opened again.
+// Closing a closed gate is a no-op.
+func (g *Gate) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.open {
+		g.open = false
+		g.ch = make(chan struct{})
+	}
+}
+
+// IsOpen reports whether the gate is currently open.
+func (g *Gate) IsOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.open
+}
+
+// Wait blocks until the gate is open or ctx is cancelled, in which case the
+// context error is returned.
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	ch := g.ch
+	g.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+package instruments
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// History is an in-memory store that keeps the most recent readings of every
+// instrument in a fixed-size ring buffer per instrument. Readings older than the
+// retention window are ignored by queries and eventually overwritten.
+//
+// History is safe for concurrent use.
+type History struct {
+	mu        sync.RWMutex
+	retention time.Duration
+	capacity  int
+	series    map[string]*series
+}
+
+// series is a ring buffer of readings for a single instrument.
+type series struct {
+	readings []Reading
+	next     int  // Index of the slot that will be written next
+	full     bool // Whether the buffer has wrapped around
+}
+
+// NewHistory creates a history store that retains readings for the given
+// duration, holding at most capacity readings per instrument.
+func NewHistory(retention time.Duration, capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &History{
+		retention: retention,
+		capacity:  capacity,
+		series:    make(map[string]*series),
+	}
+}
+
+// Record stores a reading, overwriting the oldest reading of the same
+// instrument when its buffer is full.
+func (h *History) Record(reading Reading) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[reading.Name]
+	if !ok {
+		s = &series{readings: make([]Reading, h.capacity)}
+		h.series[reading.Name] = s
+	}
+
+	s.readings[s.next] = reading
+	s.next = (s.next + 1) % len(s.readings)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// Range returns the readings of the named instrument taken between from and to
+// (inclusive), oldest first. Readings outside the retention window are never returned.
+func (h *History) Range(name string, from, to time.Time) []Reading {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s, ok := h.series[name]
+	if !ok {
+		return nil
+	}
+
+	if cutoff := time.Now().Add(-h.retention); from.Before(cutoff) {
+		from = cutoff
+	}
+
+	var result []Reading
+	for _, reading := range s.ordered() {
+		if reading.Time.Before(from) || reading.Time.After(to) {
+			continue
+		}
+		result = append(result, reading)
+	}
+
+	return result
+}
+
+// Since returns the readings of the named instrument taken within the last d, oldest first.
+func (h *History) Since(name string, d time.Duration) []Reading {
+	now := time.Now()
+	return h.Range(name, now.Add(-d), now)
+}
+
+// Latest returns the most recent reading of the named instrument, if any.
+func (h *History) Latest(name string) (Reading, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s, ok := h.series[name]
+	if !ok || (s.next == 0 && !s.full) {
+		return Reading{}, false
+	}
+
+	return s.readings[(s.next-1+len(s.readings))%len(s.readings)], true
+}
+
+// Names returns the sorted names of all instruments with recorded readings.
+func (h *History) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, 0, len(h.series))
+	for name := range h.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ordered returns the buffered readings from oldest to newest.
+func (s *series) ordered() []Reading {
+	if !s.full {
+		return s.readings[:s.next]
+	}
+	return append(append([]Reading(nil), s.readings[s.next:]...), s.readings[:s.next]...)
+}
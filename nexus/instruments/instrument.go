@@ -0,0 +1,68 @@
+package instruments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Value is a single sample produced by an Instrument. Consumers type-switch on
+// the concrete type (SystemTemperature, NetworkStats, *WeatherInfo, ...).
+type Value interface{}
+
+// Instrument is a data source that can be sampled periodically by a Scheduler.
+//
+// Implementations only need to know how to take one measurement; scheduling,
+// connection gating and delivery of the result are handled by the Scheduler.
+type Instrument interface {
+	// Name returns a unique, stable identifier for the instrument (e.g. "weather").
+	Name() string
+
+	// Interval returns how often the instrument should be sampled.
+	Interval() time.Duration
+
+	// Sample takes a single measurement. It should honour ctx cancellation
+	// where the underlying source allows it.
+	Sample(ctx context.Context) (Value, error)
+}
+
+// Reading is a sample emitted by the Scheduler for a registered instrument.
+type Reading struct {
+	Name  string    `json:"name"`
+	Value Value     `json:"value"`
+	Time  time.Time `json:"time"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Instrument
+)
+
+// Register makes an instrument available to schedulers created with Registered.
+// It panics if an instrument with the same name is already registered, since
+// that is always a programming error.
+func Register(instrument Instrument) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if instrument == nil {
+		panic("instruments: Register instrument is nil")
+	}
+
+	for _, existing := range registry {
+		if existing.Name() == instrument.Name() {
+			panic(fmt.Sprintf("instruments: Register called twice for %q", instrument.Name()))
+		}
+	}
+
+	registry = append(registry, instrument)
+}
+
+// Registered returns a snapshot of all registered instruments in registration order.
+func Registered() []Instrument {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Instrument(nil), registry...)
+}
@@ -0,0 +1,245 @@
+package instruments
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	KeyboardInstrumentName = "keyboard"
+
+	keyboardUpdateInterval = 500 * time.Millisecond
+	keyboardLayoutInterval = 5 * time.Second // How often Sample reads the keyboard layout
+)
+
+// KeyboardState holds the lock keys and the active layout of the keyboard.
+type KeyboardState struct {
+	CapsLock bool
+	NumLock  bool
+	Layout   string // Short layout name, e.g. "US" or "DE", empty if unknown
+}
+
+// Metrics exposes the lock keys as "caps_lock" and "num_lock", 1 while on.
+func (k KeyboardState) Metrics() map[string]float64 {
+	metrics := map[string]float64{"caps_lock": 0, "num_lock": 0}
+	if k.CapsLock {
+		metrics["caps_lock"] = 1
+	}
+	if k.NumLock {
+		metrics["num_lock"] = 1
+	}
+	return metrics
+}
+
+func init() {
+	Register(&KeyboardInstrument{})
+}
+
+// KeyboardInstrument samples the Caps Lock and Num Lock state and the keyboard layout.
+// The layout needs external commands on Linux and macOS, so it is only read every
+// keyboardLayoutInterval.
+type KeyboardInstrument struct {
+	layout   string    // Layout of the last read, only accessed by Sample
+	layoutAt time.Time // When layout was read
+}
+
+func (k *KeyboardInstrument) Name() string { return KeyboardInstrumentName }
+
+func (k *KeyboardInstrument) Interval() time.Duration { return keyboardUpdateInterval }
+
+// Sample reads the lock keys and, when it is due, the keyboard layout.
+func (k *KeyboardInstrument) Sample(ctx context.Context) (Value, error) {
+	state, err := getLockKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Since(k.layoutAt) >= keyboardLayoutInterval {
+		k.layout, k.layoutAt = getKeyboardLayout(ctx), time.Now()
+	}
+	state.Layout = k.layout
+
+	return state, nil
+}
+
+// GetKeyboard returns the state of the lock keys and the active keyboard layout.
+// For Linux: Reads the lock LEDs from /sys/class/leds and the layout with xkb-switch
+// or setxkbmap
+// For Windows: Reads the key state and the layout of the foreground window natively
+// For macOS: Follows the modifier flags with a long-lived osascript process and
+// reads the layout from the HIToolbox preferences; Macs have no Num Lock
+// Returns an error if the operating system is not supported or the lock keys cannot be read.
+func GetKeyboard(ctx context.Context) (KeyboardState, error) {
+	state, err := getLockKeys()
+	if err != nil {
+		return KeyboardState{}, err
+	}
+
+	state.Layout = getKeyboardLayout(ctx)
+	return state, nil
+}
+
+// getLockKeys returns the state of the lock keys without the layout.
+func getLockKeys() (KeyboardState, error) {
+	switch runtime.GOOS {
+	case "linux":
+		// Every keyboard has its own LEDs, e.g. input3::capslock; any lit one counts,
+		// which works without a display server
+		capsLEDs, _ := filepath.Glob("/sys/class/leds/*::capslock/brightness")
+		numLEDs, _ := filepath.Glob("/sys/class/leds/*::numlock/brightness")
+		return KeyboardState{CapsLock: anyLEDOn(capsLEDs), NumLock: anyLEDOn(numLEDs)}, nil
+	case "windows":
+		state, err := getWindowsKeyboard()
+		return KeyboardState{CapsLock: state.CapsLock, NumLock: state.NumLock}, err
+	case "darwin":
+		capsLock, err := macCapsLock.get()
+		if err != nil {
+			return KeyboardState{}, fmt.Errorf("failed to get modifier flags: %v", err)
+		}
+		return KeyboardState{CapsLock: capsLock}, nil
+	default:
+		return KeyboardState{}, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// getKeyboardLayout returns the short name of the active keyboard layout, "" if it
+// cannot be read.
+func getKeyboardLayout(ctx context.Context) string {
+	switch runtime.GOOS {
+	case "linux":
+		return getXKBLayout(ctx)
+	case "windows":
+		state, _ := getWindowsKeyboard()
+		return state.Layout
+	case "darwin":
+		return getMacLayout(ctx)
+	default:
+		return ""
+	}
+}
+
+// anyLEDOn reports whether any of the LED brightness files is not 0.
+func anyLEDOn(paths []string) bool {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil && strings.TrimSpace(string(data)) != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// getXKBLayout returns the active layout of the X keyboard, "" if it cannot be read.
+// setxkbmap only lists the configured layouts, so its first one is used without
+// xkb-switch.
+func getXKBLayout(ctx context.Context) string {
+	if out, err := exec.CommandContext(ctx, "xkb-switch", "-p").Output(); err == nil {
+		return shortLayout(string(out))
+	}
+
+	// Output looks like "rules: evdev\nmodel: pc105\nlayout: us,de\n"
+	out, err := exec.CommandContext(ctx, "setxkbmap", "-query").Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if layouts, ok := strings.CutPrefix(line, "layout:"); ok {
+			layout, _, _ := strings.Cut(strings.TrimSpace(layouts), ",")
+			return shortLayout(layout)
+		}
+	}
+	return ""
+}
+
+// macLayoutPattern matches the name of the selected keyboard layout in the
+// HIToolbox preferences, e.g. "KeyboardLayout Name" = "U.S.";
+var macLayoutPattern = regexp.MustCompile(`"KeyboardLayout Name" = "?([^";]+)"?;`)
+
+// getMacLayout returns the selected keyboard layout of macOS, "" if it cannot be read.
+func getMacLayout(ctx context.Context) string {
+	out, err := exec.CommandContext(ctx, "defaults", "read", "com.apple.HIToolbox", "AppleSelectedInputSources").Output()
+	if err != nil {
+		return ""
+	}
+	if match := macLayoutPattern.FindStringSubmatch(string(out)); match != nil {
+		return shortLayout(match[1])
+	}
+	return ""
+}
+
+// macCapsLockScript prints the Caps Lock state, NSEventModifierFlagCapsLock (1 << 16),
+// whenever it changes. It checks every 500ms for a minute and then exits, so a
+// process outliving Nexus does not run forever.
+const macCapsLockScript = `ObjC.import('AppKit');
+var last;
+for (var i = 0; i < 120; i++) {
+  var on = ($.NSEvent.modifierFlags & (1 << 16)) != 0;
+  if (on !== last) { console.log(on); last = on; }
+  delay(0.5);
+}`
+
+// macCapsLock follows the Caps Lock state of macOS.
+var macCapsLock = &capsLockWatcher{}
+
+// capsLockWatcher runs macCapsLockScript in an osascript process, which is started
+// again by the next read once it exited. It is safe for concurrent use.
+type capsLockWatcher struct {
+	mu      sync.Mutex
+	running bool
+	on      bool // Latest state printed by the script
+}
+
+// get returns the latest Caps Lock state, starting the script if it is not running.
+func (w *capsLockWatcher) get() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.running {
+		cmd := exec.Command("osascript", "-l", "JavaScript", "-e", macCapsLockScript)
+		// console.log writes to stderr
+		output, err := cmd.StderrPipe()
+		if err != nil {
+			return false, err
+		}
+		if err := cmd.Start(); err != nil {
+			return false, err
+		}
+		w.running = true
+		go w.follow(cmd, output)
+	}
+	return w.on, nil
+}
+
+// follow records the states printed by the script until it exits.
+func (w *capsLockWatcher) follow(cmd *exec.Cmd, output io.Reader) {
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		w.mu.Lock()
+		w.on = strings.TrimSpace(scanner.Text()) == "true"
+		w.mu.Unlock()
+	}
+	cmd.Wait()
+
+	w.mu.Lock()
+	w.running = false
+	w.mu.Unlock()
+}
+
+// shortLayout returns the upper case layout name without variant, e.g. "DE" for
+// "de(nodeadkeys)" and "US" for "U.S.".
+func shortLayout(layout string) string {
+	layout = strings.TrimSpace(layout)
+	layout, _, _ = strings.Cut(layout, "(")
+	layout, _, _ = strings.Cut(layout, "-")
+	return strings.ToUpper(strings.ReplaceAll(layout, ".", ""))
+}
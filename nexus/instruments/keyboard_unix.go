@@ -0,0 +1,10 @@
+//go:build !windows
+
+package instruments
+
+import "fmt"
+
+// getWindowsKeyboard is only available on Windows.
+func getWindowsKeyboard() (KeyboardState, error) {
+	return KeyboardState{}, fmt.Errorf("unsupported operating system")
+}
@@ -0,0 +1,57 @@
+//go:build windows
+
+package instruments
+
+import (
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32                       = windows.NewLazySystemDLL("user32.dll")
+	procGetKeyState              = user32.NewProc("GetKeyState")
+	procGetForegroundWindow      = user32.NewProc("GetForegroundWindow")
+	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
+	procGetKeyboardLayout        = user32.NewProc("GetKeyboardLayout")
+	procLCIDToLocaleName         = windows.NewLazySystemDLL("kernel32.dll").NewProc("LCIDToLocaleName")
+)
+
+// Virtual key codes of the lock keys
+const (
+	vkCapital = 0x14
+	vkNumLock = 0x90
+)
+
+// getWindowsKeyboard reads the toggle state of the lock keys and the language of the
+// keyboard layout of the foreground window, as each thread has its own layout. A
+// service runs outside the user's session and only sees its own keyboard.
+func getWindowsKeyboard() (KeyboardState, error) {
+	state := KeyboardState{
+		CapsLock: keyToggled(vkCapital),
+		NumLock:  keyToggled(vkNumLock),
+	}
+
+	window, _, _ := procGetForegroundWindow.Call()
+	thread, _, _ := procGetWindowThreadProcessId.Call(window, 0)
+	layout, _, _ := procGetKeyboardLayout.Call(thread)
+	if layout == 0 {
+		return state, nil
+	}
+
+	// The low word of the layout handle is the language identifier of a locale such
+	// as "de-CH", whose region names the layout like on the other systems
+	name := make([]uint16, 85)
+	if n, _, _ := procLCIDToLocaleName.Call(layout&0xffff, uintptr(unsafe.Pointer(&name[0])), uintptr(len(name)), 0); n > 0 {
+		locale := windows.UTF16ToString(name)
+		state.Layout = shortLayout(locale[strings.LastIndex(locale, "-")+1:])
+	}
+	return state, nil
+}
+
+// keyToggled reports whether the lock key with the virtual key code is on.
+func keyToggled(key uintptr) bool {
+	ret, _, _ := procGetKeyState.Call(key)
+	return ret&1 != 0
+}
@@ -0,0 +1,203 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nexus-open/nexus/configuration"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	nominatimCandidatesURL = "https://nominatim.openstreetmap.org/search?q=%s&format=json&limit=%d"
+	nominatimCandidates    = 5   // Matches returned by SearchLocations
+	geocodeAmbiguity       = 0.1 // Importance difference below which the best matches are ambiguous
+
+	ipGeolocationURL = "https://ipapi.co/json/"
+	ipGeolocationTTL = time.Hour // How long a detected location is reused
+)
+
+// Location is a resolved place with its display name and coordinates.
+type Location struct {
+	Name string
+	Lat  float64
+	Lon  float64
+}
+
+var (
+	detectedMu       sync.Mutex
+	detectedLocation *Location
+	detectedAt       time.Time
+)
+
+// ResolveLocation turns a configured location into coordinates. It accepts:
+//   - "auto": the location is detected from the public IP address, which is
+//     sent to ipapi.co, so it is only used when configured explicitly
+//   - "lat,lon": explicit coordinates, e.g. "40.7128,-74.0060"
+//   - anything else: a place name that is geocoded with Nominatim
+func ResolveLocation(ctx context.Context, location string) (Location, error) {
+	if strings.EqualFold(strings.TrimSpace(location), configuration.LocationAuto) {
+		return DetectLocation(ctx)
+	}
+
+	if lat, lon, ok := parseCoordinates(location); ok {
+		return Location{Name: location, Lat: lat, Lon: lon}, nil
+	}
+
+	lat, lon, err := GetCityCoordinates(ctx, location)
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Name: location, Lat: lat, Lon: lon}, nil
+}
+
+// DetectLocation determines the current location from the public IP address.
+// The result is cached for ipGeolocationTTL to stay within the provider's rate limits.
+func DetectLocation(ctx context.Context) (Location, error) {
+	detectedMu.Lock()
+	defer detectedMu.Unlock()
+
+	if detectedLocation != nil && time.Since(detectedAt) < ipGeolocationTTL {
+		return *detectedLocation, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ipGeolocationURL, nil)
+
+	if err != nil {
+		return Location{}, err
+	}
+
+	req.Header.Set("User-Agent", "Nexus Next/1.0")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return Location{}, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Location{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Error       bool    `json:"error"`
+		Reason      string  `json:"reason"`
+		City        string  `json:"city"`
+		RegionName  string  `json:"region"`
+		CountryCode string  `json:"country_code"`
+		Lat         float64 `json:"latitude"`
+		Lon         float64 `json:"longitude"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Location{}, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	if result.Error {
+		return Location{}, fmt.Errorf("location detection failed: %s", result.Reason)
+	}
+
+	name := result.City
+	if result.RegionName != "" {
+		name += ", " + result.RegionName
+	} else if result.CountryCode != "" {
+		name += ", " + result.CountryCode
+	}
+
+	detectedLocation = &Location{Name: name, Lat: result.Lat, Lon: result.Lon}
+	detectedAt = time.Now()
+
+	return *detectedLocation, nil
+}
+
+// IsPlaceName reports whether location is a place name to geocode, rather than
+// "auto" or coordinates.
+func IsPlaceName(location string) bool {
+	location = strings.TrimSpace(location)
+	if location == "" || strings.EqualFold(location, configuration.LocationAuto) {
+		return false
+	}
+	_, _, ok := parseCoordinates(location)
+	return !ok
+}
+
+// SearchLocations geocodes a place name with Nominatim, returning up to
+// nominatimCandidates matches, best first. It reports the name as ambiguous when
+// the best matches are about equally important, e.g. "Springfield", so the user
+// can pick one.
+func SearchLocations(ctx context.Context, query string) ([]Location, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(nominatimCandidatesURL, url.QueryEscape(query), nominatimCandidates), nil)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	req.Header.Set("User-Agent", "Nexus Next/1.0")
+
+	client := &http.Client{Timeout: weatherHTTPTimeout}
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		DisplayName string  `json:"display_name"`
+		Lat         string  `json:"lat"`
+		Lon         string  `json:"lon"`
+		Importance  float64 `json:"importance"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, false, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	locations := make([]Location, 0, len(results))
+	for _, result := range results {
+		lat, latErr := strconv.ParseFloat(result.Lat, 64)
+		lon, lonErr := strconv.ParseFloat(result.Lon, 64)
+		if latErr != nil || lonErr != nil {
+			continue
+		}
+		locations = append(locations, Location{Name: result.DisplayName, Lat: lat, Lon: lon})
+	}
+
+	ambiguous := len(results) > 1 && results[0].Importance-results[1].Importance < geocodeAmbiguity
+	return locations, ambiguous && len(locations) > 1, nil
+}
+
+// parseCoordinates parses a "lat,lon" string, returning false if it is not a
+// valid coordinate pair.
+func parseCoordinates(location string) (float64, float64, bool) {
+	parts := strings.Split(location, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, false
+	}
+
+	return lat, lon, true
+}
@@ -0,0 +1,19 @@
+package instruments
+
+import (
+	"nexus-open/nexus/logging"
+)
+
+// Loggers of the instruments.
+var (
+	schedulerLog  = logging.Component("instruments")
+	weatherLog    = logging.Component("weather")
+	mqttLog       = logging.Component("mqtt")
+	calendarLog   = logging.Component("calendar")
+	feedsLog      = logging.Component("feeds")
+	stocksLog     = logging.Component("stocks")
+	prometheusLog = logging.Component("prometheus")
+	octoprintLog  = logging.Component("octoprint")
+	smartLog      = logging.Component("smart")
+	pluginsLog    = logging.Component("plugins")
+)
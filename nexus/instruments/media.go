@@ -0,0 +1,607 @@
+package instruments
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // Register JPEG format
+	_ "image/png"  // Register PNG format
+	"io"
+	"net/http"
+	"net/url"
+	"nexus-open/nexus/configuration"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	MediaInstrumentName = "media"
+
+	mediaUpdateInterval = 2 * time.Second
+
+	spotifyTokenURL   = "https://accounts.spotify.com/api/token"
+	spotifyPlayerURL  = "https://api.spotify.com/v1/me/player"
+	spotifyPlayerName = "Spotify Web"
+
+	// AlbumArtSize is the width and height of album art thumbnails in pixels
+	AlbumArtSize = 48
+
+	albumArtMaxBytes  = 10 << 20         // Largest album art image read
+	albumArtMaxPixels = 4096 * 4096      // Largest album art image decoded
+	albumArtRetry     = 10 * time.Second // Delay before loading failed album art again, doubled after each failure
+	albumArtMaxRetry  = 5 * time.Minute
+)
+
+// PlaybackStatus is the playback state of a media player.
+type PlaybackStatus string
+
+const (
+	PlaybackPlaying PlaybackStatus = "playing"
+	PlaybackPaused  PlaybackStatus = "paused"
+	PlaybackStopped PlaybackStatus = "stopped"
+)
+
+// NowPlaying describes the track of the active media player.
+type NowPlaying struct {
+	Title  string
+	Artist string
+	Player string // Name of the player application
+	Status PlaybackStatus
+	ArtURL string // Album art as a file, http or https URL, empty if unknown
+
+	// Art is the album art cropped to a square and scaled to AlbumArtSize, nil
+	// if there is none or it could not be loaded
+	Art *image.RGBA `json:"-"`
+}
+
+// MediaInstrument reads the track of the active media player.
+// For Linux: Uses playerctl to query MPRIS players over D-Bus
+// For Windows: Uses a long-lived PowerShell process to query the System Media
+// Transport Controls (SMTC)
+// When no local player is active and Spotify credentials are configured, the
+// Spotify Web API is queried instead. Its value is a *NowPlaying, nil if nothing
+// is playing. Album art is read from MPRIS and Spotify.
+type MediaInstrument struct {
+	getConfig func() *configuration.NexusConfig
+
+	mu           sync.Mutex
+	lastPlayer   string    // Player of the last sample, used by TogglePlayback
+	spotifyToken string    // Cached Spotify access token
+	spotifyUntil time.Time // Expiry of spotifyToken
+
+	artURL     string        // URL of art, only accessed by Sample
+	art        *image.RGBA   // Thumbnail of the last album art, nil if it failed to load
+	artRetry   time.Time     // When art that failed to load is loaded again
+	artBackoff time.Duration // Delay before the next retry after artRetry
+}
+
+// NewMediaInstrument creates a media instrument that reads the optional Spotify
+// credentials from the configuration returned by getConfig. getConfig must not be nil.
+func NewMediaInstrument(getConfig func() *configuration.NexusConfig) *MediaInstrument {
+	if getConfig == nil {
+		panic("instruments: NewMediaInstrument getConfig is nil")
+	}
+
+	return &MediaInstrument{getConfig: getConfig}
+}
+
+func (m *MediaInstrument) Name() string { return MediaInstrumentName }
+
+func (m *MediaInstrument) Interval() time.Duration { return mediaUpdateInterval }
+
+// Sample reads the track and playback state of the active player.
+func (m *MediaInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := m.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	playing, err := getLocalNowPlaying(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if playing == nil && cfg.Media.Spotify.Enabled() {
+		if playing, err = m.getSpotifyNowPlaying(ctx, cfg.Media.Spotify); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	m.lastPlayer = ""
+	if playing != nil {
+		m.lastPlayer = playing.Player
+	}
+	m.mu.Unlock()
+
+	if playing != nil {
+		playing.Art = m.albumArt(ctx, playing.ArtURL)
+	}
+
+	return playing, nil
+}
+
+// albumArt returns the thumbnail of the album art at artURL, loading it only when
+// the URL changed since the last call. Art that fails to load is retried after
+// albumArtRetry, backing off up to albumArtMaxRetry.
+func (m *MediaInstrument) albumArt(ctx context.Context, artURL string) *image.RGBA {
+	if artURL == m.artURL && (m.art != nil || time.Now().Before(m.artRetry)) {
+		return m.art
+	}
+
+	if artURL != m.artURL {
+		m.artURL, m.art, m.artBackoff = artURL, nil, albumArtRetry
+	}
+	if artURL == "" {
+		return nil
+	}
+
+	art, err := loadAlbumArt(ctx, artURL)
+	if err != nil {
+		schedulerLog.Debug("Failed to load album art", "url", artURL, "error", err, "retry", m.artBackoff)
+		m.artRetry = time.Now().Add(m.artBackoff)
+		m.artBackoff = min(2*m.artBackoff, albumArtMaxRetry)
+		return nil
+	}
+	m.art = art
+	return art
+}
+
+// loadAlbumArt reads the image at artURL, a file, http or https URL, and returns
+// its center square scaled to AlbumArtSize.
+func loadAlbumArt(ctx context.Context, artURL string) (*image.RGBA, error) {
+	u, err := url.Parse(artURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	switch u.Scheme {
+	case "file":
+		if body, err = os.Open(u.Path); err != nil {
+			return nil, err
+		}
+	case "http", "https":
+		req, err := http.NewRequestWithContext(ctx, "GET", artURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		body = resp.Body
+	default:
+		return nil, fmt.Errorf("unsupported album art URL scheme %q", u.Scheme)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, albumArtMaxBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the dimensions first, a small file may decode to a huge image
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode album art: %w", err)
+	}
+	if config.Width*config.Height > albumArtMaxPixels {
+		return nil, fmt.Errorf("album art of %dx%d pixels is too large", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode album art: %w", err)
+	}
+
+	// Crop the center square, most covers are square already
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	offset := image.Pt((bounds.Dx()-side)/2, (bounds.Dy()-side)/2)
+	draw.Draw(square, square.Bounds(), img, bounds.Min.Add(offset), draw.Src)
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, AlbumArtSize, AlbumArtSize))
+	draw.Draw(thumbnail, thumbnail.Bounds(), resize.Resize(AlbumArtSize, AlbumArtSize, square, resize.Lanczos3), image.Point{}, draw.Src)
+	return thumbnail, nil
+}
+
+// TogglePlayback pauses or resumes the player reported by the last sample.
+func (m *MediaInstrument) TogglePlayback(ctx context.Context) error {
+	m.mu.Lock()
+	player := m.lastPlayer
+	m.mu.Unlock()
+
+	if player == spotifyPlayerName {
+		cfg := m.getConfig()
+		if cfg == nil {
+			return fmt.Errorf("no config available")
+		}
+		return m.toggleSpotifyPlayback(ctx, cfg.Media.Spotify)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if err := exec.CommandContext(ctx, "playerctl", "play-pause").Run(); err != nil {
+			return fmt.Errorf("failed to toggle playback: %v", err)
+		}
+		return nil
+	case "windows":
+		if _, err := windowsMedia.request(ctx, "toggle"); err != nil {
+			return fmt.Errorf("failed to toggle playback: %v", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// getLocalNowPlaying returns the track of the active local player, or nil if no
+// player is running or the platform has no supported media interface.
+func getLocalNowPlaying(ctx context.Context) (*NowPlaying, error) {
+	var (
+		out []byte
+		err error
+	)
+
+	switch runtime.GOOS {
+	case "linux":
+		out, err = exec.CommandContext(ctx, "playerctl", "metadata", "--format",
+			"{{status}}\t{{artist}}\t{{title}}\t{{playerName}}\t{{mpris:artUrl}}").Output()
+		if err != nil {
+			// playerctl exits with an error when no player is running
+			return nil, nil
+		}
+	case "windows":
+		line, err := windowsMedia.request(ctx, "get")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get media session: %v", err)
+		}
+		out = []byte(line)
+	default:
+		return nil, nil
+	}
+
+	line := strings.TrimSpace(string(out))
+	if line == "" {
+		return nil, nil
+	}
+
+	// Only playerctl prints the album art URL
+	parts := strings.Split(line, "\t")
+	if len(parts) != 4 && len(parts) != 5 {
+		return nil, fmt.Errorf("invalid output format")
+	}
+
+	playing := &NowPlaying{
+		Artist: strings.TrimSpace(parts[1]),
+		Title:  strings.TrimSpace(parts[2]),
+		Player: strings.TrimSpace(parts[3]),
+		Status: parsePlaybackStatus(parts[0]),
+	}
+	if len(parts) == 5 {
+		playing.ArtURL = strings.TrimSpace(parts[4])
+	}
+
+	if playing.Status == PlaybackStopped || playing.Title == "" {
+		return nil, nil
+	}
+
+	return playing, nil
+}
+
+// parsePlaybackStatus maps MPRIS ("Playing") and SMTC ("Playing", "Paused",
+// "Stopped", "Closed", ...) status names to a PlaybackStatus.
+func parsePlaybackStatus(status string) PlaybackStatus {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "playing":
+		return PlaybackPlaying
+	case "paused":
+		return PlaybackPaused
+	default:
+		return PlaybackStopped
+	}
+}
+
+// windowsMediaScript answers one request per line of its input for the current SMTC
+// session, so the WinRT types are only loaded once: "get" prints
+// "<status>\t<artist>\t<title>\t<app>" and "toggle" toggles play/pause and prints
+// "ok". Without a session it prints an empty line, failures print "error\t<message>".
+// It exits when its input is closed.
+const windowsMediaScript = `
+[Console]::OutputEncoding = [Text.Encoding]::UTF8
+Add-Type -AssemblyName System.Runtime.WindowsRuntime
+$asTask = ([System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object {
+  $_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -eq 'IAsyncOperation` + "`" + `1'
+})[0]
+function Await($op, [Type]$type) {
+  $task = $asTask.MakeGenericMethod($type).Invoke($null, @($op))
+  $task.Wait(-1) | Out-Null
+  $task.Result
+}
+function Reply($line) { [Console]::Out.WriteLine($line); [Console]::Out.Flush() }
+$managerType = [Windows.Media.Control.GlobalSystemMediaTransportControlsSessionManager, Windows.Media.Control, ContentType = WindowsRuntime]
+$propsType = [Windows.Media.Control.GlobalSystemMediaTransportControlsSessionMediaProperties, Windows.Media.Control, ContentType = WindowsRuntime]
+$manager = Await ($managerType::RequestAsync()) ($managerType)
+while (($request = [Console]::In.ReadLine()) -ne $null) {
+  try {
+    $session = $manager.GetCurrentSession()
+    if ($session -eq $null) { Reply ''; continue }
+    if ($request -eq 'toggle') {
+      Await ($session.TryTogglePlayPauseAsync()) ([bool]) | Out-Null
+      Reply 'ok'
+    } else {
+      $props = Await ($session.TryGetMediaPropertiesAsync()) ($propsType)
+      Reply ("{0}` + "`t" + `{1}` + "`t" + `{2}` + "`t" + `{3}" -f $session.GetPlaybackInfo().PlaybackStatus, $props.Artist, $props.Title, $session.SourceAppUserModelId)
+    }
+  } catch {
+    Reply ("error` + "`t" + `{0}" -f $_.Exception.Message)
+  }
+}
+`
+
+// windowsMedia is the PowerShell process running windowsMediaScript, started by the
+// first request.
+var windowsMedia = &powerShellSession{script: windowsMediaScript}
+
+// powerShellSession is a long-lived PowerShell process that answers requests with
+// one line each. It is restarted by the next request after it failed.
+type powerShellSession struct {
+	script string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Scanner
+}
+
+// request writes the request line to the process and returns its reply line. The
+// process is killed if ctx is done before it replied.
+func (p *powerShellSession) request(ctx context.Context, request string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil {
+		if err := p.start(); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := fmt.Fprintln(p.stdin, request); err != nil {
+		p.stop()
+		return "", err
+	}
+
+	var line string
+	replied := make(chan bool, 1)
+	go func() {
+		ok := p.stdout.Scan()
+		line = p.stdout.Text()
+		replied <- ok
+	}()
+
+	select {
+	case ok := <-replied:
+		if !ok {
+			p.stop()
+			return "", fmt.Errorf("powershell exited")
+		}
+	case <-ctx.Done():
+		p.stop()
+		<-replied
+		return "", ctx.Err()
+	}
+
+	if message, failed := strings.CutPrefix(line, "error\t"); failed {
+		return "", fmt.Errorf("%s", message)
+	}
+	return line, nil
+}
+
+func (p *powerShellSession) start() error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", p.script)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start powershell: %v", err)
+	}
+
+	p.cmd, p.stdin, p.stdout = cmd, stdin, bufio.NewScanner(stdout)
+	return nil
+}
+
+func (p *powerShellSession) stop() {
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	p.cmd.Wait()
+	p.cmd = nil
+}
+
+// spotifyAccessToken returns a cached access token, refreshing it when it expires.
+func (m *MediaInstrument) spotifyAccessToken(ctx context.Context, spotify configuration.SpotifyConfig) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.spotifyToken != "" && time.Now().Before(m.spotifyUntil) {
+		return m.spotifyToken, nil
+	}
+
+	var err error
+	for _, value := range []*string{&spotify.ClientID, &spotify.ClientSecret, &spotify.RefreshToken} {
+		if *value, err = configuration.ResolveSecret(*value); err != nil {
+			return "", err
+		}
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {spotify.RefreshToken},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", spotifyTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(spotify.ClientID, spotify.ClientSecret)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("spotify token refresh failed: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode spotify token: %w", err)
+	}
+
+	// Refresh a minute early so a token never expires mid-request
+	m.spotifyToken = result.AccessToken
+	m.spotifyUntil = time.Now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+
+	return m.spotifyToken, nil
+}
+
+// spotifyRequest sends an authorized request to the Spotify player API.
+func (m *MediaInstrument) spotifyRequest(ctx context.Context, spotify configuration.SpotifyConfig, method, endpoint string) (*http.Response, error) {
+	token, err := m.spotifyAccessToken(ctx, spotify)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, spotifyPlayerURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	return client.Do(req)
+}
+
+func (m *MediaInstrument) getSpotifyNowPlaying(ctx context.Context, spotify configuration.SpotifyConfig) (*NowPlaying, error) {
+	resp, err := m.spotifyRequest(ctx, spotify, "GET", "/currently-playing")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// No content means nothing is playing on any device
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		IsPlaying bool `json:"is_playing"`
+		Item      *struct {
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+			Album struct {
+				Images []struct {
+					URL   string `json:"url"`
+					Width int    `json:"width"`
+				} `json:"images"`
+			} `json:"album"`
+		} `json:"item"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode spotify playback: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	artists := make([]string, 0, len(result.Item.Artists))
+	for _, artist := range result.Item.Artists {
+		artists = append(artists, artist.Name)
+	}
+
+	status := PlaybackPaused
+	if result.IsPlaying {
+		status = PlaybackPlaying
+	}
+
+	// Images are sorted widest first, use the smallest one that is large enough
+	var artURL string
+	for _, img := range result.Item.Album.Images {
+		if artURL == "" || img.Width >= AlbumArtSize {
+			artURL = img.URL
+		}
+	}
+
+	return &NowPlaying{
+		Title:  result.Item.Name,
+		Artist: strings.Join(artists, ", "),
+		Player: spotifyPlayerName,
+		Status: status,
+		ArtURL: artURL,
+	}, nil
+}
+
+func (m *MediaInstrument) toggleSpotifyPlayback(ctx context.Context, spotify configuration.SpotifyConfig) error {
+	playing, err := m.getSpotifyNowPlaying(ctx, spotify)
+	if err != nil {
+		return err
+	}
+
+	endpoint := "/play"
+	if playing != nil && playing.Status == PlaybackPlaying {
+		endpoint = "/pause"
+	}
+
+	resp, err := m.spotifyRequest(ctx, spotify, "PUT", endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("spotify %s failed: status %d", strings.TrimPrefix(endpoint, "/"), resp.StatusCode)
+	}
+
+	return nil
+}
@@ -1,9 +1,9 @@
 package instruments
 
 import (
-	"log"
+	"context"
+	"fmt"
 	"nexus-open/nexus/configuration"
-	"sync/atomic"
 	"time"
 )
 
@@ -13,6 +13,13 @@ const (
 	networkUpdateInterval = 1 * time.Second
 )
 
+// Names of the built-in instruments
+const (
+	TemperatureInstrumentName = "temperature"
+	NetworkInstrumentName     = "network"
+	WeatherInstrumentName     = "weather"
+)
+
 type SystemTemperature struct {
 	CPU float64
 	GPU float64
@@ -23,199 +30,131 @@ type NetworkStats struct {
 	Received int
 }
 
-// WeatherState holds current weather data and update status
-type WeatherState struct {
-	lastLocation string
-	info         *WeatherInfo
-	updating     atomic.Bool
+func init() {
+	Register(&TemperatureInstrument{})
+	Register(&NetworkInstrument{})
 }
 
-// StartWeatherMonitor initializes and runs a weather monitoring service in the background.
-// It periodically fetches weather data based on the location specified in the configuration.
-//
-// Parameters:
-//   - getConfig: A function that returns the current NexusConfig. Must not be nil.
-//   - connected: A pointer to a boolean indicating if the system is currently connected.
-//
-// Returns:
-//   - A receive-only channel that provides WeatherInfo updates
-//   - A send-only channel to request immediate weather updates
-//
-// The monitor runs in a goroutine and will:
-//   - Update weather data periodically based on weatherUpdateInterval
-//   - Update immediately when requested through the update channel
-//   - Update when location changes in configuration
-//   - Only update when system is connected
-//   - Use atomic operations to prevent concurrent updates
-func StartWeatherMonitor(
-	getConfig func() *configuration.NexusConfig,
-	connected *bool,
-) (chan *WeatherInfo, chan<- struct{}) {
-	if getConfig == nil {
-		log.Fatal("Weather monitor: config getter function is required")
+// TemperatureInstrument samples CPU and GPU temperatures.
+type TemperatureInstrument struct{}
+
+func (t *TemperatureInstrument) Name() string { return TemperatureInstrumentName }
+
+func (t *TemperatureInstrument) Interval() time.Duration { return tempUpdateInterval }
+
+// Sample reads the current CPU and GPU temperatures. If either reading fails
+// no value is produced and the error is returned.
+func (t *TemperatureInstrument) Sample(ctx context.Context) (Value, error) {
+	cpu, err := GetCPUTemp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CPU temperature: %v", err)
 	}
 
-	weatherChan := make(chan *WeatherInfo, 1)
+	gpu, err := GetGPUTemp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GPU temperature: %v", err)
+	}
 
-	updateChan := make(chan struct{}, 1)
+	return SystemTemperature{
+		CPU: cpu,
+		GPU: gpu,
+	}, nil
+}
 
-	state := &WeatherState{}
+// NetworkInstrument samples combined network throughput across all interfaces.
+type NetworkInstrument struct{}
 
-	go func() {
-		ticker := time.NewTicker(weatherUpdateInterval)
-		defer ticker.Stop()
+func (n *NetworkInstrument) Name() string { return NetworkInstrumentName }
 
-		// Weather update handler
-		updateWeather := func() {
-			if !state.updating.CompareAndSwap(false, true) {
-				return // Already updating
-			}
+func (n *NetworkInstrument) Interval() time.Duration { return networkUpdateInterval }
 
-			defer state.updating.Store(false)
+// Sample measures sent and received throughput over a one second window.
+func (n *NetworkInstrument) Sample(ctx context.Context) (Value, error) {
+	sent, received, err := GetNetworkUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network usage: %v", err)
+	}
 
-			cfg := getConfig()
+	return NetworkStats{
+		Sent:     sent,
+		Received: received,
+	}, nil
+}
 
-			if cfg == nil {
-				log.Printf("Weather monitor: no config available")
-				return
-			}
+// WeatherInstrument samples current weather conditions for the configured location.
+type WeatherInstrument struct {
+	getConfig    func() *configuration.NexusConfig
+	lastLocation string
+	lastUnit     string
+	lastGood     *WeatherInfo // Last successful update for lastLocation and lastUnit
+}
 
-			// Always update if location changed
-			locationChanged := state.lastLocation != cfg.Location
+// NewWeatherInstrument creates a weather instrument that reads the location and
+// unit from the configuration returned by getConfig. getConfig must not be nil.
+func NewWeatherInstrument(getConfig func() *configuration.NexusConfig) *WeatherInstrument {
+	if getConfig == nil {
+		panic("instruments: NewWeatherInstrument getConfig is nil")
+	}
 
-			if locationChanged {
-				log.Printf("Weather monitor: location changed from %q to %q",
-					state.lastLocation, cfg.Location)
-				state.lastLocation = cfg.Location
-			}
+	return &WeatherInstrument{getConfig: getConfig}
+}
 
-			if cfg.Location == "" {
-				return
-			}
+func (w *WeatherInstrument) Name() string { return WeatherInstrumentName }
 
-			info := GetWeatherData(cfg.Location, &cfg.Unit)
+func (w *WeatherInstrument) Interval() time.Duration { return weatherUpdateInterval }
 
-			if info != nil {
-				state.info = info
-				log.Printf("Weather updated for %s: %.1f%s",
-					cfg.Location, info.Temperature,
-					map[string]string{"metric": "°C", "imperial": "°F"}[cfg.Unit])
-				select {
-				case weatherChan <- info:
-				default:
-				}
-			}
-		}
+// Sample fetches the weather for the currently configured location, logging
+// when the location has changed since the previous sample. If the update fails
+// and data for the same location is available, the last known data is returned
+// marked as stale instead of an error.
+func (w *WeatherInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := w.getConfig()
 
-		// Initial update
-		updateWeather()
-
-		// Periodic updates
-		for {
-			select {
-			case <-ticker.C:
-				if *connected {
-					updateWeather()
-				}
-			case <-updateChan:
-				// Immediate update when requested
-				if *connected {
-					log.Printf("Weather monitor: update requested")
-					updateWeather()
-				}
-			}
-		}
-	}()
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
 
-	return weatherChan, updateChan
-}
+	if w.lastLocation != cfg.Location {
+		weatherLog.Info("Location changed", "from", w.lastLocation, "to", cfg.Location)
+		w.lastLocation = cfg.Location
+		w.lastGood = nil
+	}
 
-// StartTempatureMonitor initializes and runs a temperature monitoring goroutine.
-// It takes a pointer to a boolean indicating connection status and returns a channel
-// that receives Temperature updates.
-//
-// The monitor continuously checks CPU and GPU temperatures when connected is true.
-// If either temperature check fails, it logs the error and retries after 1 second.
-// Successfully read temperatures are sent through the returned channel as Temperature structs.
-//
-// The monitoring runs in a separate goroutine and continues until the program terminates.
-// Temperature updates are sent at intervals defined by tempUpdateInterval.
-//
-// Parameters:
-//   - connected: *bool - Pointer to connection status flag
-//
-// Returns:
-//   - chan Temperature - Channel through which temperature updates are sent
-func StartTempatureMonitor(connected *bool) chan SystemTemperature {
-	systemTempChan := make(chan SystemTemperature)
-
-	go func() {
-		for {
-			if !*connected {
-				continue
-			}
-
-			cpu, err := GetCPUTemp()
-			if err != nil {
-				log.Printf("Failed to get CPU temperature: %v", err)
-				time.Sleep(tempUpdateInterval)
-				continue
-			}
-
-			gpu, err := GetGPUTemp()
-			if err != nil {
-				log.Printf("Failed to get GPU temperature: %v", err)
-				time.Sleep(tempUpdateInterval)
-				continue
-			}
-
-			systemTempChan <- SystemTemperature{
-				CPU: cpu,
-				GPU: gpu,
-			}
-			time.Sleep(tempUpdateInterval)
-		}
-	}()
+	if w.lastUnit != cfg.Unit {
+		w.lastUnit = cfg.Unit
+		w.lastGood = nil
+	}
 
-	return systemTempChan
-}
+	if cfg.Location == "" {
+		return nil, fmt.Errorf("no location configured")
+	}
+
+	var info *WeatherInfo
+	var err error
+	if lat, lon, ok := cfg.Geocoded.For(cfg.Location); ok {
+		// Coordinates chosen when the configuration was saved
+		info, err = GetWeatherAt(ctx, Location{Name: cfg.Location, Lat: lat, Lon: lon}, cfg.Unit)
+	} else {
+		info, err = GetWeatherData(ctx, cfg.Location, cfg.Unit)
+	}
 
-// StartNetworkMonitor initializes and starts a network monitoring goroutine.
-// It takes a pointer to a boolean that indicates connection status and returns
-// a channel that streams NetworkStats.
-//
-// The monitor continuously checks network usage when connected is true,
-// collecting sent and received bytes statistics. If network usage collection fails,
-// the error is logged and the monitor continues operation.
-//
-// The monitoring runs at intervals defined by networkUpdateInterval.
-// Network statistics are sent through the returned channel.
-//
-// Parameters:
-//   - connected: *bool - Pointer to connection status flag
-//
-// Returns:
-//   - chan NetworkStats - Channel streaming network statistics
-func StartNetworkMonitor(connected *bool) chan NetworkStats {
-	networkChan := make(chan NetworkStats)
-
-	go func() {
-		for {
-			if !*connected {
-				continue
-			}
-			sent, received, err := GetNetworkUsage()
-			if err != nil {
-				log.Printf("Failed to get network usage: %v", err)
-				continue
-			}
-			networkChan <- NetworkStats{
-				Sent:     sent,
-				Received: received,
-			}
-			time.Sleep(networkUpdateInterval)
+	if err != nil {
+		if w.lastGood == nil {
+			return nil, err
 		}
-	}()
 
-	return networkChan
+		weatherLog.Warn("Weather update failed, showing earlier data",
+			"error", err, "updated_at", w.lastGood.UpdatedAt.Format(time.Kitchen))
+
+		stale := *w.lastGood
+		stale.Stale = true
+		return &stale, nil
+	}
+
+	w.lastGood = info
+
+	weatherLog.Info("Weather updated", "location", cfg.Location,
+		"temperature", fmt.Sprintf("%.1f%s", info.Temperature, map[string]string{"metric": "°C", "imperial": "°F"}[cfg.Unit]))
+
+	return info, nil
 }
@@ -0,0 +1,530 @@
+package instruments
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"nexus-open/nexus/configuration"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+const (
+	MQTTInstrumentName = "mqtt"
+
+	mqttUpdateInterval = 1 * time.Second
+	mqttKeepAlive      = 30 * time.Second
+	mqttDialTimeout    = 10 * time.Second
+	mqttMaxBackoff     = time.Minute
+	mqttMaxPacketSize  = 1 << 20 // Largest packet accepted from the broker
+)
+
+// MQTT 3.1.1 control packet types
+const (
+	mqttConnect    = 1
+	mqttConnAck    = 2
+	mqttPublish    = 3
+	mqttPubAck     = 4
+	mqttSubscribe  = 8
+	mqttSubAck     = 9
+	mqttPingReq    = 12
+	mqttPingResp   = 13
+	mqttDisconnect = 14
+)
+
+// MQTTMessage is the rendered latest message of a subscribed topic.
+type MQTTMessage struct {
+	Topic string
+	Text  string // Payload rendered with the topic template
+	Value string // Raw payload
+}
+
+// MQTTMessages holds the latest message of every topic received so far, sorted by topic.
+type MQTTMessages []MQTTMessage
+
+// Metrics exposes every numeric payload under its topic name.
+func (m MQTTMessages) Metrics() map[string]float64 {
+	metrics := make(map[string]float64, len(m))
+	for _, message := range m {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(message.Value), 64); err == nil {
+			metrics[message.Topic] = v
+		}
+	}
+	return metrics
+}
+
+// MQTTInstrument keeps a connection to the configured MQTT broker, records the latest
+// message of each subscribed topic and publishes touch actions. Only QoS 0 is used.
+type MQTTInstrument struct {
+	getConfig func() *configuration.NexusConfig
+
+	mu       sync.Mutex
+	messages map[string]MQTTMessage
+	conn     net.Conn   // nil while disconnected
+	writeMu  sync.Mutex // Serializes packet writes on conn
+}
+
+// NewMQTTInstrument creates an MQTT instrument that reads the broker and topics from
+// the configuration returned by getConfig. getConfig must not be nil. The broker
+// connection is only made once Run is called.
+func NewMQTTInstrument(getConfig func() *configuration.NexusConfig) *MQTTInstrument {
+	if getConfig == nil {
+		panic("instruments: NewMQTTInstrument getConfig is nil")
+	}
+
+	return &MQTTInstrument{
+		getConfig: getConfig,
+		messages:  make(map[string]MQTTMessage),
+	}
+}
+
+func (m *MQTTInstrument) Name() string { return MQTTInstrumentName }
+
+func (m *MQTTInstrument) Interval() time.Duration { return mqttUpdateInterval }
+
+// Sample returns the latest message of every topic received so far.
+func (m *MQTTInstrument) Sample(ctx context.Context) (Value, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make(MQTTMessages, 0, len(m.messages))
+	for _, message := range m.messages {
+		messages = append(messages, message)
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Topic < messages[j].Topic })
+
+	return messages, nil
+}
+
+// Run connects to the configured broker and processes messages until ctx is
+// cancelled, reconnecting with exponential backoff when the connection fails.
+// While no broker is configured it waits for Reconnect to be called.
+func (m *MQTTInstrument) Run(ctx context.Context) {
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		cfg := m.getConfig()
+		if cfg == nil || cfg.MQTT.Broker == "" {
+			// Check again later, the broker may be configured at runtime
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		start := time.Now()
+		err := m.session(ctx, cfg.MQTT)
+		if ctx.Err() != nil {
+			return
+		}
+		mqttLog.Warn("Connection lost", "broker", cfg.MQTT.Broker, "error", err)
+
+		// Reset the backoff after a connection that was up for a while
+		if time.Since(start) > mqttMaxBackoff {
+			backoff = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, mqttMaxBackoff)
+	}
+}
+
+// Reconnect drops the current broker connection so Run reconnects with the current
+// configuration. Call it after the MQTT configuration changed.
+func (m *MQTTInstrument) Reconnect() {
+	m.mu.Lock()
+	conn := m.conn
+	m.messages = make(map[string]MQTTMessage)
+	m.mu.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// Publish sends payload to topic with QoS 0.
+func (m *MQTTInstrument) Publish(topic, payload string, retain bool) error {
+	m.mu.Lock()
+	conn := m.conn
+	m.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("not connected to broker")
+	}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, topic)
+	body.WriteString(payload)
+
+	flags := byte(0)
+	if retain {
+		flags = 1
+	}
+
+	return m.writePacket(conn, mqttPublish<<4|flags, body.Bytes())
+}
+
+// session runs a single broker connection: it connects, subscribes to the configured
+// topics and then reads messages until the connection fails or ctx is cancelled.
+func (m *MQTTInstrument) session(ctx context.Context, cfg configuration.MQTTConfig) error {
+	conn, err := dialMQTT(ctx, cfg.Broker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Close the connection on shutdown to unblock the reader
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	reader := bufio.NewReader(conn)
+
+	if err := m.connect(conn, reader, cfg); err != nil {
+		return err
+	}
+
+	templates := make([]*template.Template, len(cfg.Topics))
+	for i, topic := range cfg.Topics {
+		// Templates were checked by Validate, fall back to the raw value if not
+		tmpl, err := template.New(topic.Topic).Parse(topic.Template)
+		if err != nil || topic.Template == "" {
+			tmpl = nil
+		}
+		templates[i] = tmpl
+	}
+
+	if len(cfg.Topics) > 0 {
+		var body bytes.Buffer
+		binary.Write(&body, binary.BigEndian, uint16(1))
+		for _, topic := range cfg.Topics {
+			writeMQTTString(&body, topic.Topic)
+			body.WriteByte(0) // QoS 0
+		}
+		if err := m.writePacket(conn, mqttSubscribe<<4|2, body.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.conn = conn
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		if m.conn == conn {
+			m.conn = nil
+		}
+		m.mu.Unlock()
+	}()
+
+	mqttLog.Info("Connected", "broker", cfg.Broker)
+
+	// Keep the connection alive while idle
+	pingDone := make(chan struct{})
+	defer close(pingDone)
+	go func() {
+		ticker := time.NewTicker(mqttKeepAlive / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pingDone:
+				return
+			case <-ticker.C:
+				if err := m.writePacket(conn, mqttPingReq<<4, nil); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		// The broker disconnects after 1.5 keep-alive periods without traffic
+		conn.SetReadDeadline(time.Now().Add(mqttKeepAlive * 2))
+
+		header, body, err := readMQTTPacket(reader)
+		if err != nil {
+			return err
+		}
+
+		switch header >> 4 {
+		case mqttPublish:
+			topic, payload, packetID, err := parseMQTTPublish(header, body)
+			if err != nil {
+				return err
+			}
+			if packetID != 0 {
+				ack := make([]byte, 2)
+				binary.BigEndian.PutUint16(ack, packetID)
+				if err := m.writePacket(conn, mqttPubAck<<4, ack); err != nil {
+					return err
+				}
+			}
+			m.store(cfg.Topics, templates, topic, payload)
+		case mqttSubAck:
+			for _, code := range body[min(2, len(body)):] {
+				if code == 0x80 {
+					mqttLog.Warn("Broker rejected a subscription")
+				}
+			}
+		case mqttPingResp:
+		}
+	}
+}
+
+// connect sends CONNECT and waits for a successful CONNACK.
+func (m *MQTTInstrument) connect(conn net.Conn, reader *bufio.Reader, cfg configuration.MQTTConfig) error {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		id := make([]byte, 6)
+		rand.Read(id)
+		clientID = "nexus-" + hex.EncodeToString(id)
+	}
+
+	password, err := configuration.ResolveSecret(cfg.Password)
+	if err != nil {
+		return err
+	}
+
+	flags := byte(0x02) // Clean session
+	if cfg.Username != "" {
+		flags |= 0x80
+		if password != "" {
+			flags |= 0x40
+		}
+	}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(mqttKeepAlive/time.Second))
+	writeMQTTString(&body, clientID)
+	if flags&0x80 != 0 {
+		writeMQTTString(&body, cfg.Username)
+	}
+	if flags&0x40 != 0 {
+		writeMQTTString(&body, password)
+	}
+
+	if err := m.writePacket(conn, mqttConnect<<4, body.Bytes()); err != nil {
+		return err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(mqttDialTimeout))
+	header, ack, err := readMQTTPacket(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+
+	if header>>4 != mqttConnAck || len(ack) != 2 {
+		return fmt.Errorf("unexpected packet type %d, expected CONNACK", header>>4)
+	}
+
+	switch ack[1] {
+	case 0:
+		return nil
+	case 4, 5:
+		return fmt.Errorf("broker refused connection: not authorized (code %d)", ack[1])
+	default:
+		return fmt.Errorf("broker refused connection (code %d)", ack[1])
+	}
+}
+
+// store renders a received message with the template of the first matching topic
+// filter and records it.
+func (m *MQTTInstrument) store(topics []configuration.MQTTTopic, templates []*template.Template, topic string, payload []byte) {
+	message := MQTTMessage{Topic: topic, Value: string(payload), Text: string(payload)}
+
+	for i, filter := range topics {
+		if !mqttTopicMatches(filter.Topic, topic) {
+			continue
+		}
+
+		if tmpl := templates[i]; tmpl != nil {
+			data := struct {
+				Topic string
+				Value string
+				JSON  interface{}
+			}{Topic: topic, Value: string(payload)}
+			json.Unmarshal(payload, &data.JSON)
+
+			var text strings.Builder
+			if err := tmpl.Execute(&text, data); err != nil {
+				mqttLog.Warn("Template failed", "topic", filter.Topic, "error", err)
+			} else {
+				message.Text = text.String()
+			}
+		}
+		break
+	}
+
+	m.mu.Lock()
+	m.messages[topic] = message
+	m.mu.Unlock()
+}
+
+func (m *MQTTInstrument) writePacket(conn net.Conn, header byte, body []byte) error {
+	packet := []byte{header}
+	packet = appendMQTTLength(packet, len(body))
+	packet = append(packet, body...)
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(mqttDialTimeout))
+	_, err := conn.Write(packet)
+	return err
+}
+
+// dialMQTT opens a TCP or TLS connection to the broker URL.
+func dialMQTT(ctx context.Context, broker string) (net.Conn, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %v", err)
+	}
+
+	secure := u.Scheme == "ssl" || u.Scheme == "tls" || u.Scheme == "mqtts"
+
+	host := u.Host
+	if u.Port() == "" {
+		port := "1883"
+		if secure {
+			port = "8883"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: mqttDialTimeout}
+	if secure {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		return tlsDialer.DialContext(ctx, "tcp", host)
+	}
+
+	return dialer.DialContext(ctx, "tcp", host)
+}
+
+// readMQTTPacket reads one control packet and returns its fixed header byte and body.
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	// The broker may announce up to 256MB, which is not allocated up front
+	if length > mqttMaxPacketSize {
+		return 0, nil, fmt.Errorf("packet of %d bytes exceeds %d bytes", length, mqttMaxPacketSize)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header, body, nil
+}
+
+// parseMQTTPublish splits a PUBLISH body into topic, payload and packet ID
+// (0 for QoS 0 messages).
+func parseMQTTPublish(header byte, body []byte) (string, []byte, uint16, error) {
+	if len(body) < 2 {
+		return "", nil, 0, errors.New("malformed PUBLISH packet")
+	}
+
+	topicLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+topicLen {
+		return "", nil, 0, errors.New("malformed PUBLISH packet")
+	}
+
+	topic := string(body[2 : 2+topicLen])
+	rest := body[2+topicLen:]
+
+	var packetID uint16
+	if qos := (header >> 1) & 0x03; qos > 0 {
+		if len(rest) < 2 {
+			return "", nil, 0, errors.New("malformed PUBLISH packet")
+		}
+		packetID = binary.BigEndian.Uint16(rest)
+		rest = rest[2:]
+	}
+
+	return topic, rest, packetID, nil
+}
+
+func appendMQTTLength(b []byte, length int) []byte {
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if length == 0 {
+			return b
+		}
+	}
+}
+
+func writeMQTTString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// mqttTopicMatches reports whether topic matches filter, which may contain the
+// single-level (+) and multi-level (#) wildcards.
+func mqttTopicMatches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
@@ -1,37 +1,105 @@
 package instruments
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"net/url"
+	"nexus-open/nexus/configuration"
 	"time"
 )
 
+const (
+	NewsInstrumentName = "news"
+
+	newsUpdateInterval = 15 * time.Minute // Stays within the NewsAPI free tier of 100 requests per day
+	newsHeadlineCount  = 10
+	newsAPIURL         = "https://newsapi.org/v2/top-headlines?country=%s&pageSize=%d"
+)
+
 type NewsItem struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	PublishedAt time.Time `json:"publishedAt"`
 }
 
-func GetLatestNews() (*NewsItem, error) {
-	// Replace with your actual API key and endpoint
-	apiKey := "your-api-key"
-	url := "https://newsapi.org/v2/top-headlines?country=us&apiKey=" + apiKey
+// NewsHeadlines is the list of current top headlines, empty when the news ticker is disabled.
+type NewsHeadlines []NewsItem
+
+// NewsInstrument samples top headlines from the configured news provider.
+type NewsInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewNewsInstrument creates a news instrument that reads the provider settings from
+// the configuration returned by getConfig. getConfig must not be nil.
+func NewNewsInstrument(getConfig func() *configuration.NexusConfig) *NewsInstrument {
+	if getConfig == nil {
+		panic("instruments: NewNewsInstrument getConfig is nil")
+	}
+
+	return &NewsInstrument{getConfig: getConfig}
+}
+
+func (n *NewsInstrument) Name() string { return NewsInstrumentName }
+
+func (n *NewsInstrument) Interval() time.Duration { return newsUpdateInterval }
+
+// Sample fetches the current top headlines. When no provider is configured an
+// empty list is returned so the ticker is hidden.
+func (n *NewsInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := n.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	switch cfg.News.Provider {
+	case configuration.NewsProviderNone:
+		return NewsHeadlines{}, nil
+	case configuration.NewsProviderNewsAPI:
+		apiKey, err := configuration.ResolveSecret(cfg.News.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		return GetTopHeadlines(ctx, apiKey, cfg.News.Country)
+	default:
+		return nil, fmt.Errorf("unknown news provider %q", cfg.News.Provider)
+	}
+}
+
+// GetTopHeadlines fetches up to newsHeadlineCount top headlines for a country
+// from NewsAPI using the given API key.
+func GetTopHeadlines(ctx context.Context, apiKey, country string) (NewsHeadlines, error) {
+	if country == "" {
+		country = "us"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(newsAPIURL, url.QueryEscape(country), newsHeadlineCount), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the key as a header so it does not end up in logged URLs
+	req.Header.Set("X-Api-Key", apiKey)
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch news: %v", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
 
 	var result struct {
+		Status   string     `json:"status"`
+		Message  string     `json:"message"`
 		Articles []NewsItem `json:"articles"`
 	}
 
@@ -39,15 +107,9 @@ func GetLatestNews() (*NewsItem, error) {
 		return nil, fmt.Errorf("failed to parse JSON: %v", err)
 	}
 
-	if len(result.Articles) == 0 {
-		return nil, fmt.Errorf("no news articles found")
-	}
-
-	// Truncate the title if it's longer than 50 characters
-	news := result.Articles[0]
-	if len(news.Title) > 50 {
-		news.Title = news.Title[:47] + "..."
+	if result.Status != "ok" {
+		return nil, fmt.Errorf("news provider error: %s", result.Message)
 	}
 
-	return &news, nil
+	return NewsHeadlines(result.Articles), nil
 }
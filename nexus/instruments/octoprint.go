@@ -0,0 +1,171 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"nexus-open/nexus/configuration"
+	"strings"
+	"time"
+)
+
+const (
+	OctoPrintInstrumentName = "octoprint"
+
+	octoPrintUpdateInterval = 10 * time.Second
+)
+
+// PrintJob is the state of the current OctoPrint print job.
+type PrintJob struct {
+	File       string
+	State      string        // OctoPrint state text, e.g. "Printing" or "Paused"
+	Progress   float64       // Completion in percent
+	Remaining  time.Duration // Estimated time left, 0 if unknown
+	Hotend     float64       // Hotend temperature in °C
+	HotendGoal float64       // Hotend target temperature in °C
+	Bed        float64       // Bed temperature in °C
+	BedGoal    float64       // Bed target temperature in °C
+}
+
+// Metrics exposes the progress in percent as "progress", the remaining time in minutes
+// as "remaining" and the temperatures as "hotend" and "bed".
+func (p *PrintJob) Metrics() map[string]float64 {
+	if p == nil {
+		return nil
+	}
+	return map[string]float64{
+		"progress":  p.Progress,
+		"remaining": p.Remaining.Minutes(),
+		"hotend":    p.Hotend,
+		"bed":       p.Bed,
+	}
+}
+
+// OctoPrintInstrument samples the print job and temperatures of an OctoPrint server.
+// Its value is a *PrintJob, nil while the printer is not printing.
+type OctoPrintInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewOctoPrintInstrument creates an OctoPrint instrument that reads the server from
+// the configuration returned by getConfig. getConfig must not be nil.
+func NewOctoPrintInstrument(getConfig func() *configuration.NexusConfig) *OctoPrintInstrument {
+	if getConfig == nil {
+		panic("instruments: NewOctoPrintInstrument getConfig is nil")
+	}
+
+	return &OctoPrintInstrument{getConfig: getConfig}
+}
+
+func (o *OctoPrintInstrument) Name() string { return OctoPrintInstrumentName }
+
+func (o *OctoPrintInstrument) Interval() time.Duration { return octoPrintUpdateInterval }
+
+// Sample reads the current job and, while printing, the printer temperatures.
+func (o *OctoPrintInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := o.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.OctoPrint.URL == "" {
+		return (*PrintJob)(nil), nil
+	}
+
+	apiKey, err := configuration.ResolveSecret(cfg.OctoPrint.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	return GetPrintJob(ctx, cfg.OctoPrint.URL, apiKey)
+}
+
+// GetPrintJob queries the OctoPrint job and printer APIs. It returns nil if no job
+// is printing or paused.
+func GetPrintJob(ctx context.Context, server, apiKey string) (*PrintJob, error) {
+	var job struct {
+		State string `json:"state"`
+		Job   struct {
+			File struct {
+				Name string `json:"name"`
+			} `json:"file"`
+		} `json:"job"`
+		Progress struct {
+			Completion    *float64 `json:"completion"`
+			PrintTimeLeft *int     `json:"printTimeLeft"`
+		} `json:"progress"`
+	}
+
+	if err := getOctoPrint(ctx, server, apiKey, "/api/job", &job); err != nil {
+		return nil, err
+	}
+
+	// States include "Printing", "Pausing", "Paused", "Printing from SD" and "Operational"
+	if !strings.HasPrefix(job.State, "Printing") && !strings.HasPrefix(job.State, "Paus") {
+		return nil, nil
+	}
+
+	status := &PrintJob{
+		File:  job.Job.File.Name,
+		State: job.State,
+	}
+
+	if job.Progress.Completion != nil {
+		status.Progress = *job.Progress.Completion
+	}
+
+	if job.Progress.PrintTimeLeft != nil {
+		status.Remaining = time.Duration(*job.Progress.PrintTimeLeft) * time.Second
+	}
+
+	var printer struct {
+		Temperature map[string]struct {
+			Actual float64 `json:"actual"`
+			Target float64 `json:"target"`
+		} `json:"temperature"`
+	}
+
+	if err := getOctoPrint(ctx, server, apiKey, "/api/printer?exclude=sd,state", &printer); err != nil {
+		octoprintLog.Warn("Failed to read temperatures", "error", err)
+		return status, nil
+	}
+
+	if tool, ok := printer.Temperature["tool0"]; ok {
+		status.Hotend, status.HotendGoal = tool.Actual, tool.Target
+	}
+
+	if bed, ok := printer.Temperature["bed"]; ok {
+		status.Bed, status.BedGoal = bed.Actual, bed.Target
+	}
+
+	return status, nil
+}
+
+// getOctoPrint sends an authenticated GET request to the OctoPrint API and decodes
+// the JSON response into v.
+func getOctoPrint(ctx context.Context, server, apiKey, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(server, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Api-Key", apiKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
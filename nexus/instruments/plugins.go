@@ -0,0 +1,316 @@
+package instruments
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"nexus-open/nexus/configuration"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Plugins are executables in the plugins directory that provide values to the
+// display. Nexus starts each plugin once it is first sampled and keeps it running,
+// talking to it in JSON lines over stdin and stdout. For every sample it writes a
+// request line:
+//
+//	{"type":"sample"}
+//
+// and the plugin answers with a single response line, all fields optional:
+//
+//	{"values":{"temperature":21.5},"text":"Office 21.5°","tile":"<base64 PNG>"}
+//
+// Values are exposed as metrics for alerts and history, text is shown in the
+// ticker and the tile is drawn by the widget named after the plugin, see
+// configuration.WidgetPluginPrefix. A response with a non-empty "error" field
+// fails the sample. Lines written to stderr are logged.
+const (
+	pluginUpdateInterval = 5 * time.Second
+	pluginTimeout        = 10 * time.Second // Time a plugin has to answer a request
+	pluginMaxResponse    = 4 << 20          // Maximum length of a response line in bytes
+)
+
+// PluginOutput is the response of a plugin to a sample request.
+type PluginOutput struct {
+	Values map[string]float64 `json:"values,omitempty"` // Named numeric values
+	Text   string             `json:"text,omitempty"`   // Text for the ticker
+	Tile   image.Image        `json:"-"`                // Tile for the plugin widget, nil if none
+}
+
+// Metrics exposes the values of the plugin under their names.
+func (o *PluginOutput) Metrics() map[string]float64 {
+	if o == nil {
+		return nil
+	}
+	return o.Values
+}
+
+// pluginRequest is a request line written to a plugin.
+type pluginRequest struct {
+	Type string `json:"type"`
+}
+
+// pluginResponse is a response line read from a plugin.
+type pluginResponse struct {
+	Values map[string]float64 `json:"values"`
+	Text   string             `json:"text"`
+	Tile   string             `json:"tile"` // Base64 encoded PNG
+	Error  string             `json:"error"`
+}
+
+// PluginInstrument samples a plugin executable. The process is started by the
+// first sample, and again by the next one after it exited or failed to answer.
+type PluginInstrument struct {
+	name string // configuration.WidgetPluginPrefix followed by the executable name
+	path string
+
+	mu      sync.Mutex     // Serializes requests
+	process *pluginProcess // nil until started
+}
+
+// NewPluginInstrument creates an instrument running the plugin executable at path.
+// It is named after the executable without its extension, e.g. "plugin:radar" for
+// radar.py.
+func NewPluginInstrument(path string) *PluginInstrument {
+	base := filepath.Base(path)
+	return &PluginInstrument{
+		name: configuration.WidgetPluginPrefix + strings.TrimSuffix(base, filepath.Ext(base)),
+		path: path,
+	}
+}
+
+// DiscoverPlugins returns an instrument for every executable in dir, in name
+// order. Executables that would have the same name as an earlier one are skipped.
+func DiscoverPlugins(dir string) ([]*PluginInstrument, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []*PluginInstrument
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !isExecutable(info) {
+			continue
+		}
+
+		plugin := NewPluginInstrument(filepath.Join(dir, entry.Name()))
+		if names[plugin.name] {
+			pluginsLog.Warn("Skipping plugin with duplicate name", "plugin", plugin.name, "path", plugin.path)
+			continue
+		}
+		names[plugin.name] = true
+		plugins = append(plugins, plugin)
+	}
+
+	return plugins, nil
+}
+
+// isExecutable reports whether info describes a file that can be run as a plugin.
+func isExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(info.Name()), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+func (p *PluginInstrument) Name() string { return p.name }
+
+func (p *PluginInstrument) Interval() time.Duration { return pluginUpdateInterval }
+
+// Sample requests the current output of the plugin, starting it if it is not
+// running. A plugin that does not answer within pluginTimeout is stopped. The
+// process is stopped when ctx is done.
+func (p *PluginInstrument) Sample(ctx context.Context) (Value, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.process != nil && p.process.hasExited() {
+		p.process = nil
+	}
+
+	if p.process == nil {
+		process, err := startPlugin(ctx, p.name, p.path)
+		if err != nil {
+			return nil, err
+		}
+		p.process = process
+	}
+
+	line, err := p.process.request(ctx)
+	if err != nil {
+		// Start the plugin again on the next sample
+		p.process.kill()
+		p.process = nil
+		return nil, err
+	}
+
+	return parsePluginResponse(line)
+}
+
+// pluginProcess is a running plugin executable.
+type pluginProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  <-chan []byte   // Response lines, closed when stdout is closed
+	done   chan struct{}   // Closed by kill
+	exited <-chan struct{} // Closed once the process exited
+	once   sync.Once
+}
+
+// startPlugin starts the plugin executable at path in its directory. The process
+// is killed when ctx is done.
+func startPlugin(ctx context.Context, name, path string) (*pluginProcess, error) {
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = filepath.Dir(path)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", path, err)
+	}
+	pluginsLog.Info("Started plugin", "plugin", name, "pid", cmd.Process.Pid)
+
+	lines := make(chan []byte)
+	exited := make(chan struct{})
+	p := &pluginProcess{cmd: cmd, stdin: stdin, lines: lines, done: make(chan struct{}), exited: exited}
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+
+	go func() {
+		defer readers.Done()
+		defer close(lines)
+
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, pluginMaxResponse)
+		for scanner.Scan() {
+			select {
+			case lines <- bytes.Clone(scanner.Bytes()):
+			case <-p.done:
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			pluginsLog.Warn("Failed to read plugin output", "plugin", name, "error", err)
+		}
+	}()
+
+	go func() {
+		defer readers.Done()
+
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			pluginsLog.Info(scanner.Text(), "plugin", name)
+		}
+	}()
+
+	go func() {
+		// Wait must only be called once the pipes have been read
+		readers.Wait()
+		err := cmd.Wait()
+		close(exited)
+
+		if ctx.Err() == nil {
+			pluginsLog.Warn("Plugin exited", "plugin", name, "error", err)
+		}
+	}()
+
+	return p, nil
+}
+
+// request writes a sample request and returns the response line.
+func (p *pluginProcess) request(ctx context.Context) ([]byte, error) {
+	request, err := json.Marshal(pluginRequest{Type: "sample"})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.stdin.Write(append(request, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+
+	timeout := time.NewTimer(pluginTimeout)
+	defer timeout.Stop()
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return nil, errors.New("plugin closed its output")
+		}
+		return line, nil
+	case <-timeout.C:
+		return nil, fmt.Errorf("no response within %v", pluginTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// hasExited reports whether the process exited.
+func (p *pluginProcess) hasExited() bool {
+	select {
+	case <-p.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// kill stops the process and discards its remaining output.
+func (p *pluginProcess) kill() {
+	p.once.Do(func() {
+		close(p.done)
+		p.stdin.Close()
+		p.cmd.Process.Kill()
+	})
+}
+
+// parsePluginResponse decodes a response line of a plugin.
+func parsePluginResponse(line []byte) (*PluginOutput, error) {
+	var response pluginResponse
+	if err := json.Unmarshal(line, &response); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+
+	output := &PluginOutput{Values: response.Values, Text: response.Text}
+	if response.Tile != "" {
+		data, err := base64.StdEncoding.DecodeString(response.Tile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tile: %w", err)
+		}
+		if output.Tile, err = png.Decode(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("invalid tile: %w", err)
+		}
+	}
+
+	return output, nil
+}
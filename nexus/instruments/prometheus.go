@@ -0,0 +1,166 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nexus-open/nexus/configuration"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PrometheusInstrumentName = "prometheus"
+
+	prometheusUpdateInterval = 30 * time.Second
+	prometheusDefaultFormat  = "%.2f"
+)
+
+// PrometheusResult is the value of a configured PromQL query.
+type PrometheusResult struct {
+	Name  string
+	Value float64
+	Text  string // Value rendered with the query format
+}
+
+// PrometheusResults holds the results of all configured queries in configuration order.
+type PrometheusResults []PrometheusResult
+
+// Metrics exposes each query result under the query name.
+func (p PrometheusResults) Metrics() map[string]float64 {
+	metrics := make(map[string]float64, len(p))
+	for _, result := range p {
+		metrics[result.Name] = result.Value
+	}
+	return metrics
+}
+
+// PrometheusInstrument runs the configured instant queries against a Prometheus server.
+type PrometheusInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewPrometheusInstrument creates a Prometheus instrument that reads the server and
+// queries from the configuration returned by getConfig. getConfig must not be nil.
+func NewPrometheusInstrument(getConfig func() *configuration.NexusConfig) *PrometheusInstrument {
+	if getConfig == nil {
+		panic("instruments: NewPrometheusInstrument getConfig is nil")
+	}
+
+	return &PrometheusInstrument{getConfig: getConfig}
+}
+
+func (p *PrometheusInstrument) Name() string { return PrometheusInstrumentName }
+
+func (p *PrometheusInstrument) Interval() time.Duration { return prometheusUpdateInterval }
+
+// Sample runs every configured query. Queries that fail or return no data are logged
+// and skipped; an error is only returned if every query failed.
+func (p *PrometheusInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := p.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.Prometheus.URL == "" {
+		return PrometheusResults{}, nil
+	}
+
+	results := PrometheusResults{}
+	for _, query := range cfg.Prometheus.Queries {
+		value, err := QueryPrometheus(ctx, cfg.Prometheus.URL, query.Query)
+		if err != nil {
+			prometheusLog.Warn("Query failed", "query", query.Name, "error", err)
+			continue
+		}
+
+		format := query.Format
+		if format == "" {
+			format = prometheusDefaultFormat
+		}
+
+		results = append(results, PrometheusResult{
+			Name:  query.Name,
+			Value: value,
+			Text:  fmt.Sprintf(format, value),
+		})
+	}
+
+	if len(results) == 0 && len(cfg.Prometheus.Queries) > 0 {
+		return nil, fmt.Errorf("all %d queries failed", len(cfg.Prometheus.Queries))
+	}
+
+	return results, nil
+}
+
+// QueryPrometheus runs an instant query and returns the value of the first sample of
+// a vector result, or the value of a scalar result.
+func QueryPrometheus(ctx context.Context, server, query string) (float64, error) {
+	endpoint := strings.TrimRight(server, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response (status %d): %w", resp.StatusCode, err)
+	}
+
+	if result.Status != "success" {
+		return 0, fmt.Errorf("query failed: %s", result.Error)
+	}
+
+	// Samples are encoded as [<unix time>, "<value>"]
+	var sample []interface{}
+	switch result.Data.ResultType {
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return 0, fmt.Errorf("failed to decode vector: %w", err)
+		}
+		if len(vector) == 0 {
+			return 0, fmt.Errorf("query returned no data")
+		}
+		sample = vector[0].Value
+	case "scalar":
+		if err := json.Unmarshal(result.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("failed to decode scalar: %w", err)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported result type %q", result.Data.ResultType)
+	}
+
+	if len(sample) != 2 {
+		return 0, fmt.Errorf("malformed sample")
+	}
+
+	text, ok := sample[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed sample value")
+	}
+
+	return strconv.ParseFloat(text, 64)
+}
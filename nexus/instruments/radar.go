@@ -0,0 +1,166 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"nexus-open/nexus/configuration"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+const (
+	RadarInstrumentName = "radar"
+
+	// Size of the radar map in pixels, twice as wide as high
+	RadarWidth  = 96
+	RadarHeight = 48
+
+	radarUpdateInterval = 5 * time.Minute
+	radarHTTPTimeout    = 10 * time.Second
+	radarZoom           = 7 // About 300 km across the map in mid latitudes
+
+	// RainViewer radar frames: the tile of a frame centered on coordinates is at
+	// host + path + "/256/<zoom>/<lat>/<lon>/<color scheme>/<smooth>_<snow>.png"
+	rainViewerMapsURL = "https://api.rainviewer.com/public/weather-maps.json"
+	rainViewerTileURL = "%s%s/256/%d/%.4f/%.4f/2/1_1.png"
+)
+
+// RadarMap is the latest precipitation radar image around the configured location.
+type RadarMap struct {
+	// Image shows precipitation on a transparent background, RadarWidth by
+	// RadarHeight pixels with the location in the center
+	Image *image.RGBA `json:"-"`
+
+	Time time.Time // When the radar frame was taken
+}
+
+// RadarInstrument fetches the latest RainViewer precipitation radar frame around
+// the configured location. Its value is a *RadarMap, nil while no location is
+// configured.
+type RadarInstrument struct {
+	getConfig    func() *configuration.NexusConfig
+	lastLocation string
+	lat, lon     float64
+}
+
+// NewRadarInstrument creates a radar instrument that reads the location from the
+// configuration returned by getConfig. getConfig must not be nil.
+func NewRadarInstrument(getConfig func() *configuration.NexusConfig) *RadarInstrument {
+	if getConfig == nil {
+		panic("instruments: NewRadarInstrument getConfig is nil")
+	}
+
+	return &RadarInstrument{getConfig: getConfig}
+}
+
+func (r *RadarInstrument) Name() string { return RadarInstrumentName }
+
+func (r *RadarInstrument) Interval() time.Duration { return radarUpdateInterval }
+
+// Sample fetches the radar map for the configured location. Coordinates are only
+// looked up again when the location changes.
+func (r *RadarInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := r.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.Location == "" {
+		return (*RadarMap)(nil), nil
+	}
+
+	// Automatic locations may move, so they are resolved on every sample (cached by DetectLocation)
+	if lat, lon, ok := cfg.Geocoded.For(cfg.Location); ok {
+		r.lat, r.lon = lat, lon
+		r.lastLocation = cfg.Location
+	} else if r.lastLocation != cfg.Location || cfg.Location == configuration.LocationAuto {
+		resolved, err := ResolveLocation(ctx, cfg.Location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve location: %v", err)
+		}
+		r.lat, r.lon = resolved.Lat, resolved.Lon
+		r.lastLocation = cfg.Location
+	}
+
+	return GetRadarMap(ctx, r.lat, r.lon)
+}
+
+// GetRadarMap retrieves the latest RainViewer radar frame centered on the given
+// coordinates, cropped and scaled to RadarWidth by RadarHeight.
+func GetRadarMap(ctx context.Context, lat, lon float64) (*RadarMap, error) {
+	client := &http.Client{Timeout: radarHTTPTimeout}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rainViewerMapsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var maps struct {
+		Host  string `json:"host"`
+		Radar struct {
+			Past []struct {
+				Time int64  `json:"time"`
+				Path string `json:"path"`
+			} `json:"past"`
+		} `json:"radar"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&maps); err != nil {
+		return nil, fmt.Errorf("failed to decode radar frames: %w", err)
+	}
+
+	if len(maps.Radar.Past) == 0 {
+		return nil, fmt.Errorf("no radar frames available")
+	}
+	frame := maps.Radar.Past[len(maps.Radar.Past)-1]
+
+	req, err = http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(rainViewerTileURL, maps.Host, frame.Path, radarZoom, lat, lon), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tileResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer tileResp.Body.Close()
+
+	if tileResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", tileResp.StatusCode)
+	}
+
+	tile, err := png.Decode(tileResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode radar tile: %w", err)
+	}
+
+	// Crop the middle band of the square tile to the aspect ratio of the map
+	bounds := tile.Bounds()
+	band := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dx()*RadarHeight/RadarWidth))
+	draw.Draw(band, band.Bounds(), tile, image.Pt(bounds.Min.X, bounds.Min.Y+(bounds.Dy()-band.Bounds().Dy())/2), draw.Src)
+
+	radar := image.NewRGBA(image.Rect(0, 0, RadarWidth, RadarHeight))
+	draw.Draw(radar, radar.Bounds(), resize.Resize(RadarWidth, RadarHeight, band, resize.Bilinear), image.Point{}, draw.Src)
+
+	return &RadarMap{
+		Image: radar,
+		Time:  time.Unix(frame.Time, 0),
+	}, nil
+}
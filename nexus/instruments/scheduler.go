@@ -0,0 +1,164 @@
+package instruments
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Scheduler runs a set of instruments, each in its own goroutine, and fans
+// their samples into a single Reading channel.
+type Scheduler struct {
+	instruments []Instrument
+	gate        *Gate
+	readings    chan Reading
+	triggers    map[string]chan struct{}
+	resets      map[string]chan struct{}
+
+	mu        sync.Mutex
+	intervals map[string]time.Duration // Per-instrument interval overrides
+}
+
+// NewScheduler creates a scheduler for the given instruments. Instruments are
+// only sampled while gate is open; while it is closed their goroutines sleep.
+func NewScheduler(gate *Gate, instruments ...Instrument) *Scheduler {
+	triggers := make(map[string]chan struct{}, len(instruments))
+	resets := make(map[string]chan struct{}, len(instruments))
+	for _, instrument := range instruments {
+		triggers[instrument.Name()] = make(chan struct{}, 1)
+		resets[instrument.Name()] = make(chan struct{}, 1)
+	}
+
+	return &Scheduler{
+		instruments: instruments,
+		gate:        gate,
+		readings:    make(chan Reading, len(instruments)),
+		triggers:    triggers,
+		resets:      resets,
+		intervals:   make(map[string]time.Duration),
+	}
+}
+
+// Start launches one sampling goroutine per instrument and returns the channel
+// on which readings are delivered. The goroutines exit when ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) <-chan Reading {
+	for _, instrument := range s.instruments {
+		go s.run(ctx, instrument, s.triggers[instrument.Name()])
+	}
+
+	return s.readings
+}
+
+// Trigger requests an immediate sample of the named instrument. It never blocks
+// and returns false if no instrument with that name is scheduled.
+func (s *Scheduler) Trigger(name string) bool {
+	trigger, ok := s.triggers[name]
+	if !ok {
+		return false
+	}
+
+	select {
+	case trigger <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// SetInterval overrides the polling interval of the named instrument. A zero
+// interval restores the instrument's default. Running instruments pick up the
+// new interval immediately. It returns false if no such instrument is scheduled.
+func (s *Scheduler) SetInterval(name string, interval time.Duration) bool {
+	reset, ok := s.resets[name]
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	if interval > 0 {
+		s.intervals[name] = interval
+	} else {
+		delete(s.intervals, name)
+	}
+	s.mu.Unlock()
+
+	select {
+	case reset <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// interval returns the effective polling interval for an instrument.
+func (s *Scheduler) interval(instrument Instrument) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if interval, ok := s.intervals[instrument.Name()]; ok {
+		return interval
+	}
+	return instrument.Interval()
+}
+
+// run samples a single instrument immediately, then on every interval tick or
+// trigger, until ctx is cancelled. While the gate is closed it blocks instead
+// of polling.
+func (s *Scheduler) run(ctx context.Context, instrument Instrument, trigger <-chan struct{}) {
+	reset := s.resets[instrument.Name()]
+
+	ticker := time.NewTicker(s.interval(instrument))
+	defer ticker.Stop()
+
+	sample := func() {
+		if !s.gate.IsOpen() {
+			return
+		}
+
+		value, err := sampleRecovered(ctx, instrument)
+		if err != nil {
+			schedulerLog.Warn("Sample failed", "instrument", instrument.Name(), "error", err)
+			return
+		}
+
+		select {
+		case s.readings <- Reading{Name: instrument.Name(), Value: value, Time: time.Now()}:
+		case <-ctx.Done():
+		}
+	}
+
+	sample()
+
+	for {
+		if err := s.gate.Wait(ctx); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sample()
+		case <-trigger:
+			sample()
+		case <-reset:
+			ticker.Reset(s.interval(instrument))
+		}
+	}
+}
+
+// sampleRecovered samples instrument, turning a panic into an error so that one
+// broken instrument does not take down the process. The stack is logged, as the
+// error alone rarely explains a panic.
+func sampleRecovered(ctx context.Context, instrument Instrument) (value Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			schedulerLog.Error("Recovered from panic", "instrument", instrument.Name(), "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return instrument.Sample(ctx)
+}
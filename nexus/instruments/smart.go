@@ -0,0 +1,194 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nexus-open/nexus/configuration"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	SMARTInstrumentName = "smart"
+
+	smartUpdateInterval = 5 * time.Minute
+)
+
+// DriveHealth is the SMART health of a drive.
+type DriveHealth struct {
+	Drive       string   // Configured device, e.g. "/dev/sda"
+	Model       string   // Model name, empty if unknown
+	Temperature float64  // Temperature in °C, 0 if unknown
+	Failing     []string // Names of the failing attributes, empty while the drive is healthy
+}
+
+// Name returns the short name of the drive, e.g. "sda" for "/dev/sda".
+func (d DriveHealth) Name() string {
+	return filepath.Base(d.Drive)
+}
+
+// DiskHealth holds the health of the configured drives in configuration order.
+type DiskHealth []DriveHealth
+
+// Metrics exposes the temperature of each drive as "<drive>.temperature" and its
+// number of failing attributes as "<drive>.failing", e.g. "sda.failing".
+func (d DiskHealth) Metrics() map[string]float64 {
+	metrics := make(map[string]float64, 2*len(d))
+	for _, drive := range d {
+		metrics[drive.Name()+".temperature"] = drive.Temperature
+		metrics[drive.Name()+".failing"] = float64(len(drive.Failing))
+	}
+	return metrics
+}
+
+// SMARTInstrument reads the SMART health of the configured drives.
+type SMARTInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewSMARTInstrument creates a SMART instrument that reads the drives from the
+// configuration returned by getConfig. getConfig must not be nil.
+func NewSMARTInstrument(getConfig func() *configuration.NexusConfig) *SMARTInstrument {
+	if getConfig == nil {
+		panic("instruments: NewSMARTInstrument getConfig is nil")
+	}
+
+	return &SMARTInstrument{getConfig: getConfig}
+}
+
+func (s *SMARTInstrument) Name() string { return SMARTInstrumentName }
+
+func (s *SMARTInstrument) Interval() time.Duration { return smartUpdateInterval }
+
+// Sample reads every configured drive. Drives that cannot be read are logged and
+// skipped; an error is only returned if every drive failed.
+func (s *SMARTInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := s.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	disks := DiskHealth{}
+	for _, drive := range cfg.SMART.Drives {
+		health, err := GetDriveHealth(ctx, drive)
+		if err != nil {
+			smartLog.Warn("Failed to read drive", "drive", drive, "error", err)
+			continue
+		}
+		disks = append(disks, health)
+	}
+
+	if len(disks) == 0 && len(cfg.SMART.Drives) > 0 {
+		return nil, fmt.Errorf("all %d drives failed", len(cfg.SMART.Drives))
+	}
+
+	return disks, nil
+}
+
+// GetDriveHealth returns the SMART health of drive, e.g. "/dev/sda".
+// It runs smartctl, which needs administrator rights. Without smartctl NVMe drives
+// are read natively on Linux.
+// Returns an error if the drive cannot be opened or smartctl is not installed.
+func GetDriveHealth(ctx context.Context, drive string) (DriveHealth, error) {
+	health, err := getSmartctlHealth(ctx, drive)
+	if errors.Is(err, exec.ErrNotFound) {
+		return getNVMeHealth(drive)
+	}
+	return health, err
+}
+
+// getSmartctlHealth reads the identity, health and attributes of drive with the JSON
+// output of smartctl.
+func getSmartctlHealth(ctx context.Context, drive string) (DriveHealth, error) {
+	out, err := exec.CommandContext(ctx, "smartctl", "--json", "-i", "-H", "-A", drive).Output()
+
+	// The exit status is a bit mask; only the lowest two bits mean smartctl could
+	// not read the drive, the others report problems of the drive itself
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode()&3 == 0 {
+		err = nil
+	}
+
+	var report struct {
+		Smartctl struct {
+			Messages []struct {
+				String string `json:"string"`
+			} `json:"messages"`
+		} `json:"smartctl"`
+		ModelName   string `json:"model_name"`
+		SmartStatus *struct {
+			Passed bool `json:"passed"`
+		} `json:"smart_status"`
+		Temperature struct {
+			Current float64 `json:"current"`
+		} `json:"temperature"`
+		ATASmartAttributes struct {
+			Table []struct {
+				Name       string `json:"name"`
+				WhenFailed string `json:"when_failed"`
+			} `json:"table"`
+		} `json:"ata_smart_attributes"`
+		NVMeSmartHealth *struct {
+			CriticalWarning uint8 `json:"critical_warning"`
+		} `json:"nvme_smart_health_information_log"`
+	}
+
+	if decodeErr := json.Unmarshal(out, &report); decodeErr != nil {
+		if err != nil {
+			return DriveHealth{}, fmt.Errorf("smartctl failed: %w", err)
+		}
+		return DriveHealth{}, fmt.Errorf("failed to decode smartctl output: %v", decodeErr)
+	}
+
+	if err != nil {
+		var messages []string
+		for _, message := range report.Smartctl.Messages {
+			messages = append(messages, message.String)
+		}
+		return DriveHealth{}, fmt.Errorf("smartctl failed: %v: %s", err, strings.Join(messages, "; "))
+	}
+
+	health := DriveHealth{
+		Drive:       drive,
+		Model:       report.ModelName,
+		Temperature: report.Temperature.Current,
+		Failing:     []string{},
+	}
+
+	// Attributes failed in the past have recovered, only current failures count
+	for _, attribute := range report.ATASmartAttributes.Table {
+		if attribute.WhenFailed == "now" {
+			health.Failing = append(health.Failing, attribute.Name)
+		}
+	}
+
+	if report.NVMeSmartHealth != nil {
+		health.Failing = append(health.Failing, nvmeWarnings(report.NVMeSmartHealth.CriticalWarning)...)
+	}
+
+	// The overall assessment comes last, the attributes tell why it failed
+	if report.SmartStatus != nil && !report.SmartStatus.Passed {
+		health.Failing = append(health.Failing, "Overall_Health")
+	}
+
+	return health, nil
+}
+
+// nvmeWarnings returns the names of the bits set in the critical warning of the
+// health log of an NVMe drive.
+func nvmeWarnings(criticalWarning uint8) []string {
+	names := []string{"Available_Spare", "Temperature", "Reliability", "Read_Only", "Volatile_Memory_Backup"}
+
+	var warnings []string
+	for bit, name := range names {
+		if criticalWarning&(1<<bit) != 0 {
+			warnings = append(warnings, name)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package instruments
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// nvmeAdminCommand is struct nvme_admin_cmd of linux/nvme_ioctl.h.
+type nvmeAdminCommand struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMs   uint32
+	result      uint32
+}
+
+const (
+	nvmeIoctlAdminCmd = 0xc0484e41 // _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeGetLogPage    = 0x02       // Admin command opcode
+	nvmeLogSMART      = 0x02       // Log page identifier of the SMART / health information
+	nvmeLogSize       = 512
+	nvmeAllNamespaces = 0xffffffff
+)
+
+// getNVMeHealth reads the SMART / health information log of an NVMe drive such as
+// "/dev/nvme0" with an admin command, which needs administrator rights.
+func getNVMeHealth(drive string) (DriveHealth, error) {
+	if !strings.HasPrefix(filepath.Base(drive), "nvme") {
+		return DriveHealth{}, fmt.Errorf("smartctl is not installed")
+	}
+
+	file, err := os.Open(drive)
+	if err != nil {
+		return DriveHealth{}, err
+	}
+	defer file.Close()
+
+	page := make([]byte, nvmeLogSize)
+	cmd := nvmeAdminCommand{
+		opcode:  nvmeGetLogPage,
+		nsid:    nvmeAllNamespaces,
+		addr:    uint64(uintptr(unsafe.Pointer(&page[0]))),
+		dataLen: nvmeLogSize,
+		cdw10:   (nvmeLogSize/4-1)<<16 | nvmeLogSMART, // Number of dwords - 1 and the log page
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(page)
+	if errno != 0 {
+		return DriveHealth{}, fmt.Errorf("failed to read the NVMe health log: %v", errno)
+	}
+
+	// The log starts with the critical warning and the composite temperature in Kelvin
+	health := DriveHealth{
+		Drive:   drive,
+		Failing: append([]string{}, nvmeWarnings(page[0])...),
+	}
+	if kelvin := binary.LittleEndian.Uint16(page[1:3]); kelvin > 0 {
+		health.Temperature = float64(kelvin) - 273
+	}
+	return health, nil
+}
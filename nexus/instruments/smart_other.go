@@ -0,0 +1,10 @@
+//go:build !linux
+
+package instruments
+
+import "fmt"
+
+// getNVMeHealth is only available on Linux, elsewhere drives are read with smartctl.
+func getNVMeHealth(drive string) (DriveHealth, error) {
+	return DriveHealth{}, fmt.Errorf("smartctl is not installed")
+}
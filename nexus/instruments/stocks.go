@@ -0,0 +1,145 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nexus-open/nexus/configuration"
+	"strings"
+	"time"
+)
+
+const (
+	StocksInstrumentName = "stocks"
+
+	stocksUpdateInterval = 1 * time.Minute
+	finnhubQuoteURL      = "https://finnhub.io/api/v1/quote?symbol=%s"
+)
+
+// StockQuote is the latest price of a single symbol.
+type StockQuote struct {
+	Symbol        string
+	Price         float64
+	Change        float64 // Change since the previous close
+	ChangePercent float64 // Change since the previous close in percent
+}
+
+// StockQuotes holds the quotes of all configured symbols in configuration order.
+type StockQuotes []StockQuote
+
+// Metrics exposes the price of each symbol as "<SYMBOL>" and its daily change in
+// percent as "<SYMBOL>_change".
+func (q StockQuotes) Metrics() map[string]float64 {
+	metrics := make(map[string]float64, len(q)*2)
+	for _, quote := range q {
+		metrics[quote.Symbol] = quote.Price
+		metrics[quote.Symbol+"_change"] = quote.ChangePercent
+	}
+	return metrics
+}
+
+// StocksInstrument samples quotes for the configured symbols.
+type StocksInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewStocksInstrument creates a stocks instrument that reads the provider and symbols
+// from the configuration returned by getConfig. getConfig must not be nil.
+func NewStocksInstrument(getConfig func() *configuration.NexusConfig) *StocksInstrument {
+	if getConfig == nil {
+		panic("instruments: NewStocksInstrument getConfig is nil")
+	}
+
+	return &StocksInstrument{getConfig: getConfig}
+}
+
+func (s *StocksInstrument) Name() string { return StocksInstrumentName }
+
+func (s *StocksInstrument) Interval() time.Duration { return stocksUpdateInterval }
+
+// Sample fetches a quote for every configured symbol. Symbols that fail are logged
+// and skipped. When no provider is configured an empty list is returned.
+func (s *StocksInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := s.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.Stocks.Provider == configuration.StocksProviderNone {
+		return StockQuotes{}, nil
+	}
+
+	if cfg.Stocks.Provider != configuration.StocksProviderFinnhub {
+		return nil, fmt.Errorf("unknown stocks provider %q", cfg.Stocks.Provider)
+	}
+
+	apiKey, err := configuration.ResolveSecret(cfg.Stocks.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := StockQuotes{}
+	for _, symbol := range cfg.Stocks.Symbols {
+		quote, err := GetFinnhubQuote(ctx, apiKey, symbol)
+		if err != nil {
+			stocksLog.Warn("Quote failed", "symbol", symbol, "error", err)
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+
+	if len(quotes) == 0 && len(cfg.Stocks.Symbols) > 0 {
+		return nil, fmt.Errorf("no quotes available")
+	}
+
+	return quotes, nil
+}
+
+// GetFinnhubQuote fetches the latest quote for a symbol from Finnhub.
+func GetFinnhubQuote(ctx context.Context, apiKey, symbol string) (StockQuote, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(finnhubQuoteURL, url.QueryEscape(symbol)), nil)
+	if err != nil {
+		return StockQuote{}, err
+	}
+
+	// Send the key as a header so it does not end up in logged URLs
+	req.Header.Set("X-Finnhub-Token", apiKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return StockQuote{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return StockQuote{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Current       float64 `json:"c"`
+		Change        float64 `json:"d"`
+		ChangePercent float64 `json:"dp"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return StockQuote{}, fmt.Errorf("failed to decode quote: %w", err)
+	}
+
+	// Unknown symbols are answered with an all-zero quote
+	if result.Current == 0 {
+		return StockQuote{}, fmt.Errorf("unknown symbol")
+	}
+
+	return StockQuote{
+		Symbol:        symbol,
+		Price:         result.Current,
+		Change:        result.Change,
+		ChangePercent: result.ChangePercent,
+	}, nil
+}
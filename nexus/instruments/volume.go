@@ -0,0 +1,202 @@
+package instruments
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VolumeInstrumentName = "volume"
+
+	volumeUpdateInterval = 1 * time.Second
+)
+
+// VolumeState holds the output volume of the default audio device.
+type VolumeState struct {
+	Level int  // Volume level in percent (0-100, may exceed 100 when boosted)
+	Muted bool // Whether the output is muted
+}
+
+// Metrics exposes the volume level in percent as "level".
+func (v VolumeState) Metrics() map[string]float64 {
+	return map[string]float64{"level": float64(v.Level)}
+}
+
+func init() {
+	Register(&VolumeInstrument{})
+}
+
+// VolumeInstrument samples the output volume and mute state of the default audio device.
+type VolumeInstrument struct{}
+
+func (v *VolumeInstrument) Name() string { return VolumeInstrumentName }
+
+func (v *VolumeInstrument) Interval() time.Duration { return volumeUpdateInterval }
+
+// Sample reads the current volume level and mute state.
+func (v *VolumeInstrument) Sample(ctx context.Context) (Value, error) {
+	return GetVolume(ctx)
+}
+
+// GetVolume returns the output volume and mute state of the default audio device.
+// For Linux: Uses pactl (PulseAudio or PipeWire) with a fallback to wpctl (WirePlumber)
+// For Windows: Uses the Core Audio (WASAPI) endpoint volume through COM
+// For macOS: Uses osascript to query CoreAudio volume settings
+// Returns an error if the operating system is not supported or the volume cannot be read.
+func GetVolume(ctx context.Context) (VolumeState, error) {
+	switch runtime.GOOS {
+	case "linux":
+		if state, err := getPulseVolume(ctx); err == nil {
+			return state, nil
+		}
+		return getWirePlumberVolume(ctx)
+	case "windows":
+		return getWindowsVolume(ctx)
+	case "darwin":
+		return getMacVolume(ctx)
+	default:
+		return VolumeState{}, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+var volumePercentPattern = regexp.MustCompile(`(\d+)%`)
+
+func getPulseVolume(ctx context.Context) (VolumeState, error) {
+	out, err := exec.CommandContext(ctx, "pactl", "get-sink-volume", "@DEFAULT_SINK@").Output()
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to get volume: %v", err)
+	}
+
+	// Output looks like "Volume: front-left: 32768 /  50% / -18.06 dB, front-right: ..."
+	match := volumePercentPattern.FindStringSubmatch(string(out))
+	if match == nil {
+		return VolumeState{}, fmt.Errorf("invalid output format")
+	}
+
+	level, err := strconv.Atoi(match[1])
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to parse volume: %v", err)
+	}
+
+	out, err = exec.CommandContext(ctx, "pactl", "get-sink-mute", "@DEFAULT_SINK@").Output()
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to get mute state: %v", err)
+	}
+
+	return VolumeState{
+		Level: level,
+		Muted: strings.Contains(string(out), "yes"),
+	}, nil
+}
+
+func getWirePlumberVolume(ctx context.Context) (VolumeState, error) {
+	out, err := exec.CommandContext(ctx, "wpctl", "get-volume", "@DEFAULT_AUDIO_SINK@").Output()
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to get volume: %v", err)
+	}
+
+	// Output looks like "Volume: 0.50" or "Volume: 0.50 [MUTED]"
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		return VolumeState{}, fmt.Errorf("invalid output format")
+	}
+
+	level, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to parse volume: %v", err)
+	}
+
+	return VolumeState{
+		Level: int(level*100 + 0.5),
+		Muted: strings.Contains(string(out), "[MUTED]"),
+	}, nil
+}
+
+// ToggleMute mutes or unmutes the output of the default audio device with the same
+// tools GetVolume reads it with.
+func ToggleMute(ctx context.Context) error {
+	var err error
+	switch runtime.GOOS {
+	case "linux":
+		if err = exec.CommandContext(ctx, "pactl", "set-sink-mute", "@DEFAULT_SINK@", "toggle").Run(); err != nil {
+			err = exec.CommandContext(ctx, "wpctl", "set-mute", "@DEFAULT_AUDIO_SINK@", "toggle").Run()
+		}
+	case "windows":
+		err = toggleWindowsMute()
+	case "darwin":
+		err = exec.CommandContext(ctx, "osascript", "-e", "set volume output muted not (output muted of (get volume settings))").Run()
+	default:
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to toggle mute: %v", err)
+	}
+	return nil
+}
+
+// WatchVolume calls changed whenever the volume or mute state of the default audio
+// device may have changed, until ctx is done or watching fails. Only PulseAudio and
+// PipeWire report changes, with "pactl subscribe"; elsewhere it returns an error
+// wrapping errors.ErrUnsupported and the volume is only polled.
+func WatchVolume(ctx context.Context, changed func()) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("volume events: %w", errors.ErrUnsupported)
+	}
+
+	cmd := exec.CommandContext(ctx, "pactl", "subscribe")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to subscribe to volume events: %v", err)
+	}
+
+	// Lines look like "Event 'change' on sink #54", the server changes when another
+	// device becomes the default
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, " on sink #") || strings.Contains(line, " on server") {
+			changed()
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("volume events stopped: %v", err)
+	}
+	return errors.New("volume events stopped")
+}
+
+func getMacVolume(ctx context.Context) (VolumeState, error) {
+	out, err := exec.CommandContext(ctx, "osascript", "-e",
+		`set s to get volume settings
+return (output volume of s as text) & "," & (output muted of s as text)`).Output()
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to get volume: %v", err)
+	}
+
+	parts := strings.Split(strings.TrimSpace(string(out)), ",")
+	if len(parts) != 2 {
+		return VolumeState{}, fmt.Errorf("invalid output format")
+	}
+
+	level, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to parse volume: %v", err)
+	}
+
+	return VolumeState{
+		Level: level,
+		Muted: parts[1] == "true",
+	}, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package instruments
+
+import (
+	"context"
+	"fmt"
+)
+
+// getWindowsVolume is only available on Windows.
+func getWindowsVolume(_ context.Context) (VolumeState, error) {
+	return VolumeState{}, fmt.Errorf("unsupported operating system")
+}
+
+// toggleWindowsMute is only available on Windows.
+func toggleWindowsMute() error {
+	return fmt.Errorf("unsupported operating system")
+}
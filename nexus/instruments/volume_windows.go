@@ -0,0 +1,128 @@
+//go:build windows
+
+package instruments
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procCoCreateInstance = windows.NewLazySystemDLL("ole32.dll").NewProc("CoCreateInstance")
+
+var (
+	clsidMMDeviceEnumerator = windows.GUID{Data1: 0xBCDE0395, Data2: 0xE52F, Data3: 0x467C, Data4: [8]byte{0x8E, 0x3D, 0xC4, 0x57, 0x92, 0x91, 0x69, 0x2E}}
+	iidIMMDeviceEnumerator  = windows.GUID{Data1: 0xA95664D2, Data2: 0x9614, Data3: 0x4F35, Data4: [8]byte{0xA7, 0x46, 0xDE, 0x8D, 0xB6, 0x36, 0x17, 0xE6}}
+	iidIAudioEndpointVolume = windows.GUID{Data1: 0x5CDF2C82, Data2: 0x841E, Data3: 0x4546, Data4: [8]byte{0x97, 0x22, 0x0C, 0xF7, 0x40, 0x78, 0x22, 0x9A}}
+)
+
+// Indices into the method tables of the Core Audio interfaces, after the three
+// methods of IUnknown
+const (
+	methodRelease                    = 2
+	methodGetDefaultAudioEndpoint    = 4  // IMMDeviceEnumerator
+	methodActivate                   = 3  // IMMDevice
+	methodGetMasterVolumeLevelScalar = 9  // IAudioEndpointVolume
+	methodSetMute                    = 14 // IAudioEndpointVolume
+	methodGetMute                    = 15 // IAudioEndpointVolume
+)
+
+const (
+	clsctxAll   = 0x17
+	eRender     = 0
+	eMultimedia = 1
+)
+
+// comObject is a COM interface, whose first word points to its method table.
+type comObject struct {
+	methods *[32]uintptr
+}
+
+// call invokes the method at index of the method table with the arguments and
+// returns an error if the HRESULT reports a failure.
+func (o *comObject) call(index int, args ...uintptr) error {
+	hr, _, _ := syscall.SyscallN(o.methods[index], append([]uintptr{uintptr(unsafe.Pointer(o))}, args...)...)
+	if int32(hr) < 0 {
+		return syscall.Errno(hr)
+	}
+	return nil
+}
+
+func (o *comObject) release() {
+	o.call(methodRelease)
+}
+
+// withEndpointVolume calls f with the IAudioEndpointVolume of the default render
+// endpoint. COM is initialized for the calling thread, which stays locked for the call.
+func withEndpointVolume(f func(volume *comObject) error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED); err == nil || err == syscall.Errno(windows.S_FALSE) {
+		defer windows.CoUninitialize()
+	}
+
+	var enumerator *comObject
+	hr, _, _ := procCoCreateInstance.Call(
+		uintptr(unsafe.Pointer(&clsidMMDeviceEnumerator)), 0, clsctxAll,
+		uintptr(unsafe.Pointer(&iidIMMDeviceEnumerator)), uintptr(unsafe.Pointer(&enumerator)))
+	if int32(hr) < 0 {
+		return fmt.Errorf("failed to create device enumerator: %v", syscall.Errno(hr))
+	}
+	defer enumerator.release()
+
+	var device *comObject
+	if err := enumerator.call(methodGetDefaultAudioEndpoint, eRender, eMultimedia, uintptr(unsafe.Pointer(&device))); err != nil {
+		return fmt.Errorf("failed to get default audio endpoint: %v", err)
+	}
+	defer device.release()
+
+	var volume *comObject
+	if err := device.call(methodActivate, uintptr(unsafe.Pointer(&iidIAudioEndpointVolume)), clsctxAll, 0, uintptr(unsafe.Pointer(&volume))); err != nil {
+		return fmt.Errorf("failed to activate endpoint volume: %v", err)
+	}
+	defer volume.release()
+
+	return f(volume)
+}
+
+// getWindowsVolume reads the master volume and mute state of the default render
+// endpoint through the Core Audio (WASAPI) API.
+func getWindowsVolume(_ context.Context) (VolumeState, error) {
+	var level float32
+	var muted int32
+	err := withEndpointVolume(func(volume *comObject) error {
+		if err := volume.call(methodGetMasterVolumeLevelScalar, uintptr(unsafe.Pointer(&level))); err != nil {
+			return err
+		}
+		return volume.call(methodGetMute, uintptr(unsafe.Pointer(&muted)))
+	})
+	if err != nil {
+		return VolumeState{}, fmt.Errorf("failed to get volume: %v", err)
+	}
+
+	return VolumeState{
+		Level: int(level*100 + 0.5),
+		Muted: muted != 0,
+	}, nil
+}
+
+// toggleWindowsMute mutes or unmutes the default render endpoint.
+func toggleWindowsMute() error {
+	return withEndpointVolume(func(volume *comObject) error {
+		var muted int32
+		if err := volume.call(methodGetMute, uintptr(unsafe.Pointer(&muted))); err != nil {
+			return err
+		}
+
+		toggled := uintptr(1)
+		if muted != 0 {
+			toggled = 0
+		}
+		return volume.call(methodSetMute, toggled, 0)
+	})
+}
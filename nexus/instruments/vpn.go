@@ -0,0 +1,146 @@
+package instruments
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"nexus-open/nexus/configuration"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	VPNInstrumentName = "vpn"
+
+	vpnUpdateInterval = 10 * time.Second
+
+	// WireGuard handshakes again every two minutes while the tunnel carries traffic
+	// or keepalives, so an older handshake means the peer stopped answering
+	wireGuardStaleHandshake = 3 * time.Minute
+)
+
+// VPNStatus is the state of the configured VPN interface.
+type VPNStatus struct {
+	Interface     string
+	Up            bool      // Whether the interface exists and is up
+	Endpoint      string    // Address of the WireGuard peer, empty for other tunnels
+	LastHandshake time.Time // Latest WireGuard handshake, zero if none or unknown
+	WireGuard     bool      // Whether the handshake was read from WireGuard
+}
+
+// Connected reports whether the tunnel is up and, for WireGuard, the peer answered
+// within the last wireGuardStaleHandshake.
+func (v *VPNStatus) Connected() bool {
+	if v == nil || !v.Up {
+		return false
+	}
+	return !v.WireGuard || time.Since(v.LastHandshake) < wireGuardStaleHandshake
+}
+
+// Metrics exposes "connected", 1 while the tunnel is connected, and the seconds
+// since the last WireGuard handshake as "handshake_age".
+func (v *VPNStatus) Metrics() map[string]float64 {
+	if v == nil {
+		return nil
+	}
+
+	metrics := map[string]float64{"connected": 0}
+	if v.Connected() {
+		metrics["connected"] = 1
+	}
+	if !v.LastHandshake.IsZero() {
+		metrics["handshake_age"] = time.Since(v.LastHandshake).Seconds()
+	}
+	return metrics
+}
+
+// VPNInstrument checks the state of the configured WireGuard or OpenVPN interface.
+// Its value is a *VPNStatus, nil while no interface is configured.
+type VPNInstrument struct {
+	getConfig func() *configuration.NexusConfig
+}
+
+// NewVPNInstrument creates a VPN instrument that reads the interface from the
+// configuration returned by getConfig. getConfig must not be nil.
+func NewVPNInstrument(getConfig func() *configuration.NexusConfig) *VPNInstrument {
+	if getConfig == nil {
+		panic("instruments: NewVPNInstrument getConfig is nil")
+	}
+
+	return &VPNInstrument{getConfig: getConfig}
+}
+
+func (v *VPNInstrument) Name() string { return VPNInstrumentName }
+
+func (v *VPNInstrument) Interval() time.Duration { return vpnUpdateInterval }
+
+// Sample checks the configured interface.
+func (v *VPNInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := v.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.VPN.Interface == "" {
+		return (*VPNStatus)(nil), nil
+	}
+
+	return GetVPNStatus(ctx, cfg.VPN.Interface)
+}
+
+// GetVPNStatus returns the state of the VPN interface name, e.g. "wg0" or "tun0".
+// A missing interface is reported as down rather than as an error, as tunnels
+// remove their interface when they disconnect. For WireGuard interfaces the
+// endpoint and latest handshake of the peers are read with "wg show", which needs
+// administrator rights; without it only the interface state is known.
+func GetVPNStatus(ctx context.Context, name string) (*VPNStatus, error) {
+	status := &VPNStatus{Interface: name}
+
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return status, nil
+	}
+	status.Up = iface.Flags&net.FlagUp != 0
+
+	// The dump has a line for the interface followed by a line per peer with the
+	// public key, preshared key, endpoint, allowed IPs, latest handshake in Unix
+	// seconds, received and sent bytes and the keepalive, separated by tabs
+	out, err := exec.CommandContext(ctx, "wg", "show", name, "dump").Output()
+	if err != nil {
+		return status, nil
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 5 {
+			continue
+		}
+		status.WireGuard = true
+
+		// Show the endpoint of the peer with the latest handshake
+		endpoint := fields[2]
+		if endpoint == "(none)" {
+			endpoint = ""
+		}
+		if status.Endpoint == "" {
+			status.Endpoint = endpoint
+		}
+
+		handshake, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil || handshake == 0 {
+			continue
+		}
+		if latest := time.Unix(handshake, 0); latest.After(status.LastHandshake) {
+			status.LastHandshake = latest
+			if endpoint != "" {
+				status.Endpoint = endpoint
+			}
+		}
+	}
+
+	return status, nil
+}
@@ -4,57 +4,96 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
-var tempUnit string
-var windSpeedUnit string
-
 type WeatherInfo struct {
 	Location    string
 	Temperature float64
-	Condition   string
+	Condition   string // Icon of the condition
+	Description string // English name of the condition, e.g. "Partly cloudy"
 	WindSpeed   string
+	Forecast    []DailyForecast // Upcoming days, starting tomorrow
+	UpdatedAt   time.Time       // When the data was fetched
+	Stale       bool            // Whether this is last known data shown after a failed update
+}
+
+// DailyForecast holds the forecast for a single day.
+type DailyForecast struct {
+	Date        time.Time
+	Min         float64
+	Max         float64
+	Condition   string // Icon of the condition
+	Description string // English name of the condition
 }
 
 const (
-	openMeteoBaseURL   = "https://api.open-meteo.com/v1/forecast?temperature_unit=%s&wind_speed_unit=%s&latitude=%.4f&longitude=%.4f&current=temperature_2m,weather_code,wind_speed_10m,is_day"
+	openMeteoBaseURL   = "https://api.open-meteo.com/v1/forecast?temperature_unit=%s&wind_speed_unit=%s&latitude=%.4f&longitude=%.4f&current=temperature_2m,weather_code,wind_speed_10m,is_day&daily=weather_code,temperature_2m_max,temperature_2m_min&timezone=auto&forecast_days=%d"
 	nominatimSearchURL = "https://nominatim.openstreetmap.org/search?q=%s&format=json&limit=1"
 	defaultLat         = 40.7128  // New York, NY
 	defaultLon         = -74.0060 // New York, NY
+	forecastDays       = 3        // Number of upcoming days to forecast
+
+	weatherMaxAttempts  = 3               // Attempts per weather update
+	weatherRetryBackoff = 2 * time.Second // Initial delay between attempts, doubled each retry
+	weatherHTTPTimeout  = 10 * time.Second
 )
 
-func GetWeatherData(location string, unit *string) *WeatherInfo {
+// GetWeatherData fetches current conditions and the forecast for a location in the given
+// unit system ("imperial" or "metric"). Failed requests are retried up to weatherMaxAttempts
+// times with exponential backoff. If the location cannot be resolved, New York, NY is used.
+//
+// Returns an error if all attempts fail or ctx is cancelled; it never terminates the process.
+func GetWeatherData(ctx context.Context, location string, unit string) (*WeatherInfo, error) {
+	resolved, err := ResolveLocation(ctx, location)
+
+	if err != nil {
+		weatherLog.Warn("Failed to resolve location, falling back to New York, NY", "location", location, "error", err)
+		resolved = Location{Name: location, Lat: defaultLat, Lon: defaultLon}
+	}
+
+	return GetWeatherAt(ctx, resolved, unit)
+}
+
+// GetWeatherAt is GetWeatherData for a location that is already resolved.
+func GetWeatherAt(ctx context.Context, resolved Location, unit string) (*WeatherInfo, error) {
 	// Validate and normalize temperature unit
-	if *unit == "imperial" {
-		tempUnit = "fahrenheit"
-		windSpeedUnit = "mph"
-	} else { // metric
-		tempUnit = "celsius"
-		windSpeedUnit = "kmh"
+	tempUnit, windSpeedUnit := "celsius", "kmh"
+	if unit == "imperial" {
+		tempUnit, windSpeedUnit = "fahrenheit", "mph"
 	}
 
-	lat, lon, err := GetCityCoordinates(location)
+	var weather *WeatherInfo
+	var err error
+	for attempt := 0; attempt < weatherMaxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := weatherRetryBackoff << (attempt - 1)
+			weatherLog.Warn("Weather request failed", "error", err, "retry_in", backoff)
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
 
-	if err != nil {
-		log.Printf("Failed to get city coordinates: %v, falling back to New York, NY", err)
-		lat = defaultLat
-		lon = defaultLat
+		weather, err = GetWeatherConditions(ctx, resolved.Lat, resolved.Lon, tempUnit, windSpeedUnit)
+		if err == nil {
+			break
+		}
 	}
 
-	weather, err := GetWeatherConditions(lat, lon)
 	if err != nil {
-		log.Fatalf("Failed to get weather forecast: %v", err)
-		return nil
+		return nil, fmt.Errorf("failed to get weather forecast after %d attempts: %w", weatherMaxAttempts, err)
 	}
 
 	// Set the location in the weather info
-	weather.Location = location
+	weather.Location = resolved.Name
 
-	return weather
+	return weather, nil
 }
 
 // GetCityCoordinates takes a city name as input and returns its geographical coordinates (latitude and longitude)
@@ -76,11 +115,11 @@ func GetWeatherData(location string, unit *string) *WeatherInfo {
 //
 // The function uses the Nominatim API which requires a User-Agent header and returns coordinates as strings
 // that are converted to float64 values before being returned.
-func GetCityCoordinates(location string) (float64, float64, error) {
+func GetCityCoordinates(ctx context.Context, location string) (float64, float64, error) {
 	baseURL := fmt.Sprintf(nominatimSearchURL, url.QueryEscape(location))
 
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL, nil)
+	client := &http.Client{Timeout: weatherHTTPTimeout}
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
 
 	if err != nil {
 		return 0, 0, err
@@ -123,24 +162,35 @@ func GetCityCoordinates(location string) (float64, float64, error) {
 // GetWeatherConditions retrieves current weather information for the specified location.
 //
 // Parameters:
+//   - ctx: Context used to cancel the request
 //   - lat: The latitude of the location (float64)
 //   - lon: The longitude of the location (float64)
 //   - tempUnit: The desired temperature unit ("celsius" or "fahrenheit")
+//   - windSpeedUnit: The desired wind speed unit ("kmh" or "mph")
 //
 // Returns:
 //   - *WeatherInfo: A pointer to a WeatherInfo struct containing:
 //   - Temperature: Current temperature in the specified unit
 //   - Condition: Weather condition description
 //   - WindSpeed: Wind speed formatted to one decimal place
+//   - Forecast: Daily min/max temperature and condition for the next forecastDays days
 //   - error: An error if the API request fails or response parsing fails
 //
 // The function uses the Open-Meteo API to fetch weather data including temperature,
-// weather code, wind speed, and daylight status. It converts the weather code to
-// a human-readable condition description internally.
-func GetWeatherConditions(lat, lon float64) (*WeatherInfo, error) {
-	baseURL := fmt.Sprintf(openMeteoBaseURL, tempUnit, windSpeedUnit, lat, lon)
+// weather code, wind speed, daylight status and the daily forecast. It converts weather
+// codes to human-readable condition descriptions internally.
+func GetWeatherConditions(ctx context.Context, lat, lon float64, tempUnit, windSpeedUnit string) (*WeatherInfo, error) {
+	// Request today plus the upcoming forecast days
+	baseURL := fmt.Sprintf(openMeteoBaseURL, tempUnit, windSpeedUnit, lat, lon, forecastDays+1)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := http.Get(baseURL)
+	client := &http.Client{Timeout: weatherHTTPTimeout}
+	resp, err := client.Do(req)
 
 	if err != nil {
 		return nil, err
@@ -148,6 +198,10 @@ func GetWeatherConditions(lat, lon float64) (*WeatherInfo, error) {
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
 	var result struct {
 		Current struct {
 			Temperature float64 `json:"temperature_2m"`
@@ -155,6 +209,12 @@ func GetWeatherConditions(lat, lon float64) (*WeatherInfo, error) {
 			WindSpeed   float64 `json:"wind_speed_10m"`
 			IsDay       int     `json:"is_day"`
 		} `json:"current"`
+		Daily struct {
+			Time        []string  `json:"time"`
+			WeatherCode []int     `json:"weather_code"`
+			Max         []float64 `json:"temperature_2m_max"`
+			Min         []float64 `json:"temperature_2m_min"`
+		} `json:"daily"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -163,13 +223,66 @@ func GetWeatherConditions(lat, lon float64) (*WeatherInfo, error) {
 
 	condition := weatherCodeToCondition(result.Current.WeatherCode, result.Current.IsDay == 1)
 
+	// Skip today (index 0) and keep the upcoming days
+	var forecast []DailyForecast
+	daily := result.Daily
+	for i := 1; i < len(daily.Time) && i < len(daily.WeatherCode) && i < len(daily.Max) && i < len(daily.Min); i++ {
+		date, err := time.Parse("2006-01-02", daily.Time[i])
+		if err != nil {
+			continue
+		}
+		forecast = append(forecast, DailyForecast{
+			Date:        date,
+			Min:         daily.Min[i],
+			Max:         daily.Max[i],
+			Condition:   weatherCodeToCondition(daily.WeatherCode[i], true),
+			Description: weatherCodeToDescription(daily.WeatherCode[i]),
+		})
+	}
+
 	return &WeatherInfo{
 		Temperature: result.Current.Temperature,
 		Condition:   condition,
+		Description: weatherCodeToDescription(result.Current.WeatherCode),
 		WindSpeed:   fmt.Sprintf("\ue31e %.1f", result.Current.WindSpeed),
+		Forecast:    forecast,
+		UpdatedAt:   time.Now(),
 	}, nil
 }
 
+// weatherCodes are the icons by day and night and the English names of the WMO
+// weather codes reported by Open-Meteo.
+var weatherCodes = map[int]struct{ day, night, name string }{
+	0:  {"\ue30d", "\ue32b", "Clear sky"},
+	1:  {"\ue302", "\ue37e", "Mainly clear"},
+	2:  {"\ue312", "\ue379", "Partly cloudy"},
+	3:  {"\ue33d", "\ue33d", "Cloudy"},
+	45: {"\ue313", "\ue346", "Foggy"},
+	48: {"\ue313", "\ue346", "Rime fog"},
+	51: {"\ue308", "\ue325", "Light drizzle"},
+	53: {"\ue308", "\ue325", "Drizzle"},
+	55: {"\ue318", "\ue318", "Heavy drizzle"},
+	56: {"\ue3aa", "\ue3ac", "Light freezing drizzle"},
+	57: {"\ue3aa", "\ue3ac", "Freezing drizzle"},
+	61: {"\ue308", "\ue325", "Light rain"},
+	63: {"\ue318", "\ue318", "Rain"},
+	65: {"\ue318", "\ue318", "Heavy rain"},
+	66: {"\ue3aa", "\ue3ac", "Light freezing rain"},
+	67: {"\ue3ad", "\ue3ad", "Freezing rain"},
+	71: {"\ue31a", "\ue327", "Light snow"},
+	73: {"\ue30a", "\ue30a", "Snow"},
+	75: {"\ue30a", "\ue30a", "Heavy snow"},
+	77: {"\ue30a", "\ue30a", "Snow grains"},
+	80: {"\ue308", "\ue325", "Light showers"},
+	81: {"\ue318", "\ue318", "Showers"},
+	82: {"\ue318", "\ue318", "Heavy showers"},
+	85: {"\ue31a", "\ue327", "Light snow showers"},
+	86: {"\ue30a", "\ue30a", "Snow showers"},
+	95: {"\ue30f", "\ue32a", "Thunderstorm"},
+	96: {"\ue31d", "\ue31d", "Thunderstorm with hail"},
+	99: {"\ue31d", "\ue31d", "Heavy thunderstorm with hail"},
+}
+
 // weatherCodeToCondition converts a numerical weather code and time of day into a human-readable weather condition string.
 //
 // The function takes two parameters:
@@ -187,37 +300,6 @@ func GetWeatherConditions(lat, lon float64) (*WeatherInfo, error) {
 //   - Showers (80-86)
 //   - Thunderstorms (95-99)
 func weatherCodeToCondition(code int, isDay bool) string {
-	weatherCodes := map[int]struct{ day, night string }{
-		0:  {day: "\ue30d", night: "\ue32b"}, // Clear sky
-		1:  {day: "\ue302", night: "\ue37e"}, // Mainly clear
-		2:  {day: "\ue312", night: "\ue379"}, // Partly cloudy
-		3:  {day: "\ue33d", night: "\ue33d"}, // Cloudy
-		45: {day: "\ue313", night: "\ue346"}, // Foggy
-		48: {day: "\ue313", night: "\ue346"}, // Rime fog
-		51: {day: "\ue308", night: "\ue325"}, // Light drizzle
-		53: {day: "\ue308", night: "\ue325"}, // Drizzle
-		55: {day: "\ue318", night: "\ue318"}, // Heavy drizzle
-		56: {day: "\ue3aa", night: "\ue3ac"}, // Light freezing drizzle
-		57: {day: "\ue3aa", night: "\ue3ac"}, // Freezing drizzle
-		61: {day: "\ue308", night: "\ue325"}, // Light rain
-		63: {day: "\ue318", night: "\ue318"}, // Rain
-		65: {day: "\ue318", night: "\ue318"}, // Heavy rain
-		66: {day: "\ue3aa", night: "\ue3ac"}, // Light freezing rain
-		67: {day: "\ue3ad", night: "\ue3ad"}, // Freezing rain
-		71: {day: "\ue31a", night: "\ue327"}, // Light snow
-		73: {day: "\ue30a", night: "\ue30a"}, // Snow
-		75: {day: "\ue30a", night: "\ue30a"}, // Heavy snow
-		77: {day: "\ue30a", night: "\ue30a"}, // Snow grains
-		80: {day: "\ue308", night: "\ue325"}, // Light showers
-		81: {day: "\ue318", night: "\ue318"}, // Showers
-		82: {day: "\ue318", night: "\ue318"}, // Heavy showers
-		85: {day: "\ue31a", night: "\ue327"}, // Light snow showers
-		86: {day: "\ue30a", night: "\ue30a"}, // Snow showers
-		95: {day: "\ue30f", night: "\ue32a"}, // Thunderstorm
-		96: {day: "\ue31d", night: "\ue31d"}, // Thunderstorm with hail
-		99: {day: "\ue31d", night: "\ue31d"}, // Heavy thunderstorm with hail
-	}
-
 	if weather, ok := weatherCodes[code]; ok {
 		if isDay {
 			return weather.day
@@ -226,3 +308,12 @@ func weatherCodeToCondition(code int, isDay bool) string {
 	}
 	return "❓"
 }
+
+// weatherCodeToDescription returns the English name of a WMO weather code, e.g.
+// "Partly cloudy", or "Unknown" if the code is not recognized.
+func weatherCodeToDescription(code int) string {
+	if weather, ok := weatherCodes[code]; ok {
+		return weather.name
+	}
+	return "Unknown"
+}
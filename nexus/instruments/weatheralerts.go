@@ -0,0 +1,169 @@
+package instruments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"nexus-open/nexus/configuration"
+	"sort"
+	"time"
+)
+
+const (
+	WeatherAlertsInstrumentName = "weather_alerts"
+
+	weatherAlertsUpdateInterval = 5 * time.Minute
+	nwsAlertsURL                = "https://api.weather.gov/alerts/active?point=%.4f,%.4f"
+)
+
+// WeatherAlert is an active severe weather alert for the configured location.
+type WeatherAlert struct {
+	Event    string    // e.g. "Tornado Warning"
+	Severity string    // "Extreme", "Severe" or "Moderate"
+	Headline string    // Full headline issued by the provider
+	Expires  time.Time // When the alert ends, zero if unknown
+}
+
+// WeatherAlerts is the set of active alerts, most severe first. An empty set
+// means no alerts are in effect.
+type WeatherAlerts []WeatherAlert
+
+// Metrics exposes the number of active alerts as "count".
+func (a WeatherAlerts) Metrics() map[string]float64 {
+	return map[string]float64{"count": float64(len(a))}
+}
+
+// alertSeverityRank orders severities from most to least severe. Severities not
+// listed here (Minor, Unknown) are not shown on the panel.
+var alertSeverityRank = map[string]int{
+	"Extreme":  0,
+	"Severe":   1,
+	"Moderate": 2,
+}
+
+// WeatherAlertsInstrument polls the National Weather Service for active alerts
+// at the configured location. Locations outside NWS coverage report no alerts.
+type WeatherAlertsInstrument struct {
+	getConfig    func() *configuration.NexusConfig
+	lastLocation string
+	lat, lon     float64
+}
+
+// NewWeatherAlertsInstrument creates a weather alerts instrument that reads the
+// location from the configuration returned by getConfig. getConfig must not be nil.
+func NewWeatherAlertsInstrument(getConfig func() *configuration.NexusConfig) *WeatherAlertsInstrument {
+	if getConfig == nil {
+		panic("instruments: NewWeatherAlertsInstrument getConfig is nil")
+	}
+
+	return &WeatherAlertsInstrument{getConfig: getConfig}
+}
+
+func (w *WeatherAlertsInstrument) Name() string { return WeatherAlertsInstrumentName }
+
+func (w *WeatherAlertsInstrument) Interval() time.Duration { return weatherAlertsUpdateInterval }
+
+// Sample fetches the active alerts for the configured location. Coordinates are
+// only looked up again when the location changes.
+func (w *WeatherAlertsInstrument) Sample(ctx context.Context) (Value, error) {
+	cfg := w.getConfig()
+
+	if cfg == nil {
+		return nil, fmt.Errorf("no config available")
+	}
+
+	if cfg.Location == "" {
+		return WeatherAlerts{}, nil
+	}
+
+	// Automatic locations may move, so they are resolved on every sample (cached by DetectLocation)
+	if lat, lon, ok := cfg.Geocoded.For(cfg.Location); ok {
+		w.lat, w.lon = lat, lon
+		w.lastLocation = cfg.Location
+	} else if w.lastLocation != cfg.Location || cfg.Location == configuration.LocationAuto {
+		resolved, err := ResolveLocation(ctx, cfg.Location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve location: %v", err)
+		}
+		w.lat, w.lon = resolved.Lat, resolved.Lon
+		w.lastLocation = cfg.Location
+	}
+
+	return GetWeatherAlerts(ctx, w.lat, w.lon)
+}
+
+// GetWeatherAlerts retrieves the active Extreme, Severe and Moderate alerts for the
+// given coordinates from the National Weather Service, most severe first.
+// Coordinates outside NWS coverage yield an empty result rather than an error.
+func GetWeatherAlerts(ctx context.Context, lat, lon float64) (WeatherAlerts, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(nwsAlertsURL, lat, lon), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// NWS requires a User-Agent identifying the application
+	req.Header.Set("User-Agent", "Nexus Next/1.0")
+	req.Header.Set("Accept", "application/geo+json")
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	// Points outside the United States are rejected by the API
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return WeatherAlerts{}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Features []struct {
+			Properties struct {
+				Event    string `json:"event"`
+				Severity string `json:"severity"`
+				Headline string `json:"headline"`
+				Expires  string `json:"expires"`
+				Ends     string `json:"ends"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode alerts: %w", err)
+	}
+
+	alerts := WeatherAlerts{}
+	for _, feature := range result.Features {
+		properties := feature.Properties
+		if _, ok := alertSeverityRank[properties.Severity]; !ok {
+			continue
+		}
+
+		// Prefer the end of the event over the expiry of the message itself
+		expires, _ := time.Parse(time.RFC3339, properties.Ends)
+		if expires.IsZero() {
+			expires, _ = time.Parse(time.RFC3339, properties.Expires)
+		}
+
+		alerts = append(alerts, WeatherAlert{
+			Event:    properties.Event,
+			Severity: properties.Severity,
+			Headline: properties.Headline,
+			Expires:  expires,
+		})
+	}
+
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alertSeverityRank[alerts[i].Severity] < alertSeverityRank[alerts[j].Severity]
+	})
+
+	return alerts, nil
+}
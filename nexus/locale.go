@@ -0,0 +1,98 @@
+package nexus
+
+import (
+	"slices"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Regions writing dates month first, and day first with dots, e.g. "31.12."
+var (
+	monthFirstRegions = []string{"US", "PH", "FM", "MH", "PW", "BZ", "CA", "CN", "JP", "KR", "TW", "HU", "LT", "MN"}
+	dottedDateRegions = []string{"DE", "AT", "CH", "LI", "RU", "BY", "UA", "PL", "CZ", "SK", "FI", "NO", "DK", "TR", "RO", "HR", "RS", "SI"}
+)
+
+// displayLocale formats numbers and dates drawn on the display and translates
+// its texts.
+type displayLocale struct {
+	printer    *message.Printer
+	translator *message.Printer // Printer of the display language with the messages catalog
+	dateLayout string           // time layout of a day and month
+}
+
+// SetLocale sets the BCP 47 locale used to format numbers and dates on the
+// display, e.g. "de-DE" for decimal commas and "31.12.", and the language its texts
+// are translated to, e.g. "de". An empty or invalid locale formats like "en-US",
+// an empty or invalid language is the language of the locale. This function is
+// safe for concurrent use.
+func SetLocale(locale, lang string) {
+	defaultNexus.renderer.SetLocale(locale, lang)
+}
+
+// SetLocale sets the locale and language of r, see the package function SetLocale.
+func (r *Renderer) SetLocale(locale, lang string) {
+	r.locale.Store(newDisplayLocale(locale, lang))
+}
+
+// newDisplayLocale returns the displayLocale of a BCP 47 locale and language, see
+// SetLocale.
+func newDisplayLocale(locale, lang string) *displayLocale {
+	tag, err := language.Parse(locale)
+	if locale == "" || err != nil {
+		tag = language.AmericanEnglish
+	}
+
+	langTag, err := language.Parse(lang)
+	if lang == "" || err != nil {
+		langTag = tag
+	}
+
+	region, _ := tag.Region()
+	dateLayout := "02/01"
+	switch {
+	case slices.Contains(monthFirstRegions, region.String()):
+		dateLayout = "01/02"
+	case slices.Contains(dottedDateRegions, region.String()):
+		dateLayout = "02.01."
+	}
+
+	return &displayLocale{
+		printer:    message.NewPrinter(tag),
+		translator: message.NewPrinter(langTag, message.Catalog(messages)),
+		dateLayout: dateLayout,
+	}
+}
+
+// translate returns the text of key in the language of l, formatted with args like
+// fmt.Sprintf. Keys are the English texts, returned for languages without a
+// translation.
+func (l *displayLocale) translate(key string, args ...interface{}) string {
+	return l.translator.Sprintf(key, args...)
+}
+
+// formatDecimal formats value with the given number of decimals and the
+// separators of the display locale.
+func (r *Renderer) formatDecimal(value float64, decimals int) string {
+	return r.locale.Load().printer.Sprint(number.Decimal(value, number.Scale(decimals)))
+}
+
+// dateLayout returns the time layout of a day and month in the display locale,
+// e.g. "01/02" for the United States.
+func (r *Renderer) dateLayout() string {
+	return r.locale.Load().dateLayout
+}
+
+// translate returns the text of key in the display language, see
+// displayLocale.translate.
+func (r *Renderer) translate(key string, args ...interface{}) string {
+	return r.locale.Load().translate(key, args...)
+}
+
+// weekdayName returns the abbreviated name of the weekday of t in the display
+// language, e.g. "Mon".
+func (r *Renderer) weekdayName(t time.Time) string {
+	return r.translate(t.Format("Mon"))
+}
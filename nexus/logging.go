@@ -0,0 +1,88 @@
+package nexus
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/logging"
+)
+
+// Destinations of log messages accepted by SetLogOutput
+const (
+	LogOutputStderr = "stderr" // Standard error only
+	LogOutputFile   = "file"   // The log file only, see configuration.GetLogPath
+	LogOutputBoth   = "both"   // Standard error and the log file
+)
+
+// Loggers of the components of the display engine.
+var (
+	usbLog      = logging.Component("usb")
+	renderLog   = logging.Component("render")
+	touchLog    = logging.Component("touch")
+	configLog   = logging.Component("config")
+	apiLog      = logging.Component("api")
+	webhookLog  = logging.Component("webhooks")
+	dbusLog     = logging.Component("dbus")
+	scheduleLog = logging.Component("schedules")
+	pluginsLog  = logging.Component("plugins")
+	idleLog     = logging.Component("idle")
+	notifyLog   = logging.Component("notifications")
+)
+
+// SetLogLevel sets the minimum level of logged messages: "debug", "info", "warn"
+// or "error".
+func SetLogLevel(level string) error {
+	return logging.SetLevel(level)
+}
+
+// SetLogFormat writes log messages as text or JSON ("text" or "json"), see
+// logging.SetFormat.
+func SetLogFormat(format string) error {
+	return logging.SetFormat(format)
+}
+
+// logFile is the log file written by SetLogOutput, nil if messages are only
+// written to standard error.
+var logFile *logging.RotatingFile
+
+// SetLogOutput writes log messages to standard error, the rotating log file in the
+// config directory or both (LogOutputStderr, LogOutputFile or LogOutputBoth). The
+// file is rotated once it would grow beyond maxSize bytes, and rotated files older
+// than maxAge are removed. It returns a function closing the file, and must be
+// called after SetLogFormat.
+func SetLogOutput(output string, maxSize int64, maxAge time.Duration) (func() error, error) {
+	switch output {
+	case LogOutputStderr:
+		logFile = nil
+		logging.SetOutput(os.Stderr)
+		return func() error { return nil }, nil
+	case LogOutputFile, LogOutputBoth:
+	default:
+		return nil, fmt.Errorf("invalid log output %q, expected %s, %s or %s", output, LogOutputStderr, LogOutputFile, LogOutputBoth)
+	}
+
+	path, err := configuration.GetLogPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := logging.OpenRotatingFile(path, maxSize, maxAge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	if err := file.CaptureCrashes(); err != nil {
+		slog.Warn("Failed to write crashes to the log file", "error", err)
+	}
+
+	logFile = file
+	if output == LogOutputFile {
+		logging.SetOutput(file)
+	} else {
+		logging.SetOutput(io.MultiWriter(os.Stderr, file))
+	}
+	return file.Close, nil
+}
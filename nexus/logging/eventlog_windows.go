@@ -0,0 +1,85 @@
+//go:build windows
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventID is the event ID of every message written to the event log.
+const eventID = 1
+
+// UseEventLog installs a default handler writing to the Windows event log as
+// source, for services without a console. Messages are formatted as by the text
+// handler, without the time the event log records itself. It returns a function
+// closing the event log.
+func UseEventLog(source string) (func() error, error) {
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &eventLogWriter{log: log}
+	handler := slog.NewTextHandler(writer, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+
+	slog.SetDefault(slog.New(eventLogHandler{Handler: handler, writer: writer}))
+	return log.Close, nil
+}
+
+// eventLogWriter writes formatted records to the event log with the event type of
+// the level of the record being handled.
+type eventLogWriter struct {
+	mu    sync.Mutex // Held while a record is handled
+	log   *eventlog.Log
+	level slog.Level
+}
+
+func (w *eventLogWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSuffix(string(p), "\n")
+
+	var err error
+	switch {
+	case w.level >= slog.LevelError:
+		err = w.log.Error(eventID, message)
+	case w.level >= slog.LevelWarn:
+		err = w.log.Warning(eventID, message)
+	default:
+		err = w.log.Info(eventID, message)
+	}
+	return len(p), err
+}
+
+// eventLogHandler formats records with a text handler writing to the event log.
+type eventLogHandler struct {
+	slog.Handler
+	writer *eventLogWriter
+}
+
+func (h eventLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.writer.mu.Lock()
+	defer h.writer.mu.Unlock()
+
+	h.writer.level = r.Level
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h eventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return eventLogHandler{Handler: h.Handler.WithAttrs(attrs), writer: h.writer}
+}
+
+func (h eventLogHandler) WithGroup(name string) slog.Handler {
+	return eventLogHandler{Handler: h.Handler.WithGroup(name), writer: h.writer}
+}
@@ -0,0 +1,102 @@
+// Package logging configures the structured logger of Nexus and provides the
+// loggers of its components. Records of a component logger carry a component
+// attribute, for example component=usb, and are written by the handler installed
+// with SetFormat, including the ones of the standard log package.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Formats accepted by SetFormat.
+const (
+	FormatText = "text" // key=value pairs, see slog.TextHandler
+	FormatJSON = "json" // one JSON object per line, see slog.JSONHandler
+)
+
+// level is the minimum level of the handler installed by SetFormat.
+var level = new(slog.LevelVar)
+
+// Format and destination of the handler installed by SetFormat
+var (
+	format           = FormatText
+	output io.Writer = os.Stderr
+)
+
+// SetLevel sets the minimum level of messages logged by the handler installed with
+// SetFormat: "debug", "info", "warn" or "error".
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("invalid log level %q, expected debug, info, warn or error", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+// SetFormat installs the default handler writing to standard error, or the
+// writer set with SetOutput, in format, FormatText or FormatJSON. Messages of the
+// standard log package are written through it at level info.
+func SetFormat(name string) error {
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch name {
+	case FormatText:
+		handler = slog.NewTextHandler(output, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, options)
+	default:
+		return fmt.Errorf("invalid log format %q, expected %s or %s", name, FormatText, FormatJSON)
+	}
+
+	format = name
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetOutput installs the default handler writing to w in the format last set with
+// SetFormat, text by default.
+func SetOutput(w io.Writer) {
+	output = w
+	SetFormat(format)
+}
+
+// Component returns the logger of the named component. It writes through the
+// default handler at the time of logging, so it may be created before SetFormat
+// is called.
+func Component(name string) *slog.Logger {
+	return slog.New(componentHandler{wrap: func(h slog.Handler) slog.Handler {
+		return h.WithAttrs([]slog.Attr{slog.String("component", name)})
+	}})
+}
+
+// componentHandler passes records to the handler of the default logger, wrapped
+// with the attributes and groups added to the component logger.
+type componentHandler struct {
+	wrap func(slog.Handler) slog.Handler
+}
+
+func (h componentHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return slog.Default().Handler().Enabled(ctx, l)
+}
+
+func (h componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.wrap(slog.Default().Handler()).Handle(ctx, r)
+}
+
+func (h componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return componentHandler{wrap: func(next slog.Handler) slog.Handler {
+		return h.wrap(next).WithAttrs(attrs)
+	}}
+}
+
+func (h componentHandler) WithGroup(name string) slog.Handler {
+	return componentHandler{wrap: func(next slog.Handler) slog.Handler {
+		return h.wrap(next).WithGroup(name)
+	}}
+}
@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat is the time a log file was rotated at, added to its name.
+const rotatedTimeFormat = "20060102T150405"
+
+// RotatingFile is a log file that is renamed once it reaches a size, and a new file
+// started. Rotated files are named after the file with the time of the rotation,
+// e.g. nexus-20240131T120000.log, and removed once they are older than the maximum
+// age. It is safe for concurrent use.
+type RotatingFile struct {
+	path    string
+	maxSize int64         // Rotate before the file grows beyond, 0 to never rotate
+	maxAge  time.Duration // Remove rotated files older than, 0 to keep them
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	crashes bool // Fatal errors of the runtime are written to the file
+}
+
+// OpenRotatingFile opens the log file at path, appending to it if it exists, and
+// removes rotated files older than maxAge.
+func OpenRotatingFile(path string, maxSize int64, maxAge time.Duration) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxAge: maxAge}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.prune()
+	return f, nil
+}
+
+// Write writes p to the file, rotating it first if p would make it exceed the
+// maximum size. A record is never split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the full file rather than losing messages
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// CaptureCrashes writes the fatal errors of the runtime, like unrecovered panics,
+// to the file in addition to standard error, also after rotations.
+func (f *RotatingFile) CaptureCrashes() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.crashes = true
+	return debug.SetCrashOutput(f.file, debug.CrashOptions{})
+}
+
+// Close closes the file. Writes after Close fail.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	if f.crashes {
+		debug.SetCrashOutput(nil, debug.CrashOptions{})
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file for appending and records its size.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file, f.size = file, info.Size()
+	if f.crashes {
+		debug.SetCrashOutput(file, debug.CrashOptions{})
+	}
+	return nil
+}
+
+// rotate renames the file after the current time and starts a new one. f.mu must
+// be held.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	// Files rotated within the same second are numbered
+	ext := filepath.Ext(f.path)
+	base := fmt.Sprintf("%s-%s", strings.TrimSuffix(f.path, ext), time.Now().Format(rotatedTimeFormat))
+	rotated := base + ext
+	for i := 1; fileExists(rotated); i++ {
+		rotated = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	renameErr := os.Rename(f.path, rotated)
+
+	// Reopen even if the rename failed, so that writing continues
+	if err := f.open(); err != nil {
+		f.file = nil
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	go f.prune()
+	return nil
+}
+
+// prune removes rotated files older than the maximum age.
+func (f *RotatingFile) prune() {
+	if f.maxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	rotated, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+
+	for _, path := range rotated {
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > f.maxAge {
+			os.Remove(path)
+		}
+	}
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
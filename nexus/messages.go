@@ -0,0 +1,230 @@
+package nexus
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message/catalog"
+)
+
+// messages is the catalog of the texts drawn on the display, keyed by their English
+// text, see translate.
+var messages = catalog.NewBuilder(catalog.Fallback(language.English))
+
+// translations are the texts of the display in languages other than English: the
+// widget labels, the weekday abbreviations and the names of the weather conditions
+// in instruments.WeatherInfo.Description.
+var translations = map[language.Tag]map[string]string{
+	language.German: {
+		// Labels
+		"Muted":    "Stumm",
+		"until %s": "bis %s",
+		"today":    "heute",
+		"in %dm":   "in %d Min.",
+		"Work":     "Arbeit",
+		"Break":    "Pause",
+		"Paused":   "Angehalten",
+		"Alarm":    "Wecker",
+
+		// Weekdays
+		"Mon": "Mo",
+		"Tue": "Di",
+		"Wed": "Mi",
+		"Thu": "Do",
+		"Fri": "Fr",
+		"Sat": "Sa",
+		"Sun": "So",
+
+		// Weather conditions
+		"Clear sky":                    "Klarer Himmel",
+		"Mainly clear":                 "Überwiegend klar",
+		"Partly cloudy":                "Teilweise bewölkt",
+		"Cloudy":                       "Bewölkt",
+		"Foggy":                        "Neblig",
+		"Rime fog":                     "Reifnebel",
+		"Light drizzle":                "Leichter Nieselregen",
+		"Drizzle":                      "Nieselregen",
+		"Heavy drizzle":                "Starker Nieselregen",
+		"Light freezing drizzle":       "Leichter gefrierender Nieselregen",
+		"Freezing drizzle":             "Gefrierender Nieselregen",
+		"Light rain":                   "Leichter Regen",
+		"Rain":                         "Regen",
+		"Heavy rain":                   "Starker Regen",
+		"Light freezing rain":          "Leichter gefrierender Regen",
+		"Freezing rain":                "Gefrierender Regen",
+		"Light snow":                   "Leichter Schneefall",
+		"Snow":                         "Schneefall",
+		"Heavy snow":                   "Starker Schneefall",
+		"Snow grains":                  "Schneegriesel",
+		"Light showers":                "Leichte Schauer",
+		"Showers":                      "Schauer",
+		"Heavy showers":                "Starke Schauer",
+		"Light snow showers":           "Leichte Schneeschauer",
+		"Snow showers":                 "Schneeschauer",
+		"Thunderstorm":                 "Gewitter",
+		"Thunderstorm with hail":       "Gewitter mit Hagel",
+		"Heavy thunderstorm with hail": "Schweres Gewitter mit Hagel",
+		"Unknown":                      "Unbekannt",
+	},
+	language.French: {
+		// Labels
+		"Muted":    "Muet",
+		"until %s": "jusqu'à %s",
+		"today":    "aujourd'hui",
+		"in %dm":   "dans %d min",
+		"Work":     "Travail",
+		"Break":    "Pause",
+		"Paused":   "En pause",
+		"Alarm":    "Réveil",
+
+		// Weekdays
+		"Mon": "lun.",
+		"Tue": "mar.",
+		"Wed": "mer.",
+		"Thu": "jeu.",
+		"Fri": "ven.",
+		"Sat": "sam.",
+		"Sun": "dim.",
+
+		// Weather conditions
+		"Clear sky":                    "Ciel dégagé",
+		"Mainly clear":                 "Plutôt dégagé",
+		"Partly cloudy":                "Partiellement nuageux",
+		"Cloudy":                       "Nuageux",
+		"Foggy":                        "Brouillard",
+		"Rime fog":                     "Brouillard givrant",
+		"Light drizzle":                "Bruine légère",
+		"Drizzle":                      "Bruine",
+		"Heavy drizzle":                "Forte bruine",
+		"Light freezing drizzle":       "Bruine verglaçante légère",
+		"Freezing drizzle":             "Bruine verglaçante",
+		"Light rain":                   "Pluie légère",
+		"Rain":                         "Pluie",
+		"Heavy rain":                   "Forte pluie",
+		"Light freezing rain":          "Pluie verglaçante légère",
+		"Freezing rain":                "Pluie verglaçante",
+		"Light snow":                   "Neige légère",
+		"Snow":                         "Neige",
+		"Heavy snow":                   "Forte neige",
+		"Snow grains":                  "Neige en grains",
+		"Light showers":                "Averses légères",
+		"Showers":                      "Averses",
+		"Heavy showers":                "Fortes averses",
+		"Light snow showers":           "Averses de neige légères",
+		"Snow showers":                 "Averses de neige",
+		"Thunderstorm":                 "Orage",
+		"Thunderstorm with hail":       "Orage avec grêle",
+		"Heavy thunderstorm with hail": "Violent orage avec grêle",
+		"Unknown":                      "Inconnu",
+	},
+	language.Spanish: {
+		// Labels
+		"Muted":    "Silencio",
+		"until %s": "hasta %s",
+		"today":    "hoy",
+		"in %dm":   "en %d min",
+		"Work":     "Trabajo",
+		"Break":    "Descanso",
+		"Paused":   "En pausa",
+		"Alarm":    "Alarma",
+
+		// Weekdays
+		"Mon": "lun",
+		"Tue": "mar",
+		"Wed": "mié",
+		"Thu": "jue",
+		"Fri": "vie",
+		"Sat": "sáb",
+		"Sun": "dom",
+
+		// Weather conditions
+		"Clear sky":                    "Cielo despejado",
+		"Mainly clear":                 "Mayormente despejado",
+		"Partly cloudy":                "Parcialmente nublado",
+		"Cloudy":                       "Nublado",
+		"Foggy":                        "Niebla",
+		"Rime fog":                     "Niebla engelante",
+		"Light drizzle":                "Llovizna ligera",
+		"Drizzle":                      "Llovizna",
+		"Heavy drizzle":                "Llovizna intensa",
+		"Light freezing drizzle":       "Llovizna helada ligera",
+		"Freezing drizzle":             "Llovizna helada",
+		"Light rain":                   "Lluvia ligera",
+		"Rain":                         "Lluvia",
+		"Heavy rain":                   "Lluvia intensa",
+		"Light freezing rain":          "Lluvia helada ligera",
+		"Freezing rain":                "Lluvia helada",
+		"Light snow":                   "Nevada ligera",
+		"Snow":                         "Nieve",
+		"Heavy snow":                   "Nevada intensa",
+		"Snow grains":                  "Cinarra",
+		"Light showers":                "Chubascos ligeros",
+		"Showers":                      "Chubascos",
+		"Heavy showers":                "Chubascos fuertes",
+		"Light snow showers":           "Chubascos de nieve ligeros",
+		"Snow showers":                 "Chubascos de nieve",
+		"Thunderstorm":                 "Tormenta",
+		"Thunderstorm with hail":       "Tormenta con granizo",
+		"Heavy thunderstorm with hail": "Tormenta fuerte con granizo",
+		"Unknown":                      "Desconocido",
+	},
+	language.Dutch: {
+		// Labels
+		"Muted":    "Gedempt",
+		"until %s": "tot %s",
+		"today":    "vandaag",
+		"in %dm":   "over %d min",
+		"Work":     "Werk",
+		"Break":    "Pauze",
+		"Paused":   "Gepauzeerd",
+		"Alarm":    "Wekker",
+
+		// Weekdays
+		"Mon": "ma",
+		"Tue": "di",
+		"Wed": "wo",
+		"Thu": "do",
+		"Fri": "vr",
+		"Sat": "za",
+		"Sun": "zo",
+
+		// Weather conditions
+		"Clear sky":                    "Onbewolkt",
+		"Mainly clear":                 "Overwegend helder",
+		"Partly cloudy":                "Half bewolkt",
+		"Cloudy":                       "Bewolkt",
+		"Foggy":                        "Mist",
+		"Rime fog":                     "Rijpmist",
+		"Light drizzle":                "Lichte motregen",
+		"Drizzle":                      "Motregen",
+		"Heavy drizzle":                "Zware motregen",
+		"Light freezing drizzle":       "Lichte onderkoelde motregen",
+		"Freezing drizzle":             "Onderkoelde motregen",
+		"Light rain":                   "Lichte regen",
+		"Rain":                         "Regen",
+		"Heavy rain":                   "Zware regen",
+		"Light freezing rain":          "Lichte onderkoelde regen",
+		"Freezing rain":                "Onderkoelde regen",
+		"Light snow":                   "Lichte sneeuw",
+		"Snow":                         "Sneeuw",
+		"Heavy snow":                   "Zware sneeuw",
+		"Snow grains":                  "Motsneeuw",
+		"Light showers":                "Lichte buien",
+		"Showers":                      "Buien",
+		"Heavy showers":                "Zware buien",
+		"Light snow showers":           "Lichte sneeuwbuien",
+		"Snow showers":                 "Sneeuwbuien",
+		"Thunderstorm":                 "Onweer",
+		"Thunderstorm with hail":       "Onweer met hagel",
+		"Heavy thunderstorm with hail": "Zwaar onweer met hagel",
+		"Unknown":                      "Onbekend",
+	},
+}
+
+func init() {
+	for tag, texts := range translations {
+		for key, text := range texts {
+			if err := messages.SetString(tag, key, text); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
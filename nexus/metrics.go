@@ -0,0 +1,195 @@
+package nexus
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/instruments"
+)
+
+// renderBuckets are the upper bounds in seconds of the render duration histogram.
+var renderBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// histogram counts observations in buckets, written cumulatively like a Prometheus
+// histogram. It is not safe for concurrent use.
+type histogram struct {
+	bounds []float64
+	counts []uint64 // Observations per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogram(bounds []float64) histogram {
+	return histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+// observe records a value.
+func (h *histogram) observe(v float64) {
+	if i := sort.SearchFloat64s(h.bounds, v); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format.
+type metricsWriter struct {
+	w *bufio.Writer
+}
+
+// family starts a metric family with its type and help text.
+func (m *metricsWriter) family(name, kind, help string) {
+	fmt.Fprintf(m.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample. labels are name and value pairs.
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.w.WriteString(name)
+	if len(labels) > 0 {
+		m.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.w.WriteByte(',')
+			}
+			fmt.Fprintf(m.w, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		m.w.WriteByte('}')
+	}
+	m.w.WriteByte(' ')
+	m.w.WriteString(formatMetricValue(value))
+	m.w.WriteByte('\n')
+}
+
+// histogram writes the buckets, sum and count of h.
+func (m *metricsWriter) histogram(name string, h histogram) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		m.sample(name+"_bucket", float64(cumulative), "le", formatMetricValue(bound))
+	}
+	m.sample(name+"_bucket", float64(h.count), "le", "+Inf")
+	m.sample(name+"_sum", h.sum)
+	m.sample(name+"_count", float64(h.count))
+}
+
+// labelEscaper escapes label values as required by the exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatMetricValue formats v as Prometheus expects, including infinities and NaN.
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// boolMetric returns 1 for true and 0 for false.
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// writeMetrics writes the frame and USB error counters and the render duration.
+func (s *renderStats) writeMetrics(m *metricsWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fps := s.fps
+	if time.Since(s.windowStart) > 2*time.Second {
+		fps = 0
+	}
+
+	m.family("nexus_frames_sent_total", "counter", "Frames written to the device.")
+	m.sample("nexus_frames_sent_total", float64(s.framesSent))
+	m.family("nexus_frames_dropped_total", "counter", "Frames that failed to be written to the device.")
+	m.sample("nexus_frames_dropped_total", float64(s.dropped))
+	m.family("nexus_frames_skipped_total", "counter", "Frames not drawn or sent because earlier frames were late.")
+	m.sample("nexus_frames_skipped_total", float64(s.skipped))
+	m.family("nexus_usb_errors_total", "counter", "USB errors while writing frames or reading touch events.")
+	m.sample("nexus_usb_errors_total", float64(s.usbErrors))
+	m.family("nexus_frame_rate", "gauge", "Frames sent per second.")
+	m.sample("nexus_frame_rate", fps)
+	m.family("nexus_render_duration_seconds", "histogram", "Time taken to render and send an internal frame.")
+	m.histogram("nexus_render_duration_seconds", s.render)
+}
+
+// metricsHandler serves the daemon metrics in the Prometheus text format (GET
+// /metrics). The latest instrument values are included when api.metrics_instruments
+// is enabled.
+func (n *Nexus) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	m := &metricsWriter{w: bufio.NewWriter(w)}
+	defer m.w.Flush()
+
+	isConnected := n.transport.Attached()
+
+	paused, _ := n.pause.active()
+
+	m.family("nexus_start_time_seconds", "gauge", "Start time of the process since the Unix epoch in seconds.")
+	m.sample("nexus_start_time_seconds", float64(startTime.UnixNano())/1e9)
+	m.family("nexus_device_connected", "gauge", "Whether the display is connected.")
+	m.sample("nexus_device_connected", boolMetric(isConnected))
+	m.family("nexus_display_on", "gauge", "Whether the display is switched on.")
+	m.sample("nexus_display_on", boolMetric(n.power()))
+	m.family("nexus_display_paused", "gauge", "Whether display updates are paused.")
+	m.sample("nexus_display_paused", boolMetric(paused))
+	m.family("nexus_display_brightness_percent", "gauge", "Display brightness in percent.")
+	m.sample("nexus_display_brightness_percent", float64(n.transport.Brightness()))
+
+	n.stats.writeMetrics(m)
+
+	names := n.history.Names()
+	latest := make([]instruments.Reading, 0, len(names))
+	for _, name := range names {
+		if reading, ok := n.history.Latest(name); ok {
+			latest = append(latest, reading)
+		}
+	}
+
+	m.family("nexus_instrument_sample_age_seconds", "gauge", "Seconds since the latest sample of an instrument.")
+	for _, reading := range latest {
+		m.sample("nexus_instrument_sample_age_seconds", time.Since(reading.Time).Seconds(), "instrument", reading.Name)
+	}
+
+	if cfg := n.configs.Get(); cfg == nil || !cfg.API.MetricsInstruments {
+		return
+	}
+
+	m.family("nexus_instrument_value", "gauge", "Latest value of an instrument metric, e.g. temperature cpu.")
+	for _, reading := range latest {
+		metered, ok := reading.Value.(instruments.Metered)
+		if !ok {
+			continue
+		}
+
+		values := metered.Metrics()
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			m.sample("nexus_instrument_value", values[key], "instrument", reading.Name, "metric", key)
+		}
+	}
+}
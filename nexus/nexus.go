@@ -1,97 +1,350 @@
 package nexus
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"nexus-open/nexus/api"
 	"nexus-open/nexus/configuration"
 	"nexus-open/nexus/instruments"
+	"nexus-open/nexus/nexusdisplay"
 	"sync"
-
-	"github.com/google/gousb"
+	"sync/atomic"
+	"time"
 )
 
-// Device-specific constants
+// Display settings
 const (
-	vid = 0x1b1c // Corsair Vendor ID
-	pid = 0x1b8e // iCUE Nexus Product ID
+	width             = nexusdisplay.Width  // Display width in pixels
+	height            = nexusdisplay.Height // Display height in pixels
+	brightness        = 2                   // Display brightness (0-2)
+	screenRefreshRate = 24                  // Refresh rate in Hz
+	configRefreshRate = 1                   // Configuration refresh rate in seconds when file events are unavailable
 )
 
-// Display settings
+// configDebounce is how long watchConfig waits after the last change to the
+// configuration file before reloading it.
+const configDebounce = 100 * time.Millisecond
+
+// Metrics history settings
 const (
-	width             = 640 // Display width in pixels
-	height            = 48  // Display height in pixels
-	brightness        = 2   // Display brightness (0-2)
-	screenRefreshRate = 24  // Refresh rate in Hz
-	configRefreshRate = 1   // Configuration refresh rate in seconds
+	historyRetention = time.Hour // How long instrument readings are kept, enough for the longest graph
+	historyCapacity  = 3600      // Maximum readings kept per instrument, one per second
 )
 
-// Configuration variables
-var (
-	unit     = "imperial" // Temperature/wind speed unit (imperial/metric)
-	location string       // User's location (city, country
-)
+// shutdownTimeout bounds how long Run waits for active API requests on shutdown.
+const shutdownTimeout = 5 * time.Second
 
-// Device connection state
-var (
-	device    *gousb.Device    // Nexus USB device
-	usbintf   *gousb.Interface // Nexus USB interface
-	connected bool             // Connection status
-)
+// volumeWatchRetry is how long watchVolume waits before watching volume changes
+// again after watching stopped.
+const volumeWatchRetry = 30 * time.Second
 
-// Configuration state
-var (
-	config          *configuration.NexusConfig
-	configMu        sync.RWMutex
-	updateCh        = make(chan struct{}, 1) // Channel to signal config updates
-	weatherUpdateCh chan<- struct{}          // Channel to trigger weather updates
-)
+// Nexus holds the state of a running display: the USB transport, the renderer,
+// the configuration, the instruments and what the display shows. Its components
+// lock their own state, so the display loop, the touch monitor and the API can
+// share them, and nothing is shared between two Nexus values.
+type Nexus struct {
+	transport *Transport        // Connection to the device
+	renderer  *Renderer         // Draws frames, owned by the display loop
+	frames    *framePipeline    // Hands frames from the display loop to the frame sender
+	configs   *ConfigStore      // Current configuration
+	gate      *instruments.Gate // Open while connected, pauses instruments otherwise
+
+	scheduler *instruments.Scheduler       // Runs registered instruments, set by Run
+	history   *instruments.History         // Recent instrument readings
+	alerts    *instruments.AlertEngine     // Threshold alert rules
+	media     *instruments.MediaInstrument // Now-playing source, controlled by touch
+	mqtt      *instruments.MQTTInstrument  // MQTT broker connection
+	events    *EventBus                    // Events reaching several consumers
+
+	pages         *pageManager       // Available pages and the active one
+	schedules     *scheduleManager   // Schedule active at the current time
+	alarms        *alarmManager      // Ringing alarm
+	notifications *notificationQueue // Banners shown over the display
+	externalFrame *externalFrame     // Frame pushed by an external renderer
+	pause         *displayPause      // Freezes the renderer
+	pomodoro      *pomodoroTimer
+	countdown     *countdownTimer
+	stopwatch     *stopwatchTimer
+	idle          *userIdle     // Whether the user is away from the computer
+	stats         *renderStats  // Frame and USB error statistics
+	preview       *framePreview // Fans rendered frames out to live preview clients
+	displayOff    atomic.Bool   // Set while the display is switched off, see SetPower
+	applied       appliedDevice // Settings last applied from the configuration
+
+	previewRenderer *Renderer                 // Draws configuration previews, owned by the display loop
+	configPreviews  chan configPreviewRequest // Passes preview requests to the display loop
+	redraw          chan struct{}             // Asks the display loop to render a frame right away
+	webhooks        chan webhookDelivery      // Deliveries for the webhook sender, events are dropped when full
+	server          *http.Server              // Running API server, nil before setupAPI succeeded
+
+	// Settings of the command line flags, which must be set before Run
+	apiListenOverride atomic.Value // stores string, see SetAPIListen
+	readOnlyOverride  atomic.Bool  // see SetReadOnly
+	debugEndpoints    atomic.Bool  // see SetDebug
+	serviceMode       atomic.Bool  // Run reports to the service manager, see RunService
+	webUI             fs.FS        // Served at /, nil if the binary has none, see SetWebUI
+
+	registerInstruments sync.Once      // Instruments are registered by the first Run
+	workers             sync.WaitGroup // Goroutines of Run, waited for on shutdown
+	displayTick         atomic.Int64   // Time of the latest display refresh in Unix nanoseconds
+}
+
+// New returns a Nexus with a disconnected transport and no configuration.
+func New() *Nexus {
+	history := instruments.NewHistory(historyRetention, historyCapacity)
+	alerts := instruments.NewAlertEngine(nil)
+
+	stats := &renderStats{page: configuration.PageMain, render: newHistogram(renderBuckets)}
+
+	n := &Nexus{
+		transport: NewTransport(),
+		renderer:  NewRenderer(history, alerts),
+		frames:    newFramePipeline(stats),
+		configs:   NewConfigStore(),
+		gate:      instruments.NewGate(false),
+		history:   history,
+		alerts:    alerts,
+		events:    NewEventBus(),
+
+		pages:         &pageManager{pages: builtinPages, active: configuration.PageMain},
+		notifications: &notificationQueue{},
+		externalFrame: &externalFrame{},
+		pause:         &displayPause{},
+		pomodoro:      &pomodoroTimer{},
+		countdown:     &countdownTimer{},
+		stopwatch:     &stopwatchTimer{},
+		idle:          &userIdle{},
+		stats:         stats,
+		preview:       &framePreview{subscribers: make(map[chan struct{}]struct{})},
+
+		previewRenderer: NewRenderer(history, alerts),
+		configPreviews:  make(chan configPreviewRequest),
+		redraw:          make(chan struct{}, 1),
+		webhooks:        make(chan webhookDelivery, webhookQueueSize),
+	}
+	n.schedules = &scheduleManager{pages: n.pages, redraw: n.requestRedraw}
+	n.alarms = &alarmManager{redraw: n.requestRedraw}
 
-func StartNexus() {
-	var err error
+	n.alerts.OnChange(func(alert instruments.Alert, active bool) {
+		n.events.Alert.Publish(api.AlertEvent{
+			Metric:    alert.Rule.Metric,
+			Operator:  alert.Rule.Operator,
+			Threshold: alert.Rule.Threshold,
+			Value:     alert.Value,
+			Action:    alert.Rule.Action,
+			Active:    active,
+		})
+	})
+
+	return n
+}
+
+// defaultNexus is the Nexus of the package-level functions such as Run, GetConfig
+// and Events, which the main program, the tray icon and the desktop app use.
+var defaultNexus = New()
+
+// Run loads the configuration, starts the API server, the instruments and the
+// display loop, and then runs until ctx is done, at which point it shuts everything
+// down gracefully and releases the device. Once it returned, Run can be called again
+// to restart the engine, for example by a tray icon or the desktop app.
+//
+// Returns:
+//   - error: if the configuration cannot be loaded or the API server cannot be
+//     started; nil after a clean shutdown
+func Run(ctx context.Context) error {
+	return defaultNexus.Run(ctx)
+}
+
+// Run runs n as described for the package function Run. It must not be called
+// again before it returned.
+func (n *Nexus) Run(ctx context.Context) error {
 	// Load initial configuration
-	config, err = configuration.LoadConfig("")
+	config, err := configuration.LoadConfig("")
 	if err != nil {
-		log.Printf("Error loading initial config: %v", err)
-		return
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	n.configs.Set(config)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start API server first so a bind error fails before the device is claimed
+	if err := n.setupAPI(ctx); err != nil {
+		return fmt.Errorf("failed to start API server: %v", err)
 	}
 
+	// Export the D-Bus service for desktop integration
+	n.startDBusService(ctx)
+
+	// Start delivering webhook events
+	n.startWebhooks(ctx)
+
 	// Set initial settings
-	SetTimeFormat(config.TimeFormat)
-	SetTextColor(config.TextColor)
+	n.renderer.SetTimeFormat(config.TimeFormat)
+	n.renderer.SetTimezone(config.Timezone, config.WorldClocks)
+	n.renderer.SetLocale(config.Locale, config.Language)
+	n.renderer.SetTextColor(config.TextColor)
+	n.alerts.SetRules(config.Alerts)
+	n.applyDeviceConfig(config)
 
 	// Start configuration watcher
-	go WatchConfig()
+	n.supervise(ctx, "config watcher", func() { n.watchConfig(ctx) })
 
 	// Initialize device connection
-	InitializeDevice()
-
-	// Start monitoring channels with proper type declarations
-	tempChan := instruments.StartTempatureMonitor(&connected)
-	networkChan := instruments.StartNetworkMonitor(&connected)
-	weatherChan, weatherTrigger := instruments.StartWeatherMonitor(GetConfig, &connected)
-
-	// Store weather update channel globally
-	weatherUpdateCh = weatherTrigger
-
-	// Convert channels to proper types
-	tempChanRead := (<-chan instruments.SystemTemperature)(tempChan)
-	networkChanRead := (<-chan instruments.NetworkStats)(networkChan)
-	weatherChanRead := (<-chan *instruments.WeatherInfo)(weatherChan)
-
-	// Start display update loop with all required channels
-	StartDisplayUpdate(
-		tempChanRead,
-		networkChanRead,
-		weatherChanRead,
-		updateCh,
-		weatherTrigger,
-	)
+	n.initializeDevice(ctx)
+
+	// Register instruments that depend on runtime state and start sampling
+	n.registerInstruments.Do(func() {
+		instruments.Register(instruments.NewWeatherInstrument(n.configs.Get))
+		instruments.Register(instruments.NewWeatherAlertsInstrument(n.configs.Get))
+		instruments.Register(instruments.NewRadarInstrument(n.configs.Get))
+		instruments.Register(instruments.NewNewsInstrument(n.configs.Get))
+		instruments.Register(instruments.NewFeedsInstrument(n.configs.Get))
+		instruments.Register(instruments.NewStocksInstrument(n.configs.Get))
+		instruments.Register(instruments.NewCalendarInstrument(n.configs.Get))
+		n.media = instruments.NewMediaInstrument(n.configs.Get)
+		instruments.Register(n.media)
+		n.mqtt = instruments.NewMQTTInstrument(n.configs.Get)
+		instruments.Register(n.mqtt)
+		instruments.Register(instruments.NewPrometheusInstrument(n.configs.Get))
+		instruments.Register(instruments.NewOctoPrintInstrument(n.configs.Get))
+		instruments.Register(instruments.NewSMARTInstrument(n.configs.Get))
+		instruments.Register(instruments.NewVPNInstrument(n.configs.Get))
+	})
+	n.scheduler = instruments.NewScheduler(n.gate, instruments.Registered()...)
+	n.applyIntervals(config)
+	readings := n.scheduler.Start(ctx)
+	n.supervise(ctx, "mqtt", func() { n.mqtt.Run(ctx) })
+	n.supervise(ctx, "volume watcher", func() { n.watchVolume(ctx) })
+
+	// Switch themes and pages on schedule
+	n.supervise(ctx, "schedules", func() { n.runSchedules(ctx) })
+
+	// Ring alarms when they are due
+	n.supervise(ctx, "alarms", func() { n.runAlarms(ctx) })
+
+	// Start display update loop
+	n.supervise(ctx, "display", func() { n.runDisplay(ctx, readings) })
+	n.supervise(ctx, "frame sender", func() { n.sendFrames(ctx) })
 
 	// Start touch input reading
-	StartTouchMonitor()
+	n.supervise(ctx, "touch monitor", func() { n.runTouchMonitor(ctx) })
+
+	// Blank or slow the display while the user is away
+	n.supervise(ctx, "idle monitor", func() { n.monitorIdle(ctx) })
 
-	// Start API server
-	SetupAPI()
+	// Show the notifications of desktop apps as banners
+	n.supervise(ctx, "desktop notifications", func() { n.relayDesktopNotifications(ctx) })
+
+	if n.serviceMode.Load() {
+		n.notifyReady(ctx)
+	}
 
-	// Keep main thread running
-	select {}
+	// Run until asked to stop
+	<-ctx.Done()
+	slog.Info("Shutting down")
+	if n.serviceMode.Load() {
+		sdNotify("STOPPING=1")
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	if err := n.stopAPI(shutdownCtx); err != nil {
+		apiLog.Warn("API server shutdown", "error", err)
+	}
+
+	n.workers.Wait()
+	n.showShutdownScreen(shutdownCtx)
+	n.resetDevice()
+
+	return nil
+}
+
+// spawn runs fn in a goroutine that Run waits for before releasing the device.
+func (n *Nexus) spawn(fn func()) {
+	n.workers.Add(1)
+	go func() {
+		defer n.workers.Done()
+		fn()
+	}()
+}
+
+// triggerWeatherUpdate requests an immediate weather sample without blocking.
+func (n *Nexus) triggerWeatherUpdate() bool {
+	if n.scheduler == nil {
+		return false
+	}
+	return n.scheduler.Trigger(instruments.WeatherInstrumentName)
+}
+
+// toggleMediaPlayback pauses or resumes the active media player and refreshes the
+// now-playing widget.
+func (n *Nexus) toggleMediaPlayback() {
+	if n.media == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := n.media.TogglePlayback(ctx); err != nil {
+		touchLog.Warn("Toggle playback failed", "error", err)
+		return
+	}
+
+	n.scheduler.Trigger(instruments.MediaInstrumentName)
+}
+
+// toggleMute mutes or unmutes the audio output and refreshes the volume widget.
+func (n *Nexus) toggleMute() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := instruments.ToggleMute(ctx); err != nil {
+		touchLog.Warn("Toggle mute failed", "error", err)
+		return
+	}
+
+	n.scheduler.Trigger(instruments.VolumeInstrumentName)
+}
+
+// watchVolume samples the volume as soon as it changes anywhere in the system,
+// until ctx is done. Where changes are not reported, or watching fails for
+// good, the volume is only polled by the scheduler.
+func (n *Nexus) watchVolume(ctx context.Context) {
+	for {
+		start := time.Now()
+		err := instruments.WatchVolume(ctx, func() { n.scheduler.Trigger(instruments.VolumeInstrumentName) })
+		if ctx.Err() != nil || errors.Is(err, errors.ErrUnsupported) {
+			return
+		}
+
+		// Give up if watching fails right away, e.g. without pactl
+		if time.Since(start) < volumeWatchRetry {
+			slog.Debug("Volume changes are polled", "error", err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(volumeWatchRetry):
+		}
+	}
+}
+
+// publishMQTTAction publishes the message of a tapped MQTT touch action.
+func (n *Nexus) publishMQTTAction(action configuration.MQTTAction) {
+	if n.mqtt == nil {
+		return
+	}
+
+	if err := n.mqtt.Publish(action.Topic, action.Payload, action.Retain); err != nil {
+		touchLog.Warn("MQTT publish failed", "topic", action.Topic, "error", err)
+	}
 }
@@ -0,0 +1,47 @@
+package nexus
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNexusState checks that changing what one Nexus shows leaves another alone.
+func TestNexusState(t *testing.T) {
+	n, other := New(), New()
+
+	if err := n.setPage("clock"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.transport.SetBrightness(20); err != nil {
+		t.Fatal(err)
+	}
+	n.setPower(false)
+	n.pause.set(time.Minute)
+	n.countdown.start(time.Minute, time.Now())
+	n.renderer.SetTimeFormat("24h")
+	n.requestRedraw()
+
+	if page := other.pages.current().Name; page == "clock" {
+		t.Errorf("page of the other Nexus = %q", page)
+	}
+	if brightness := other.transport.Brightness(); brightness != MaxBrightness {
+		t.Errorf("brightness of the other Nexus = %d", brightness)
+	}
+	if !other.power() {
+		t.Error("the other Nexus was switched off")
+	}
+	if paused, _ := other.pause.active(); paused {
+		t.Error("the other Nexus was paused")
+	}
+	if state := other.countdown.state(time.Now()); state.duration != 0 {
+		t.Errorf("countdown of the other Nexus = %v", state.duration)
+	}
+	if format := other.renderer.timeFormat.Load(); format != "12h" {
+		t.Errorf("time format of the other Nexus = %q", format)
+	}
+	select {
+	case <-other.redraw:
+		t.Error("the other Nexus was asked to redraw")
+	default:
+	}
+}
@@ -0,0 +1,357 @@
+// Package nexusdisplay drives the display of a Corsair iCUE Nexus over USB: it
+// sends frames in the chunked format of the device and reads its touch reports.
+//
+// Example usage:
+//
+//	display, err := nexusdisplay.Open(nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer display.Close()
+//
+//	frame := make([]byte, nexusdisplay.FrameSize) // RGBA, Width x Height pixels
+//	if err := display.WriteFrame(ctx, frame); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	for {
+//	    touch, err := display.ReadTouch(ctx)
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Printf("Touch at (%d,%d)\n", touch.X, touch.Y)
+//	}
+package nexusdisplay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/gousb"
+)
+
+// USB identifiers of the iCUE Nexus.
+const (
+	VendorID  = 0x1b1c // Corsair
+	ProductID = 0x1b8e // iCUE Nexus
+)
+
+// Display dimensions.
+const (
+	Width     = 640                // Display width in pixels
+	Height    = 48                 // Display height in pixels
+	FrameSize = Width * Height * 4 // Length of an RGBA frame in bytes
+)
+
+// Protocol details: frames are sent as 121 chunks of chunkSize bytes, each an
+// 8 byte header followed by up to chunkPixels BGRA pixels.
+const (
+	outEndpoint = 2        // Endpoint frames are written to
+	inEndpoint  = 1        // Endpoint touch reports are read from
+	chunkSize   = 1024 * 4 // Bytes per chunk
+	lastChunk   = 120      // Index of the final chunk
+	chunkPixels = 254      // Pixels a chunk advances by
+	reportSize  = 1024     // Bytes read per touch report
+)
+
+var (
+	// ErrNotFound is returned by Open if no iCUE Nexus is attached.
+	ErrNotFound = errors.New("nexusdisplay: no iCUE Nexus found")
+	// ErrDisconnected is returned when the device was unplugged.
+	ErrDisconnected = errors.New("nexusdisplay: device was disconnected")
+	// ErrClosed is returned after Close.
+	ErrClosed = errors.New("nexusdisplay: display closed")
+)
+
+// Display is an opened iCUE Nexus. WriteFrame and ReadTouch may be called
+// concurrently with each other.
+type Display struct {
+	mu      sync.Mutex
+	usb     *gousb.Context // Owned and closed by Close if created by Open
+	ownsUSB bool
+	device  *gousb.Device
+	config  *gousb.Config
+	intf    *gousb.Interface
+	out     *gousb.OutEndpoint
+	in      *gousb.InEndpoint
+	serial  string
+	closed  bool
+
+	writeMu sync.Mutex // Serializes frames
+	chunk   []byte     // Chunk buffer, guarded by writeMu
+}
+
+// Open opens the first iCUE Nexus attached, detaching the kernel driver if
+// needed. If usb is nil, Open creates a USB context that is closed with the
+// display. It returns ErrNotFound if no device is attached.
+func Open(usb *gousb.Context) (*Display, error) {
+	d := &Display{usb: usb}
+	if usb == nil {
+		d.usb = gousb.NewContext()
+		d.ownsUSB = true
+	}
+
+	if err := d.open(); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// open claims the interface of the first device found.
+func (d *Display) open() error {
+	devices, err := d.usb.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return desc.Vendor == gousb.ID(VendorID) && desc.Product == gousb.ID(ProductID)
+	})
+	// Keep the first device, OpenDevices may return some along with an error
+	for i, device := range devices {
+		if i == 0 {
+			d.device = device
+		} else {
+			device.Close()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("nexusdisplay: failed to open devices: %v", err)
+	}
+	if d.device == nil {
+		return ErrNotFound
+	}
+
+	if err := d.device.SetAutoDetach(true); err != nil {
+		return fmt.Errorf("nexusdisplay: failed to set auto detach: %v", err)
+	}
+
+	if d.config, err = d.device.Config(1); err != nil {
+		return fmt.Errorf("nexusdisplay: failed to get config: %v", err)
+	}
+
+	if d.intf, err = d.config.Interface(0, 0); err != nil {
+		return fmt.Errorf("nexusdisplay: failed to get interface: %v", err)
+	}
+
+	if d.out, err = d.intf.OutEndpoint(outEndpoint); err != nil {
+		return fmt.Errorf("nexusdisplay: OutEndpoint(%d): %v", outEndpoint, err)
+	}
+
+	if d.in, err = d.intf.InEndpoint(inEndpoint); err != nil {
+		return fmt.Errorf("nexusdisplay: InEndpoint(%d): %v", inEndpoint, err)
+	}
+
+	if serial, err := d.device.SerialNumber(); err == nil {
+		d.serial = serial
+	}
+
+	return nil
+}
+
+// Info describes an attached iCUE Nexus.
+type Info struct {
+	Bus     int    // USB bus number
+	Address int    // USB address on the bus
+	Serial  string // USB serial number, "" if it cannot be read
+}
+
+// List returns the iCUE Nexus devices attached, without claiming them, so it also
+// lists devices in use. If usb is nil, List creates a USB context for the call.
+func List(usb *gousb.Context) ([]Info, error) {
+	if usb == nil {
+		usb = gousb.NewContext()
+		defer usb.Close()
+	}
+
+	devices, err := usb.OpenDevices(func(desc *gousb.DeviceDesc) bool {
+		return desc.Vendor == gousb.ID(VendorID) && desc.Product == gousb.ID(ProductID)
+	})
+	// OpenDevices may return some devices along with an error
+	infos := make([]Info, 0, len(devices))
+	for _, device := range devices {
+		info := Info{Bus: device.Desc.Bus, Address: device.Desc.Address}
+		info.Serial, _ = device.SerialNumber()
+		device.Close()
+		infos = append(infos, info)
+	}
+	if err != nil && len(infos) == 0 {
+		return nil, fmt.Errorf("nexusdisplay: failed to open devices: %v", err)
+	}
+	return infos, nil
+}
+
+// Serial returns the USB serial number of the device, "" if unknown.
+func (d *Display) Serial() string {
+	return d.serial
+}
+
+// Close releases the device. Pending writes and reads fail with ErrClosed or a
+// USB error.
+func (d *Display) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+
+	if d.intf != nil {
+		d.intf.Close()
+	}
+	if d.config != nil {
+		d.config.Close()
+	}
+	var err error
+	if d.device != nil {
+		err = d.device.Close()
+	}
+	if d.ownsUSB {
+		d.usb.Close()
+	}
+	return err
+}
+
+// endpoints returns the endpoints of the device, or ErrClosed after Close.
+func (d *Display) endpoints() (*gousb.OutEndpoint, *gousb.InEndpoint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil, nil, ErrClosed
+	}
+	return d.out, d.in, nil
+}
+
+// WriteFrame sends an RGBA frame of FrameSize bytes, Width x Height pixels in
+// rows from the top left, to the display. The alpha channel is ignored. The
+// transfer is aborted when ctx is done. It returns ErrDisconnected if the device
+// was unplugged.
+func (d *Display) WriteFrame(ctx context.Context, frame []byte) error {
+	if len(frame) != FrameSize {
+		return fmt.Errorf("nexusdisplay: frame is %d bytes, expected %d", len(frame), FrameSize)
+	}
+
+	out, _, err := d.endpoints()
+	if err != nil {
+		return err
+	}
+
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+
+	if d.chunk == nil {
+		d.chunk = make([]byte, chunkSize)
+	}
+	writer := bufio.NewWriterSize(endpointWriter{ctx, out}, chunkSize)
+
+	for i := 0; i <= lastChunk; i++ {
+		encodeChunk(d.chunk, frame, i)
+
+		if _, err := writer.Write(d.chunk); err != nil {
+			return writeError(err)
+		}
+	}
+
+	// Flush the buffered writer to ensure all data is sent
+	if err := writer.Flush(); err != nil {
+		return writeError(err)
+	}
+
+	return nil
+}
+
+// encodeChunk fills chunk with the header and the BGRA pixels of chunk i of frame.
+func encodeChunk(chunk, frame []byte, i int) {
+	chunk[0] = 2
+	chunk[1] = 5
+	chunk[2] = 31
+	chunk[3] = 0
+	chunk[4] = byte(i)
+	chunk[5] = 0
+	chunk[6] = 248
+	chunk[7] = 3
+	if i == lastChunk {
+		chunk[3] = 1
+		chunk[6] = 192
+	}
+
+	pixel := i * chunkPixels
+
+	for num := 0; num < 255 && pixel < Width*Height; num++ {
+		chunk[8+num*4] = frame[pixel*4+2]   // B
+		chunk[8+num*4+1] = frame[pixel*4+1] // G
+		chunk[8+num*4+2] = frame[pixel*4]   // R
+		chunk[8+num*4+3] = 255              // A
+		pixel++
+	}
+}
+
+// writeError maps the error of a failed transfer.
+func writeError(err error) error {
+	if err.Error() == "libusb: device was disconnected" {
+		return ErrDisconnected
+	}
+	return fmt.Errorf("nexusdisplay: failed to write frame: %v", err)
+}
+
+// endpointWriter writes to an OUT endpoint, aborting transfers when ctx is done.
+type endpointWriter struct {
+	ctx context.Context
+	ep  *gousb.OutEndpoint
+}
+
+func (w endpointWriter) Write(p []byte) (int, error) {
+	return w.ep.WriteContext(w.ctx, p)
+}
+
+// Touch is a touch report of the display. The display only sends reports while it
+// is touched, so a touch ends with the pause after its last report.
+type Touch struct {
+	X int // Horizontal position in pixels from the left
+	Y int // Vertical position in pixels from the top
+}
+
+// ReadTouch waits for the next touch report of the display until ctx is done.
+// Reports in other formats are skipped. It returns ErrDisconnected if the device
+// was unplugged, and the error of ctx once it is done.
+func (d *Display) ReadTouch(ctx context.Context) (Touch, error) {
+	_, in, err := d.endpoints()
+	if err != nil {
+		return Touch{}, err
+	}
+
+	report := make([]byte, reportSize)
+	for {
+		n, err := in.ReadContext(ctx, report)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Touch{}, ctx.Err()
+			}
+			if err.Error() == "libusb: no device [code -4]" {
+				return Touch{}, ErrDisconnected
+			}
+			return Touch{}, fmt.Errorf("nexusdisplay: failed to read touch report: %v", err)
+		}
+
+		if touch, ok := ParseTouch(report[:n]); ok {
+			return touch, nil
+		}
+	}
+}
+
+// ParseTouch decodes a raw touch report. It reports false if report is not a
+// touch report.
+//
+// A touch report starts with the magic numbers 1, 2, 33 followed by two bytes
+// and the X and Y coordinates as big-endian 16-bit values.
+func ParseTouch(report []byte) (Touch, bool) {
+	if len(report) < 9 || report[0] != 1 || report[1] != 2 || report[2] != 33 {
+		return Touch{}, false
+	}
+
+	return Touch{
+		X: int(report[5])*256 + int(report[6]),
+		Y: int(report[7])*256 + int(report[8]),
+	}, true
+}
@@ -0,0 +1,193 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"net/http"
+	"sync"
+	"time"
+
+	"nexus-open/nexus/api"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Notification settings
+const (
+	notificationDefaultDuration = 5 * time.Second
+	notificationMaxDuration     = 5 * time.Minute
+	notificationQueueSize       = 10
+)
+
+// errNotificationQueueFull is returned when a notification cannot be queued.
+var errNotificationQueueFull = errors.New("too many pending notifications")
+
+// notificationIcons maps icon names accepted by /api/notify to Nerd Font glyphs.
+// Icons not in the map are drawn verbatim, so any glyph can be passed directly.
+var notificationIcons = map[string]string{
+	"info":    "\uf05a",
+	"warning": "\uf071",
+	"error":   "\uf057",
+	"success": "\uf058",
+	"bell":    "\uf0f3",
+	"build":   "\uf0ad",
+	"mail":    "\uf0e0",
+}
+
+// Notification is a banner shown over the display for a limited time.
+type Notification struct {
+	Text     string
+	Icon     string
+	Color    color.RGBA
+	Duration time.Duration
+}
+
+// notificationQueue shows notifications one after another. The first entry is the
+// visible notification; it is removed once its duration has passed.
+type notificationQueue struct {
+	mu      sync.Mutex
+	pending []Notification
+	shownAt time.Time // When the first pending notification became visible
+}
+
+// push queues a notification. It returns false if the queue is full.
+func (q *notificationQueue) push(n Notification) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) >= notificationQueueSize {
+		return false
+	}
+
+	if len(q.pending) == 0 {
+		q.shownAt = time.Now()
+	}
+	q.pending = append(q.pending, n)
+	return true
+}
+
+// current returns the visible notification, advancing past expired ones.
+func (q *notificationQueue) current() (Notification, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.pending) > 0 && time.Since(q.shownAt) >= q.pending[0].Duration {
+		q.pending = q.pending[1:]
+		q.shownAt = time.Now()
+	}
+
+	if len(q.pending) == 0 {
+		return Notification{}, false
+	}
+	return q.pending[0], true
+}
+
+// DrawNotification replaces the display content with a notification banner: the
+// configured background, an accent bar on the left edge and the icon and text in the
+// notification color. Text wider than the display scrolls.
+//
+// Parameters:
+//   - n: Notification to draw
+//   - background: Color filling the display behind the banner
+func (r *Renderer) DrawNotification(n Notification, background color.RGBA) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok {
+		return
+	}
+
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(dst, image.Rect(0, 0, 4, height), image.NewUniform(n.Color), image.Point{}, draw.Src)
+
+	text := n.Text
+	if n.Icon != "" {
+		text = n.Icon + " " + text
+	}
+
+	region := image.Rect(14, 0, width-10, height)
+	textWidth := (&font.Drawer{Face: r.face}).MeasureString(text)
+
+	if textWidth <= fixed.I(region.Dx()) {
+		src := r.d.Src
+		r.d.Src = image.NewUniform(n.Color)
+		r.d.Dot = fixed.Point26_6{
+			X: (fixed.I(width) - textWidth) / 2,
+			Y: fixed.I(height/2 + 5),
+		}
+		r.d.DrawString(text)
+		r.d.Src = src
+		return
+	}
+
+	r.drawMarquee(region, height/2+5, []TickerItem{{Text: text, Color: &n.Color}})
+}
+
+// notifyHandler queues a notification banner (POST /api/notify).
+//
+// The JSON body has the fields:
+//   - text: message to show (required)
+//   - color: hex ("#RRGGBB") or named color of the banner (default: text color)
+//   - duration: seconds to show the banner (default 5, at most 300)
+//   - icon: icon name (info, warning, error, success, bell, build, mail) or a glyph
+func (n *Nexus) notifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request api.NotifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	duration, err := n.queueNotification(request)
+	if errors.Is(err, errNotificationQueueFull) {
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.NotifyResponse{Status: api.StatusOK.Status, Duration: duration.Seconds()})
+}
+
+// queueNotification validates a notification request and queues the banner. It
+// returns how long the banner will be shown, or errNotificationQueueFull if too
+// many notifications are pending.
+func (n *Nexus) queueNotification(request api.NotifyRequest) (time.Duration, error) {
+	if request.Text == "" {
+		return 0, errors.New("missing text")
+	}
+
+	duration := notificationDefaultDuration
+	if request.Duration < 0 {
+		return 0, errors.New("invalid duration")
+	}
+	if request.Duration > 0 {
+		duration = min(time.Duration(request.Duration*float64(time.Second)), notificationMaxDuration)
+	}
+
+	icon := request.Icon
+	if glyph, ok := notificationIcons[icon]; ok {
+		icon = glyph
+	}
+
+	notification := Notification{
+		Text:     request.Text,
+		Icon:     icon,
+		Color:    parseColor(request.Color, n.renderer.textColor.Load().(color.RGBA)),
+		Duration: duration,
+	}
+
+	if !n.notifications.push(notification) {
+		return 0, errNotificationQueueFull
+	}
+	return duration, nil
+}
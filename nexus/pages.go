@@ -0,0 +1,198 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+)
+
+// Page is a named set of widgets shown together on the display. Empty colors
+// fall back to the configured colors.
+type Page struct {
+	Name            string
+	Widgets         []string
+	BackgroundColor string
+	TextColor       string
+}
+
+// Shows reports whether the page contains widget.
+func (p Page) Shows(widget string) bool {
+	return slices.Contains(p.Widgets, widget)
+}
+
+// builtinPages are the pages available without configuration.
+var builtinPages = []Page{
+	{Name: configuration.PageMain, Widgets: configuration.Widgets},
+	{Name: "system", Widgets: []string{configuration.WidgetTemperatures, configuration.WidgetNetwork, configuration.WidgetTempGraph, configuration.WidgetClock, configuration.WidgetDisks}},
+	{Name: "network", Widgets: []string{configuration.WidgetVPN, configuration.WidgetNetwork, configuration.WidgetNetGraph, configuration.WidgetClock}},
+	{Name: "media", Widgets: []string{configuration.WidgetClock, configuration.WidgetVolume, configuration.WidgetMedia}},
+	{Name: "weather", Widgets: []string{configuration.WidgetRadar, configuration.WidgetClock, configuration.WidgetWeather, configuration.WidgetTicker}},
+	{Name: "clock", Widgets: []string{configuration.WidgetClock}},
+	{Name: "pomodoro", Widgets: []string{configuration.WidgetPomodoro}},
+	{Name: "timer", Widgets: []string{configuration.WidgetTimer}},
+	{Name: "stopwatch", Widgets: []string{configuration.WidgetStopwatch}},
+}
+
+// pageManager tracks the available pages and the active one. It is safe for
+// concurrent use.
+type pageManager struct {
+	mu     sync.RWMutex
+	pages  []Page
+	active string
+}
+
+// configure replaces the available pages with the configured ones, or restores
+// the built-in pages if there are none. If the active page is gone, the first page
+// becomes active.
+func (m *pageManager) configure(configured []configuration.PageConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pages = builtinPages
+	if len(configured) > 0 {
+		m.pages = make([]Page, len(configured))
+		for i, page := range configured {
+			m.pages[i] = Page{
+				Name:            page.Name,
+				Widgets:         page.Widgets,
+				BackgroundColor: page.BackgroundColor,
+				TextColor:       page.TextColor,
+			}
+		}
+	}
+
+	if !slices.ContainsFunc(m.pages, func(page Page) bool { return page.Name == m.active }) {
+		m.active = m.pages[0].Name
+	}
+}
+
+// list returns the available pages and the name of the active page.
+func (m *pageManager) list() ([]Page, string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Clone(m.pages), m.active
+}
+
+// current returns the active page.
+func (m *pageManager) current() Page {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, page := range m.pages {
+		if page.Name == m.active {
+			return page
+		}
+	}
+	return m.pages[0]
+}
+
+// activate switches to the named page. It returns whether the page changed, or an
+// error if no page has that name.
+func (m *pageManager) activate(name string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, page := range m.pages {
+		if page.Name == name {
+			changed := m.active != name
+			m.active = name
+			return changed, nil
+		}
+	}
+	return false, fmt.Errorf("unknown page %q", name)
+}
+
+// SetPage activates the named page, redraws the display immediately and publishes
+// the change on the event bus.
+func SetPage(name string) error {
+	return defaultNexus.setPage(name)
+}
+
+// setPage is SetPage for n.
+func (n *Nexus) setPage(name string) error {
+	changed, err := n.pages.activate(name)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		n.requestRedraw()
+		n.events.Page.Publish(api.PageEvent{Page: name})
+	}
+	return nil
+}
+
+// Pages returns the names of the available pages and the name of the active page.
+func Pages() ([]string, string) {
+	list, active := defaultNexus.pages.list()
+
+	names := make([]string, len(list))
+	for i, page := range list {
+		names[i] = page.Name
+	}
+	return names, active
+}
+
+// requestRedraw asks the display loop to render a frame without waiting for the
+// next refresh tick. It never blocks.
+func (n *Nexus) requestRedraw() {
+	select {
+	case n.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// pagesResponse returns the available pages in API form.
+func (n *Nexus) pagesResponse() api.Pages {
+	list, active := n.pages.list()
+
+	response := api.Pages{Active: active, Pages: make([]api.Page, 0, len(list))}
+	for _, page := range list {
+		response.Pages = append(response.Pages, api.Page{
+			Name:            page.Name,
+			Widgets:         page.Widgets,
+			BackgroundColor: page.BackgroundColor,
+			TextColor:       page.TextColor,
+		})
+	}
+	return response
+}
+
+// pagesHandler lists the available pages and the active page (GET /api/pages).
+func (n *Nexus) pagesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(n.pagesResponse())
+}
+
+// pageHandler activates a page (POST /api/page). The JSON body is {"name": "<page>"}.
+func (n *Nexus) pageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request api.PageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := n.setPage(request.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(n.pagesResponse())
+}
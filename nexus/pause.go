@@ -0,0 +1,101 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"nexus-open/nexus/api"
+)
+
+// Display pause settings
+const (
+	pauseDefaultTimeout = 5 * time.Minute
+	pauseMaxTimeout     = time.Hour
+)
+
+// displayPause freezes the internal renderer until a deadline. While paused the
+// device keeps showing the last frame, or frames pushed to /api/frame.
+type displayPause struct {
+	mu    sync.Mutex
+	until time.Time // Zero while not paused
+}
+
+// set pauses updates for timeout and returns when they resume.
+func (p *displayPause) set(timeout time.Duration) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.until = time.Now().Add(timeout)
+	return p.until
+}
+
+// clear resumes updates.
+func (p *displayPause) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.until = time.Time{}
+}
+
+// active reports whether updates are paused and until when.
+func (p *displayPause) active() (bool, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.until.IsZero() || time.Now().After(p.until) {
+		p.until = time.Time{}
+		return false, time.Time{}
+	}
+	return true, p.until
+}
+
+// pauseHandler freezes display updates (POST /api/display/pause).
+//
+// The optional JSON body has the field:
+//   - timeout: seconds after which updates resume automatically (default 300, at most 3600)
+//
+// Pausing again replaces the timeout.
+func (n *Nexus) pauseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request api.PauseRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if request.Timeout < 0 {
+		http.Error(w, "Invalid timeout", http.StatusBadRequest)
+		return
+	}
+
+	timeout := pauseDefaultTimeout
+	if request.Timeout > 0 {
+		timeout = min(time.Duration(request.Timeout*float64(time.Second)), pauseMaxTimeout)
+	}
+
+	until := n.pause.set(timeout)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.PauseResponse{Status: api.StatusOK.Status, Paused: true, Until: &until})
+}
+
+// resumeHandler resumes display updates immediately (POST /api/display/resume).
+func (n *Nexus) resumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n.pause.clear()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.PauseResponse{Status: api.StatusOK.Status, Paused: false})
+}
@@ -0,0 +1,144 @@
+package nexus
+
+import (
+	"context"
+	"image"
+	"time"
+)
+
+// framebufferCount is the number of framebuffers of the frame pipeline: one is
+// drawn into by the display loop while another is written to the device.
+const framebufferCount = 2
+
+// outgoingFrame is a frame handed from the display loop to the frame sender.
+type outgoingFrame struct {
+	pix    []byte
+	buffer *image.RGBA // Framebuffer holding pix, nil for frames not drawn by the renderer
+	start  time.Time   // When drawing the frame started
+}
+
+// framePipeline hands frames from the display loop to sendFrames, so the next
+// frame is drawn while the previous one is written over USB. Framebuffers go back
+// to the pool once sent.
+type framePipeline struct {
+	free    chan *image.RGBA   // Framebuffers ready to be drawn into
+	pending chan outgoingFrame // Frames waiting to be sent
+	stats   *renderStats       // Counts the skipped frames
+}
+
+// newFramePipeline returns a pipeline with framebufferCount framebuffers that
+// counts skipped frames in stats.
+func newFramePipeline(stats *renderStats) *framePipeline {
+	p := &framePipeline{
+		free:    make(chan *image.RGBA, framebufferCount),
+		pending: make(chan outgoingFrame, 1),
+		stats:   stats,
+	}
+	for range framebufferCount {
+		p.free <- image.NewRGBA(image.Rect(0, 0, width, height))
+	}
+	return p
+}
+
+// acquire returns a framebuffer to draw into, or nil if all of them wait to be
+// sent because the device is slower than the display loop. The skipped frame is
+// counted in the render statistics.
+func (p *framePipeline) acquire() *image.RGBA {
+	select {
+	case img := <-p.free:
+		return img
+	default:
+		p.stats.framesSkipped(1)
+		return nil
+	}
+}
+
+// submit queues frame to be sent. If a frame is already waiting, frame is skipped:
+// its framebuffer is released and it is counted in the render statistics.
+func (p *framePipeline) submit(frame outgoingFrame) {
+	select {
+	case p.pending <- frame:
+	default:
+		p.release(frame)
+		p.stats.framesSkipped(1)
+	}
+}
+
+// release returns the framebuffer of frame, if any, to the pool.
+func (p *framePipeline) release(frame outgoingFrame) {
+	if frame.buffer != nil {
+		p.free <- frame.buffer
+	}
+}
+
+// sendFrames writes the frames submitted by the display loop to the device until
+// ctx is done. A failed write resets the device, so that it is reconnected.
+func (n *Nexus) sendFrames(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case frame := <-n.frames.pending:
+			n.sendOutgoing(ctx, frame)
+		}
+	}
+}
+
+// sendOutgoing writes frame to the device and returns its framebuffer to the pool,
+// also if sending panics.
+func (n *Nexus) sendOutgoing(ctx context.Context, frame outgoingFrame) {
+	defer n.frames.release(frame)
+
+	if err := n.sendFrame(ctx, frame.pix); err != nil {
+		renderLog.Error("Screen update failed", "error", err)
+		n.resetDevice()
+		return
+	}
+	if frame.buffer != nil {
+		n.stats.rendered(time.Since(frame.start))
+	}
+}
+
+// framePacer schedules display refreshes at a fixed rate. Refreshes that are due
+// while an earlier one is still running are skipped, rather than run in a burst
+// once it finished, and counted in the render statistics.
+type framePacer struct {
+	interval time.Duration
+	next     time.Time // When the next refresh is due
+	timer    *time.Timer
+	stats    *renderStats // Counts the skipped refreshes
+}
+
+// newFramePacer returns a pacer with the first refresh due after interval that
+// counts skipped refreshes in stats.
+func newFramePacer(interval time.Duration, stats *renderStats) *framePacer {
+	return &framePacer{
+		interval: interval,
+		next:     time.Now().Add(interval),
+		timer:    time.NewTimer(interval),
+		stats:    stats,
+	}
+}
+
+// C receives when a refresh is due. Call advance after each refresh.
+func (p *framePacer) C() <-chan time.Time {
+	return p.timer.C
+}
+
+// advance schedules the refresh after the one that just ran. Refreshes whose time
+// already passed are skipped.
+func (p *framePacer) advance() {
+	now := time.Now()
+	p.next = p.next.Add(p.interval)
+	if late := now.Sub(p.next); late >= 0 {
+		skipped := late/p.interval + 1
+		p.next = p.next.Add(skipped * p.interval)
+		p.stats.framesSkipped(uint64(skipped))
+	}
+	p.timer.Reset(p.next.Sub(now))
+}
+
+// Stop stops the pacer.
+func (p *framePacer) Stop() {
+	p.timer.Stop()
+}
@@ -0,0 +1,55 @@
+package nexus
+
+import (
+	"image/draw"
+	"maps"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+	"slices"
+)
+
+// discoverPlugins returns an instrument for every plugin executable in the plugins
+// directory. Plugins are discovered once, when the first Run registers instruments.
+func discoverPlugins() []*instruments.PluginInstrument {
+	dir, err := configuration.GetPluginsDir()
+	if err != nil {
+		pluginsLog.Error("Error locating plugins directory", "error", err)
+		return nil
+	}
+
+	plugins, err := instruments.DiscoverPlugins(dir)
+	if err != nil {
+		pluginsLog.Error("Error discovering plugins", "dir", dir, "error", err)
+		return nil
+	}
+
+	for _, plugin := range plugins {
+		pluginsLog.Info("Found plugin", "plugin", plugin.Name())
+	}
+	return plugins
+}
+
+// DrawPluginTile draws the tile of a plugin at the top left of the display. The
+// widget offset of the plugin moves it into place. Nothing is drawn if the plugin
+// has not provided a tile.
+func (r *Renderer) DrawPluginTile(output *instruments.PluginOutput) {
+	if output == nil || output.Tile == nil {
+		return
+	}
+
+	dst := r.d.Dst.(draw.Image)
+	bounds := output.Tile.Bounds()
+	draw.Draw(dst, bounds.Sub(bounds.Min), output.Tile, bounds.Min, draw.Over)
+}
+
+// pluginTickerItems returns the texts of the plugins for the ticker, in plugin name
+// order.
+func pluginTickerItems(outputs map[string]*instruments.PluginOutput) []TickerItem {
+	var items []TickerItem
+	for _, name := range slices.Sorted(maps.Keys(outputs)) {
+		if text := outputs[name].Text; text != "" {
+			items = append(items, TickerItem{Text: text})
+		}
+	}
+	return items
+}
@@ -0,0 +1,161 @@
+package nexus
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"nexus-open/nexus/configuration"
+)
+
+// Pomodoro widget settings
+const (
+	pomodoroFlashDuration = 3 * time.Second        // Flashing after an interval ended
+	pomodoroFlashPeriod   = 250 * time.Millisecond // Half a flash cycle
+	pomodoroBarHeight     = 4                      // Height of the progress bar in pixels
+)
+
+// pomodoroTimer alternates between work and break intervals, moving on to the next
+// interval when one ends. It is safe for concurrent use.
+type pomodoroTimer struct {
+	mu         sync.Mutex
+	onBreak    bool
+	running    bool
+	elapsed    time.Duration // Time spent in the interval before the last start
+	started    time.Time     // When the timer was last started, while running
+	flashUntil time.Time     // When the flash after the end of an interval stops
+}
+
+// pomodoroState is the state of the pomodoro timer at the time of a frame.
+type pomodoroState struct {
+	onBreak   bool
+	running   bool
+	flashing  bool
+	remaining time.Duration
+	total     time.Duration
+}
+
+// toggle starts or pauses the timer.
+func (t *pomodoroTimer) toggle(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running {
+		t.elapsed += now.Sub(t.started)
+	} else {
+		t.started = now
+	}
+	t.running = !t.running
+}
+
+// reset stops the timer and returns to the start of a work interval.
+func (t *pomodoroTimer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.onBreak, t.running, t.elapsed = false, false, 0
+	t.started, t.flashUntil = time.Time{}, time.Time{}
+}
+
+// state returns the state of the timer at now with the durations of cfg. Intervals
+// that ended since the last call are skipped over, and the flash starts when the
+// last of them ended.
+func (t *pomodoroTimer) state(cfg configuration.PomodoroConfig, now time.Time) pomodoroState {
+	work, rest := cfg.Durations()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	interval := func() time.Duration {
+		if t.onBreak {
+			return rest
+		}
+		return work
+	}
+
+	elapsed := t.elapsed
+	if t.running {
+		elapsed += now.Sub(t.started)
+		if elapsed >= interval() {
+			for elapsed >= interval() {
+				elapsed -= interval()
+				t.onBreak = !t.onBreak
+			}
+			t.elapsed = 0
+			t.started = now.Add(-elapsed)
+			t.flashUntil = t.started.Add(pomodoroFlashDuration)
+		}
+	}
+
+	return pomodoroState{
+		onBreak:   t.onBreak,
+		running:   t.running,
+		flashing:  now.Before(t.flashUntil),
+		remaining: max(interval()-elapsed, 0),
+		total:     interval(),
+	}
+}
+
+// DrawPomodoro fills the display with the pomodoro timer: the interval and whether
+// it is paused on the left, the remaining time in the center and a progress bar
+// along the bottom edge across the whole width. The display flashes in the text
+// color when an interval ended.
+func (r *Renderer) DrawPomodoro(state pomodoroState) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok {
+		return
+	}
+
+	if state.flashing && (time.Now().UnixNano()/int64(pomodoroFlashPeriod))%2 == 0 {
+		draw.Draw(dst, dst.Bounds(), r.d.Src, image.Point{}, draw.Src)
+		return
+	}
+
+	label := r.translate("Work")
+	if state.onBreak {
+		label = r.translate("Break")
+	}
+	if !state.running {
+		label += "  " + r.translate("Paused")
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: fixed.I(10),
+		Y: fixed.I(28),
+	}
+	r.d.DrawString(label)
+
+	remaining := formatTimer(state.remaining)
+	r.d.Dot = fixed.Point26_6{
+		X: (fixed.I(width) - (&font.Drawer{Face: r.face}).MeasureString(remaining)) / 2,
+		Y: fixed.I(28),
+	}
+	r.d.DrawString(remaining)
+
+	// Progress bar: a dim track in the text color, filled up to the elapsed time
+	bar := image.Rect(0, height-pomodoroBarHeight, width, height)
+	filled := bar
+	if state.total > 0 {
+		filled.Max.X = bar.Min.X + int(int64(bar.Dx())*int64(state.total-state.remaining)/int64(state.total))
+	}
+
+	track := color.Alpha{A: 64}
+	draw.DrawMask(dst, bar, r.d.Src, image.Point{}, image.NewUniform(track), image.Point{}, draw.Over)
+	draw.Draw(dst, filled, r.d.Src, image.Point{}, draw.Over)
+}
+
+// formatTimer formats a duration as "m:ss", or "h:mm:ss" from an hour on, rounding
+// up to whole seconds.
+func formatTimer(d time.Duration) string {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
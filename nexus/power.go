@@ -0,0 +1,53 @@
+package nexus
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"nexus-open/nexus/api"
+)
+
+// blackFrame is sent to the device while it is switched off.
+var blackFrame = make([]byte, width*height*4)
+
+// SetPower switches the display on or off. While off the device shows black and
+// the internal renderer is idle; tapping the display switches it back on.
+func SetPower(on bool) {
+	defaultNexus.setPower(on)
+}
+
+// Power reports whether the display is switched on.
+func Power() bool {
+	return defaultNexus.power()
+}
+
+// setPower is SetPower for n.
+func (n *Nexus) setPower(on bool) {
+	n.displayOff.Store(!on)
+}
+
+// power is Power for n.
+func (n *Nexus) power() bool {
+	return !n.displayOff.Load()
+}
+
+// powerHandler reads (GET) or changes (PUT) the display power state
+// (/api/display/power). The JSON body of both is {"on": true|false}.
+func (n *Nexus) powerHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var request api.Power
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		n.setPower(request.On)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(api.Power{On: n.power()})
+}
@@ -0,0 +1,161 @@
+package nexus
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Preview stream settings
+const (
+	previewDefaultFPS = 10
+	previewMaxFPS     = screenRefreshRate
+)
+
+// framePreview fans rendered frames out to live preview clients. Frames are only
+// retained while at least one client is subscribed.
+type framePreview struct {
+	mu          sync.Mutex
+	frame       []byte // Latest RGBA frame, width*height*4 bytes
+	subscribers map[chan struct{}]struct{}
+}
+
+// publish stores a copy of frame as the latest rendered frame and notifies
+// subscribers. The caller may reuse frame afterwards, as the display loop does with
+// its framebuffers.
+func (p *framePreview) publish(frame []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.subscribers) == 0 {
+		return
+	}
+
+	// Clients encode the frame after releasing the lock, so it is never overwritten
+	p.frame = bytes.Clone(frame)
+	for ch := range p.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// subscribe registers a client and returns a channel signalled on new frames
+// and a function to unsubscribe.
+func (p *framePreview) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	// Send the current frame right away if there is one
+	if p.frame != nil {
+		ch <- struct{}{}
+	}
+	p.mu.Unlock()
+
+	return ch, func() {
+		p.mu.Lock()
+		delete(p.subscribers, ch)
+		if len(p.subscribers) == 0 {
+			p.frame = nil
+		}
+		p.mu.Unlock()
+	}
+}
+
+// latest returns the most recently published frame.
+func (p *framePreview) latest() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.frame
+}
+
+// previewHandler streams the rendered display over a WebSocket (GET /api/preview/ws).
+// Each binary message holds one frame.
+//
+// Query parameters:
+//   - format: "png" (default) for PNG images or "rgba" for raw 640x48 RGBA pixels
+//   - fps: maximum frames per second (default 10, at most the screen refresh rate)
+func (n *Nexus) previewHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "png"
+	}
+	if format != "png" && format != "rgba" {
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	fps := previewDefaultFPS
+	if value := r.URL.Query().Get("fps"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid fps", http.StatusBadRequest)
+			return
+		}
+		fps = min(n, previewMaxFPS)
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	frames, unsubscribe := n.preview.subscribe()
+	defer unsubscribe()
+
+	// Read until the client goes away; incoming messages are ignored
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	throttle := time.NewTicker(time.Second / time.Duration(fps))
+	defer throttle.Stop()
+
+	var buf bytes.Buffer
+	for {
+		select {
+		case <-closed:
+			return
+		case <-frames:
+		}
+
+		frame := n.preview.latest()
+		if frame == nil {
+			continue
+		}
+
+		data := frame
+		if format == "png" {
+			buf.Reset()
+			img := &image.RGBA{Pix: frame, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+			if err := png.Encode(&buf, img); err != nil {
+				return
+			}
+			data = buf.Bytes()
+		}
+
+		if err := ws.WriteMessage(wsOpBinary, data); err != nil {
+			return
+		}
+
+		// Wait out the frame interval before sending the next frame
+		select {
+		case <-closed:
+			return
+		case <-throttle.C:
+		}
+	}
+}
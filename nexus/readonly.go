@@ -0,0 +1,33 @@
+package nexus
+
+import (
+	"net/http"
+)
+
+// SetReadOnly enables read-only mode when enabled, e.g. from a command line flag,
+// in addition to the read_only configuration. It must be called before Run.
+func SetReadOnly(enabled bool) {
+	defaultNexus.readOnlyOverride.Store(enabled)
+}
+
+// readOnly reports whether API requests changing the configuration, the images or
+// what the display shows are rejected.
+func (n *Nexus) readOnly() bool {
+	if n.readOnlyOverride.Load() {
+		return true
+	}
+	cfg := n.configs.Get()
+	return cfg != nil && cfg.ReadOnly
+}
+
+// readOnlyGuard answers requests other than GET and HEAD with 403 Forbidden in
+// read-only mode, and passes everything else to next.
+func (n *Nexus) readOnlyGuard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && n.readOnly() {
+			http.Error(w, "Read-only mode is enabled", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,40 @@
+package nexus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nexus-open/nexus/configuration"
+)
+
+func TestReadOnlyGuard(t *testing.T) {
+	tests := []struct {
+		method   string
+		readOnly bool
+		want     int
+	}{
+		{http.MethodGet, false, http.StatusOK},
+		{http.MethodPost, false, http.StatusOK},
+		{http.MethodGet, true, http.StatusOK},
+		{http.MethodHead, true, http.StatusOK},
+		{http.MethodPost, true, http.StatusForbidden},
+		{http.MethodPut, true, http.StatusForbidden},
+		{http.MethodPatch, true, http.StatusForbidden},
+		{http.MethodDelete, true, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		n := New()
+		cfg := configuration.DefaultConfig()
+		cfg.ReadOnly = tt.readOnly
+		n.configs.Set(cfg)
+
+		handler := n.readOnlyGuard(func(w http.ResponseWriter, r *http.Request) {})
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(tt.method, "/api/display/power", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s with read-only %v = %d, want %d", tt.method, tt.readOnly, w.Code, tt.want)
+		}
+	}
+}
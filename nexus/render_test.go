@@ -0,0 +1,160 @@
+package nexus
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata")
+
+// testFont is the font name of the test configuration, it is always the basic font
+// so the images do not depend on the fonts installed.
+const testFont = "nexus-test"
+
+// testConfig returns a configuration with a single page showing widgets, drawn on
+// a solid background because its image does not exist.
+func testConfig(t testing.TB, widgets ...string) *configuration.NexusConfig {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg := configuration.DefaultConfig()
+	cfg.Pages = []configuration.PageConfig{{Name: "test", Widgets: widgets}}
+	cfg.ImagePaths = []string{"missing.png"}
+	cfg.Font = testFont
+	cfg.Locale = "en-US"
+	cfg.Unit = "metric"
+
+	fontCacheMu.Lock()
+	fontCache[fontKey{name: testFont, size: cfg.FontSize}] = basicfont.Face7x13
+	fontCacheMu.Unlock()
+	return cfg
+}
+
+// testState returns instrument values for the widgets that do not change with
+// the time of day.
+func testState() *displayState {
+	return &displayState{
+		cpu:      54,
+		gpu:      61,
+		network:  instruments.NetworkStats{Sent: 320, Received: 4096},
+		weather:  &instruments.WeatherInfo{Location: "Berlin", Temperature: 18, Condition: "\ue302", WindSpeed: "12"},
+		volume:   &instruments.VolumeState{Level: 65},
+		keyboard: &instruments.KeyboardState{CapsLock: true, Layout: "US"},
+		disks:    instruments.DiskHealth{{Drive: "/dev/sda", Model: "SSD", Temperature: 38}},
+	}
+}
+
+// TestRenderGolden compares rendered pages with the images in testdata/golden.
+// Run with -update to record them again after an intended change of the layout.
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		widgets []string
+		offsets map[string]configuration.WidgetOffset
+	}{
+		{"temperatures", []string{configuration.WidgetTemperatures}, nil},
+		{"network", []string{configuration.WidgetNetwork}, nil},
+		{"weather", []string{configuration.WidgetWeather}, nil},
+		{"volume", []string{configuration.WidgetVolume}, nil},
+		{"keyboard", []string{configuration.WidgetKeyboard}, nil},
+		{"disks", []string{configuration.WidgetDisks}, nil},
+		{"offsets", []string{configuration.WidgetTemperatures, configuration.WidgetVolume}, map[string]configuration.WidgetOffset{
+			configuration.WidgetTemperatures: {X: 40, Y: 4},
+			configuration.WidgetVolume:       {X: -60, Y: -2},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t, tt.widgets...)
+			cfg.WidgetOffsets = tt.offsets
+			img := New().renderPreview(testState(), cfg, "test")
+			path := filepath.Join("testdata", "golden", tt.name+".png")
+
+			if *update {
+				var buf bytes.Buffer
+				if err := png.Encode(&buf, img); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			want, err := png.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x, y, ok := firstDifference(img, want); !ok {
+				t.Errorf("image differs from %s at (%d, %d)", path, x, y)
+			}
+		})
+	}
+}
+
+// TestRenderOffsetAllocs checks that widgets moved by an offset are drawn
+// without allocating a layer for every frame.
+func TestRenderOffsetAllocs(t *testing.T) {
+	n, state := New(), testState()
+	cfg := testConfig(t, configuration.WidgetTemperatures, configuration.WidgetVolume)
+	moved := *cfg
+	moved.WidgetOffsets = map[string]configuration.WidgetOffset{configuration.WidgetVolume: {X: 10}}
+
+	n.renderPreview(state, &moved, "test")
+	withoutOffset := testing.AllocsPerRun(20, func() { n.renderPreview(state, cfg, "test") })
+	withOffset := testing.AllocsPerRun(20, func() { n.renderPreview(state, &moved, "test") })
+	if withOffset > withoutOffset {
+		t.Errorf("rendering with an offset allocates %v times per frame, without %v", withOffset, withoutOffset)
+	}
+}
+
+// firstDifference returns the first pixel in which got and want differ, and
+// false, or true if they are equal.
+func firstDifference(got *image.RGBA, want image.Image) (int, int, bool) {
+	if got.Bounds() != want.Bounds() {
+		return 0, 0, false
+	}
+	b := got.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, a1 := got.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return x, y, false
+			}
+		}
+	}
+	return 0, 0, true
+}
+
+// BenchmarkRenderPreview renders one frame of a page with the temperature,
+// network, weather and volume widgets.
+//
+// Baseline (amd64, AMD EPYC): 19 µs/frame, 128 kB/op, 109 allocs/op
+func BenchmarkRenderPreview(b *testing.B) {
+	n := New()
+	state := testState()
+	cfg := testConfig(b, configuration.WidgetTemperatures, configuration.WidgetNetwork, configuration.WidgetWeather, configuration.WidgetVolume)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		n.renderPreview(state, cfg, "test")
+	}
+}
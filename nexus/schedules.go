@@ -0,0 +1,112 @@
+package nexus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"nexus-open/nexus/configuration"
+)
+
+// scheduleCheckInterval is how often runSchedules checks which schedule is active.
+const scheduleCheckInterval = 15 * time.Second
+
+// scheduleManager applies the configured schedule active at the current time. The
+// theme of a schedule overrides the configured colors without saving them, and the
+// page it shows is left for the page shown before when it ends. It is safe for
+// concurrent use.
+type scheduleManager struct {
+	mu           sync.Mutex
+	active       *configuration.Schedule // Active schedule, nil if none
+	theme        *configuration.Theme    // Theme of the active schedule
+	previousPage string                  // Page shown before the active schedule switched pages
+
+	pages  *pageManager // Pages a schedule switches between
+	redraw func()       // Asks the display loop to show a change right away
+}
+
+// runSchedules applies the active schedule until ctx is done, checking every
+// scheduleCheckInterval.
+func (n *Nexus) runSchedules(ctx context.Context) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if cfg := n.configs.Get(); cfg != nil {
+			n.schedules.apply(cfg, time.Now().In(n.renderer.location()), n.setPage)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply switches to the schedule of cfg active at now, which is in the clock's
+// time zone, unless it is active already. A schedule that was edited is applied again. Pages
+// are switched with setPage.
+func (m *scheduleManager) apply(cfg *configuration.NexusConfig, now time.Time, setPage func(string) error) {
+	schedule, ok := cfg.ActiveSchedule(now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if (m.active == nil && !ok) || (m.active != nil && ok && reflect.DeepEqual(*m.active, schedule)) {
+		return
+	}
+
+	if m.previousPage != "" {
+		if err := setPage(m.previousPage); err != nil {
+			scheduleLog.Warn("Schedule failed", "schedule", m.active.Name, "error", err)
+		}
+		m.previousPage = ""
+	}
+
+	m.active, m.theme = nil, nil
+	if !ok {
+		scheduleLog.Info("No schedule active")
+		m.redraw()
+		return
+	}
+
+	scheduleLog.Info("Schedule active", "schedule", schedule.Name)
+	m.active = &schedule
+	if theme, ok := configuration.FindTheme(schedule.Theme); ok {
+		m.theme = &theme
+	}
+	if schedule.Page != "" {
+		current := m.pages.current().Name
+		if err := setPage(schedule.Page); err != nil {
+			scheduleLog.Warn("Schedule failed", "schedule", schedule.Name, "error", err)
+		} else if current != schedule.Page {
+			m.previousPage = current
+		}
+	}
+	m.redraw()
+}
+
+// colors returns the text and background colors of the active schedule's theme,
+// or the given colors if it has none.
+func (m *scheduleManager) colors(textColor, backgroundColor string) (string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.theme == nil {
+		return textColor, backgroundColor
+	}
+	return m.theme.TextColor, m.theme.BackgroundColor
+}
+
+// current returns the name of the active schedule, "" if none is.
+func (m *scheduleManager) current() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.active == nil {
+		return ""
+	}
+	return m.active.Name
+}
@@ -0,0 +1,84 @@
+package nexus
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sdNotify sends state, e.g. "READY=1", to the service manager through the socket
+// in $NOTIFY_SOCKET. It does nothing if the variable is unset.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Abstract sockets are passed with a leading @
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// watchdogInterval returns how often the service manager expects a keep-alive,
+// half the timeout in $WATCHDOG_USEC, or 0 if the watchdog is disabled for this
+// process.
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
+
+// notifyReady tells the service manager that n is running and keeps the watchdog
+// fed until ctx is done, as long as the display loop ticks.
+func (n *Nexus) notifyReady(ctx context.Context) {
+	if err := sdNotify("READY=1"); err != nil {
+		slog.Warn("sd_notify failed", "error", err)
+		return
+	}
+
+	interval := watchdogInterval()
+	if interval == 0 {
+		return
+	}
+
+	n.supervise(ctx, "watchdog", func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// Let the watchdog restart Nexus if the display loop is stuck
+			if time.Since(time.Unix(0, n.displayTick.Load())) > interval {
+				renderLog.Warn("Display loop is not running, skipping watchdog keep-alive")
+				continue
+			}
+			if err := sdNotify("WATCHDOG=1"); err != nil {
+				slog.Warn("sd_notify failed", "error", err)
+			}
+		}
+	})
+}
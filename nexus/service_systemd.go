@@ -0,0 +1,81 @@
+//go:build !windows
+
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// serviceUnit is the name of the systemd user unit written by InstallService.
+const serviceUnit = "nexus-open.service"
+
+// serviceUnitTemplate is the unit file written by InstallService, filled with the
+// command line. The watchdog restarts Nexus if the display loop hangs.
+const serviceUnitTemplate = `[Unit]
+Description=Nexus Open display for the Corsair iCUE Nexus
+After=graphical-session.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+Restart=on-failure
+WatchdogSec=30
+
+[Install]
+WantedBy=default.target
+`
+
+// RunService runs Nexus as a systemd service: like Run, but reporting readiness
+// and watchdog keep-alives with sd_notify while the display loop runs. It stops
+// when ctx is done, e.g. on SIGTERM.
+func RunService(ctx context.Context) error {
+	defaultNexus.serviceMode.Store(true)
+	return Run(ctx)
+}
+
+// InstallService writes a systemd user unit starting the running executable with
+// --service and args, and writes how to enable it to out.
+func InstallService(out io.Writer, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(configDir, "systemd", "user")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	command := []string{unitQuote(executable), "--service"}
+	for _, arg := range args {
+		command = append(command, unitQuote(arg))
+	}
+
+	path := filepath.Join(dir, serviceUnit)
+	unit := fmt.Sprintf(serviceUnitTemplate, strings.Join(command, " "))
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Wrote %s\nEnable it with:\n  systemctl --user daemon-reload\n  systemctl --user enable --now %s\n", path, serviceUnit)
+	return nil
+}
+
+// unitQuote quotes arg for an ExecStart line if it contains spaces or quotes.
+func unitQuote(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"'\\") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
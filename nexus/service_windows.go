@@ -0,0 +1,133 @@
+//go:build windows
+
+package nexus
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"nexus-open/nexus/logging"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Windows service settings
+const (
+	serviceName        = "NexusOpen"
+	serviceDisplayName = "Nexus Open"
+	serviceDescription = "Drives the display of the Corsair iCUE Nexus"
+)
+
+// RunService runs Nexus as a Windows service when started by the service manager:
+// like Run, but stopped by the service manager and logging to the event log unless
+// the log file is written, see SetLogOutput.
+// Started from a console, it is the same as Run.
+func RunService(ctx context.Context) error {
+	defaultNexus.serviceMode.Store(true)
+
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		return Run(ctx)
+	}
+
+	// Without a console, messages go to the event log unless written to a file
+	if logFile == nil {
+		closeLog, err := logging.UseEventLog(serviceName)
+		if err != nil {
+			return fmt.Errorf("failed to open event log: %v", err)
+		}
+		defer closeLog()
+	}
+
+	service := &windowsService{ctx: ctx}
+	if err := svc.Run(serviceName, service); err != nil {
+		return err
+	}
+	return service.err
+}
+
+// windowsService runs Nexus for the service manager.
+type windowsService struct {
+	ctx context.Context
+	err error // Returned by Run
+}
+
+// Execute runs Nexus until the service manager stops it or Run fails.
+func (s *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx) }()
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			s.err = err
+			if err != nil {
+				// Report a service specific exit code so the failure shows in the service manager
+				return true, 1
+			}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
+
+// InstallService registers the running executable as a Windows service started
+// with --service and args, and as a source of the event log. It needs to be run as
+// administrator. How to start the service is written to out.
+func InstallService(out io.Writer, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %v", err)
+	}
+	defer manager.Disconnect()
+
+	if service, err := manager.OpenService(serviceName); err == nil {
+		service.Close()
+		return fmt.Errorf("service %s already exists", serviceName)
+	}
+
+	service, err := manager.CreateService(serviceName, executable, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, append([]string{"--service"}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %v", err)
+	}
+	defer service.Close()
+
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		// Without an event log source the service would log nowhere
+		service.Delete()
+		return fmt.Errorf("failed to register event log source: %v", err)
+	}
+
+	fmt.Fprintf(out, "Installed service %s\nStart it with:\n  sc start %s\n", serviceName, serviceName)
+	return nil
+}
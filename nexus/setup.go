@@ -4,93 +4,241 @@
 //
 // The package includes functionality for:
 //   - Monitoring configuration file changes in real-time
-//   - Thread-safe access to global configuration settings
+//   - Thread-safe access to the current configuration settings
 //   - Automatic weather updates when location or unit settings change
 //   - Configuration comparison and change detection
 //
 // The package uses mutex locks to ensure thread-safety when accessing shared configuration data
-// and implements channels for notifying other components about configuration changes.
+// and publishes configuration changes on the event bus to notify other components.
 //
-// Configuration changes are detected and processed at regular intervals defined by configRefreshRate.
-// When changes are detected, appropriate update signals are sent through dedicated channels to
+// Configuration changes are detected through file system events, falling back to checking the
+// file at regular intervals defined by configRefreshRate. When changes are detected, the new configuration is published on the event bus to
 // notify dependent components.
 package nexus
 
 import (
-	"log"
+	"context"
+	"maps"
 	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/instruments"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
-// WatchConfig periodically monitors and reloads the configuration file.
-// It runs as a goroutine that checks for configuration changes at regular intervals
-// defined by configRefreshRate.
+// watchConfig monitors the configuration file and reloads it when it changes.
+// It runs as a goroutine watching the file's directory for file system events, so
+// edits apply immediately and editors replacing the file are noticed too. Bursts of
+// events are coalesced by waiting configDebounce after the last one. If events are
+// not available, it falls back to checking the file's modification time every
+// configRefreshRate seconds.
 //
 // When changes are detected in the configuration:
 //   - If location or unit settings change, it triggers an immediate weather update
-//   - For any configuration changes, it updates the global configuration and notifies
-//     listeners through the update channel
+//   - For any configuration changes, it updates the current configuration and notifies
+//     listeners through the event bus
 //
 // The function uses mutex locks to ensure thread-safe access to shared configuration.
-// It will continue running until the program terminates, constantly watching for
+// It will continue running until ctx is done, constantly watching for
 // configuration changes.
-func WatchConfig() {
+func (n *Nexus) watchConfig(ctx context.Context) {
+	path, err := configuration.GetConfigPath()
+	if err != nil {
+		configLog.Error("Error locating config", "error", err)
+		return
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = watcher.Add(filepath.Dir(path)); err != nil {
+			watcher.Close()
+		}
+	}
+	if err != nil {
+		configLog.Warn("Config file events unavailable, polling for changes", "interval", configRefreshRate*time.Second, "error", err)
+		n.pollConfig(ctx, path)
+		return
+	}
+	defer watcher.Close()
+
+	debounce := time.NewTimer(configDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == path && !event.Has(fsnotify.Chmod) {
+				debounce.Reset(configDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			configLog.Warn("Error watching config", "error", err)
+		case <-debounce.C:
+			n.reloadConfig()
+		}
+	}
+}
+
+// pollConfig reloads the configuration whenever the modification time or size
+// of the file at path changes, checking every configRefreshRate seconds until ctx
+// is done.
+func (n *Nexus) pollConfig(ctx context.Context, path string) {
+	var lastMod time.Time
+	var lastSize int64
+	if info, err := os.Stat(path); err == nil {
+		lastMod, lastSize = info.ModTime(), info.Size()
+	}
+
 	ticker := time.NewTicker(configRefreshRate * time.Second)
-	for range ticker.C {
-		newConfig, err := configuration.LoadConfig("")
-		if err != nil {
-			log.Printf("Error loading config: %v", err)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || (info.ModTime().Equal(lastMod) && info.Size() == lastSize) {
 			continue
 		}
+		lastMod, lastSize = info.ModTime(), info.Size()
+		n.reloadConfig()
+	}
+}
 
-		configMu.Lock()
-		if newConfig.Location != config.Location || newConfig.Unit != config.Unit {
-			// Location or unit changed, trigger immediate weather update
-			if weatherUpdateCh != nil {
-				select {
-				case weatherUpdateCh <- struct{}{}:
-					log.Printf("Triggered weather update for location: %s", newConfig.Location)
-				default:
-				}
-			}
+// reloadConfig loads the configuration file and applies what changed.
+func (n *Nexus) reloadConfig() {
+	newConfig, err := configuration.LoadConfig("")
+	if err != nil {
+		configLog.Error("Error loading config", "error", err)
+		return
+	}
+
+	n.configs.mu.Lock()
+	defer n.configs.mu.Unlock()
+	config := n.configs.config
+
+	if newConfig.Location != config.Location || newConfig.Geocoded != config.Geocoded || newConfig.Unit != config.Unit {
+		// Location or unit changed, trigger immediate weather update
+		if n.triggerWeatherUpdate() {
+			configLog.Info("Triggered weather update", "location", newConfig.Location)
 		}
+	}
 
-		// Update config if anything changed
-		if configChanged(config, newConfig) {
-			config = newConfig
-			unit = newConfig.Unit
-			location = newConfig.Location
-			select {
-			case updateCh <- struct{}{}:
-			default:
-			}
+	if !maps.Equal(newConfig.Intervals, config.Intervals) {
+		n.applyIntervals(newConfig)
+	}
+
+	if !slices.Equal(newConfig.Alerts, config.Alerts) {
+		n.alerts.SetRules(newConfig.Alerts)
+	}
+
+	if !reflect.DeepEqual(newConfig.Pages, config.Pages) || newConfig.Brightness != config.Brightness ||
+		newConfig.Rotation != config.Rotation || !reflect.DeepEqual(newConfig.Devices, config.Devices) {
+		n.applyDeviceConfig(newConfig)
+	}
+
+	if !reflect.DeepEqual(newConfig.Schedules, config.Schedules) {
+		// Switching pages sends webhooks, which read the config once it is unlocked
+		go n.schedules.apply(newConfig, time.Now().In(n.renderer.location()), n.setPage)
+	}
+
+	if newConfig.Font != config.Font || newConfig.FontSize != config.FontSize {
+		// Load the fonts again, the file may have been installed or replaced
+		resetFontCache()
+	}
+
+	// The background image or font file may have changed even if their names did not
+	n.invalidateRenderers()
+
+	mqttChanged := !reflect.DeepEqual(newConfig.MQTT, config.MQTT)
+
+	// Update config if anything changed
+	if configChanged(config, newConfig) {
+		// Let the display and the settings UIs show the change, including settings not
+		// applied at runtime
+		n.events.Config.Publish(newConfig)
+
+		n.configs.config = newConfig
+		if mqttChanged && n.mqtt != nil {
+			// Reconnect with the new broker settings and subscriptions
+			n.mqtt.Reconnect()
 		}
-		configMu.Unlock()
 	}
 }
 
-// GetConfig returns the global Nexus configuration in a thread-safe manner.
-// This function uses a read lock to ensure concurrent access safety.
+// ConfigStore holds the current configuration. It is safe for concurrent use.
+type ConfigStore struct {
+	mu     sync.RWMutex
+	config *configuration.NexusConfig
+}
+
+// NewConfigStore returns a store without a configuration.
+func NewConfigStore() *ConfigStore {
+	return &ConfigStore{}
+}
+
+// Get returns the current configuration, nil before one is loaded. The returned
+// configuration should not be modified directly.
+func (s *ConfigStore) Get() *configuration.NexusConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Set replaces the current configuration.
+func (s *ConfigStore) Set(config *configuration.NexusConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// GetConfig returns the configuration of the running Nexus in a thread-safe manner.
 // The returned configuration should not be modified directly.
 func GetConfig() *configuration.NexusConfig {
-	configMu.RLock()
-	defer configMu.RUnlock()
-	return config
+	return defaultNexus.configs.Get()
 }
 
 // configChanged compares two NexusConfig configurations and determines if there are any differences
-// between them. It checks for changes in Unit, Location, TimeFormat, TextColor, and BackgroundColor settings.
-//
-// Parameters:
-//   - old: A pointer to the original NexusConfig configuration
-//   - new: A pointer to the new NexusConfig configuration
-//
-// Returns:
-//   - bool: true if any configuration setting has changed, false if all settings are identical
+// between them.
 func configChanged(old, new *configuration.NexusConfig) bool {
-	return old.Unit != new.Unit ||
-		old.Location != new.Location ||
-		old.TimeFormat != new.TimeFormat ||
-		old.TextColor != new.TextColor ||
-		old.BackgroundColor != new.BackgroundColor
+	return !reflect.DeepEqual(old, new)
+}
+
+// applyIntervals pushes the configured instrument polling intervals to the scheduler.
+// Instruments without an override are reset to their default interval, and overrides
+// naming unknown instruments are logged and ignored.
+func (n *Nexus) applyIntervals(cfg *configuration.NexusConfig) {
+	if n.scheduler == nil || cfg == nil {
+		return
+	}
+
+	intervals, err := cfg.PollIntervals()
+	if err != nil {
+		configLog.Warn("Ignoring polling intervals", "error", err)
+		return
+	}
+
+	for _, instrument := range instruments.Registered() {
+		n.scheduler.SetInterval(instrument.Name(), intervals[instrument.Name()])
+		delete(intervals, instrument.Name())
+	}
+
+	for name := range intervals {
+		configLog.Warn("Ignoring polling interval for unknown instrument", "instrument", name)
+	}
 }
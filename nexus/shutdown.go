@@ -0,0 +1,56 @@
+package nexus
+
+import (
+	"context"
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"nexus-open/nexus/configuration"
+)
+
+// showShutdownScreen sends the configured shutdown screen to the device, so it
+// does not keep showing stale readings after Nexus stopped. It is called by Run
+// once the display loop has stopped.
+func (n *Nexus) showShutdownScreen(ctx context.Context) {
+	if !n.transport.Attached() {
+		return
+	}
+
+	frame := blackFrame
+	if cfg := n.configs.Get(); cfg != nil {
+		switch cfg.Shutdown.Screen {
+		case configuration.ShutdownScreenKeep:
+			return
+		case configuration.ShutdownScreenText:
+			frame = n.renderer.DrawShutdownScreen(cfg).Pix
+		}
+	}
+
+	if err := n.transport.Send(ctx, frame); err != nil {
+		usbLog.Warn("Failed to show the shutdown screen", "error", err)
+	}
+}
+
+// DrawShutdownScreen draws the text of the "text" shutdown screen centered in the
+// text color on the background color of cfg.
+func (r *Renderer) DrawShutdownScreen(cfg *configuration.NexusConfig) *image.RGBA {
+	img := r.CreateImageContext(ImageConfig{
+		BgColor:  cfg.BackgroundColor,
+		Font:     cfg.Font,
+		FontSize: cfg.FontSize,
+	})
+
+	text := cfg.Shutdown.Text
+	if text == "" {
+		text = configuration.ShutdownText
+	}
+
+	r.d.Dot = fixed.Point26_6{
+		X: (fixed.I(width) - (&font.Drawer{Face: r.face}).MeasureString(text)) / 2,
+		Y: fixed.I(height/2 + 5),
+	}
+	r.d.DrawString(text)
+	return img
+}
@@ -0,0 +1,158 @@
+package nexus
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"nexus-open/nexus/api"
+)
+
+// Displayed pages reported by the status endpoint, besides the widget pages
+const (
+	pageNotification = "notification"
+	pageAlert        = "alert"
+	pageAlarm        = "alarm"
+	pageExternal     = "external"
+	pagePaused       = "paused"
+	pageOff          = "off"
+	pageIdle         = "idle"
+)
+
+// startTime is when the process started, used to report the uptime.
+var startTime = time.Now()
+
+// renderStats counts frames sent to the device and tracks USB errors. It is safe
+// for concurrent use.
+type renderStats struct {
+	mu           sync.Mutex
+	page         string    // Page shown by the latest frame
+	framesSent   uint64    // Frames written to the device
+	dropped      uint64    // Frames that failed to be written
+	skipped      uint64    // Frames not drawn or sent as earlier ones were late
+	windowStart  time.Time // Start of the current frame rate window
+	windowFrames int       // Frames sent in the current window
+	fps          float64   // Frame rate of the last complete window
+	usbErrors    uint64    // USB errors, including dropped frames
+	lastError    string
+	lastErrorAt  time.Time
+	render       histogram // Time taken to render and send internal frames
+}
+
+// setPage records the page shown by the frame being rendered.
+func (s *renderStats) setPage(page string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.page = page
+}
+
+// frameSent counts a frame written to the device and updates the frame rate once a
+// second.
+func (s *renderStats) frameSent() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.framesSent++
+	s.windowFrames++
+
+	if elapsed := now.Sub(s.windowStart); elapsed >= time.Second {
+		if !s.windowStart.IsZero() {
+			s.fps = float64(s.windowFrames) / elapsed.Seconds()
+		}
+		s.windowStart = now
+		s.windowFrames = 0
+	}
+}
+
+// frameDropped counts a frame that could not be written and records the error.
+func (s *renderStats) frameDropped(err error) {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+
+	s.usbError(err)
+}
+
+// framesSkipped counts frames that were not drawn or sent to keep the frame rate.
+func (s *renderStats) framesSkipped(count uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.skipped += count
+}
+
+// usbError records the latest USB error.
+func (s *renderStats) usbError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usbErrors++
+	s.lastError = err.Error()
+	s.lastErrorAt = time.Now()
+}
+
+// rendered records the time taken to render and send an internal frame.
+func (s *renderStats) rendered(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.render.observe(d.Seconds())
+}
+
+// snapshot returns the statistics as reported by the status endpoint.
+func (s *renderStats) snapshot() api.RuntimeStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := api.RuntimeStatus{
+		Page:          s.page,
+		FPS:           s.fps,
+		FramesSent:    s.framesSent,
+		DroppedFrames: s.dropped,
+		SkippedFrames: s.skipped,
+		LastUSBError:  s.lastError,
+	}
+
+	// No frame for a while means the render loop is idle
+	if time.Since(s.windowStart) > 2*time.Second {
+		status.FPS = 0
+	}
+
+	if !s.lastErrorAt.IsZero() {
+		at := s.lastErrorAt
+		status.LastUSBErrorAt = &at
+	}
+
+	return status
+}
+
+// statusHandler reports the device connection and render loop state (GET /api/status).
+func (n *Nexus) statusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := n.stats.snapshot()
+
+	status.Connected = n.transport.Attached()
+	status.Virtual = n.transport.Virtual()
+	status.Serial = n.transport.Serial()
+
+	status.ReadOnly = n.readOnly()
+
+	status.Schedule = n.schedules.current()
+
+	paused, _ := n.pause.active()
+	status.Paused = paused
+	status.Power = n.power()
+	status.Brightness = n.transport.Brightness()
+	status.StartedAt = startTime
+	status.Uptime = time.Since(startTime).Seconds()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
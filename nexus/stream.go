@@ -0,0 +1,104 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"nexus-open/nexus/instruments"
+)
+
+// Instrument stream settings
+const (
+	streamBuffer    = 32               // Readings buffered per client before dropping
+	streamKeepAlive = 15 * time.Second // Interval of comments keeping idle connections open
+)
+
+// instrumentStreamHandler streams instrument readings as Server-Sent Events
+// (GET /api/instruments/stream). Every event is named "reading" and holds one
+// reading as JSON; the latest reading of each instrument is sent on connect.
+//
+// Query parameters:
+//   - instrument: instruments to stream, repeated or comma separated (default: all)
+func (n *Nexus) instrumentStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var names []string
+	for _, value := range r.URL.Query()["instrument"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	wanted := func(name string) bool {
+		return len(names) == 0 || slices.Contains(names, name)
+	}
+
+	// The stream outlives the server write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	readings, unsubscribe := n.events.Readings.Subscribe(streamBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(reading instruments.Reading) error {
+		data, err := json.Marshal(reading)
+		if err != nil {
+			return nil // Skip values that cannot be encoded
+		}
+		if _, err := fmt.Fprintf(w, "event: reading\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	for _, name := range n.history.Names() {
+		if reading, ok := n.history.Latest(name); ok && wanted(name) {
+			if err := send(reading); err != nil {
+				return
+			}
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case reading := <-readings:
+			if !wanted(reading.Name) {
+				continue
+			}
+			if err := send(reading); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
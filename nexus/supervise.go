@@ -0,0 +1,57 @@
+package nexus
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// Restart backoff of supervised goroutines
+const (
+	restartMinBackoff = time.Second
+	restartMaxBackoff = time.Minute
+)
+
+// supervise runs fn in a goroutine that Run waits for, like spawn. If fn panics,
+// the panic and its stack are logged and fn is run again after a backoff that
+// doubles up to restartMaxBackoff, until ctx is done. The backoff is reset after
+// fn ran for restartMaxBackoff. The goroutine ends when fn returns.
+func (n *Nexus) supervise(ctx context.Context, name string, fn func()) {
+	n.spawn(func() {
+		backoff := restartMinBackoff
+
+		for {
+			start := time.Now()
+			if !recovered(name, fn) {
+				return
+			}
+
+			if time.Since(start) > restartMaxBackoff {
+				backoff = restartMinBackoff
+			}
+
+			slog.Warn("Restarting after panic", "task", name, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, restartMaxBackoff)
+		}
+	})
+}
+
+// recovered runs fn and reports whether it panicked, logging the panic and the
+// stack of the goroutine.
+func recovered(name string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Recovered from panic", "task", name, "panic", r, "stack", string(debug.Stack()))
+			panicked = true
+		}
+	}()
+
+	fn()
+	return false
+}
@@ -0,0 +1,103 @@
+package nexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+)
+
+// errUnknownTheme is returned when activating a theme that does not exist.
+var errUnknownTheme = errors.New("unknown theme")
+
+// ActivateTheme applies the colors of the named theme, saves them to the
+// configuration file and redraws the display.
+func ActivateTheme(name string) error {
+	return defaultNexus.activateTheme(name)
+}
+
+// activateTheme is ActivateTheme for n.
+func (n *Nexus) activateTheme(name string) error {
+	theme, ok := configuration.FindTheme(name)
+	if !ok {
+		return fmt.Errorf("%w %q", errUnknownTheme, name)
+	}
+
+	cfg := n.configs.Get()
+	if cfg == nil {
+		return fmt.Errorf("no configuration available")
+	}
+
+	updated := *cfg
+	updated.TextColor = theme.TextColor
+	updated.BackgroundColor = theme.BackgroundColor
+
+	if err := configuration.SaveConfig(&updated, ""); err != nil {
+		return err
+	}
+
+	// Show the new colors right away rather than on the next config reload
+	n.configs.Set(&updated)
+	n.requestRedraw()
+	return nil
+}
+
+// themesResponse returns the built-in themes in API form. active is the theme the
+// configuration uses.
+func themesResponse(active string) api.Themes {
+	response := api.Themes{Active: active, Themes: make([]api.Theme, 0, len(configuration.Themes))}
+	for _, theme := range configuration.Themes {
+		response.Themes = append(response.Themes, api.Theme{
+			Name:            theme.Name,
+			Description:     theme.Description,
+			TextColor:       theme.TextColor,
+			BackgroundColor: theme.BackgroundColor,
+		})
+	}
+	return response
+}
+
+// themesHandler lists the themes and the active theme (GET /api/themes).
+func (n *Nexus) themesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var active string
+	if cfg := n.configs.Get(); cfg != nil {
+		active = cfg.ActiveTheme()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(themesResponse(active))
+}
+
+// activateThemeHandler activates a theme (POST /api/themes/activate). The JSON body
+// is {"name": "<theme>"}.
+func (n *Nexus) activateThemeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request api.ThemeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	if err := n.activateTheme(request.Name); errors.Is(err, errUnknownTheme) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to save config", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(themesResponse(request.Name))
+}
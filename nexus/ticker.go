@@ -0,0 +1,190 @@
+package nexus
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"nexus-open/nexus/instruments"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Ticker settings
+const (
+	tickerSpeed     = 40 // Scroll speed in pixels per second
+	tickerSeparator = "  •  "
+)
+
+// Colors of rising and falling stock quotes
+var (
+	tickerUpColor   = color.RGBA{R: 0, G: 200, B: 0, A: 255}
+	tickerDownColor = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+)
+
+// tickerRegion is the area of the top row between the volume and the time widgets
+// in which ticker items scroll. Text outside the region is clipped.
+var tickerRegion = image.Rect(width/2+65, 0, width-85, 22)
+
+// TickerItem is a single entry shown in the scrolling ticker.
+type TickerItem struct {
+	Text  string
+	Color *color.RGBA // nil draws the item in the current text color
+}
+
+// tickerItems collects the entries of all ticker sources in display order.
+func (r *Renderer) tickerItems(config CreateScreenConfig) []TickerItem {
+	var items []TickerItem
+
+	if event := config.nextEvent; event != nil && event.Start.After(time.Now()) {
+		items = append(items, TickerItem{Text: "\uf073 " + event.Title + " " + r.formatEventTime(event)})
+	}
+
+	for _, headline := range config.news {
+		items = append(items, TickerItem{Text: " " + headline.Title})
+	}
+
+	for _, headline := range config.feeds {
+		items = append(items, TickerItem{Text: "\uf09e " + headline.Title})
+	}
+
+	for _, message := range config.mqtt {
+		if message.Text != "" {
+			items = append(items, TickerItem{Text: message.Text})
+		}
+	}
+
+	for _, result := range config.prometheus {
+		items = append(items, TickerItem{Text: result.Name + " " + result.Text})
+	}
+
+	items = append(items, pluginTickerItems(config.plugins)...)
+
+	for _, quote := range config.stocks {
+		// Green for gains, red for losses, text color when unchanged
+		var quoteColor *color.RGBA
+		arrow, sign := "\u25b8", "+"
+		if quote.ChangePercent < 0 {
+			sign = "-"
+		}
+		if quote.Change > 0 {
+			quoteColor, arrow = &tickerUpColor, "\u25b2"
+		} else if quote.Change < 0 {
+			quoteColor, arrow = &tickerDownColor, "\u25bc"
+		}
+
+		items = append(items, TickerItem{
+			Text:  fmt.Sprintf("%s %s %s%s%s%%", quote.Symbol, r.formatDecimal(quote.Price, 2), arrow, sign, r.formatDecimal(math.Abs(quote.ChangePercent), 2)),
+			Color: quoteColor,
+		})
+	}
+
+	return items
+}
+
+// DrawTicker renders items as a horizontally scrolling marquee inside tickerRegion.
+// Items that fit the region without scrolling are drawn statically.
+// If there are no items, nothing is drawn.
+//
+// Parameters:
+//   - items: Slice of TickerItem entries to display in order
+func (r *Renderer) DrawTicker(items []TickerItem) {
+	r.drawMarquee(tickerRegion, 15, items)
+}
+
+// drawMarquee draws items separated by tickerSeparator on the given baseline,
+// clipped to region. If the items are wider than the region they scroll
+// continuously at tickerSpeed, otherwise they are drawn statically.
+func (r *Renderer) drawMarquee(region image.Rectangle, baselineY int, items []TickerItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	measure := (&font.Drawer{Face: r.face}).MeasureString
+	separatorWidth := measure(tickerSeparator)
+
+	var total fixed.Int26_6
+	for _, item := range items {
+		total += measure(item.Text) + separatorWidth
+	}
+
+	// Clip all drawing to the region
+	dst := r.d.Dst
+	if rgba, ok := dst.(*image.RGBA); ok {
+		r.d.Dst = rgba.SubImage(region).(*image.RGBA)
+	}
+	src := r.d.Src
+	defer func() {
+		r.d.Dst = dst
+		r.d.Src = src
+	}()
+
+	baseline := fixed.I(baselineY)
+	regionStart := fixed.I(region.Min.X)
+	regionEnd := fixed.I(region.Max.X)
+
+	x := regionStart
+	scrolling := total-separatorWidth > regionEnd-regionStart
+	if scrolling {
+		x -= fixed.I(int(time.Now().UnixMilli()*tickerSpeed/1000)) % total
+	}
+
+	for x < regionEnd {
+		for i, item := range items {
+			r.d.Src = src
+			if item.Color != nil {
+				r.d.Src = image.NewUniform(*item.Color)
+			}
+
+			r.d.Dot = fixed.Point26_6{X: x, Y: baseline}
+			r.d.DrawString(item.Text)
+
+			if scrolling || i < len(items)-1 {
+				r.d.Src = src
+				r.d.DrawString(tickerSeparator)
+			}
+
+			x = r.d.Dot.X
+		}
+
+		if !scrolling {
+			break
+		}
+	}
+}
+
+// formatEventTime describes when a calendar event starts: a countdown within the
+// next hour, the time of day for events today, the weekday for events within a
+// week and the date in the display locale otherwise.
+func (r *Renderer) formatEventTime(event *instruments.UpcomingEvent) string {
+	now := time.Now()
+	until := event.Start.Sub(now)
+
+	if !event.AllDay && until < time.Hour {
+		return r.translate("in %dm", int(until.Minutes())+1)
+	}
+
+	clock := "15:04"
+	if r.timeFormat.Load().(string) == "12h" {
+		clock = "3:04 PM"
+	}
+
+	sameDay := event.Start.YearDay() == now.YearDay() && event.Start.Year() == now.Year()
+	withinWeek := until < 6*24*time.Hour
+	switch {
+	case event.AllDay && sameDay:
+		return r.translate("today")
+	case event.AllDay && withinWeek:
+		return r.weekdayName(event.Start)
+	case event.AllDay:
+		return event.Start.Format(r.dateLayout())
+	case sameDay:
+		return event.Start.Format(clock)
+	case withinWeek:
+		return r.weekdayName(event.Start) + " " + event.Start.Format(clock)
+	default:
+		return event.Start.Format(r.dateLayout() + " " + clock)
+	}
+}
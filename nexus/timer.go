@@ -0,0 +1,351 @@
+package nexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+)
+
+// Timer widget settings
+const (
+	timerFontSize      = 40             // Size of the digits of the timer and stopwatch
+	timerMaxDuration   = 24 * time.Hour // Longest countdown
+	timerAlertDuration = time.Minute    // Flashing after the countdown reached zero
+	timerEntryDigits   = 6              // Digits typed on the numpad, as hhmmss
+	timerKeyBackspace  = "\uf55a"       // Numpad key removing the last digit
+	timerKeyStart      = "\uf04b"       // Numpad key starting the countdown
+	timerFlashPeriod   = pomodoroFlashPeriod
+)
+
+// numpadRegion is the area of the numpad shown by the timer widget while no
+// countdown is set, the typed time is shown left of it.
+var numpadRegion = image.Rect(200, 0, width, height)
+
+// numpadKeys are the keys of the numpad from left to right.
+var numpadKeys = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "0", timerKeyBackspace, timerKeyStart}
+
+// countdownTimer counts down from a duration set on the numpad or through the API
+// and flashes when it reaches zero. It is safe for concurrent use.
+type countdownTimer struct {
+	mu        sync.Mutex
+	duration  time.Duration // Length of the countdown, 0 while the numpad is shown
+	remaining time.Duration // Time left before the last start
+	started   time.Time     // When the countdown was last started, while running
+	running   bool
+	doneAt    time.Time // When the countdown reached zero, zero while it did not
+	entry     string    // Digits typed on the numpad
+}
+
+// countdownState is the state of the countdown timer at the time of a frame.
+type countdownState struct {
+	entry     string // Digits typed on the numpad, while no countdown is set
+	duration  time.Duration
+	remaining time.Duration
+	running   bool
+	done      bool // Reached zero and flashing until dismissed
+}
+
+// start counts down from duration.
+func (t *countdownTimer) start(duration time.Duration, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.restart(duration, now)
+}
+
+// cancel stops the countdown and shows the numpad.
+func (t *countdownTimer) cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.restart(0, time.Time{})
+}
+
+// restart counts down from duration, or shows the numpad if duration is 0. The
+// caller must hold t.mu.
+func (t *countdownTimer) restart(duration time.Duration, now time.Time) {
+	t.duration, t.remaining, t.started, t.running = duration, duration, now, duration > 0
+	t.doneAt, t.entry = time.Time{}, ""
+}
+
+// toggle pauses or resumes the countdown, or dismisses it once it reached zero.
+func (t *countdownTimer) toggle(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.duration == 0:
+	case !t.doneAt.IsZero():
+		t.restart(0, time.Time{})
+	case t.running:
+		t.remaining = max(t.remaining-now.Sub(t.started), 0)
+		t.running = false
+	default:
+		t.started = now
+		t.running = true
+	}
+}
+
+// press handles a key of the numpad: digits are appended to the typed time, the
+// backspace key removes the last digit and the start key starts the countdown.
+func (t *countdownTimer) press(key string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch key {
+	case timerKeyBackspace:
+		if t.entry != "" {
+			t.entry = t.entry[:len(t.entry)-1]
+		}
+	case timerKeyStart:
+		if duration := entryDuration(t.entry); duration > 0 {
+			t.restart(duration, now)
+		}
+	default:
+		if len(t.entry) < timerEntryDigits && (t.entry != "" || key != "0") {
+			t.entry += key
+		}
+	}
+}
+
+// state returns the state of the countdown at now, stopping it when it reached zero.
+func (t *countdownTimer) state(now time.Time) countdownState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	remaining := t.remaining
+	if t.running {
+		remaining -= now.Sub(t.started)
+		if remaining <= 0 {
+			t.doneAt = t.started.Add(t.remaining)
+			t.remaining, t.running, remaining = 0, false, 0
+		}
+	}
+
+	// The alert stops by itself after a while
+	if !t.doneAt.IsZero() && now.Sub(t.doneAt) > timerAlertDuration {
+		t.restart(0, time.Time{})
+	}
+
+	return countdownState{
+		entry:     t.entry,
+		duration:  t.duration,
+		remaining: remaining,
+		running:   t.running,
+		done:      !t.doneAt.IsZero(),
+	}
+}
+
+// entryDuration converts digits typed on the numpad to a duration. They are read
+// right to left as seconds, minutes and hours, so "130" is 1:30.
+func entryDuration(entry string) time.Duration {
+	if entry == "" {
+		return 0
+	}
+
+	value, _ := strconv.Atoi(entry)
+	hours, minutes, seconds := value/10000, value/100%100, value%100
+	duration := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	return min(duration, timerMaxDuration)
+}
+
+// stopwatchTimer measures elapsed time. It is safe for concurrent use.
+type stopwatchTimer struct {
+	mu      sync.Mutex
+	elapsed time.Duration // Time measured before the last start
+	started time.Time     // When the stopwatch was last started, while running
+	running bool
+}
+
+// stopwatchState is the state of the stopwatch at the time of a frame.
+type stopwatchState struct {
+	elapsed time.Duration
+	running bool
+}
+
+// toggle starts or stops the stopwatch.
+func (s *stopwatchTimer) toggle(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		s.elapsed += now.Sub(s.started)
+	} else {
+		s.started = now
+	}
+	s.running = !s.running
+}
+
+// reset stops the stopwatch and sets it to zero.
+func (s *stopwatchTimer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.elapsed, s.started, s.running = 0, time.Time{}, false
+}
+
+// state returns the state of the stopwatch at now.
+func (s *stopwatchTimer) state(now time.Time) stopwatchState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := s.elapsed
+	if s.running {
+		elapsed += now.Sub(s.started)
+	}
+	return stopwatchState{elapsed: elapsed, running: s.running}
+}
+
+// DrawCountdown fills the display with the countdown timer: the numpad with the
+// typed time while no countdown is set, otherwise the remaining time in large
+// digits. Once it reached zero the display flashes in the text color.
+func (r *Renderer) DrawCountdown(state countdownState, fontName string) {
+	dst, ok := r.d.Dst.(draw.Image)
+	if !ok {
+		return
+	}
+
+	if state.duration == 0 {
+		r.drawLargeText(formatTimer(entryDuration(state.entry)), fontName, image.Rect(0, 0, numpadRegion.Min.X, height))
+		r.drawNumpad(dst)
+		return
+	}
+
+	if state.done && (time.Now().UnixNano()/int64(timerFlashPeriod))%2 == 0 {
+		draw.Draw(dst, dst.Bounds(), r.d.Src, image.Point{}, draw.Src)
+		return
+	}
+
+	if !state.running && !state.done {
+		r.d.Dot = fixed.Point26_6{X: fixed.I(10), Y: fixed.I(28)}
+		r.d.DrawString(r.translate("Paused"))
+	}
+	r.drawLargeText(formatTimer(state.remaining), fontName, dst.Bounds())
+}
+
+// drawNumpad draws the keys of the numpad in numpadRegion, separated by lines.
+func (r *Renderer) drawNumpad(dst draw.Image) {
+	keyWidth := numpadRegion.Dx() / len(numpadKeys)
+	for i, key := range numpadKeys {
+		x := numpadRegion.Min.X + i*keyWidth
+		draw.Draw(dst, image.Rect(x, 8, x+1, height-8), r.d.Src, image.Point{}, draw.Over)
+
+		r.d.Dot = fixed.Point26_6{
+			X: fixed.I(x) + (fixed.I(keyWidth)-(&font.Drawer{Face: r.face}).MeasureString(key))/2,
+			Y: fixed.I(height/2 + 5),
+		}
+		r.d.DrawString(key)
+	}
+}
+
+// numpadKey returns the numpad key at x, false if x is outside numpadRegion.
+func numpadKey(x int) (string, bool) {
+	if x < numpadRegion.Min.X || x >= numpadRegion.Max.X {
+		return "", false
+	}
+	return numpadKeys[min((x-numpadRegion.Min.X)/(numpadRegion.Dx()/len(numpadKeys)), len(numpadKeys)-1)], true
+}
+
+// DrawStopwatch fills the display with the elapsed time of the stopwatch in large
+// digits, with tenths of a second, and marks it while it is stopped.
+func (r *Renderer) DrawStopwatch(state stopwatchState, fontName string) {
+	if !state.running && state.elapsed > 0 {
+		r.d.Dot = fixed.Point26_6{X: fixed.I(10), Y: fixed.I(28)}
+		r.d.DrawString(r.translate("Paused"))
+	}
+
+	elapsed := state.elapsed.Truncate(100 * time.Millisecond)
+	text := fmt.Sprintf("%s.%d", formatTimer(elapsed.Truncate(time.Second)), elapsed/(100*time.Millisecond)%10)
+	r.drawLargeText(text, fontName, image.Rect(0, 0, width, height))
+}
+
+// drawLargeText draws text in the font of the timer digits, centered in region.
+func (r *Renderer) drawLargeText(text, fontName string, region image.Rectangle) {
+	face := LoadSystemFont(fontName, timerFontSize)
+	metrics := face.Metrics()
+
+	d := font.Drawer{Dst: r.d.Dst, Src: r.d.Src, Face: face}
+	d.Dot = fixed.Point26_6{
+		X: fixed.I(region.Min.X) + (fixed.I(region.Dx())-d.MeasureString(text))/2,
+		Y: fixed.I(region.Min.Y) + (fixed.I(region.Dy())+metrics.Ascent-metrics.Descent)/2,
+	}
+	d.DrawString(text)
+}
+
+// timerResponse returns the state of the countdown timer in API form.
+func (n *Nexus) timerResponse() api.Timer {
+	state := n.countdown.state(time.Now())
+	return api.Timer{
+		Duration:  state.duration.Seconds(),
+		Remaining: state.remaining.Seconds(),
+		Running:   state.running,
+		Done:      state.done,
+	}
+}
+
+// timerHandler reads (GET), starts (POST) or cancels (DELETE) the countdown timer
+// (/api/timer).
+//
+// The JSON body of POST has the field:
+//   - duration: seconds to count down (required, at most 86400)
+//
+// Starting the timer replaces a running countdown and switches to the first page
+// showing the timer widget, unless the active page shows it.
+func (n *Nexus) timerHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var request api.TimerRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+
+		duration := time.Duration(request.Duration * float64(time.Second))
+		if duration < time.Second || duration > timerMaxDuration {
+			http.Error(w, fmt.Sprintf("Duration must be between 1 and %d seconds", int(timerMaxDuration.Seconds())), http.StatusBadRequest)
+			return
+		}
+
+		n.countdown.start(duration, time.Now())
+		n.showWidgetPage(configuration.WidgetTimer)
+	case http.MethodDelete:
+		n.countdown.cancel()
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n.requestRedraw()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(n.timerResponse())
+}
+
+// showWidgetPage activates the first page showing widget, unless the active page
+// shows it or no page does.
+func (n *Nexus) showWidgetPage(widget string) {
+	list, active := n.pages.list()
+	for _, page := range list {
+		if page.Name == active && page.Shows(widget) {
+			return
+		}
+	}
+	for _, page := range list {
+		if page.Shows(widget) {
+			n.setPage(page.Name)
+			return
+		}
+	}
+}
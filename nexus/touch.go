@@ -1,160 +1,225 @@
 // Package main provides functionality for monitoring and processing touch events from a USB device.
 //
 // The package implements a touch monitor system that:
-// - Continuously reads touch input data from a USB device
-// - Parses raw touch data into structured touch events
+// - Continuously reads touch reports from the device, see nexusdisplay.Display.ReadTouch
+// - Converts them into structured touch events
 // - Handles device disconnection and reconnection gracefully
-// - Provides touch event information through a channel-based interface
 //
 // The touch event monitoring system operates asynchronously using goroutines and channels,
 // allowing for non-blocking touch event processing. It includes automatic retry mechanisms
 // for handling device disconnections and reconnections.
 //
-// TouchEvent represents a single touch interaction with coordinates (X,Y) and press state.
+// TouchEvent represents a touch report of the device with coordinates (X,Y).
 // The system filters duplicate events to prevent event flooding and provides detailed error
 // handling for various USB device states.
 //
-// Example usage:
+// The monitor is run by Nexus.Run until its context is done.
 //
-//	eventChan := StartTouchMonitor()
-//	for event := range eventChan {
-//	    // Process touch events
-//	    fmt.Printf("Touch at (%d,%d), pressed: %v\n", event.X, event.Y, event.Pressed)
-//	}
-//
-// The package relies on the github.com/google/gousb library for USB device communication.
+// The device is accessed through the nexusdisplay package.
 package nexus
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"image"
 	"math"
 	"time"
 
-	"github.com/google/gousb"
+	"nexus-open/nexus/configuration"
+	"nexus-open/nexus/nexusdisplay"
+)
+
+// Touch timing
+const (
+	tapGap    = 250 * time.Millisecond // Minimum pause between touch reports that separates two touches
+	longPress = 800 * time.Millisecond // Touch duration that makes a long press
 )
 
 type TouchEvent struct {
 	X         int
 	Y         int
-	Pressed   bool
 	Timestamp time.Time
 }
 
-func StartTouchMonitor() <-chan TouchEvent {
-	events := make(chan TouchEvent)
-
-	go func() {
-		for {
-			if err := readTouchInput(device); err != nil {
-				connected = false
-				time.Sleep(time.Second) // Wait before retrying
-				if !connected {
-					continue
-				}
-			}
+// runTouchMonitor reads touch input from the device until ctx is done, waiting
+// for the device while it is unavailable.
+func (n *Nexus) runTouchMonitor(ctx context.Context) {
+	for {
+		if err := n.readTouchInput(ctx); err != nil && ctx.Err() == nil {
+			n.setConnected(false)
 		}
-	}()
-
-	return events
-}
-
-// readTouchInput handles USB touch input events from the specified USB device.
-// It opens the device's input endpoint and processes incoming touch events.
-// The function takes ownership of device lifecycle and ensures proper cleanup.
-//
-// Parameters:
-//   - device: Pointer to an initialized gousb.Device to read touch input from
-//
-// Returns:
-//   - error: Returns nil on successful processing, or an error if:
-//   - The device is not initialized
-//   - Failed to get input endpoint
-//   - Error occurred during touch event processing
-func readTouchInput(device *gousb.Device) error {
-	if device == nil {
-		return fmt.Errorf("device not initialized")
-	}
-
-	defer usbintf.Close() // Close USB interface on function exit
 
-	// Get input endpoint
-	in, err := usbintf.InEndpoint(1) // Input endpoint is 1
-
-	if err != nil {
-		return fmt.Errorf("failed to get input endpoint: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second): // Wait before retrying
+		}
 	}
-
-	return processTouchEvents(in)
 }
 
-// processTouchEvents continuously reads touch data from a USB endpoint and processes it into touch events.
-// It reads raw touch data in bytes, parses it into TouchEvent structs, and prints changes in touch state.
-// The function filters duplicate events by comparing with the last processed event.
-// If the device is disconnected, it sets the global connected flag to false and returns an error.
-//
-// Parameters:
-//   - in: Pointer to a gousb.InEndpoint for reading USB touch data
+// readTouchInput reads touch reports from the device of the transport and
+// processes them into touch events. The function filters duplicate events by
+// comparing with the last processed event. If the device is disconnected, it marks
+// the device as disconnected and returns an error.
 //
 // Returns:
-//   - error: Returns an error if the device is disconnected or if other USB read errors occur
+//   - error: Returns an error if:
+//   - The device is not initialized
+//   - The device is disconnected
+//   - ctx is done
 //
-// The function runs in an infinite loop until an error occurs or the device is disconnected.
-func processTouchEvents(in *gousb.InEndpoint) error {
-	touchData := make([]byte, 1024)
+// The function runs in an infinite loop until an error occurs, the device is disconnected
+// or ctx is done.
+func (n *Nexus) readTouchInput(ctx context.Context) error {
 	var lastEvent *TouchEvent
+	var lastReport, touchStart time.Time
+	var longPressed bool
 
 	for {
-		_, err := in.Read(touchData)
+		touch, err := n.transport.ReadTouch(ctx)
 		if err != nil {
-			if err.Error() == "libusb: no device [code -4]" {
-				connected = false
-				return fmt.Errorf("device disconnected")
+			if ctx.Err() != nil || errors.Is(err, errNoDevice) {
+				return err
+			}
+			if errors.Is(err, errDeviceDisconnected) {
+				n.stats.usbError(err)
+				n.setConnected(false)
+				return err
 			}
 			time.Sleep(100 * time.Millisecond)
 			continue
 		}
 
-		if evt := parseTouchEvent(touchData, lastEvent); evt != nil {
-			if lastEvent == nil || *evt != *lastEvent {
-				// fmt.Printf("Touch event: x=%d, y=%d, pressed=%v\n", evt.X, evt.Y, evt.Pressed)
-				lastEvent = evt
-			}
+		evt := newTouchEvent(touch, lastEvent)
+		// A report after a pause in the stream starts a new touch
+		if evt.Timestamp.Sub(lastReport) > tapGap {
+			touchStart, longPressed = evt.Timestamp, false
+			n.handleTap(*evt)
+		} else if !longPressed && evt.Timestamp.Sub(touchStart) >= longPress {
+			longPressed = true
+			n.handleLongPress(*evt)
+		}
+		lastReport = evt.Timestamp
+		if lastEvent == nil || *evt != *lastEvent {
+			lastEvent = evt
 		}
 	}
 }
 
-// parseTouchEvent processes raw touch event data and converts it into a TouchEvent struct.
-// It validates the touch event protocol by checking magic numbers in the first 3 bytes.
+// handleTap dispatches the start of a touch to the widget under it and publishes
+// it on the event bus for D-Bus and webhooks. A tap:
+//   - dismisses the ringing alarm
+//   - switches the switched off display on
+//   - publishes the message of the MQTT action under it
+//   - starts or pauses the pomodoro, the timer or the stopwatch, or types a key
+//     on the numpad of the timer
+//   - toggles media playback on the now-playing widget or its album art
+//   - toggles mute on the volume widget
 //
-// The function expects raw touch data in the following format:
-// - Bytes 0-2: Protocol magic numbers (1,2,33)
-// - Bytes 5-6: X coordinate (high byte, low byte)
-// - Bytes 7-8: Y coordinate (high byte, low byte)
+// The tap acts on touch down, so a long press first starts or pauses the pomodoro,
+// timer or stopwatch before handleLongPress resets it, which discards that.
+func (n *Nexus) handleTap(evt TouchEvent) {
+	point := image.Pt(evt.X, evt.Y)
+
+	n.events.Touch.Publish(evt)
+
+	// A tap on the ringing alarm only dismisses it
+	if n.alarms.dismiss() {
+		n.requestRedraw()
+		return
+	}
+
+	// A tap on the switched off display only wakes it
+	if !n.power() {
+		n.setPower(true)
+		return
+	}
+
+	cfg := n.configs.Get()
+	if cfg != nil {
+		for _, action := range cfg.MQTT.Actions {
+			if point.In(image.Rect(action.X, action.Y, action.X+action.Width, action.Y+action.Height)) {
+				go n.publishMQTTAction(action)
+				return
+			}
+		}
+	}
+
+	switch {
+	case n.showsWidget(cfg, configuration.WidgetPomodoro):
+		n.pomodoro.toggle(evt.Timestamp)
+		n.requestRedraw()
+		return
+	case n.showsWidget(cfg, configuration.WidgetTimer):
+		if key, ok := numpadKey(evt.X); ok && n.countdown.state(evt.Timestamp).duration == 0 {
+			n.countdown.press(key, evt.Timestamp)
+		} else {
+			n.countdown.toggle(evt.Timestamp)
+		}
+		n.requestRedraw()
+		return
+	case n.showsWidget(cfg, configuration.WidgetStopwatch):
+		n.stopwatch.toggle(evt.Timestamp)
+		n.requestRedraw()
+		return
+	}
+
+	switch {
+	case (point.In(nowPlayingRegion) || point.In(albumArtRegion)) && n.showsWidget(cfg, configuration.WidgetMedia):
+		go n.toggleMediaPlayback()
+	case point.In(volumeRegion) && n.showsWidget(cfg, configuration.WidgetVolume):
+		go n.toggleMute()
+	}
+}
+
+// handleLongPress dispatches a touch held for longPress to the widget under it.
+// Holding the pomodoro widget or the stopwatch resets it, holding the timer
+// cancels the n.countdown.
+func (n *Nexus) handleLongPress(evt TouchEvent) {
+	if !n.power() {
+		return
+	}
+
+	cfg := n.configs.Get()
+	switch {
+	case n.showsWidget(cfg, configuration.WidgetPomodoro):
+		n.pomodoro.reset()
+	case n.showsWidget(cfg, configuration.WidgetTimer):
+		n.countdown.cancel()
+	case n.showsWidget(cfg, configuration.WidgetStopwatch):
+		n.stopwatch.reset()
+	default:
+		return
+	}
+	n.requestRedraw()
+}
+
+// showsWidget reports whether the active page shows widget and cfg, if any, does
+// not hide it.
+func (n *Nexus) showsWidget(cfg *configuration.NexusConfig, widget string) bool {
+	return n.pages.current().Shows(widget) && (cfg == nil || cfg.ShowsWidget(widget))
+}
+
+// newTouchEvent converts a touch report of the device into a TouchEvent received now.
 //
 // It also detects swipe gestures by comparing the current event with the last event
-// if provided. A swipe is detected when the squared distance between points exceeds 1000.
+// if provided.
 //
 // Parameters:
-//   - data: Raw touch event byte array
+//   - touch: Touch report read from the device
 //   - lastEvent: Pointer to previous TouchEvent for swipe detection, can be nil
 //
 // Returns:
-//   - *TouchEvent: Parsed touch event or nil if invalid protocol magic numbers
-func parseTouchEvent(data []byte, lastEvent *TouchEvent) *TouchEvent {
-	// Validate protocol magic numbers
-	if data[0] != 1 || data[1] != 2 || data[2] != 33 {
-		return nil
-	}
-
+//   - *TouchEvent: The touch event
+func newTouchEvent(touch nexusdisplay.Touch, lastEvent *TouchEvent) *TouchEvent {
 	evt := &TouchEvent{
-		X:         int(data[5])*256 + int(data[6]),
-		Y:         int(data[7])*256 + int(data[8]),
-		Pressed:   data[2] == 33,
+		X:         touch.X,
+		Y:         touch.Y,
 		Timestamp: time.Now(),
 	}
 
 	// Process swipe gestures only when we have a previous event
-	if lastEvent != nil && evt.Pressed && lastEvent.Pressed {
+	if lastEvent != nil {
 		dx := float64(evt.X - lastEvent.X)
 		dy := float64(evt.Y - lastEvent.Y)
 		duration := time.Since(lastEvent.Timestamp)
@@ -176,15 +241,15 @@ func parseTouchEvent(data []byte, lastEvent *TouchEvent) *TouchEvent {
 
 			if isHorizontal && math.Abs(vx) > minSwipeVelocity {
 				if vx < -minSwipeVelocity {
-					fmt.Printf("Left swipe (%.0f px/s)\n", vx)
+					touchLog.Debug("Left swipe", "velocity", vx)
 				} else if vx > minSwipeVelocity {
-					fmt.Printf("Right swipe (%.0f px/s)\n", vx)
+					touchLog.Debug("Right swipe", "velocity", vx)
 				}
 			} else if isVertical && math.Abs(vy) > minSwipeVelocity {
 				if vy < -minSwipeVelocity {
-					fmt.Printf("Up swipe (%.0f px/s)\n", vy)
+					touchLog.Debug("Up swipe", "velocity", vy)
 				} else if vy > minSwipeVelocity {
-					fmt.Printf("Down swipe (%.0f px/s)\n", vy)
+					touchLog.Debug("Down swipe", "velocity", vy)
 				}
 			}
 		}
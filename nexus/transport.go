@@ -0,0 +1,226 @@
+package nexus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"nexus-open/nexus/nexusdisplay"
+
+	"github.com/google/gousb"
+)
+
+// errDeviceDisconnected is returned by Transport.Send and Transport.ReadTouch
+// when the device was unplugged.
+var errDeviceDisconnected = nexusdisplay.ErrDisconnected
+
+// errNoDevice is returned by Transport.ReadTouch while no device is open.
+var errNoDevice = errors.New("device not initialized")
+
+// device is the display a Transport sends frames to, an opened
+// nexusdisplay.Display outside of tests.
+type device interface {
+	WriteFrame(ctx context.Context, frame []byte) error
+	ReadTouch(ctx context.Context) (nexusdisplay.Touch, error)
+	Serial() string
+	Close() error
+}
+
+// Transport is the USB connection to the device, or the virtual display when
+// there is none. It is safe for concurrent use.
+type Transport struct {
+	mu        sync.Mutex
+	usb       *gousb.Context
+	display   device // Opened device, nil while there is none
+	connected bool   // Connection status
+	serial    string // USB serial number of the device
+	virtual   bool   // Frames are only rendered, see SetVirtual
+	rotation  int    // Degrees frames are turned by, see SetRotation
+
+	brightness atomic.Int32              // Brightness in percent, see SetBrightness
+	dim        atomic.Pointer[[256]byte] // Maps channel values to dimmed values, nil at full brightness
+
+	out []byte // Buffer for turned and dimmed frames, owned by Send
+}
+
+// NewTransport returns a disconnected transport at full brightness.
+func NewTransport() *Transport {
+	t := &Transport{}
+	t.brightness.Store(MaxBrightness)
+	return t
+}
+
+// Open opens the first iCUE Nexus found, see nexusdisplay.Open. It returns
+// false if none is attached or it cannot be opened. A previously opened device
+// is closed.
+func (t *Transport) Open() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.usb == nil {
+		t.usb = gousb.NewContext()
+	}
+
+	display, err := nexusdisplay.Open(t.usb)
+	if err != nil {
+		if !errors.Is(err, nexusdisplay.ErrNotFound) {
+			usbLog.Error("Failed to open device", "error", err)
+		}
+		return false
+	}
+
+	if t.display != nil {
+		t.display.Close()
+	}
+	t.display = display
+	t.serial = display.Serial()
+
+	return true
+}
+
+// Close closes the device. The connection status is left to the caller, see
+// Nexus.setConnected.
+func (t *Transport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.display != nil {
+		t.display.Close()
+	}
+
+	t.display = nil
+}
+
+// setConnected sets the connection status, returning whether it changed.
+func (t *Transport) setConnected(value bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	changed := t.connected != value
+	t.connected = value
+	return changed
+}
+
+// Connected reports the connection status.
+func (t *Transport) Connected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected
+}
+
+// Attached reports whether frames have somewhere to go: a connected device or
+// the virtual display.
+func (t *Transport) Attached() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected && (t.display != nil || t.virtual)
+}
+
+// Serial returns the USB serial number of the device, "" if unknown.
+func (t *Transport) Serial() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.serial
+}
+
+// SetVirtual runs the transport without a device when enabled. It must be called
+// before the device is opened.
+func (t *Transport) SetVirtual(enabled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.virtual = enabled
+}
+
+// SetRotation turns the frames sent by 180 degrees, for a device mounted upside
+// down, or by 0.
+func (t *Transport) SetRotation(degrees int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rotation = degrees
+}
+
+// Virtual reports whether the transport runs without a device.
+func (t *Transport) Virtual() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.virtual
+}
+
+// healthy verifies that the device is open.
+func (t *Transport) healthy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.display == nil {
+		usbLog.Warn("Device handle is not available")
+		return false
+	}
+
+	return true
+}
+
+// ReadTouch waits for the next touch report of the device until ctx is done, see
+// nexusdisplay.Display.ReadTouch.
+func (t *Transport) ReadTouch(ctx context.Context) (nexusdisplay.Touch, error) {
+	t.mu.Lock()
+	display := t.display
+	t.mu.Unlock()
+
+	if display == nil {
+		return nexusdisplay.Touch{}, errNoDevice
+	}
+	return display.ReadTouch(ctx)
+}
+
+// Send writes a complete RGBA frame to the device, turned by the rotation and
+// dimmed to the current brightness. It does nothing while disconnected or for the virtual display. The
+// transfer is aborted when ctx is done. Send must not be called concurrently.
+func (t *Transport) Send(ctx context.Context, frame []byte) error {
+	t.mu.Lock()
+	connected, virtual, display, rotation := t.connected, t.virtual, t.display, t.rotation
+	t.mu.Unlock()
+
+	if !connected {
+		usbLog.Debug("Not connected")
+		return nil
+	}
+
+	// The virtual display has no device to send frames to
+	if virtual {
+		return nil
+	}
+
+	if display == nil {
+		return errNoDevice
+	}
+
+	// Turn and dim the pixels in the buffer of Send, the frame belongs to the caller
+	dim := t.dim.Load()
+	if (dim != nil || rotation == 180) && len(frame) == nexusdisplay.FrameSize {
+		if t.out == nil {
+			t.out = make([]byte, nexusdisplay.FrameSize)
+		}
+		transformFrame(t.out, frame, rotation == 180, dim)
+		frame = t.out
+	}
+
+	return display.WriteFrame(ctx, frame)
+}
+
+// transformFrame writes the pixels of src to dst, in reverse order if upsideDown
+// and mapped through dim unless it is nil.
+func transformFrame(dst, src []byte, upsideDown bool, dim *[256]byte) {
+	for i := 0; i+4 <= len(src); i += 4 {
+		j := i
+		if upsideDown {
+			// Turning upside down reverses the order of the pixels
+			j = len(src) - i - 4
+		}
+		if dim != nil {
+			dst[j], dst[j+1], dst[j+2], dst[j+3] = dim[src[i]], dim[src[i+1]], dim[src[i+2]], dim[src[i+3]]
+		} else {
+			copy(dst[j:j+4], src[i:i+4])
+		}
+	}
+}
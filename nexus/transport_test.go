@@ -0,0 +1,224 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"nexus-open/nexus/nexusdisplay"
+)
+
+// fakeDevice records the frames sent to it and answers ReadTouch with touches.
+type fakeDevice struct {
+	frames  [][]byte
+	touches []nexusdisplay.Touch
+	err     error // Returned by WriteFrame and, once touches ran out, ReadTouch
+	closed  bool
+}
+
+func (d *fakeDevice) WriteFrame(ctx context.Context, frame []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.frames = append(d.frames, bytes.Clone(frame))
+	return nil
+}
+
+func (d *fakeDevice) ReadTouch(ctx context.Context) (nexusdisplay.Touch, error) {
+	if len(d.touches) == 0 {
+		return nexusdisplay.Touch{}, d.err
+	}
+	touch := d.touches[0]
+	d.touches = d.touches[1:]
+	return touch, nil
+}
+
+func (d *fakeDevice) Serial() string { return "FAKE0001" }
+
+func (d *fakeDevice) Close() error {
+	d.closed = true
+	return nil
+}
+
+// newFakeTransport returns a connected transport sending to a fake device.
+func newFakeTransport() (*Transport, *fakeDevice) {
+	dev := &fakeDevice{}
+	t := NewTransport()
+	t.display, t.serial = dev, dev.Serial()
+	t.setConnected(true)
+	return t, dev
+}
+
+// testFrame returns a frame with every byte set to value.
+func testFrame(value byte) []byte {
+	return bytes.Repeat([]byte{value}, nexusdisplay.FrameSize)
+}
+
+func TestTransportSend(t *testing.T) {
+	transport, dev := newFakeTransport()
+
+	if !transport.Attached() {
+		t.Fatal("transport with a device is not attached")
+	}
+	if err := transport.Send(context.Background(), testFrame(200)); err != nil {
+		t.Fatal(err)
+	}
+	if len(dev.frames) != 1 || !bytes.Equal(dev.frames[0], testFrame(200)) {
+		t.Errorf("device received %d frames, want the sent frame", len(dev.frames))
+	}
+}
+
+func TestTransportSendDimmed(t *testing.T) {
+	transport, dev := newFakeTransport()
+
+	if err := transport.SetBrightness(50); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := testFrame(200)
+	if err := transport.Send(context.Background(), frame); err != nil {
+		t.Fatal(err)
+	}
+	if len(dev.frames) != 1 || !bytes.Equal(dev.frames[0], testFrame(100)) {
+		t.Error("device did not receive the frame dimmed to half")
+	}
+	if !bytes.Equal(frame, testFrame(200)) {
+		t.Error("Send modified the frame of the caller")
+	}
+}
+
+// pixelFrame returns a frame whose pixels all differ.
+func pixelFrame() []byte {
+	frame := make([]byte, nexusdisplay.FrameSize)
+	for i := range frame {
+		frame[i] = byte(i / 4 * 7)
+	}
+	return frame
+}
+
+func TestTransportSendRotated(t *testing.T) {
+	tests := []struct {
+		name       string
+		brightness int
+	}{
+		{"full brightness", MaxBrightness},
+		{"dimmed", 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, dev := newFakeTransport()
+			transport.SetRotation(180)
+			if err := transport.SetBrightness(tt.brightness); err != nil {
+				t.Fatal(err)
+			}
+
+			frame := pixelFrame()
+			if err := transport.Send(context.Background(), frame); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(frame, pixelFrame()) {
+				t.Error("Send modified the frame of the caller")
+			}
+
+			sent, last := dev.frames[0], len(frame)-4
+			for _, i := range []int{0, 4, last} {
+				want := frame[last-i : last-i+4]
+				if tt.brightness != MaxBrightness {
+					want = []byte{want[0] / 2, want[1] / 2, want[2] / 2, want[3] / 2}
+				}
+				if got := sent[i : i+4]; !bytes.Equal(got, want) {
+					t.Errorf("pixel at %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// discardDevice drops the frames sent to it.
+type discardDevice struct {
+	fakeDevice
+}
+
+func (d *discardDevice) WriteFrame(ctx context.Context, frame []byte) error {
+	return nil
+}
+
+func TestTransportSendRotatedAllocs(t *testing.T) {
+	transport := NewTransport()
+	transport.display = &discardDevice{}
+	transport.setConnected(true)
+	transport.SetRotation(180)
+
+	frame := pixelFrame()
+	transport.Send(context.Background(), frame)
+	if allocs := testing.AllocsPerRun(10, func() { transport.Send(context.Background(), frame) }); allocs != 0 {
+		t.Errorf("Send allocates %v times per rotated frame, want 0", allocs)
+	}
+}
+
+func TestTransportSendSkipped(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(*Transport)
+	}{
+		{"disconnected", func(t *Transport) { t.setConnected(false) }},
+		{"virtual", func(t *Transport) { t.SetVirtual(true) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, dev := newFakeTransport()
+			tt.prepare(transport)
+
+			if err := transport.Send(context.Background(), testFrame(1)); err != nil {
+				t.Fatal(err)
+			}
+			if len(dev.frames) != 0 {
+				t.Errorf("device received %d frames, want none", len(dev.frames))
+			}
+		})
+	}
+}
+
+func TestTransportUnplugged(t *testing.T) {
+	transport, dev := newFakeTransport()
+	dev.err = nexusdisplay.ErrDisconnected
+
+	if err := transport.Send(context.Background(), testFrame(1)); !errors.Is(err, errDeviceDisconnected) {
+		t.Errorf("Send() = %v, want errDeviceDisconnected", err)
+	}
+	if _, err := transport.ReadTouch(context.Background()); !errors.Is(err, errDeviceDisconnected) {
+		t.Errorf("ReadTouch() = %v, want errDeviceDisconnected", err)
+	}
+}
+
+func TestTransportReadTouch(t *testing.T) {
+	want := nexusdisplay.Touch{X: 602, Y: 31}
+	transport, dev := newFakeTransport()
+	dev.touches = []nexusdisplay.Touch{want}
+
+	touch, err := transport.ReadTouch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if touch != want {
+		t.Errorf("ReadTouch() = %+v, want %+v", touch, want)
+	}
+}
+
+func TestTransportClose(t *testing.T) {
+	transport, dev := newFakeTransport()
+	transport.Close()
+
+	if !dev.closed {
+		t.Error("device was not closed")
+	}
+	if transport.Attached() {
+		t.Error("transport is attached after Close")
+	}
+	if _, err := transport.ReadTouch(context.Background()); !errors.Is(err, errNoDevice) {
+		t.Errorf("ReadTouch() = %v, want errNoDevice", err)
+	}
+}
@@ -0,0 +1,8 @@
+package nexus
+
+// SetVirtual runs the display without a device when enabled: frames are rendered
+// and published to the preview, status and metrics but not sent over USB. It must
+// be called before Run.
+func SetVirtual(enabled bool) {
+	defaultNexus.transport.SetVirtual(enabled)
+}
@@ -0,0 +1,161 @@
+package nexus
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"nexus-open/nexus/api"
+	"nexus-open/nexus/configuration"
+)
+
+// Webhook delivery settings
+const (
+	webhookQueueSize    = 100
+	webhookTimeout      = 10 * time.Second
+	webhookAttempts     = 5
+	webhookBackoff      = time.Second // Doubled after every failed attempt
+	webhookSignatureKey = "X-Nexus-Signature"
+)
+
+// webhookDelivery is a payload waiting to be posted to a webhook.
+type webhookDelivery struct {
+	webhook configuration.Webhook
+	payload []byte
+}
+
+var webhookClient = &http.Client{Timeout: webhookTimeout}
+
+// startWebhooks sends the touch, device, page and alert events of the event bus
+// to webhooks and delivers them until ctx is done.
+func (n *Nexus) startWebhooks(ctx context.Context) {
+	n.supervise(ctx, "webhooks", func() {
+		touches, unsubscribeTouch := n.events.Touch.Subscribe(eventBuffer)
+		devices, unsubscribeDevice := n.events.Device.Subscribe(eventBuffer)
+		pageSwitches, unsubscribePage := n.events.Page.Subscribe(eventBuffer)
+		alertChanges, unsubscribeAlert := n.events.Alert.Subscribe(eventBuffer)
+
+		defer unsubscribeTouch()
+		defer unsubscribeDevice()
+		defer unsubscribePage()
+		defer unsubscribeAlert()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt := <-touches:
+				n.sendWebhook(configuration.WebhookEventTouch, api.TouchEvent{X: evt.X, Y: evt.Y})
+			case evt := <-devices:
+				event := configuration.WebhookEventDisconnect
+				if evt.Connected {
+					event = configuration.WebhookEventConnect
+				}
+				n.sendWebhook(event, evt)
+			case evt := <-pageSwitches:
+				n.sendWebhook(configuration.WebhookEventPage, evt)
+			case evt := <-alertChanges:
+				n.sendWebhook(configuration.WebhookEventAlert, evt)
+			case delivery := <-n.webhooks:
+				// Deliver concurrently so one slow receiver does not delay the others
+				go deliverWebhook(ctx, delivery)
+			}
+		}
+	})
+}
+
+// sendWebhook queues event for every configured webhook subscribed to it.
+//
+// Parameters:
+//   - event: Event name, one of the configuration.WebhookEvent constants
+//   - data: Event details, encoded as the data field of the payload
+func (n *Nexus) sendWebhook(event string, data interface{}) {
+	cfg := n.configs.Get()
+	if cfg == nil || len(cfg.Webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(api.WebhookEvent{Event: event, Time: time.Now(), Data: data})
+	if err != nil {
+		webhookLog.Error("Failed to encode event", "event", event, "error", err)
+		return
+	}
+
+	for _, webhook := range cfg.Webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+
+		select {
+		case n.webhooks <- webhookDelivery{webhook: webhook, payload: payload}:
+		default:
+			webhookLog.Warn("Queue full, dropping event", "event", event, "url", webhook.URL)
+		}
+	}
+}
+
+// deliverWebhook posts a payload, retrying with exponential backoff on network
+// errors, 429 and 5xx responses.
+func deliverWebhook(ctx context.Context, delivery webhookDelivery) {
+	backoff := webhookBackoff
+
+	for attempt := 1; ; attempt++ {
+		retry, err := postWebhook(ctx, delivery)
+		if err == nil {
+			return
+		}
+
+		if !retry || attempt == webhookAttempts {
+			webhookLog.Warn("Delivery failed", "url", delivery.webhook.URL, "attempts", attempt, "error", err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// postWebhook sends one request. It returns whether a failed request may be retried.
+func postWebhook(ctx context.Context, delivery webhookDelivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.webhook.URL, bytes.NewReader(delivery.payload))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "nexus-open")
+	if delivery.webhook.Secret != "" {
+		secret, err := configuration.ResolveSecret(delivery.webhook.Secret)
+		if err != nil {
+			return false, err
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(delivery.payload)
+		req.Header.Set(webhookSignatureKey, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	default:
+		return false, fmt.Errorf("status %s", resp.Status)
+	}
+}
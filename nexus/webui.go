@@ -0,0 +1,64 @@
+package nexus
+
+import (
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// webUIMissing explains how to include the web UI in a build without it.
+const webUIMissing = "The web UI is not included in this build. Build the frontend with \"npm run build\" in frontend/ and rebuild the binary."
+
+// SetWebUI sets the built frontend served at / by the API server. fsys holds
+// index.html and its assets. It must be called before Run.
+func SetWebUI(fsys fs.FS) {
+	defaultNexus.webUI = fsys
+}
+
+// webUIHandler serves the web UI. Paths that match no file get index.html, so the
+// UI can route on the client; unknown API paths are answered with 404.
+func (n *Nexus) webUIHandler(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if n.webUI == nil {
+		http.Error(w, webUIMissing, http.StatusNotFound)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	if info, err := fs.Stat(n.webUI, name); err != nil || info.IsDir() {
+		// Missing assets are real 404s, anything else is a client side route
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = "index.html"
+	}
+
+	if _, err := fs.Stat(n.webUI, name); err != nil {
+		http.Error(w, webUIMissing, http.StatusNotFound)
+		return
+	}
+
+	// Hashed assets never change, index.html must be revalidated to pick them up
+	if strings.HasPrefix(name, "assets/") {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	http.ServeFileFS(w, r, n.webUI, name)
+}
@@ -0,0 +1,337 @@
+//go:build systray
+
+// The tray is only built with the systray build tag, as it needs cgo on macOS:
+//
+//	go build -tags systray
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log/slog"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"nexus-open/nexus"
+	"nexus-open/nexus/configuration"
+
+	"fyne.io/systray"
+)
+
+// Colors of the tray icon
+var (
+	trayConnectedColor    = color.RGBA{R: 0x33, G: 0xCC, B: 0x66, A: 255}
+	trayDisconnectedColor = color.RGBA{R: 0x88, G: 0x88, B: 0x88, A: 255}
+	trayPausedColor       = color.RGBA{R: 0xFF, G: 0xB0, B: 0x00, A: 255}
+)
+
+// trayBrightness are the brightness levels offered in the tray menu, in percent.
+var trayBrightness = []int{25, 50, 75, 100}
+
+// tray runs Nexus behind a status icon. Pausing stops the engine and releases the
+// device, resuming starts it again.
+type tray struct {
+	ctx context.Context // Ends the tray, e.g. on SIGTERM
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // Stops the engine, nil while paused
+	done   chan struct{}      // Closed once the engine stopped
+
+	pages      *systray.MenuItem
+	pageItems  []*systray.MenuItem // Reused as the configured pages change
+	pageNames  []string
+	themes     map[string]*systray.MenuItem
+	brightness map[int]*systray.MenuItem
+	pause      *systray.MenuItem
+}
+
+// runTray shows the tray icon and runs Nexus until Quit is chosen or ctx is done.
+func runTray(ctx context.Context) error {
+	t := &tray{
+		ctx:        ctx,
+		themes:     make(map[string]*systray.MenuItem),
+		brightness: make(map[int]*systray.MenuItem),
+	}
+	systray.Run(t.onReady, t.stop)
+	return nil
+}
+
+// onReady builds the menu and starts the engine.
+func (t *tray) onReady() {
+	systray.SetTitle("Nexus Open")
+	t.setStatus(nexus.Connected())
+
+	t.pages = systray.AddMenuItem("Page", "Switch the page shown on the display")
+	profiles := systray.AddMenuItem("Profile", "Apply a color theme")
+	for _, theme := range configuration.Themes {
+		item := profiles.AddSubMenuItemCheckbox(theme.Name, theme.Description, false)
+		t.themes[theme.Name] = item
+		go t.onClick(item, func() {
+			if err := nexus.ActivateTheme(theme.Name); err != nil {
+				slog.Warn("Failed to apply theme", "theme", theme.Name, "error", err)
+			}
+			t.refreshMenu()
+		})
+	}
+	brightness := systray.AddMenuItem("Brightness", "Dim the display")
+	for _, level := range trayBrightness {
+		item := brightness.AddSubMenuItemCheckbox(fmt.Sprintf("%d%%", level), "", level == nexus.Brightness())
+		t.brightness[level] = item
+		go t.onClick(item, func() {
+			if err := nexus.SetBrightness(level); err != nil {
+				slog.Warn("Failed to set brightness", "error", err)
+			}
+			t.refreshMenu()
+		})
+	}
+
+	systray.AddSeparator()
+	t.pause = systray.AddMenuItemCheckbox("Pause", "Stop updating the display and release the device", false)
+	go t.onClick(t.pause, t.togglePause)
+	quit := systray.AddMenuItem("Quit", "Quit Nexus Open")
+	go t.onClick(quit, systray.Quit)
+
+	go t.follow()
+	go func() {
+		<-t.ctx.Done()
+		systray.Quit()
+	}()
+
+	t.start()
+}
+
+// onClick calls fn whenever item is clicked.
+func (t *tray) onClick(item *systray.MenuItem, fn func()) {
+	for range item.ClickedCh {
+		fn()
+	}
+}
+
+// follow updates the icon and the menu on device, page and configuration events
+// until the tray ends.
+func (t *tray) follow() {
+	events := nexus.Events()
+
+	devices, unsubscribeDevices := events.Device.Subscribe(1)
+	defer unsubscribeDevices()
+	pages, unsubscribePages := events.Page.SubscribeLatest()
+	defer unsubscribePages()
+	configs, unsubscribeConfigs := events.Config.SubscribeLatest()
+	defer unsubscribeConfigs()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case event := <-devices:
+			t.setStatus(event.Connected)
+		case <-pages:
+			t.refreshMenu()
+		case <-configs:
+			t.refreshMenu()
+		}
+	}
+}
+
+// start runs the engine in the background. If it fails to start, the tray shows it
+// as paused.
+func (t *tray) start() {
+	ctx, cancel := context.WithCancel(t.ctx)
+	done := make(chan struct{})
+
+	t.mu.Lock()
+	t.cancel, t.done = cancel, done
+	t.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		err := nexus.Run(ctx)
+		if err == nil {
+			return
+		}
+		slog.Error("Nexus stopped", "error", err)
+
+		t.mu.Lock()
+		failed := t.done == done && t.cancel != nil
+		if failed {
+			t.cancel = nil
+		}
+		t.mu.Unlock()
+
+		if failed {
+			t.pause.Check()
+			t.setStatus(false)
+			systray.SetTooltip("Nexus Open: " + err.Error())
+		}
+	}()
+
+	// The pages are known once the configuration is loaded
+	go func() {
+		for range 10 {
+			select {
+			case <-done:
+				return
+			case <-time.After(500 * time.Millisecond):
+			}
+			if t.refreshMenu() {
+				return
+			}
+		}
+	}()
+}
+
+// stop stops the engine, if it runs, and waits until it released the device.
+func (t *tray) stop() {
+	t.mu.Lock()
+	cancel, done := t.cancel, t.done
+	t.cancel = nil
+	t.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// togglePause stops or restarts the engine.
+func (t *tray) togglePause() {
+	t.mu.Lock()
+	running := t.cancel != nil
+	t.mu.Unlock()
+
+	if running {
+		t.stop()
+		t.pause.Check()
+		t.setStatus(false)
+		return
+	}
+
+	t.pause.Uncheck()
+	t.start()
+}
+
+// setStatus shows whether the device is connected in the icon and tooltip.
+func (t *tray) setStatus(connected bool) {
+	t.mu.Lock()
+	paused := t.cancel == nil && t.done != nil
+	t.mu.Unlock()
+
+	switch {
+	case paused:
+		systray.SetIcon(trayIcon(trayPausedColor))
+		systray.SetTooltip("Nexus Open: paused")
+	case connected:
+		systray.SetIcon(trayIcon(trayConnectedColor))
+		systray.SetTooltip("Nexus Open: connected")
+	default:
+		systray.SetIcon(trayIcon(trayDisconnectedColor))
+		systray.SetTooltip("Nexus Open: disconnected")
+	}
+}
+
+// refreshMenu lists the available pages and checks the active page, theme and
+// brightness. It returns false while no configuration is loaded.
+func (t *tray) refreshMenu() bool {
+	cfg := nexus.GetConfig()
+	if cfg == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names, active := nexus.Pages()
+	if !slices.Equal(names, t.pageNames) {
+		t.pageNames = names
+		for i, name := range names {
+			if i == len(t.pageItems) {
+				item := t.pages.AddSubMenuItemCheckbox(name, "", false)
+				t.pageItems = append(t.pageItems, item)
+				go t.onClick(item, func() { t.selectPage(i) })
+			}
+			t.pageItems[i].SetTitle(name)
+			t.pageItems[i].Show()
+		}
+		for _, item := range t.pageItems[len(names):] {
+			item.Hide()
+		}
+	}
+	for i, name := range names {
+		setChecked(t.pageItems[i], name == active)
+	}
+
+	theme := cfg.ActiveTheme()
+	for name, item := range t.themes {
+		setChecked(item, name == theme)
+	}
+	for level, item := range t.brightness {
+		setChecked(item, level == nexus.Brightness())
+	}
+
+	return true
+}
+
+// selectPage activates the i-th page listed in the menu.
+func (t *tray) selectPage(i int) {
+	t.mu.Lock()
+	var name string
+	if i < len(t.pageNames) {
+		name = t.pageNames[i]
+	}
+	t.mu.Unlock()
+
+	if name == "" {
+		return
+	}
+	if err := nexus.SetPage(name); err != nil {
+		slog.Warn("Failed to switch page", "page", name, "error", err)
+	}
+}
+
+// setChecked checks or unchecks item.
+func setChecked(item *systray.MenuItem, checked bool) {
+	if checked {
+		item.Check()
+	} else {
+		item.Uncheck()
+	}
+}
+
+// trayIcon returns a round icon in the given color, as ICO on Windows and PNG
+// elsewhere.
+func trayIcon(c color.RGBA) []byte {
+	const size = 32
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := range size {
+		for x := range size {
+			dx, dy := float64(x)-size/2+0.5, float64(y)-size/2+0.5
+			if dx*dx+dy*dy <= (size/2-2)*(size/2-2) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	if runtime.GOOS != "windows" {
+		return buf.Bytes()
+	}
+
+	// An ICO file with a single PNG image: the header, the directory entry and the image
+	var ico bytes.Buffer
+	binary.Write(&ico, binary.LittleEndian, [3]uint16{0, 1, 1})
+	ico.Write([]byte{size, size, 0, 0})
+	binary.Write(&ico, binary.LittleEndian, [2]uint16{1, 32})
+	binary.Write(&ico, binary.LittleEndian, [2]uint32{uint32(buf.Len()), 22})
+	ico.Write(buf.Bytes())
+	return ico.Bytes()
+}
@@ -0,0 +1,13 @@
+//go:build !systray
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// runTray fails in builds without the systray build tag, see tray.go.
+func runTray(ctx context.Context) error {
+	return errors.New("built without tray support, rebuild with -tags systray")
+}